@@ -1,14 +1,32 @@
 package config
 
 import (
+	"bytes"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/joho/godotenv"
+	"github.com/pkg/errors"
 )
 
+// secretFilePrefix - префикс значения, указывающий, что значение нужно прочитать из файла.
+const secretFilePrefix = "file:"
+
+// interpolationRegex находит ссылки на переменные вида ${NAME}.
+var interpolationRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 // Load читает файл с расширением ".env" из path и устанавливает переменные окружения программы, исходя из содержимого .env файла.
 //
 // env файл содержит набор пар Ключ-Значение.
 //
 // Названия переменных окружения равны ключу, значения переменных окружения равны значению ключа из env-файла.
+// Переменные, уже заданные в окружении программы, не перезаписываются.
+//
+// Чтобы не хранить секреты (пароль БД, ключи) в файле конфигурации, значения поддерживают:
+//   - подстановку "${NAME}": значение переменной окружения NAME, а если ее нет - значение ключа NAME из env-файла;
+//   - чтение из файла "file:/path/to/secret": значением становится содержимое файла без завершающего перевода строки.
 //
 // Доступ к полученным переменным окружения осуществляется через метод os.GetEnv().
 //
@@ -17,12 +35,125 @@ import (
 //	-path: путь к файлу с конфигурацией (*.env).
 //
 // Возвращает:
-//   - error: ошибка, если что-то пошло не так.
+//   - error: ошибка, если что-то пошло не так, в том числе если подставляемая переменная или файл с секретом не найдены.
 func Load(path string) error {
-	err := godotenv.Load(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
+	// godotenv подставляет в "${NAME}" только ключи из самого файла, а отсутствующие заменяет пустой
+	// строкой. Экранируем ссылки, чтобы подстановка выполнялась в resolveValues с учетом окружения.
+	content = bytes.ReplaceAll(content, []byte("${"), []byte(`\${`))
+
+	values, err := godotenv.UnmarshalBytes(content)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		values[key] = strings.ReplaceAll(value, `\${`, "${")
+	}
+
+	resolvedValues, err := resolveValues(values)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range resolvedValues {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err = os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// resolveValues выполняет подстановку "${NAME}" и чтение секретов из файлов "file:/path"
+// для всех значений из env-файла.
+//
+// Возвращает ошибку со списком всех не найденных переменных и файлов.
+func resolveValues(values map[string]string) (map[string]string, error) {
+	resolver := &valueResolver{
+		values:   values,
+		resolved: make(map[string]string, len(values)),
+		visiting: make(map[string]bool),
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		if _, err := resolver.resolve(key); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Errorf("unable to resolve config values: %s", strings.Join(problems, "; "))
+	}
+
+	return resolver.resolved, nil
+}
+
+// valueResolver вычисляет значения ключей env-файла с учетом ссылок между ними.
+type valueResolver struct {
+	values   map[string]string
+	resolved map[string]string
+	visiting map[string]bool
+}
+
+// resolve возвращает итоговое значение ключа key из env-файла.
+func (r *valueResolver) resolve(key string) (string, error) {
+	if value, ok := r.resolved[key]; ok {
+		return value, nil
+	}
+	if r.visiting[key] {
+		return "", errors.Errorf("%s: cyclic reference", key)
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	var resolveErr error
+	value := interpolationRegex.ReplaceAllStringFunc(r.values[key], func(reference string) string {
+		name := interpolationRegex.FindStringSubmatch(reference)[1]
+
+		if envValue, ok := os.LookupEnv(name); ok {
+			return envValue
+		}
+
+		if _, ok := r.values[name]; ok {
+			fileValue, err := r.resolve(name)
+			if err != nil && resolveErr == nil {
+				resolveErr = err
+			}
+			return fileValue
+		}
+
+		if resolveErr == nil {
+			resolveErr = errors.Errorf("%s: variable %s is not set", key, name)
+		}
+		return ""
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	if strings.HasPrefix(value, secretFilePrefix) {
+		secretPath := strings.TrimPrefix(value, secretFilePrefix)
+		secret, err := os.ReadFile(secretPath) // #nosec G304 -- путь к секрету задается в конфиге
+		if err != nil {
+			return "", errors.Wrapf(err, "%s: unable to read secret file %s", key, secretPath)
+		}
+		value = strings.TrimRight(string(secret), "\r\n")
+	}
+
+	r.resolved[key] = value
+	return value, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	secretsDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(secretsDir, "jwt_secret"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "env interpolation",
+			content: "CFGTEST_DSN=postgres://app:${CFGTEST_PASSWORD}@db:5432/auth\n",
+			env:     map[string]string{"CFGTEST_PASSWORD": "p@ss"},
+			want:    map[string]string{"CFGTEST_DSN": "postgres://app:p@ss@db:5432/auth"},
+		},
+		{
+			name:    "file key interpolation",
+			content: "CFGTEST_HOST=db\nCFGTEST_DSN=postgres://${CFGTEST_HOST}:5432/auth\n",
+			want:    map[string]string{"CFGTEST_HOST": "db", "CFGTEST_DSN": "postgres://db:5432/auth"},
+		},
+		{
+			name:    "env wins over file key",
+			content: "CFGTEST_HOST=db\nCFGTEST_DSN=postgres://${CFGTEST_HOST}:5432/auth\n",
+			env:     map[string]string{"CFGTEST_HOST": "replica"},
+			want:    map[string]string{"CFGTEST_HOST": "replica", "CFGTEST_DSN": "postgres://replica:5432/auth"},
+		},
+		{
+			name:    "file secret",
+			content: "CFGTEST_JWT_SECRET=file:" + filepath.Join(secretsDir, "jwt_secret") + "\n",
+			want:    map[string]string{"CFGTEST_JWT_SECRET": "s3cret"},
+		},
+		{
+			name:    "file secret with interpolated path",
+			content: "CFGTEST_JWT_SECRET=file:${CFGTEST_SECRETS_DIR}/jwt_secret\n",
+			env:     map[string]string{"CFGTEST_SECRETS_DIR": secretsDir},
+			want:    map[string]string{"CFGTEST_JWT_SECRET": "s3cret"},
+		},
+		{
+			name:    "missing variable",
+			content: "CFGTEST_HOST=db\nCFGTEST_DSN=postgres://app:${CFGTEST_PASSWORD}@db:5432/auth\n",
+			wantErr: "CFGTEST_DSN: variable CFGTEST_PASSWORD is not set",
+		},
+		{
+			name:    "missing secret file",
+			content: "CFGTEST_JWT_SECRET=file:" + filepath.Join(secretsDir, "missing") + "\n",
+			wantErr: "CFGTEST_JWT_SECRET: unable to read secret file",
+		},
+		{
+			name:    "cyclic reference",
+			content: "CFGTEST_HOST=${CFGTEST_DSN}\nCFGTEST_DSN=${CFGTEST_HOST}\n",
+			wantErr: "cyclic reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Load устанавливает переменные окружения: t.Setenv восстанавливает их после теста
+			for _, key := range []string{"CFGTEST_DSN", "CFGTEST_HOST", "CFGTEST_PASSWORD", "CFGTEST_JWT_SECRET", "CFGTEST_SECRETS_DIR"} {
+				t.Setenv(key, "")
+				if err := os.Unsetenv(key); err != nil {
+					t.Fatalf("Unsetenv() error = %v", err)
+				}
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			path := filepath.Join(t.TempDir(), "test.env")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			err := Load(path)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, ok := os.LookupEnv("CFGTEST_HOST"); ok {
+					t.Error("Load() set variables despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			for key, want := range tt.want {
+				if got := os.Getenv(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
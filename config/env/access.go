@@ -0,0 +1,51 @@
+package env
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	accessPolicyCacheTTLEnvName = "ACCESS_POLICY_CACHE_TTL"
+)
+
+// AccessConfig - интерфейс конфига проверки доступа к методам.
+//
+// Методы:
+//   - PolicyCacheTTL() time.Duration: время, в течение которого правила доступа, прочитанные из БД,
+//     используются без повторного чтения.
+type AccessConfig interface {
+	PolicyCacheTTL() time.Duration
+}
+
+// accessConfig - структура конфига проверки доступа, реализующая интерфейс AccessConfig.
+type accessConfig struct {
+	policyCacheTTL time.Duration
+}
+
+// NewAccessConfig - метод создания конфига проверки доступа, реализующего интерфейс AccessConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Время задается в формате time.ParseDuration ("30s", "1m"), по умолчанию - 1 минута.
+// Правила, измененные через АПИ этого экземпляра сервера, применяются сразу, измененные
+// другими экземплярами - не позже чем через это время.
+//
+// Возвращает:
+//   - AccessConfig: созданный объект конфига проверки доступа.
+//   - error: ошибка, если что-то пошло не так.
+func NewAccessConfig() (AccessConfig, error) {
+	policyCacheTTL, err := time.ParseDuration(getenv(accessPolicyCacheTTLEnvName))
+	if err != nil || policyCacheTTL < 0 {
+		return nil, errors.New("access policy cache ttl is invalid")
+	}
+
+	return &accessConfig{
+		policyCacheTTL: policyCacheTTL,
+	}, nil
+}
+
+// PolicyCacheTTL - метод возвращает время кеширования правил доступа.
+func (cfg *accessConfig) PolicyCacheTTL() time.Duration {
+	return cfg.policyCacheTTL
+}
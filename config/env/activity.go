@@ -0,0 +1,48 @@
+package env
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	activityFlushIntervalEnvName = "ACTIVITY_FLUSH_INTERVAL"
+)
+
+// ActivityConfig - интерфейс конфига учета активности пользователей (last_seen).
+//
+// Методы:
+//   - FlushInterval() time.Duration: интервал, с которым накопленная активность записывается в БД.
+type ActivityConfig interface {
+	FlushInterval() time.Duration
+}
+
+// activityConfig - структура конфига учета активности, реализующая интерфейс ActivityConfig.
+type activityConfig struct {
+	flushInterval time.Duration
+}
+
+// NewActivityConfig - метод создания конфига учета активности, реализующего интерфейс ActivityConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Интервал задается в формате time.ParseDuration ("10s", "1m"), по умолчанию - 10 секунд.
+//
+// Возвращает:
+//   - ActivityConfig: созданный объект конфига учета активности.
+//   - error: ошибка, если что-то пошло не так.
+func NewActivityConfig() (ActivityConfig, error) {
+	flushInterval, err := time.ParseDuration(getenv(activityFlushIntervalEnvName))
+	if err != nil || flushInterval <= 0 {
+		return nil, errors.New("activity flush interval is invalid")
+	}
+
+	return &activityConfig{
+		flushInterval: flushInterval,
+	}, nil
+}
+
+// FlushInterval - метод возвращает интервал записи накопленной активности в БД.
+func (cfg *activityConfig) FlushInterval() time.Duration {
+	return cfg.flushInterval
+}
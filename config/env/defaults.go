@@ -0,0 +1,72 @@
+package env
+
+import "os"
+
+// defaults - значения необязательных переменных окружения по умолчанию в том же виде, в котором
+// они задаются в окружении. Значение по умолчанию действует, если переменная не задана или пустая.
+//
+// В таблицу не входят обязательные переменные, переменные, которые по умолчанию не заданы
+// (например, пути к файлам и PG_HEALTH_CHECK_PERIOD), и перекрытие ключей JWT: по умолчанию
+// оно равно времени жизни токена.
+var defaults = map[string]string{
+	accessPolicyCacheTTLEnvName: "1m",
+
+	activityFlushIntervalEnvName: "10s",
+
+	emailVerificationTokenTTLEnvName:         "24h",
+	emailVerificationRequiredForLoginEnvName: "false",
+
+	grpcTimestampPrecisionEnvName: "ns",
+	grpcInsecureEnvName:           "false",
+
+	jwtAccessTokenTTLEnvName:        "15m",
+	jwtRefreshTokenTTLEnvName:       "720h",
+	jwtImpersonationTokenTTLEnvName: "10m",
+	jwtReauthTokenTTLEnvName:        "5m",
+	jwtKeysReloadIntervalEnvName:    "1m",
+
+	lockoutUserMaxFailuresEnvName: "5",
+	lockoutIPMaxFailuresEnvName:   "20",
+	lockoutWindowEnvName:          "15m",
+	lockoutDurationEnvName:        "15m",
+	lockoutMaxDurationEnvName:     "24h",
+
+	mailSenderEnvName: mailSenderLog,
+
+	passwordHashAlgorithmEnvName:       "bcrypt",
+	passwordBcryptCostEnvName:          "10",
+	passwordArgon2idMemoryEnvName:      "65536",
+	passwordArgon2idIterationsEnvName:  "3",
+	passwordArgon2idParallelismEnvName: "2",
+
+	passwordMinLengthEnvName:     "8",
+	passwordRequireLowerEnvName:  "false",
+	passwordRequireUpperEnvName:  "false",
+	passwordRequireDigitEnvName:  "false",
+	passwordRequireSymbolEnvName: "false",
+	passwordDenyCommonEnvName:    "true",
+
+	passwordResetTokenTTLEnvName: "1h",
+
+	logLevelEnvName:           "none",
+	deadlockMaxRetriesEnvName: "3",
+	connectModeEnvName:        connectModeEager,
+	connectMaxRetriesEnvName:  "5",
+
+	totpIssuerEnvName: "auth",
+
+	validationWarningsAsErrorsEnvName:       "false",
+	validationRejectPasswordIdentityEnvName: "true",
+}
+
+// getenv возвращает значение переменной окружения envName либо ее значение по умолчанию из defaults.
+//
+// Конструкторы конфигов читают необязательные переменные только через getenv, поэтому значение
+// по умолчанию меняется в одном месте.
+func getenv(envName string) string {
+	if value := os.Getenv(envName); len(value) > 0 {
+		return value
+	}
+
+	return defaults[envName]
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	emailVerificationURLEnvName              = "EMAIL_VERIFICATION_URL"
+	emailVerificationTokenTTLEnvName         = "EMAIL_VERIFICATION_TOKEN_TTL"
+	emailVerificationRequiredForLoginEnvName = "EMAIL_VERIFICATION_REQUIRED_FOR_LOGIN"
+)
+
+// EmailVerificationConfig - интерфейс конфига подтверждения email.
+//
+// Методы:
+//   - URL() *url.URL: адрес страницы подтверждения email, на которую ведет ссылка из письма.
+//     Токен добавляется к адресу параметром "token".
+//   - TokenTTL() time.Duration: время жизни токена подтверждения email.
+//   - RequiredForLogin() bool: true, если пользователи с неподтвержденным email не могут войти.
+type EmailVerificationConfig interface {
+	URL() *url.URL
+	TokenTTL() time.Duration
+	RequiredForLogin() bool
+}
+
+// emailVerificationConfig - структура конфига подтверждения email, реализующая интерфейс EmailVerificationConfig.
+type emailVerificationConfig struct {
+	url              *url.URL
+	tokenTTL         time.Duration
+	requiredForLogin bool
+}
+
+// NewEmailVerificationConfig - метод создания конфига подтверждения email, реализующего интерфейс EmailVerificationConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Адрес страницы подтверждения обязателен и должен быть абсолютным. Время жизни токена задается
+// в формате time.ParseDuration ("12h", "48h"), по умолчанию - 24 часа. По умолчанию пользователи
+// с неподтвержденным email могут войти.
+//
+// Возвращает:
+//   - EmailVerificationConfig: созданный объект конфига подтверждения email.
+//   - error: ошибка, если что-то пошло не так.
+func NewEmailVerificationConfig() (EmailVerificationConfig, error) {
+	rawURL := os.Getenv(emailVerificationURLEnvName)
+	if len(rawURL) == 0 {
+		return nil, errors.New("email verification url not found")
+	}
+
+	verificationURL, err := url.Parse(rawURL)
+	if err != nil || !verificationURL.IsAbs() || len(verificationURL.Host) == 0 {
+		return nil, errors.New("email verification url is invalid")
+	}
+
+	tokenTTL, err := time.ParseDuration(getenv(emailVerificationTokenTTLEnvName))
+	if err != nil || tokenTTL <= 0 {
+		return nil, errors.New("email verification token ttl is invalid")
+	}
+
+	requiredForLogin, err := strconv.ParseBool(getenv(emailVerificationRequiredForLoginEnvName))
+	if err != nil {
+		return nil, errors.New("email verification required for login is invalid")
+	}
+
+	return &emailVerificationConfig{
+		url:              verificationURL,
+		tokenTTL:         tokenTTL,
+		requiredForLogin: requiredForLogin,
+	}, nil
+}
+
+// URL - метод возвращает копию адреса страницы подтверждения email.
+func (cfg *emailVerificationConfig) URL() *url.URL {
+	verificationURL := *cfg.url
+	return &verificationURL
+}
+
+// TokenTTL - метод возвращает время жизни токена подтверждения email.
+func (cfg *emailVerificationConfig) TokenTTL() time.Duration {
+	return cfg.tokenTTL
+}
+
+// RequiredForLogin - метод возвращает true, если пользователи с неподтвержденным email не могут войти.
+func (cfg *emailVerificationConfig) RequiredForLogin() bool {
+	return cfg.requiredForLogin
+}
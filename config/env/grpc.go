@@ -3,33 +3,67 @@ package env
 import (
 	"net"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 const (
-	grpcHostEnvName = "GRPC_HOST"
-	grpcPortEnvName = "GRPC_PORT"
+	grpcHostEnvName               = "GRPC_HOST"
+	grpcPortEnvName               = "GRPC_PORT"
+	grpcTimestampPrecisionEnvName = "GRPC_TIMESTAMP_PRECISION"
+	grpcTLSCertFileEnvName        = "GRPC_TLS_CERT_FILE"
+	grpcTLSKeyFileEnvName         = "GRPC_TLS_KEY_FILE"
+	grpcTLSClientCAFileEnvName    = "GRPC_TLS_CLIENT_CA_FILE"
+	grpcInsecureEnvName           = "GRPC_INSECURE"
 )
 
+// timestampPrecisions - допустимые значения GRPC_TIMESTAMP_PRECISION.
+var timestampPrecisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
 // GRPCConfig - интерфейс конфига для инициализации GRPC-сервера.
 //
 // Методы:
 //   - Address() string: адрес, на котором развернут GRPC-сервер в формате "хост:порт".
+//   - TimestampPrecision() time.Duration: точность, до которой округляются временные метки в ответах.
+//   - TLSCertFile() string, TLSKeyFile() string: пути к сертификату и закрытому ключу сервера в формате PEM.
+//   - TLSClientCAFile() string: путь к сертификатам УЦ, которыми подписаны сертификаты клиентов
+//     (пустая строка - сертификат клиента не требуется).
+//   - Insecure() bool: true, если сервер принимает соединения без TLS (для локального запуска).
 type GRPCConfig interface {
 	Address() string
+	TimestampPrecision() time.Duration
+	TLSCertFile() string
+	TLSKeyFile() string
+	TLSClientCAFile() string
+	Insecure() bool
 }
 
 // grpcConfig - структура конфига GRPC-сервера, реализующая интерфейс GRPCConfig.
 type grpcConfig struct {
-	host string
-	port string
+	host               string
+	port               string
+	timestampPrecision time.Duration
+	tlsCertFile        string
+	tlsKeyFile         string
+	tlsClientCAFile    string
+	insecure           bool
 }
 
 // NewGRPCConfig - Метод для создания объекта конфига GRPC-сервера, реализующего
 // интерфейс GRPCConfig.
 // Параметры конфига берутся из переменных окружения программы.
 //
+// Сервер принимает только TLS-соединения: сертификат сервера (GRPC_TLS_CERT_FILE) и его ключ
+// (GRPC_TLS_KEY_FILE) обязательны. Если заданы сертификаты УЦ клиентов (GRPC_TLS_CLIENT_CA_FILE),
+// клиенты должны предъявить сертификат, подписанный ими (взаимный TLS). Для локального запуска
+// TLS можно отключить (GRPC_INSECURE=true), тогда пути к сертификатам не задаются.
+//
 // Возвращает:
 //   - GRPCConfig: созданный объект конфига GRPC-сервера.
 //   - error: ошибка, если что-то пошло не так.
@@ -44,9 +78,37 @@ func NewGRPCConfig() (GRPCConfig, error) {
 		return nil, errors.New("grpc port not found")
 	}
 
+	// Точность временных меток необязательна, по умолчанию - наносекунды (без округления)
+	timestampPrecision, ok := timestampPrecisions[getenv(grpcTimestampPrecisionEnvName)]
+	if !ok {
+		return nil, errors.New("grpc timestamp precision is invalid")
+	}
+
+	tlsCertFile := os.Getenv(grpcTLSCertFileEnvName)
+	tlsKeyFile := os.Getenv(grpcTLSKeyFileEnvName)
+	tlsClientCAFile := os.Getenv(grpcTLSClientCAFileEnvName)
+
+	insecure, err := boolFromEnv(grpcInsecureEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	if insecure {
+		if len(tlsCertFile) > 0 || len(tlsKeyFile) > 0 || len(tlsClientCAFile) > 0 {
+			return nil, errors.New("grpc tls files must not be set in insecure mode")
+		}
+	} else if len(tlsCertFile) == 0 || len(tlsKeyFile) == 0 {
+		return nil, errors.New("grpc tls cert file and key file not found")
+	}
+
 	return &grpcConfig{
-		host: host,
-		port: port,
+		host:               host,
+		port:               port,
+		timestampPrecision: timestampPrecision,
+		tlsCertFile:        tlsCertFile,
+		tlsKeyFile:         tlsKeyFile,
+		tlsClientCAFile:    tlsClientCAFile,
+		insecure:           insecure,
 	}, nil
 }
 
@@ -55,3 +117,29 @@ func NewGRPCConfig() (GRPCConfig, error) {
 func (cfg *grpcConfig) Address() string {
 	return net.JoinHostPort(cfg.host, cfg.port)
 }
+
+// TimestampPrecision - метод возвращает точность, до которой округляются (вниз) временные
+// метки в ответах GRPC-сервера: time.Nanosecond, time.Microsecond, time.Millisecond или time.Second.
+func (cfg *grpcConfig) TimestampPrecision() time.Duration {
+	return cfg.timestampPrecision
+}
+
+// TLSCertFile - метод возвращает путь к сертификату GRPC-сервера.
+func (cfg *grpcConfig) TLSCertFile() string {
+	return cfg.tlsCertFile
+}
+
+// TLSKeyFile - метод возвращает путь к закрытому ключу GRPC-сервера.
+func (cfg *grpcConfig) TLSKeyFile() string {
+	return cfg.tlsKeyFile
+}
+
+// TLSClientCAFile - метод возвращает путь к сертификатам УЦ, которыми подписаны сертификаты клиентов.
+func (cfg *grpcConfig) TLSClientCAFile() string {
+	return cfg.tlsClientCAFile
+}
+
+// Insecure - метод возвращает true, если GRPC-сервер принимает соединения без TLS.
+func (cfg *grpcConfig) Insecure() bool {
+	return cfg.insecure
+}
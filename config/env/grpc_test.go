@@ -0,0 +1,41 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewGRPCConfigTimestampPrecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "not set", value: "", want: time.Nanosecond},
+		{name: "microseconds", value: "us", want: time.Microsecond},
+		{name: "milliseconds", value: "ms", want: time.Millisecond},
+		{name: "seconds", value: "s", want: time.Second},
+		{name: "invalid", value: "1ms", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(grpcHostEnvName, "localhost")
+			t.Setenv(grpcPortEnvName, "50051")
+			t.Setenv(grpcInsecureEnvName, "true")
+			t.Setenv(grpcTLSCertFileEnvName, "")
+			t.Setenv(grpcTLSKeyFileEnvName, "")
+			t.Setenv(grpcTLSClientCAFileEnvName, "")
+			t.Setenv(grpcTimestampPrecisionEnvName, tt.value)
+
+			cfg, err := NewGRPCConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGRPCConfig() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && cfg.TimestampPrecision() != tt.want {
+				t.Errorf("TimestampPrecision() = %s, want %s", cfg.TimestampPrecision(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package env
+
+import (
+	"net"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	httpHostEnvName = "HTTP_HOST"
+	httpPortEnvName = "HTTP_PORT"
+)
+
+// HTTPConfig - интерфейс конфига встроенного HTTP-сервера, на котором публикуются
+// OIDC discovery и JWKS.
+//
+// Методы:
+//   - Address() string: адрес HTTP-сервера в формате "хост:порт".
+type HTTPConfig interface {
+	Address() string
+}
+
+// httpConfig - структура конфига HTTP-сервера, реализующая интерфейс HTTPConfig.
+type httpConfig struct {
+	host string
+	port string
+}
+
+// NewHTTPConfig - метод создания конфига HTTP-сервера, реализующего интерфейс HTTPConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Возвращает:
+//   - HTTPConfig: созданный объект конфига HTTP-сервера.
+//   - error: ошибка, если что-то пошло не так.
+func NewHTTPConfig() (HTTPConfig, error) {
+	host := os.Getenv(httpHostEnvName)
+	if len(host) == 0 {
+		return nil, errors.New("http host not found")
+	}
+
+	port := os.Getenv(httpPortEnvName)
+	if len(port) == 0 {
+		return nil, errors.New("http port not found")
+	}
+
+	return &httpConfig{
+		host: host,
+		port: port,
+	}, nil
+}
+
+// Address - метод возвращает адрес HTTP-сервера в формате "хост:порт".
+func (cfg *httpConfig) Address() string {
+	return net.JoinHostPort(cfg.host, cfg.port)
+}
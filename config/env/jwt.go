@@ -0,0 +1,369 @@
+package env
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	jwtAccessTokenSecretEnvName     = "JWT_ACCESS_TOKEN_SECRET"
+	jwtAccessTokenTTLEnvName        = "JWT_ACCESS_TOKEN_TTL"
+	jwtRefreshTokenSecretEnvName    = "JWT_REFRESH_TOKEN_SECRET"
+	jwtRefreshTokenTTLEnvName       = "JWT_REFRESH_TOKEN_TTL"
+	jwtImpersonationTokenTTLEnvName = "JWT_IMPERSONATION_TOKEN_TTL"
+	jwtReauthTokenTTLEnvName        = "JWT_REAUTH_TOKEN_TTL"
+	jwtIssuerEnvName                = "JWT_ISSUER"
+	jwtAudienceEnvName              = "JWT_AUDIENCE"
+
+	jwtAccessTokenPrivateKeyEnvName  = "JWT_ACCESS_TOKEN_PRIVATE_KEY"
+	jwtRefreshTokenPrivateKeyEnvName = "JWT_REFRESH_TOKEN_PRIVATE_KEY"
+
+	jwtAccessTokenKeysFileEnvName    = "JWT_ACCESS_TOKEN_KEYS_FILE"
+	jwtAccessTokenKeyOverlapEnvName  = "JWT_ACCESS_TOKEN_KEY_OVERLAP"
+	jwtRefreshTokenKeysFileEnvName   = "JWT_REFRESH_TOKEN_KEYS_FILE"
+	jwtRefreshTokenKeyOverlapEnvName = "JWT_REFRESH_TOKEN_KEY_OVERLAP"
+	jwtKeysReloadIntervalEnvName     = "JWT_KEYS_RELOAD_INTERVAL"
+
+	// minJWTSecretLength - минимальная длина секрета для подписи токенов алгоритмом HS256 (256 бит).
+	minJWTSecretLength = 32
+)
+
+// JWTConfig - интерфейс конфига выпуска JWT-токенов.
+//
+// Методы:
+//   - AccessTokenSecret() []byte: секрет для подписи access-токенов алгоритмом HS256, если не заданы
+//     файл ключей и закрытый ключ.
+//   - AccessTokenPrivateKey() []byte: закрытый ключ RSA или Ed25519 в формате PEM для подписи
+//     access-токенов, если не задан файл ключей (пустой - токены подписываются секретом).
+//   - AccessTokenTTL() time.Duration: время жизни access-токена.
+//   - AccessTokenKeysFile() string: путь к файлу ключей подписи access-токенов (пустая строка -
+//     токены подписываются одним ключом AccessTokenPrivateKey или AccessTokenSecret).
+//   - AccessTokenKeyOverlap() time.Duration: сколько предыдущий ключ access-токенов принимается
+//     при проверке после активации следующего.
+//   - RefreshTokenSecret() []byte, RefreshTokenPrivateKey() []byte, RefreshTokenTTL() time.Duration,
+//     RefreshTokenKeysFile() string, RefreshTokenKeyOverlap() time.Duration: то же для refresh-токенов.
+//   - ImpersonationTokenTTL() time.Duration: время жизни access-токена, с которым администратор
+//     действует от имени пользователя.
+//   - ReauthTokenTTL() time.Duration: время жизни токена повторной аутентификации, который выпускается
+//     после повторной проверки пароля вошедшего пользователя.
+//   - KeysReloadInterval() time.Duration: интервал перечитывания файлов ключей.
+//   - Issuer() string: URL издателя токенов (claim "iss"), по нему публикуется OIDC discovery.
+//   - Audience() []string: получатели токенов (claim "aud").
+type JWTConfig interface {
+	AccessTokenSecret() []byte
+	AccessTokenPrivateKey() []byte
+	AccessTokenTTL() time.Duration
+	AccessTokenKeysFile() string
+	AccessTokenKeyOverlap() time.Duration
+	RefreshTokenSecret() []byte
+	RefreshTokenPrivateKey() []byte
+	RefreshTokenTTL() time.Duration
+	RefreshTokenKeysFile() string
+	RefreshTokenKeyOverlap() time.Duration
+	ImpersonationTokenTTL() time.Duration
+	ReauthTokenTTL() time.Duration
+	KeysReloadInterval() time.Duration
+	Issuer() string
+	Audience() []string
+}
+
+// jwtConfig - структура конфига выпуска JWT-токенов, реализующая интерфейс JWTConfig.
+type jwtConfig struct {
+	accessTokenSecret      []byte
+	accessTokenPrivateKey  []byte
+	accessTokenTTL         time.Duration
+	accessTokenKeysFile    string
+	accessTokenKeyOverlap  time.Duration
+	refreshTokenSecret     []byte
+	refreshTokenPrivateKey []byte
+	refreshTokenTTL        time.Duration
+	refreshTokenKeysFile   string
+	refreshTokenKeyOverlap time.Duration
+	impersonationTokenTTL  time.Duration
+	reauthTokenTTL         time.Duration
+	keysReloadInterval     time.Duration
+	issuer                 string
+	audience               []string
+}
+
+// NewJWTConfig - метод создания конфига выпуска JWT-токенов, реализующего интерфейс JWTConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Ключи подписи задаются файлом ключей (JWT_*_TOKEN_KEYS_FILE), в котором можно описать несколько
+// ключей с "kid" и временем активации для плановой ротации, либо одним ключом: закрытым ключом RSA
+// или Ed25519 в формате PEM (JWT_*_TOKEN_PRIVATE_KEY) или секретом HS256 (JWT_*_TOKEN_SECRET).
+// Закрытым ключом токены подписываются асимметрично (RS256 или EdDSA), и другие сервисы проверяют
+// их по открытому ключу из JWKS без общего секрета. Закрытый ключ и секрет взаимоисключающие.
+// Секрет обязателен, если не заданы ни файл ключей, ни закрытый ключ, и должен быть не короче 32 байт.
+// Секрет, закрытый ключ и файл ключей должны отличаться от заданных для другого вида токенов, чтобы
+// access-токен нельзя было использовать вместо refresh-токена. Совпадение ключей внутри файлов
+// проверяется при их чтении.
+//
+// Время жизни токенов задается в формате time.ParseDuration ("15m", "720h"), по умолчанию -
+// 15 минут для access-токена и 30 дней для refresh-токена. Перекрытие ключей (JWT_*_TOKEN_KEY_OVERLAP)
+// по умолчанию равно времени жизни токена и не может быть меньше него, иначе токены, подписанные
+// предыдущим ключом, перестанут приниматься до истечения. Файлы ключей перечитываются раз
+// в JWT_KEYS_RELOAD_INTERVAL (по умолчанию - раз в минуту). Время жизни токена, выпущенного
+// администратору от имени пользователя (JWT_IMPERSONATION_TOKEN_TTL), по умолчанию - 10 минут
+// и не может превышать время жизни access-токена. Время жизни токена повторной аутентификации
+// (JWT_REAUTH_TOKEN_TTL) по умолчанию - 5 минут и тоже не может превышать время жизни access-токена.
+//
+// Издатель (JWT_ISSUER) обязателен и должен быть абсолютным http(s) URL без query и fragment.
+// Получатели (JWT_AUDIENCE) обязательны и перечисляются через запятую.
+//
+// Возвращает:
+//   - JWTConfig: созданный объект конфига выпуска JWT-токенов.
+//   - error: ошибка, если что-то пошло не так.
+func NewJWTConfig() (JWTConfig, error) {
+	accessTokenKeysFile := os.Getenv(jwtAccessTokenKeysFileEnvName)
+	accessTokenPrivateKey := os.Getenv(jwtAccessTokenPrivateKeyEnvName)
+	accessTokenSecret, err := jwtSecretFromEnv(jwtAccessTokenSecretEnvName, "access",
+		len(accessTokenKeysFile) == 0 && len(accessTokenPrivateKey) == 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(accessTokenPrivateKey) > 0 && len(accessTokenSecret) > 0 {
+		return nil, errors.New("only one of jwt access token secret and private key must be set")
+	}
+
+	accessTokenTTL, err := jwtTTLFromEnv(jwtAccessTokenTTLEnvName, "access")
+	if err != nil {
+		return nil, err
+	}
+
+	accessTokenKeyOverlap, err := jwtKeyOverlapFromEnv(jwtAccessTokenKeyOverlapEnvName, "access", accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenKeysFile := os.Getenv(jwtRefreshTokenKeysFileEnvName)
+	refreshTokenPrivateKey := os.Getenv(jwtRefreshTokenPrivateKeyEnvName)
+	refreshTokenSecret, err := jwtSecretFromEnv(jwtRefreshTokenSecretEnvName, "refresh",
+		len(refreshTokenKeysFile) == 0 && len(refreshTokenPrivateKey) == 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(refreshTokenPrivateKey) > 0 && len(refreshTokenSecret) > 0 {
+		return nil, errors.New("only one of jwt refresh token secret and private key must be set")
+	}
+	if len(refreshTokenPrivateKey) > 0 && refreshTokenPrivateKey == accessTokenPrivateKey {
+		return nil, errors.New("jwt refresh token private key must differ from access token private key")
+	}
+	if len(refreshTokenSecret) > 0 && refreshTokenSecret == accessTokenSecret {
+		return nil, errors.New("jwt refresh token secret must differ from access token secret")
+	}
+	if len(refreshTokenKeysFile) > 0 && len(accessTokenKeysFile) > 0 &&
+		filepath.Clean(refreshTokenKeysFile) == filepath.Clean(accessTokenKeysFile) {
+		return nil, errors.New("jwt refresh token keys file must differ from access token keys file")
+	}
+
+	refreshTokenTTL, err := jwtTTLFromEnv(jwtRefreshTokenTTLEnvName, "refresh")
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenKeyOverlap, err := jwtKeyOverlapFromEnv(jwtRefreshTokenKeyOverlapEnvName, "refresh", refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonationTokenTTL, err := jwtTTLFromEnv(jwtImpersonationTokenTTLEnvName, "impersonation")
+	if err != nil {
+		return nil, err
+	}
+	if impersonationTokenTTL > accessTokenTTL {
+		return nil, errors.New("jwt impersonation token ttl must not exceed access token ttl")
+	}
+
+	reauthTokenTTL, err := jwtTTLFromEnv(jwtReauthTokenTTLEnvName, "reauth")
+	if err != nil {
+		return nil, err
+	}
+	if reauthTokenTTL > accessTokenTTL {
+		return nil, errors.New("jwt reauth token ttl must not exceed access token ttl")
+	}
+
+	keysReloadInterval, err := positiveDurationFromEnv(jwtKeysReloadIntervalEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := jwtIssuerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	var audience []string
+	for _, value := range strings.Split(os.Getenv(jwtAudienceEnvName), ",") {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			audience = append(audience, value)
+		}
+	}
+	if len(audience) == 0 {
+		return nil, errors.New("jwt audience not found")
+	}
+
+	return &jwtConfig{
+		accessTokenSecret:      []byte(accessTokenSecret),
+		accessTokenPrivateKey:  []byte(accessTokenPrivateKey),
+		accessTokenTTL:         accessTokenTTL,
+		accessTokenKeysFile:    accessTokenKeysFile,
+		accessTokenKeyOverlap:  accessTokenKeyOverlap,
+		refreshTokenSecret:     []byte(refreshTokenSecret),
+		refreshTokenPrivateKey: []byte(refreshTokenPrivateKey),
+		refreshTokenTTL:        refreshTokenTTL,
+		refreshTokenKeysFile:   refreshTokenKeysFile,
+		refreshTokenKeyOverlap: refreshTokenKeyOverlap,
+		impersonationTokenTTL:  impersonationTokenTTL,
+		reauthTokenTTL:         reauthTokenTTL,
+		keysReloadInterval:     keysReloadInterval,
+		issuer:                 issuer,
+		audience:               audience,
+	}, nil
+}
+
+// jwtIssuerFromEnv читает обязательный URL издателя токенов из переменной JWT_ISSUER.
+//
+// Завершающий "/" отбрасывается, чтобы issuer в токенах совпадал с issuer из OIDC discovery.
+func jwtIssuerFromEnv() (string, error) {
+	issuer := strings.TrimSuffix(os.Getenv(jwtIssuerEnvName), "/")
+	if len(issuer) == 0 {
+		return "", errors.New("jwt issuer not found")
+	}
+
+	issuerURL, err := url.Parse(issuer)
+	if err != nil || (issuerURL.Scheme != "https" && issuerURL.Scheme != "http") || len(issuerURL.Host) == 0 ||
+		len(issuerURL.RawQuery) > 0 || len(issuerURL.Fragment) > 0 {
+		return "", errors.New("jwt issuer must be an absolute http(s) URL without query and fragment")
+	}
+
+	return issuer, nil
+}
+
+// jwtSecretFromEnv читает секрет для подписи токенов вида tokenKind из переменной envName.
+// Если секрет не обязателен (required == false) и не задан, возвращает пустую строку.
+func jwtSecretFromEnv(envName, tokenKind string, required bool) (string, error) {
+	secret := os.Getenv(envName)
+	if len(secret) == 0 {
+		if !required {
+			return "", nil
+		}
+		return "", errors.Errorf("jwt %s token secret not found", tokenKind)
+	}
+	if len(secret) < minJWTSecretLength {
+		return "", errors.Errorf("jwt %s token secret must be at least %d bytes long", tokenKind, minJWTSecretLength)
+	}
+
+	return secret, nil
+}
+
+// jwtTTLFromEnv читает необязательное время жизни токенов вида tokenKind из переменной envName.
+func jwtTTLFromEnv(envName, tokenKind string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(getenv(envName))
+	if err != nil || ttl <= 0 {
+		return 0, errors.Errorf("jwt %s token ttl is invalid", tokenKind)
+	}
+
+	return ttl, nil
+}
+
+// jwtKeyOverlapFromEnv читает необязательное перекрытие ключей токенов вида tokenKind из переменной
+// envName. По умолчанию перекрытие равно времени жизни токена ttl и не может быть меньше него.
+func jwtKeyOverlapFromEnv(envName, tokenKind string, ttl time.Duration) (time.Duration, error) {
+	if len(getenv(envName)) == 0 {
+		return ttl, nil
+	}
+
+	overlap, err := positiveDurationFromEnv(envName)
+	if err != nil {
+		return 0, err
+	}
+	if overlap < ttl {
+		return 0, errors.Errorf("jwt %s token key overlap must not be less than token ttl", tokenKind)
+	}
+
+	return overlap, nil
+}
+
+// AccessTokenSecret - метод возвращает секрет для подписи access-токенов.
+func (cfg *jwtConfig) AccessTokenSecret() []byte {
+	return cfg.accessTokenSecret
+}
+
+// AccessTokenPrivateKey - метод возвращает закрытый ключ в формате PEM для подписи access-токенов.
+func (cfg *jwtConfig) AccessTokenPrivateKey() []byte {
+	return cfg.accessTokenPrivateKey
+}
+
+// AccessTokenTTL - метод возвращает время жизни access-токена.
+func (cfg *jwtConfig) AccessTokenTTL() time.Duration {
+	return cfg.accessTokenTTL
+}
+
+// RefreshTokenSecret - метод возвращает секрет для подписи refresh-токенов.
+func (cfg *jwtConfig) RefreshTokenSecret() []byte {
+	return cfg.refreshTokenSecret
+}
+
+// RefreshTokenPrivateKey - метод возвращает закрытый ключ в формате PEM для подписи refresh-токенов.
+func (cfg *jwtConfig) RefreshTokenPrivateKey() []byte {
+	return cfg.refreshTokenPrivateKey
+}
+
+// RefreshTokenTTL - метод возвращает время жизни refresh-токена.
+func (cfg *jwtConfig) RefreshTokenTTL() time.Duration {
+	return cfg.refreshTokenTTL
+}
+
+// Issuer - метод возвращает URL издателя токенов.
+func (cfg *jwtConfig) Issuer() string {
+	return cfg.issuer
+}
+
+// Audience - метод возвращает получателей токенов.
+func (cfg *jwtConfig) Audience() []string {
+	return cfg.audience
+}
+
+// AccessTokenKeysFile - метод возвращает путь к файлу ключей подписи access-токенов.
+func (cfg *jwtConfig) AccessTokenKeysFile() string {
+	return cfg.accessTokenKeysFile
+}
+
+// AccessTokenKeyOverlap - метод возвращает, сколько предыдущий ключ access-токенов принимается
+// при проверке после активации следующего.
+func (cfg *jwtConfig) AccessTokenKeyOverlap() time.Duration {
+	return cfg.accessTokenKeyOverlap
+}
+
+// RefreshTokenKeysFile - метод возвращает путь к файлу ключей подписи refresh-токенов.
+func (cfg *jwtConfig) RefreshTokenKeysFile() string {
+	return cfg.refreshTokenKeysFile
+}
+
+// RefreshTokenKeyOverlap - метод возвращает, сколько предыдущий ключ refresh-токенов принимается
+// при проверке после активации следующего.
+func (cfg *jwtConfig) RefreshTokenKeyOverlap() time.Duration {
+	return cfg.refreshTokenKeyOverlap
+}
+
+// ImpersonationTokenTTL - метод возвращает время жизни access-токена, с которым администратор
+// действует от имени пользователя.
+func (cfg *jwtConfig) ImpersonationTokenTTL() time.Duration {
+	return cfg.impersonationTokenTTL
+}
+
+// ReauthTokenTTL - метод возвращает время жизни токена повторной аутентификации.
+func (cfg *jwtConfig) ReauthTokenTTL() time.Duration {
+	return cfg.reauthTokenTTL
+}
+
+// KeysReloadInterval - метод возвращает интервал перечитывания файлов ключей подписи токенов.
+func (cfg *jwtConfig) KeysReloadInterval() time.Duration {
+	return cfg.keysReloadInterval
+}
@@ -0,0 +1,66 @@
+package env
+
+import "testing"
+
+func TestNewJWTConfigSharedKeys(t *testing.T) {
+	const (
+		accessSecret  = "access-secret-0123456789abcdef012345"
+		refreshSecret = "refresh-secret-0123456789abcdef01234"
+	)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{
+			name: "different secrets",
+			env: map[string]string{
+				jwtAccessTokenSecretEnvName:  accessSecret,
+				jwtRefreshTokenSecretEnvName: refreshSecret,
+			},
+		},
+		{
+			name: "same secret",
+			env: map[string]string{
+				jwtAccessTokenSecretEnvName:  accessSecret,
+				jwtRefreshTokenSecretEnvName: accessSecret,
+			},
+			wantErr: true,
+		},
+		{
+			name: "different keys files",
+			env: map[string]string{
+				jwtAccessTokenKeysFileEnvName:  "/etc/auth/access-keys.json",
+				jwtRefreshTokenKeysFileEnvName: "/etc/auth/refresh-keys.json",
+			},
+		},
+		{
+			name: "same keys file",
+			env: map[string]string{
+				jwtAccessTokenKeysFileEnvName:  "/etc/auth/keys.json",
+				jwtRefreshTokenKeysFileEnvName: "/etc/auth/../auth/keys.json",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envName := range []string{
+				jwtAccessTokenSecretEnvName, jwtRefreshTokenSecretEnvName,
+				jwtAccessTokenPrivateKeyEnvName, jwtRefreshTokenPrivateKeyEnvName,
+				jwtAccessTokenKeysFileEnvName, jwtRefreshTokenKeysFileEnvName,
+			} {
+				t.Setenv(envName, tt.env[envName])
+			}
+			t.Setenv(jwtIssuerEnvName, "https://auth.example.com")
+			t.Setenv(jwtAudienceEnvName, "api")
+
+			_, err := NewJWTConfig()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewJWTConfig() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package env
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	lockoutUserMaxFailuresEnvName = "LOCKOUT_USER_MAX_FAILURES"
+	lockoutIPMaxFailuresEnvName   = "LOCKOUT_IP_MAX_FAILURES"
+	lockoutWindowEnvName          = "LOCKOUT_WINDOW"
+	lockoutDurationEnvName        = "LOCKOUT_DURATION"
+	lockoutMaxDurationEnvName     = "LOCKOUT_MAX_DURATION"
+)
+
+// LockoutConfig - интерфейс конфига защиты от подбора пароля.
+//
+// Методы:
+//   - UserMaxFailures() int: количество неудачных попыток входа с одним email за окно, после которого
+//     вход блокируется (0 - блокировка по email выключена).
+//   - IPMaxFailures() int: то же для одного IP-адреса (0 - блокировка по IP-адресу выключена).
+//   - Window() time.Duration: окно, в котором считаются неудачные попытки.
+//   - Duration() time.Duration: длительность первой блокировки. Каждая следующая блокировка
+//     подряд длится вдвое дольше предыдущей.
+//   - MaxDuration() time.Duration: максимальная длительность блокировки.
+type LockoutConfig interface {
+	UserMaxFailures() int
+	IPMaxFailures() int
+	Window() time.Duration
+	Duration() time.Duration
+	MaxDuration() time.Duration
+}
+
+// lockoutConfig - структура конфига защиты от подбора пароля, реализующая интерфейс LockoutConfig.
+type lockoutConfig struct {
+	userMaxFailures int
+	ipMaxFailures   int
+	window          time.Duration
+	duration        time.Duration
+	maxDuration     time.Duration
+}
+
+// NewLockoutConfig - метод создания конфига защиты от подбора пароля, реализующего интерфейс LockoutConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Все параметры необязательны. По умолчанию вход блокируется после 5 неудачных попыток с одним
+// email или 20 с одного IP-адреса за 15 минут. Первая блокировка длится 15 минут, максимальная - сутки.
+//
+// Возвращает:
+//   - LockoutConfig: созданный объект конфига защиты от подбора пароля.
+//   - error: ошибка, если что-то пошло не так.
+func NewLockoutConfig() (LockoutConfig, error) {
+	userMaxFailures, err := nonNegativeIntFromEnv(lockoutUserMaxFailuresEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	ipMaxFailures, err := nonNegativeIntFromEnv(lockoutIPMaxFailuresEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := positiveDurationFromEnv(lockoutWindowEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := positiveDurationFromEnv(lockoutDurationEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDuration, err := positiveDurationFromEnv(lockoutMaxDurationEnvName)
+	if err != nil {
+		return nil, err
+	}
+	if maxDuration < duration {
+		return nil, errors.Errorf("%s must not be less than %s", lockoutMaxDurationEnvName, lockoutDurationEnvName)
+	}
+
+	return &lockoutConfig{
+		userMaxFailures: userMaxFailures,
+		ipMaxFailures:   ipMaxFailures,
+		window:          window,
+		duration:        duration,
+		maxDuration:     maxDuration,
+	}, nil
+}
+
+// nonNegativeIntFromEnv возвращает неотрицательное целое число из переменной окружения envName
+// либо ее значение по умолчанию, если переменная не задана.
+func nonNegativeIntFromEnv(envName string) (int, error) {
+	number, err := strconv.Atoi(getenv(envName))
+	if err != nil || number < 0 {
+		return 0, errors.Errorf("%s is invalid", envName)
+	}
+
+	return number, nil
+}
+
+// positiveDurationFromEnv возвращает положительную длительность из переменной окружения envName
+// либо ее значение по умолчанию, если переменная не задана.
+func positiveDurationFromEnv(envName string) (time.Duration, error) {
+	duration, err := time.ParseDuration(getenv(envName))
+	if err != nil || duration <= 0 {
+		return 0, errors.Errorf("%s is invalid", envName)
+	}
+
+	return duration, nil
+}
+
+// UserMaxFailures - метод возвращает количество неудачных попыток с одним email до блокировки.
+func (cfg *lockoutConfig) UserMaxFailures() int {
+	return cfg.userMaxFailures
+}
+
+// IPMaxFailures - метод возвращает количество неудачных попыток с одного IP-адреса до блокировки.
+func (cfg *lockoutConfig) IPMaxFailures() int {
+	return cfg.ipMaxFailures
+}
+
+// Window - метод возвращает окно, в котором считаются неудачные попытки.
+func (cfg *lockoutConfig) Window() time.Duration {
+	return cfg.window
+}
+
+// Duration - метод возвращает длительность первой блокировки.
+func (cfg *lockoutConfig) Duration() time.Duration {
+	return cfg.duration
+}
+
+// MaxDuration - метод возвращает максимальную длительность блокировки.
+func (cfg *lockoutConfig) MaxDuration() time.Duration {
+	return cfg.maxDuration
+}
@@ -0,0 +1,103 @@
+package env
+
+import (
+	"net"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	mailSenderEnvName   = "MAIL_SENDER"
+	mailFromEnvName     = "MAIL_FROM"
+	smtpAddrEnvName     = "SMTP_ADDR"
+	smtpUsernameEnvName = "SMTP_USERNAME"
+	smtpPasswordEnvName = "SMTP_PASSWORD"
+
+	mailSenderLog  = "log"
+	mailSenderSMTP = "smtp"
+)
+
+// MailConfig - интерфейс конфига отправки писем пользователям.
+//
+// Методы:
+//   - Sender() string: способ отправки писем ("log" - письма пишутся в лог, "smtp" - отправляются через SMTP-сервер).
+//   - From() string: адрес отправителя.
+//   - SMTPAddr() string: адрес SMTP-сервера в формате "host:port".
+//   - SMTPUsername() string: имя пользователя SMTP-сервера (пустое - без аутентификации).
+//   - SMTPPassword() string: пароль пользователя SMTP-сервера.
+type MailConfig interface {
+	Sender() string
+	From() string
+	SMTPAddr() string
+	SMTPUsername() string
+	SMTPPassword() string
+}
+
+// mailConfig - структура конфига отправки писем, реализующая интерфейс MailConfig.
+type mailConfig struct {
+	sender       string
+	from         string
+	smtpAddr     string
+	smtpUsername string
+	smtpPassword string
+}
+
+// NewMailConfig - метод создания конфига отправки писем, реализующего интерфейс MailConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// По умолчанию письма пишутся в лог. Для способа "smtp" обязательны адрес отправителя
+// и адрес SMTP-сервера.
+//
+// Возвращает:
+//   - MailConfig: созданный объект конфига отправки писем.
+//   - error: ошибка, если что-то пошло не так.
+func NewMailConfig() (MailConfig, error) {
+	cfg := &mailConfig{
+		sender:       getenv(mailSenderEnvName),
+		from:         os.Getenv(mailFromEnvName),
+		smtpAddr:     os.Getenv(smtpAddrEnvName),
+		smtpUsername: os.Getenv(smtpUsernameEnvName),
+		smtpPassword: os.Getenv(smtpPasswordEnvName),
+	}
+
+	switch cfg.sender {
+	case mailSenderLog:
+	case mailSenderSMTP:
+		if len(cfg.from) == 0 {
+			return nil, errors.New("mail from not found")
+		}
+		if _, _, err := net.SplitHostPort(cfg.smtpAddr); err != nil {
+			return nil, errors.New("smtp addr is invalid")
+		}
+	default:
+		return nil, errors.New("mail sender is invalid")
+	}
+
+	return cfg, nil
+}
+
+// Sender - метод возвращает способ отправки писем.
+func (cfg *mailConfig) Sender() string {
+	return cfg.sender
+}
+
+// From - метод возвращает адрес отправителя.
+func (cfg *mailConfig) From() string {
+	return cfg.from
+}
+
+// SMTPAddr - метод возвращает адрес SMTP-сервера.
+func (cfg *mailConfig) SMTPAddr() string {
+	return cfg.smtpAddr
+}
+
+// SMTPUsername - метод возвращает имя пользователя SMTP-сервера.
+func (cfg *mailConfig) SMTPUsername() string {
+	return cfg.smtpUsername
+}
+
+// SMTPPassword - метод возвращает пароль пользователя SMTP-сервера.
+func (cfg *mailConfig) SMTPPassword() string {
+	return cfg.smtpPassword
+}
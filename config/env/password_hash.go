@@ -0,0 +1,113 @@
+package env
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordHashAlgorithmEnvName       = "PASSWORD_HASH_ALGORITHM"
+	passwordBcryptCostEnvName          = "PASSWORD_BCRYPT_COST"
+	passwordArgon2idMemoryEnvName      = "PASSWORD_ARGON2ID_MEMORY_KIB"
+	passwordArgon2idIterationsEnvName  = "PASSWORD_ARGON2ID_ITERATIONS"
+	passwordArgon2idParallelismEnvName = "PASSWORD_ARGON2ID_PARALLELISM"
+)
+
+// PasswordHashConfig - интерфейс конфига хеширования паролей.
+//
+// Методы:
+//   - Algorithm() string: алгоритм, которым вычисляются новые хеши ("bcrypt" или "argon2id").
+//   - BcryptCost() int: стоимость bcrypt.
+//   - Argon2idMemory() uint32: объем памяти argon2id в КиБ.
+//   - Argon2idIterations() uint32: количество проходов argon2id.
+//   - Argon2idParallelism() uint8: количество потоков argon2id.
+type PasswordHashConfig interface {
+	Algorithm() string
+	BcryptCost() int
+	Argon2idMemory() uint32
+	Argon2idIterations() uint32
+	Argon2idParallelism() uint8
+}
+
+// passwordHashConfig - структура конфига хеширования паролей, реализующая интерфейс PasswordHashConfig.
+type passwordHashConfig struct {
+	algorithm           string
+	bcryptCost          int
+	argon2idMemory      uint32
+	argon2idIterations  uint32
+	argon2idParallelism uint8
+}
+
+// NewPasswordHashConfig - метод создания конфига хеширования паролей, реализующего интерфейс PasswordHashConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Все параметры необязательны. По умолчанию используется bcrypt со стоимостью 10, параметры
+// argon2id по умолчанию - 64 МиБ памяти, 3 прохода, 2 потока. Корректность алгоритма и
+// диапазоны параметров проверяются при создании hasher.
+//
+// Возвращает:
+//   - PasswordHashConfig: созданный объект конфига хеширования паролей.
+//   - error: ошибка, если что-то пошло не так.
+func NewPasswordHashConfig() (PasswordHashConfig, error) {
+	algorithm := getenv(passwordHashAlgorithmEnvName)
+
+	bcryptCost, err := uintFromEnv(passwordBcryptCostEnvName, 32)
+	if err != nil {
+		return nil, errors.New("password bcrypt cost is invalid")
+	}
+
+	argon2idMemory, err := uintFromEnv(passwordArgon2idMemoryEnvName, 32)
+	if err != nil {
+		return nil, errors.New("password argon2id memory is invalid")
+	}
+
+	argon2idIterations, err := uintFromEnv(passwordArgon2idIterationsEnvName, 32)
+	if err != nil {
+		return nil, errors.New("password argon2id iterations is invalid")
+	}
+
+	argon2idParallelism, err := uintFromEnv(passwordArgon2idParallelismEnvName, 8)
+	if err != nil {
+		return nil, errors.New("password argon2id parallelism is invalid")
+	}
+
+	return &passwordHashConfig{
+		algorithm:           algorithm,
+		bcryptCost:          int(bcryptCost),
+		argon2idMemory:      uint32(argon2idMemory),
+		argon2idIterations:  uint32(argon2idIterations),
+		argon2idParallelism: uint8(argon2idParallelism),
+	}, nil
+}
+
+// uintFromEnv читает из переменной envName неотрицательное целое размером не более bitSize бит
+// либо возвращает ее значение по умолчанию, если переменная не задана.
+func uintFromEnv(envName string, bitSize int) (uint64, error) {
+	return strconv.ParseUint(getenv(envName), 10, bitSize)
+}
+
+// Algorithm - метод возвращает алгоритм, которым вычисляются новые хеши паролей.
+func (cfg *passwordHashConfig) Algorithm() string {
+	return cfg.algorithm
+}
+
+// BcryptCost - метод возвращает стоимость bcrypt.
+func (cfg *passwordHashConfig) BcryptCost() int {
+	return cfg.bcryptCost
+}
+
+// Argon2idMemory - метод возвращает объем памяти argon2id в КиБ.
+func (cfg *passwordHashConfig) Argon2idMemory() uint32 {
+	return cfg.argon2idMemory
+}
+
+// Argon2idIterations - метод возвращает количество проходов argon2id.
+func (cfg *passwordHashConfig) Argon2idIterations() uint32 {
+	return cfg.argon2idIterations
+}
+
+// Argon2idParallelism - метод возвращает количество потоков argon2id.
+func (cfg *passwordHashConfig) Argon2idParallelism() uint8 {
+	return cfg.argon2idParallelism
+}
@@ -0,0 +1,147 @@
+package env
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordMinLengthEnvName     = "PASSWORD_MIN_LENGTH"
+	passwordRequireLowerEnvName  = "PASSWORD_REQUIRE_LOWER"
+	passwordRequireUpperEnvName  = "PASSWORD_REQUIRE_UPPER"
+	passwordRequireDigitEnvName  = "PASSWORD_REQUIRE_DIGIT"
+	passwordRequireSymbolEnvName = "PASSWORD_REQUIRE_SYMBOL"
+	passwordDenyCommonEnvName    = "PASSWORD_DENY_COMMON"
+	passwordDenyListFileEnvName  = "PASSWORD_DENY_LIST_FILE"
+)
+
+// PasswordPolicyConfig - интерфейс конфига политики паролей.
+//
+// Методы:
+//   - MinLength() int: минимальная длина пароля в символах.
+//   - RequireLower() bool: true, если пароль должен содержать строчную букву.
+//   - RequireUpper() bool: true, если пароль должен содержать заглавную букву.
+//   - RequireDigit() bool: true, если пароль должен содержать цифру.
+//   - RequireSymbol() bool: true, если пароль должен содержать символ, не являющийся буквой или цифрой.
+//   - DenyCommon() bool: true, если запрещены пароли из встроенного списка распространенных паролей.
+//   - DenyListFile() string: путь к файлу с дополнительным списком запрещенных паролей
+//     (пустая строка - дополнительного списка нет).
+type PasswordPolicyConfig interface {
+	MinLength() int
+	RequireLower() bool
+	RequireUpper() bool
+	RequireDigit() bool
+	RequireSymbol() bool
+	DenyCommon() bool
+	DenyListFile() string
+}
+
+// passwordPolicyConfig - структура конфига политики паролей, реализующая интерфейс PasswordPolicyConfig.
+type passwordPolicyConfig struct {
+	minLength     int
+	requireLower  bool
+	requireUpper  bool
+	requireDigit  bool
+	requireSymbol bool
+	denyCommon    bool
+	denyListFile  string
+}
+
+// NewPasswordPolicyConfig - метод создания конфига политики паролей, реализующего интерфейс PasswordPolicyConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Все параметры необязательны. По умолчанию пароль должен быть не короче 8 символов и не входить
+// во встроенный список распространенных паролей, наличие классов символов не требуется.
+//
+// Возвращает:
+//   - PasswordPolicyConfig: созданный объект конфига политики паролей.
+//   - error: ошибка, если что-то пошло не так.
+func NewPasswordPolicyConfig() (PasswordPolicyConfig, error) {
+	minLength, err := nonNegativeIntFromEnv(passwordMinLengthEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	requireLower, err := boolFromEnv(passwordRequireLowerEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	requireUpper, err := boolFromEnv(passwordRequireUpperEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	requireDigit, err := boolFromEnv(passwordRequireDigitEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	requireSymbol, err := boolFromEnv(passwordRequireSymbolEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	denyCommon, err := boolFromEnv(passwordDenyCommonEnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passwordPolicyConfig{
+		minLength:     minLength,
+		requireLower:  requireLower,
+		requireUpper:  requireUpper,
+		requireDigit:  requireDigit,
+		requireSymbol: requireSymbol,
+		denyCommon:    denyCommon,
+		denyListFile:  os.Getenv(passwordDenyListFileEnvName),
+	}, nil
+}
+
+// MinLength - метод возвращает минимальную длину пароля в символах.
+func (cfg *passwordPolicyConfig) MinLength() int {
+	return cfg.minLength
+}
+
+// RequireLower - метод возвращает true, если пароль должен содержать строчную букву.
+func (cfg *passwordPolicyConfig) RequireLower() bool {
+	return cfg.requireLower
+}
+
+// RequireUpper - метод возвращает true, если пароль должен содержать заглавную букву.
+func (cfg *passwordPolicyConfig) RequireUpper() bool {
+	return cfg.requireUpper
+}
+
+// RequireDigit - метод возвращает true, если пароль должен содержать цифру.
+func (cfg *passwordPolicyConfig) RequireDigit() bool {
+	return cfg.requireDigit
+}
+
+// RequireSymbol - метод возвращает true, если пароль должен содержать символ, не являющийся буквой или цифрой.
+func (cfg *passwordPolicyConfig) RequireSymbol() bool {
+	return cfg.requireSymbol
+}
+
+// DenyCommon - метод возвращает true, если запрещены пароли из встроенного списка распространенных паролей.
+func (cfg *passwordPolicyConfig) DenyCommon() bool {
+	return cfg.denyCommon
+}
+
+// DenyListFile - метод возвращает путь к файлу с дополнительным списком запрещенных паролей.
+func (cfg *passwordPolicyConfig) DenyListFile() string {
+	return cfg.denyListFile
+}
+
+// boolFromEnv возвращает логическое значение из переменной окружения envName
+// либо ее значение по умолчанию, если переменная не задана.
+func boolFromEnv(envName string) (bool, error) {
+	result, err := strconv.ParseBool(getenv(envName))
+	if err != nil {
+		return false, errors.Errorf("%s is invalid", envName)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordResetURLEnvName      = "PASSWORD_RESET_URL"
+	passwordResetTokenTTLEnvName = "PASSWORD_RESET_TOKEN_TTL"
+)
+
+// PasswordResetConfig - интерфейс конфига сброса пароля.
+//
+// Методы:
+//   - URL() *url.URL: адрес страницы сброса пароля, на которую ведет ссылка из письма.
+//     Токен добавляется к адресу параметром "token".
+//   - TokenTTL() time.Duration: время жизни токена сброса пароля.
+type PasswordResetConfig interface {
+	URL() *url.URL
+	TokenTTL() time.Duration
+}
+
+// passwordResetConfig - структура конфига сброса пароля, реализующая интерфейс PasswordResetConfig.
+type passwordResetConfig struct {
+	url      *url.URL
+	tokenTTL time.Duration
+}
+
+// NewPasswordResetConfig - метод создания конфига сброса пароля, реализующего интерфейс PasswordResetConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Адрес страницы сброса пароля обязателен и должен быть абсолютным. Время жизни токена задается
+// в формате time.ParseDuration ("30m", "1h"), по умолчанию - 1 час.
+//
+// Возвращает:
+//   - PasswordResetConfig: созданный объект конфига сброса пароля.
+//   - error: ошибка, если что-то пошло не так.
+func NewPasswordResetConfig() (PasswordResetConfig, error) {
+	rawURL := os.Getenv(passwordResetURLEnvName)
+	if len(rawURL) == 0 {
+		return nil, errors.New("password reset url not found")
+	}
+
+	resetURL, err := url.Parse(rawURL)
+	if err != nil || !resetURL.IsAbs() || len(resetURL.Host) == 0 {
+		return nil, errors.New("password reset url is invalid")
+	}
+
+	tokenTTL, err := time.ParseDuration(getenv(passwordResetTokenTTLEnvName))
+	if err != nil || tokenTTL <= 0 {
+		return nil, errors.New("password reset token ttl is invalid")
+	}
+
+	return &passwordResetConfig{
+		url:      resetURL,
+		tokenTTL: tokenTTL,
+	}, nil
+}
+
+// URL - метод возвращает копию адреса страницы сброса пароля.
+func (cfg *passwordResetConfig) URL() *url.URL {
+	resetURL := *cfg.url
+	return &resetURL
+}
+
+// TokenTTL - метод возвращает время жизни токена сброса пароля.
+func (cfg *passwordResetConfig) TokenTTL() time.Duration {
+	return cfg.tokenTTL
+}
@@ -3,39 +3,122 @@ package env
 import (
 	"errors"
 	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 )
 
 const (
-	dsnEnvName = "PG_DSN"
+	databaseURLEnvName        = "DATABASE_URL"
+	dsnEnvName                = "PG_DSN"
+	logLevelEnvName           = "PG_LOG_LEVEL"
+	deadlockMaxRetriesEnvName = "PG_DEADLOCK_MAX_RETRIES"
+	connectModeEnvName        = "PG_CONNECT_MODE"
+	healthCheckPeriodEnvName  = "PG_HEALTH_CHECK_PERIOD"
+	connectMaxRetriesEnvName  = "PG_CONNECT_MAX_RETRIES"
+
+	connectModeEager = "eager"
+	connectModeLazy  = "lazy"
 )
 
 // PGConfig - интерфейс конфига для подключения к БД Postgres.
 //
 // Методы:
 //   - DSN() string: Data Source Name - строка, содержащая всю информацию, необходимую для подключения к базе данных.
+//   - LogLevel() pgx.LogLevel: уровень логирования запросов к БД (pgx.LogLevelNone - логирование выключено).
+//   - DeadlockMaxRetries() int: максимальное количество повторов запроса, завершившегося взаимной блокировкой (deadlock).
+//   - LazyConnect() bool: true, если подключение к БД откладывается до первого запроса.
+//   - ConnectMaxRetries() int: максимальное количество повторов подключения к БД при старте в режиме eager.
+//   - HealthCheckPeriod() time.Duration: интервал проверки простаивающих соединений пула
+//     (0 - не задан в PG_HEALTH_CHECK_PERIOD).
 type PGConfig interface {
 	DSN() string
+	LogLevel() pgx.LogLevel
+	DeadlockMaxRetries() int
+	LazyConnect() bool
+	ConnectMaxRetries() int
+	HealthCheckPeriod() time.Duration
 }
 
 // pgConfig - структура конфига для подключения к БД Postgres, реализующая интерфейс PGConfig.
 type pgConfig struct {
-	dsn string
+	dsn                string
+	logLevel           pgx.LogLevel
+	deadlockMaxRetries int
+	lazyConnect        bool
+	connectMaxRetries  int
+	healthCheckPeriod  time.Duration
 }
 
 // NewPGConfig - метод создания конфига БД Postgres, реализующего интерфейс PGConfig.
 // Параметры конфига берутся из переменных окружения программы.
 //
+// Если задана переменная DATABASE_URL, строка подключения берется из нее, а PG_DSN игнорируется.
+//
 // Возвращает:
 //   - PGConfig: структура конфига БД Postgres, реализующая интерфейс PGConfig.
 //   - error: ошибка, если что-то пошло не так.
 func NewPGConfig() (PGConfig, error) {
-	dsn := os.Getenv(dsnEnvName)
+	dsn := os.Getenv(databaseURLEnvName)
+	if len(dsn) == 0 {
+		dsn = os.Getenv(dsnEnvName)
+	}
 	if len(dsn) == 0 {
 		return nil, errors.New("pg dsn not found")
 	}
 
+	// Проверка, что строка подключения корректна
+	if _, err := pgx.ParseConfig(dsn); err != nil {
+		return nil, errors.New("pg dsn is invalid")
+	}
+
+	// Уровень логирования необязателен, по умолчанию запросы к БД не логируются
+	logLevel, err := pgx.LogLevelFromString(getenv(logLevelEnvName))
+	if err != nil {
+		return nil, errors.New("pg log level is invalid")
+	}
+
+	// Количество повторов при deadlock необязательно, по умолчанию - 3
+	deadlockMaxRetries, err := strconv.Atoi(getenv(deadlockMaxRetriesEnvName))
+	if err != nil || deadlockMaxRetries < 0 {
+		return nil, errors.New("pg deadlock max retries is invalid")
+	}
+
+	// Режим подключения необязателен, по умолчанию eager - подключение при старте сервера
+	var lazyConnect bool
+	switch connectMode := getenv(connectModeEnvName); connectMode {
+	case connectModeEager:
+		lazyConnect = false
+	case connectModeLazy:
+		lazyConnect = true
+	default:
+		return nil, errors.New("pg connect mode is invalid")
+	}
+
+	// Количество повторов подключения необязательно, по умолчанию - 5
+	connectMaxRetries, err := strconv.Atoi(getenv(connectMaxRetriesEnvName))
+	if err != nil || connectMaxRetries < 0 {
+		return nil, errors.New("pg connect max retries is invalid")
+	}
+
+	// Интервал проверки соединений необязателен: если он не задан, действует pool_health_check_period
+	// из строки подключения либо значение pgxpool по умолчанию
+	var healthCheckPeriod time.Duration
+	if healthCheckPeriodValue := os.Getenv(healthCheckPeriodEnvName); len(healthCheckPeriodValue) > 0 {
+		healthCheckPeriod, err = time.ParseDuration(healthCheckPeriodValue)
+		if err != nil || healthCheckPeriod <= 0 {
+			return nil, errors.New("pg health check period is invalid")
+		}
+	}
+
 	return &pgConfig{
-		dsn: dsn,
+		dsn:                dsn,
+		logLevel:           logLevel,
+		deadlockMaxRetries: deadlockMaxRetries,
+		lazyConnect:        lazyConnect,
+		connectMaxRetries:  connectMaxRetries,
+		healthCheckPeriod:  healthCheckPeriod,
 	}, nil
 }
 
@@ -43,3 +126,34 @@ func NewPGConfig() (PGConfig, error) {
 func (cfg *pgConfig) DSN() string {
 	return cfg.dsn
 }
+
+// LogLevel - метод возвращает уровень логирования запросов к БД.
+func (cfg *pgConfig) LogLevel() pgx.LogLevel {
+	return cfg.logLevel
+}
+
+// DeadlockMaxRetries - метод возвращает максимальное количество повторов запроса, завершившегося deadlock.
+func (cfg *pgConfig) DeadlockMaxRetries() int {
+	return cfg.deadlockMaxRetries
+}
+
+// LazyConnect - метод возвращает true, если подключение к БД откладывается до первого запроса
+// (режим lazy), и false, если сервер подключается к БД при старте (режим eager).
+func (cfg *pgConfig) LazyConnect() bool {
+	return cfg.lazyConnect
+}
+
+// ConnectMaxRetries - метод возвращает максимальное количество повторов подключения к БД при старте
+// сервера в режиме eager. 0 - сервер останавливается после первой неудачной попытки.
+func (cfg *pgConfig) ConnectMaxRetries() int {
+	return cfg.connectMaxRetries
+}
+
+// HealthCheckPeriod - метод возвращает интервал, с которым пул проверяет простаивающие соединения,
+// либо 0, если интервал не задан в PG_HEALTH_CHECK_PERIOD.
+//
+// Периодическая проверка не дает сетевому оборудованию (NAT, firewall) закрыть долго простаивающие
+// TCP-соединения и вовремя заменяет уже закрытые.
+func (cfg *pgConfig) HealthCheckPeriod() time.Duration {
+	return cfg.healthCheckPeriod
+}
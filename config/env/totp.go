@@ -0,0 +1,71 @@
+package env
+
+import (
+	"encoding/base64"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	totpIssuerEnvName        = "TOTP_ISSUER"
+	totpEncryptionKeyEnvName = "TOTP_ENCRYPTION_KEY"
+
+	// totpEncryptionKeyLength - длина ключа шифрования секретов TOTP (AES-256).
+	totpEncryptionKeyLength = 32
+)
+
+// TOTPConfig - интерфейс конфига двухфакторной аутентификации по одноразовым кодам (TOTP).
+//
+// Методы:
+//   - Issuer() string: название сервиса, которое показывает приложение-аутентификатор.
+//   - EncryptionKey() []byte: ключ, которым секреты TOTP шифруются перед сохранением в БД.
+type TOTPConfig interface {
+	Issuer() string
+	EncryptionKey() []byte
+}
+
+// totpConfig - структура конфига TOTP, реализующая интерфейс TOTPConfig.
+type totpConfig struct {
+	issuer        string
+	encryptionKey []byte
+}
+
+// NewTOTPConfig - метод создания конфига TOTP, реализующего интерфейс TOTPConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Ключ шифрования обязателен и задается в base64, после декодирования его длина должна быть
+// 32 байта. Смена ключа делает сохраненные секреты нечитаемыми. Название сервиса
+// по умолчанию - "auth".
+//
+// Возвращает:
+//   - TOTPConfig: созданный объект конфига TOTP.
+//   - error: ошибка, если что-то пошло не так.
+func NewTOTPConfig() (TOTPConfig, error) {
+	issuer := getenv(totpIssuerEnvName)
+
+	encodedKey := os.Getenv(totpEncryptionKeyEnvName)
+	if len(encodedKey) == 0 {
+		return nil, errors.New("totp encryption key not found")
+	}
+
+	encryptionKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(encryptionKey) != totpEncryptionKeyLength {
+		return nil, errors.Errorf("totp encryption key must be %d bytes encoded in base64", totpEncryptionKeyLength)
+	}
+
+	return &totpConfig{
+		issuer:        issuer,
+		encryptionKey: encryptionKey,
+	}, nil
+}
+
+// Issuer - метод возвращает название сервиса для приложения-аутентификатора.
+func (cfg *totpConfig) Issuer() string {
+	return cfg.issuer
+}
+
+// EncryptionKey - метод возвращает ключ шифрования секретов TOTP.
+func (cfg *totpConfig) EncryptionKey() []byte {
+	return cfg.encryptionKey
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	validationWarningsAsErrorsEnvName       = "VALIDATION_WARNINGS_AS_ERRORS"
+	validationRejectPasswordIdentityEnvName = "VALIDATION_REJECT_PASSWORD_IDENTITY"
+)
+
+// ValidationConfig - интерфейс конфига валидации запросов к АПИ.
+//
+// Методы:
+//   - WarningsAsErrors() bool: true, если предупреждения валидации (слабый пароль, необычный email)
+//     должны блокировать запрос так же, как ошибки.
+//   - RejectPasswordIdentity() bool: true, если запрещены пароли, совпадающие с email или именем
+//     пользователя либо содержащие их.
+type ValidationConfig interface {
+	WarningsAsErrors() bool
+	RejectPasswordIdentity() bool
+}
+
+// validationConfig - структура конфига валидации, реализующая интерфейс ValidationConfig.
+type validationConfig struct {
+	warningsAsErrors       bool
+	rejectPasswordIdentity bool
+}
+
+// NewValidationConfig - метод создания конфига валидации, реализующего интерфейс ValidationConfig.
+// Параметры конфига берутся из переменных окружения программы.
+//
+// Все параметры необязательны. По умолчанию предупреждения не блокируют запрос, а пароли,
+// содержащие email или имя пользователя, запрещены.
+//
+// Возвращает:
+//   - ValidationConfig: созданный объект конфига валидации.
+//   - error: ошибка, если что-то пошло не так.
+func NewValidationConfig() (ValidationConfig, error) {
+	warningsAsErrors, err := strconv.ParseBool(getenv(validationWarningsAsErrorsEnvName))
+	if err != nil {
+		return nil, errors.New("validation warnings as errors is invalid")
+	}
+
+	rejectPasswordIdentity, err := strconv.ParseBool(getenv(validationRejectPasswordIdentityEnvName))
+	if err != nil {
+		return nil, errors.New("validation reject password identity is invalid")
+	}
+
+	return &validationConfig{
+		warningsAsErrors:       warningsAsErrors,
+		rejectPasswordIdentity: rejectPasswordIdentity,
+	}, nil
+}
+
+// WarningsAsErrors - метод возвращает true, если предупреждения валидации блокируют запрос.
+func (cfg *validationConfig) WarningsAsErrors() bool {
+	return cfg.warningsAsErrors
+}
+
+// RejectPasswordIdentity - метод возвращает true, если запрещены пароли, содержащие email или имя пользователя.
+func (cfg *validationConfig) RejectPasswordIdentity() bool {
+	return cfg.rejectPasswordIdentity
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.uber.org/zap"
+)
+
+// activityRecorder накапливает ID пользователей, проявивших активность, и периодически
+// одним запросом обновляет у них last_seen.
+//
+// Повторная активность одного пользователя между записями в БД схлопывается в одно обновление.
+type activityRecorder struct {
+	dbPool   dbQuerier
+	log      *zap.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	userIDs map[int64]struct{}
+}
+
+// newActivityRecorder создает activityRecorder, записывающий активность в БД раз в interval.
+func newActivityRecorder(dbPool dbQuerier, log *zap.Logger, interval time.Duration) *activityRecorder {
+	return &activityRecorder{
+		dbPool:   dbPool,
+		log:      log,
+		interval: interval,
+		userIDs:  make(map[int64]struct{}),
+	}
+}
+
+// Record добавляет активность пользователя userID в очередь на запись.
+func (r *activityRecorder) Record(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.userIDs[userID] = struct{}{}
+}
+
+// Run записывает накопленную активность в БД раз в r.interval, пока не будет отменен ctx.
+//
+// После отмены ctx выполняет последнюю запись, чтобы не потерять активность при остановке сервера.
+func (r *activityRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Контекст уже отменен, поэтому последняя запись выполняется с новым контекстом
+			r.flush(context.Background())
+			return
+		case <-ticker.C:
+			r.flush(ctx)
+		}
+	}
+}
+
+// flush обновляет last_seen у всех накопленных пользователей и очищает очередь.
+func (r *activityRecorder) flush(ctx context.Context) {
+	r.mu.Lock()
+	if len(r.userIDs) == 0 {
+		r.mu.Unlock()
+		return
+	}
+
+	userIDs := make([]int64, 0, len(r.userIDs))
+	for userID := range r.userIDs {
+		userIDs = append(userIDs, userID)
+	}
+	r.userIDs = make(map[int64]struct{})
+	r.mu.Unlock()
+
+	builderUpdate := sq.
+		Update("auth").
+		PlaceholderFormat(sq.Dollar).
+		Set("last_seen", sq.Expr("now()")).
+		Where(sq.Expr("id = ANY(?)", userIDs))
+
+	query, args, err := builderUpdate.ToSql()
+	if err != nil {
+		r.log.Error("Activity flush. Unable to create SQL query from builder", zap.Error(err))
+		return
+	}
+
+	if _, err = r.dbPool.Exec(ctx, query, args...); err != nil {
+		r.log.Error("Activity flush. Unable to execute SQL query", zap.Error(err), zap.Int("Users count", len(userIDs)))
+		return
+	}
+
+	r.log.Debug("Activity flushed", zap.Int("Users count", len(userIDs)))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"go.uber.org/zap"
+)
+
+// execRecorder - БД, которая отправляет ID пользователей из каждого запроса Exec в канал userIDs.
+type execRecorder struct {
+	dbQuerier
+	userIDs chan []int64
+}
+
+func (db *execRecorder) Exec(_ context.Context, _ string, args ...interface{}) (pgconn.CommandTag, error) {
+	userIDs := append([]int64(nil), args[0].([]int64)...)
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	db.userIDs <- userIDs
+
+	return pgconn.CommandTag("UPDATE 1"), nil
+}
+
+// receiveUserIDs ждет запрос к db и возвращает ID пользователей из него.
+func receiveUserIDs(t *testing.T, db *execRecorder) []int64 {
+	t.Helper()
+
+	select {
+	case userIDs := <-db.userIDs:
+		return userIDs
+	case <-time.After(time.Second):
+		t.Fatal("activity was not flushed")
+		return nil
+	}
+}
+
+func TestActivityRecorderFlush(t *testing.T) {
+	db := &fakeDB{}
+	recorder := newActivityRecorder(db, zap.NewNop(), time.Hour)
+
+	for _, userID := range []int64{3, 1, 3, 2, 1} {
+		recorder.Record(userID)
+	}
+	recorder.flush(context.Background())
+
+	if len(db.queries) != 1 {
+		t.Fatalf("queries = %d, want 1", len(db.queries))
+	}
+	if want := "UPDATE auth SET last_seen = now() WHERE id = ANY($1)"; db.queries[0] != want {
+		t.Errorf("query = %q, want %q", db.queries[0], want)
+	}
+	userIDs := db.args[0][0].([]int64)
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(userIDs, want) {
+		t.Errorf("user ids = %v, want %v", userIDs, want)
+	}
+
+	// Записанная активность удаляется из очереди, пустая очередь не записывается
+	recorder.flush(context.Background())
+	if len(db.queries) != 1 {
+		t.Errorf("queries = %d after second flush, want 1", len(db.queries))
+	}
+}
+
+func TestActivityRecorderRun(t *testing.T) {
+	t.Run("on interval", func(t *testing.T) {
+		db := &execRecorder{userIDs: make(chan []int64, 10)}
+		recorder := newActivityRecorder(db, zap.NewNop(), 10*time.Millisecond)
+
+		recorder.Record(1)
+		recorder.Record(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			recorder.Run(ctx)
+			close(done)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+
+		if got := receiveUserIDs(t, db); !reflect.DeepEqual(got, []int64{1}) {
+			t.Errorf("user ids = %v, want [1]", got)
+		}
+
+		recorder.Record(2)
+		if got := receiveUserIDs(t, db); !reflect.DeepEqual(got, []int64{2}) {
+			t.Errorf("user ids = %v, want [2]", got)
+		}
+	})
+
+	t.Run("on shutdown", func(t *testing.T) {
+		db := &execRecorder{userIDs: make(chan []int64, 10)}
+		recorder := newActivityRecorder(db, zap.NewNop(), time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			recorder.Run(ctx)
+			close(done)
+		}()
+
+		recorder.Record(2)
+		recorder.Record(1)
+		cancel()
+		<-done
+
+		if got := receiveUserIDs(t, db); !reflect.DeepEqual(got, []int64{1, 2}) {
+			t.Errorf("user ids = %v, want [1 2]", got)
+		}
+	})
+}
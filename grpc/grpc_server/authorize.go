@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// authorizeUser проверяет, что метод с данными пользователя id вызывает сам этот пользователь
+// или администратор (см. isAdmin).
+//
+// Вызывающий сохраняется в контексте проверкой доступа (interceptor.AccessInterceptor).
+//
+// Возвращает:
+//   - error с кодом Unauthenticated, если метод вызван без access-токена и API-ключа.
+//   - error с кодом PermissionDenied, если вызывающий - другой пользователь или сервис без
+//     административной роли.
+//   - nil в остальных случаях.
+func authorizeUser(ctx context.Context, id int64) error {
+	caller, ok := identity.CallerFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "Access token is required")
+	}
+
+	if isAdmin(caller) || (caller.UserID != 0 && caller.UserID == id) {
+		return nil
+	}
+
+	return status.Error(codes.PermissionDenied, "Access denied")
+}
+
+// callerIsAdmin возвращает true, если метод вызван администратором (см. isAdmin).
+func callerIsAdmin(ctx context.Context) bool {
+	caller, ok := identity.CallerFromContext(ctx)
+	return ok && isAdmin(caller)
+}
+
+// isAdmin возвращает true, если вызывающий - пользователь (или API-ключ) с ролью ADMIN либо
+// сервисный аккаунт с ролью SERVICE_ADMIN. Токен, выпущенный администратору от имени пользователя,
+// содержит роль пользователя, поэтому по нему администратор действует с правами пользователя.
+func isAdmin(caller *model.Caller) bool {
+	switch desc.UserRole(caller.Role) {
+	case desc.UserRole_ADMIN:
+		return true
+	case desc.UserRole_SERVICE_ADMIN:
+		return len(caller.ClientID) > 0
+	default:
+		return false
+	}
+}
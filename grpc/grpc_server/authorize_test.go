@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+)
+
+func TestAuthorizeUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *model.Caller
+		id       int64
+		wantCode codes.Code
+	}{
+		{name: "anonymous", id: 5, wantCode: codes.Unauthenticated},
+		{name: "self", caller: &model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}, id: 5, wantCode: codes.OK},
+		{name: "other user", caller: &model.Caller{UserID: 6, Role: int32(desc.UserRole_USER)}, id: 5, wantCode: codes.PermissionDenied},
+		{name: "admin", caller: &model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}, id: 5, wantCode: codes.OK},
+		{
+			name:     "service admin",
+			caller:   &model.Caller{ClientID: "sa_importer", Role: int32(desc.UserRole_SERVICE_ADMIN)},
+			id:       5,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "service admin role without service account",
+			caller:   &model.Caller{UserID: 6, Role: int32(desc.UserRole_SERVICE_ADMIN)},
+			id:       5,
+			wantCode: codes.PermissionDenied,
+		},
+		{name: "api key without user", caller: &model.Caller{APIKeyID: 3, Role: int32(desc.UserRole_USER)}, id: 0, wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = identity.WithCaller(ctx, tt.caller)
+			}
+
+			if code := status.Code(authorizeUser(ctx, tt.id)); code != tt.wantCode {
+				t.Errorf("authorizeUser() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestChangeUserPasswordOfOtherUser(t *testing.T) {
+	s := &server{log: zap.NewNop()}
+	ctx := identity.WithCaller(context.Background(), &model.Caller{UserID: 6, Role: int32(desc.UserRole_USER)})
+
+	_, err := s.ChangeUserPassword(ctx, &desc.ChangeUserPasswordRequest{
+		Id:                 5,
+		OldPassword:        "old-password-1",
+		NewPassword:        "new-password-1",
+		NewPasswordConfirm: "new-password-1",
+	})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("ChangeUserPassword() code = %s, want %s", code, codes.PermissionDenied)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// userToCreate проверяет запрос на создание пользователя и возвращает данные для сохранения
+// и предупреждения, не блокирующие создание.
+//
+// Роль, отличную от USER, может назначить только администратор: при самостоятельной регистрации
+// и вызове другим пользователем такой запрос отклоняется ошибкой PermissionDenied.
+//
+// Если в конфиге включено warningsAsErrors, предупреждения возвращаются ошибкой InvalidArgument.
+func (s *server) userToCreate(ctx context.Context, req *desc.CreateUserRequest) (*model.UserToCreate, []string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	warnings := req.Warnings()
+	if len(warnings) > 0 && s.warningsAsErrors {
+		return nil, warnings, status.Error(codes.InvalidArgument, strings.Join(warnings, ". "))
+	}
+
+	// Телефон необязателен, хранится в формате E.164 либо NULL
+	var phone sql.NullString
+	if len(strings.TrimSpace(req.Phone)) > 0 {
+		phone.String, _ = desc.NormalizePhone(req.Phone)
+		phone.Valid = true
+	}
+
+	if req.Role != desc.UserRole_USER && !callerIsAdmin(ctx) {
+		s.log.Warn("Only administrators can assign roles", zap.String("Role", req.Role.String()))
+		return nil, warnings, status.Error(codes.PermissionDenied, "Only administrators can assign roles")
+	}
+
+	return &model.UserToCreate{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     int32(req.Role),
+		Phone:    phone,
+	}, warnings, nil
+}
+
+// createUserStatus преобразует ошибку создания пользователя в ошибку gRPC: нарушение политики
+// паролей - InvalidArgument, нарушение уникальности - AlreadyExists, отмена пакета - Aborted.
+// Ошибки валидации запроса возвращаются как есть, остальные - с кодом Internal.
+func createUserStatus(err error) *status.Status {
+	var violationErr *passwordpolicy.ViolationError
+	if errors.As(err, &violationErr) {
+		return status.New(codes.InvalidArgument, violationErr.Error())
+	}
+	if alreadyExistsErr := uniqueViolationError(err); alreadyExistsErr != nil {
+		return status.Convert(alreadyExistsErr)
+	}
+	if errors.Is(err, service.ErrBatchAborted) {
+		return status.New(codes.Aborted, "User was not created because of errors in other users of the batch")
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	return status.New(codes.Internal, "Unable to create user")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+// batchUser возвращает корректный запрос на создание пользователя с email user<index>@example.com.
+func batchUser(index int) *desc.CreateUserRequest {
+	return &desc.CreateUserRequest{
+		Name:            "User",
+		Email:           fmt.Sprintf("user%d@example.com", index),
+		Password:        "Str0ng-password",
+		PasswordConfirm: "Str0ng-password",
+		Role:            desc.UserRole_USER,
+	}
+}
+
+// invalidBatchUser возвращает запрос на создание пользователя, не проходящий валидацию.
+func invalidBatchUser(index int) *desc.CreateUserRequest {
+	user := batchUser(index)
+	user.PasswordConfirm = "Other-passw0rd"
+	return user
+}
+
+func TestBatchCreateUsers(t *testing.T) {
+	tests := []struct {
+		name           string
+		users          []*desc.CreateUserRequest
+		bestEffort     bool
+		duplicateEmail string
+		wantCodes      []codes.Code
+		wantIDs        []int64
+		wantBatches    int
+		wantEmails     []string
+	}{
+		{
+			name:        "atomic",
+			users:       []*desc.CreateUserRequest{batchUser(0), batchUser(1)},
+			wantCodes:   []codes.Code{codes.OK, codes.OK},
+			wantIDs:     []int64{1, 2},
+			wantBatches: 1,
+			wantEmails:  []string{"user0@example.com", "user1@example.com"},
+		},
+		{
+			name:      "atomic with invalid user",
+			users:     []*desc.CreateUserRequest{batchUser(0), invalidBatchUser(1), batchUser(2)},
+			wantCodes: []codes.Code{codes.Aborted, codes.InvalidArgument, codes.Aborted},
+			wantIDs:   []int64{0, 0, 0},
+		},
+		{
+			name:        "best effort with invalid user",
+			users:       []*desc.CreateUserRequest{batchUser(0), invalidBatchUser(1), batchUser(2)},
+			bestEffort:  true,
+			wantCodes:   []codes.Code{codes.OK, codes.InvalidArgument, codes.OK},
+			wantIDs:     []int64{1, 0, 2},
+			wantBatches: 1,
+			wantEmails:  []string{"user0@example.com", "user2@example.com"},
+		},
+		{
+			name:           "best effort with duplicate",
+			users:          []*desc.CreateUserRequest{batchUser(0), batchUser(1)},
+			bestEffort:     true,
+			duplicateEmail: "user0@example.com",
+			wantCodes:      []codes.Code{codes.AlreadyExists, codes.OK},
+			wantIDs:        []int64{0, 1},
+			wantBatches:    1,
+			wantEmails:     []string{"user1@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := &fakeUserService{duplicateEmail: tt.duplicateEmail}
+			emailVerification := &fakeEmailVerification{}
+			client := newTestServer(t, withUserService(userService), withEmailVerification(emailVerification))
+
+			resp, err := client.BatchCreateUsers(context.Background(), &desc.BatchCreateUsersRequest{
+				Users:      tt.users,
+				BestEffort: tt.bestEffort,
+			})
+			if err != nil {
+				t.Fatalf("BatchCreateUsers() error = %v", err)
+			}
+
+			var (
+				gotCodes     []codes.Code
+				gotIDs       []int64
+				createdCount uint32
+			)
+			for i, result := range resp.GetResults() {
+				if result.GetIndex() != uint32(i) {
+					t.Errorf("results[%d].Index = %d, want %d", i, result.GetIndex(), i)
+				}
+				gotCodes = append(gotCodes, codes.Code(result.GetErrorCode()))
+				gotIDs = append(gotIDs, result.GetId())
+				if result.GetErrorCode() == uint32(codes.OK) {
+					createdCount++
+				}
+			}
+			if !slices.Equal(gotCodes, tt.wantCodes) {
+				t.Errorf("result codes = %v, want %v", gotCodes, tt.wantCodes)
+			}
+			if !slices.Equal(gotIDs, tt.wantIDs) {
+				t.Errorf("result ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			if resp.GetCreatedCount() != createdCount {
+				t.Errorf("CreatedCount = %d, want %d", resp.GetCreatedCount(), createdCount)
+			}
+			if userService.batches != tt.wantBatches {
+				t.Errorf("CreateBatch() calls = %d, want %d", userService.batches, tt.wantBatches)
+			}
+			// Письма для подтверждения email отправляются только созданным пользователям
+			if !slices.Equal(emailVerification.emails, tt.wantEmails) {
+				t.Errorf("verification emails = %q, want %q", emailVerification.emails, tt.wantEmails)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/anton0701/auth/config/env"
+)
+
+const (
+	// dbConnectBaseDelay - задержка перед первым повтором подключения к БД в режиме eager.
+	dbConnectBaseDelay = 500 * time.Millisecond
+	// dbConnectMaxDelay - максимальная задержка между попытками подключения к БД в режиме eager.
+	dbConnectMaxDelay = 10 * time.Second
+)
+
+// newPoolConfig создает конфиг пула соединений с БД из строки подключения и настроек pgConfig.
+//
+// Параметры пула из строки подключения (например, pool_health_check_period) заменяются
+// только настройками, явно заданными в переменных окружения.
+//
+// Параметры:
+//   - pgConfig: конфиг подключения к БД.
+//   - logger: логгер, в который пишутся запросы к БД, если задан уровень логирования.
+//
+// Возвращает:
+//   - *pgxpool.Config: конфиг пула соединений.
+//   - error: ошибка разбора строки подключения.
+func newPoolConfig(pgConfig env.PGConfig, logger *zap.Logger) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(pgConfig.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	if pgConfig.LogLevel() != pgx.LogLevelNone {
+		poolConfig.ConnConfig.Logger = newPGLogger(logger)
+		poolConfig.ConnConfig.LogLevel = pgConfig.LogLevel()
+	}
+
+	// В режиме lazy сервер стартует, даже если БД пока недоступна
+	poolConfig.LazyConnect = pgConfig.LazyConnect()
+
+	if pgConfig.HealthCheckPeriod() > 0 {
+		poolConfig.HealthCheckPeriod = pgConfig.HealthCheckPeriod()
+	}
+
+	return poolConfig, nil
+}
+
+// pinger - соединение с БД, доступность которого можно проверить.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// connectWithRetry вызывает connect, пока подключение к БД не удастся, с экспоненциальной задержкой
+// между попытками, начиная с baseDelay и не больше dbConnectMaxDelay.
+//
+// Используется в режиме eager: сервер не стартует без БД, но переживает ее кратковременную
+// недоступность (например, когда БД и сервер запускаются одновременно).
+//
+// Параметры:
+//   - ctx: контекст, при отмене ожидание повтора прерывается.
+//   - maxRetries: максимальное количество повторов после первой попытки.
+//   - baseDelay: задержка перед первым повтором.
+//   - connect: функция, выполняющая одну попытку подключения.
+//   - logger: логгер, в который пишутся неудачные попытки.
+//
+// Возвращает:
+//   - error: ошибка последней попытки подключения либо ошибка контекста.
+func connectWithRetry(ctx context.Context, maxRetries int, baseDelay time.Duration, connect func(ctx context.Context) error, logger *zap.Logger) error {
+	delay := baseDelay
+
+	for attempt := 0; ; attempt++ {
+		err := connect(ctx)
+		if err == nil || attempt >= maxRetries {
+			return err
+		}
+
+		logger.Warn("Unable to connect to db, retrying", zap.Int("Attempt", attempt+1), zap.Duration("Delay", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, dbConnectMaxDelay)
+	}
+}
+
+// watchDB проверяет доступность БД каждые interval, пока не отменен ctx, и отмечает сервер
+// готовым (SERVING) в readiness, пока БД доступна, и неготовым (NOT_SERVING), пока недоступна.
+//
+// onFirstReachable вызывается один раз, когда БД впервые станет доступна, до того как сервер
+// будет отмечен готовым (например, для проверки схемы БД в режиме lazy). Может быть nil.
+func watchDB(ctx context.Context, db pinger, interval time.Duration, readiness *health.Server, onFirstReachable func(), logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	reachable := false
+	for {
+		err := db.Ping(ctx)
+		switch {
+		case err == nil && !reachable:
+			logger.Info("Database is reachable")
+			if onFirstReachable != nil {
+				onFirstReachable()
+				onFirstReachable = nil
+			}
+			readiness.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			reachable = true
+		case err != nil && ctx.Err() == nil && reachable:
+			logger.Warn("Database is not reachable", zap.Error(err))
+			readiness.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			reachable = false
+		case err != nil && !reachable:
+			logger.Warn("Database is not reachable yet", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+// eventSubscriptionBufferSize - количество событий, которые могут ждать отправки одному подписчику.
+const eventSubscriptionBufferSize = 64
+
+// userEvent - событие изменения пользователя во внутренней шине событий.
+type userEvent struct {
+	eventType  desc.UserEventType
+	userID     int64
+	occurredAt time.Time
+}
+
+// eventSubscription - подписка на события шины.
+//
+// Если подписчик не успевает забирать события, самые старые из них отбрасываются,
+// а их количество накапливается в dropped.
+type eventSubscription struct {
+	events  chan userEvent
+	dropped atomic.Uint64
+}
+
+// eventBus - внутренняя шина событий об изменении пользователей.
+type eventBus struct {
+	mu            sync.Mutex
+	subscriptions map[*eventSubscription]struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newEventBus создает пустую шину событий.
+func newEventBus() *eventBus {
+	return &eventBus{
+		subscriptions: make(map[*eventSubscription]struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+// Close сообщает подписчикам, что шина останавливается (см. Done).
+//
+// Нужен при остановке сервера: потоковые запросы подписчиков должны завершиться,
+// иначе grpc.Server.GracefulStop будет ждать их бесконечно.
+func (b *eventBus) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+}
+
+// Done возвращает канал, который закрывается при вызове Close.
+func (b *eventBus) Done() <-chan struct{} {
+	return b.closed
+}
+
+// Subscribe создает подписку на все последующие события шины.
+//
+// Подписку нужно закрыть через Unsubscribe.
+func (b *eventBus) Subscribe() *eventSubscription {
+	subscription := &eventSubscription{
+		events: make(chan userEvent, eventSubscriptionBufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe удаляет подписку, после чего события в нее больше не поступают.
+func (b *eventBus) Unsubscribe(subscription *eventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscriptions, subscription)
+}
+
+// Publish отправляет событие всем подписчикам.
+//
+// Publish не блокируется: если буфер подписчика заполнен, из него отбрасывается самое старое событие.
+func (b *eventBus) Publish(eventType desc.UserEventType, userID int64) {
+	event := userEvent{
+		eventType:  eventType,
+		userID:     userID,
+		occurredAt: time.Now(),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscriptions {
+		select {
+		case subscription.events <- event:
+			continue
+		default:
+		}
+
+		// Буфер заполнен - отбрасываем самое старое событие и повторяем попытку
+		select {
+		case <-subscription.events:
+			subscription.dropped.Add(1)
+		default:
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+func TestEventBusDropsOldest(t *testing.T) {
+	bus := newEventBus()
+	subscription := bus.Subscribe()
+
+	const extra = 3
+	for userID := int64(1); userID <= eventSubscriptionBufferSize+extra; userID++ {
+		bus.Publish(desc.UserEventType_UPDATED, userID)
+	}
+
+	if got := subscription.dropped.Load(); got != extra {
+		t.Errorf("dropped = %d, want %d", got, extra)
+	}
+	if got := len(subscription.events); got != eventSubscriptionBufferSize {
+		t.Fatalf("buffered %d events, want %d", got, eventSubscriptionBufferSize)
+	}
+
+	// Отброшены самые старые события, порядок оставшихся сохранен
+	for want := int64(extra + 1); want <= eventSubscriptionBufferSize+extra; want++ {
+		if event := <-subscription.events; event.userID != want {
+			t.Fatalf("event user id = %d, want %d", event.userID, want)
+		}
+	}
+}
+
+func TestEventBusPublishToSubscribers(t *testing.T) {
+	bus := newEventBus()
+	first := bus.Subscribe()
+	second := bus.Subscribe()
+
+	bus.Publish(desc.UserEventType_CREATED, 1)
+	bus.Unsubscribe(second)
+	bus.Publish(desc.UserEventType_DELETED, 1)
+
+	if got := len(first.events); got != 2 {
+		t.Errorf("first subscription got %d events, want 2", got)
+	}
+	if got := len(second.events); got != 1 {
+		t.Errorf("unsubscribed subscription got %d events, want 1", got)
+	}
+	if event := <-second.events; event.eventType != desc.UserEventType_CREATED {
+		t.Errorf("event type = %v, want %v", event.eventType, desc.UserEventType_CREATED)
+	}
+	if first.dropped.Load() != 0 || second.dropped.Load() != 0 {
+		t.Errorf("dropped = %d, %d, want 0", first.dropped.Load(), second.dropped.Load())
+	}
+}
+
+func TestEventBusClose(t *testing.T) {
+	bus := newEventBus()
+
+	select {
+	case <-bus.Done():
+		t.Fatal("Done() is closed before Close()")
+	default:
+	}
+
+	bus.Close()
+	// Повторный вызов не паникует
+	bus.Close()
+
+	select {
+	case <-bus.Done():
+	default:
+		t.Fatal("Done() is not closed after Close()")
+	}
+}
@@ -0,0 +1,210 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// testDSNEnvName - переменная окружения со строкой подключения к тестовой БД.
+//
+// Интеграционные тесты запускаются командой
+//
+//	TEST_PG_DSN=... go test -tags integration ./...
+//
+// на БД с примененными миграциями (make local-migration-up). Без TEST_PG_DSN тесты пропускаются.
+const testDSNEnvName = "TEST_PG_DSN"
+
+// newTestPool подключается к тестовой БД и закрывает пул по завершении теста.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnvName)
+	if len(dsn) == 0 {
+		t.Skipf("%s is not set", testDSNEnvName)
+	}
+
+	pool, err := pgxpool.Connect(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.Connect() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// insertTestUser создает пользователя с уникальным email и удаляет его по завершении теста.
+func insertTestUser(t *testing.T, pool *pgxpool.Pool) int64 {
+	t.Helper()
+
+	email := fmt.Sprintf("user-%d@example.com", time.Now().UnixNano())
+
+	var userID int64
+	err := pool.QueryRow(context.Background(),
+		"INSERT INTO auth (name, email, role, password) VALUES ($1, $2, $3, $4) RETURNING id",
+		"Test user", email, 1, "hash",
+	).Scan(&userID)
+	if err != nil {
+		t.Fatalf("unable to insert user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM auth WHERE id = $1", userID)
+	})
+
+	return userID
+}
+
+func TestUpdatedAtCheckConstraint(t *testing.T) {
+	pool := newTestPool(t)
+	userID := insertTestUser(t, pool)
+
+	_, err := pool.Exec(context.Background(), "UPDATE auth SET updated_at = created_at - interval '1 second' WHERE id = $1", userID)
+	if !isCheckViolation(err, updatedAtCheckConstraint) {
+		t.Fatalf("update with updated_at before created_at: error = %v, want %s violation", err, updatedAtCheckConstraint)
+	}
+
+	_, err = pool.Exec(context.Background(), "UPDATE auth SET updated_at = created_at WHERE id = $1", userID)
+	if err != nil {
+		t.Errorf("update with updated_at equal to created_at: error = %v", err)
+	}
+}
+
+func TestClaimHandleConcurrently(t *testing.T) {
+	pool := newTestPool(t)
+	s := &server{log: zaptest.NewLogger(t), dbPool: pool}
+	ctx := identity.WithCaller(context.Background(), &model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)})
+
+	const claims = 10
+	handle := fmt.Sprintf("handle_%d", time.Now().UnixNano()%1e9)
+
+	userIDs := make([]int64, claims)
+	for i := range userIDs {
+		userIDs[i] = insertTestUser(t, pool)
+	}
+
+	codesCh := make(chan codes.Code, claims)
+	var wg sync.WaitGroup
+	for _, userID := range userIDs {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+
+			_, err := s.ClaimHandle(ctx, &desc.ClaimHandleRequest{Id: userID, Handle: handle})
+			codesCh <- status.Code(err)
+		}(userID)
+	}
+	wg.Wait()
+	close(codesCh)
+
+	results := make(map[codes.Code]int)
+	for code := range codesCh {
+		results[code]++
+	}
+	if results[codes.OK] != 1 || results[codes.AlreadyExists] != claims-1 {
+		t.Fatalf("claim results = %v, want 1 OK and %d AlreadyExists", results, claims-1)
+	}
+
+	// Повторный запрос того же handle владельцем завершается успешно
+	var ownerID int64
+	if err := pool.QueryRow(ctx, "SELECT user_id FROM user_handles WHERE handle = $1", handle).Scan(&ownerID); err != nil {
+		t.Fatalf("unable to read handle owner: %v", err)
+	}
+	if _, err := s.ClaimHandle(ctx, &desc.ClaimHandleRequest{Id: ownerID, Handle: strings.ToUpper(handle)}); err != nil {
+		t.Errorf("repeated ClaimHandle() by owner error = %v", err)
+	}
+}
+
+func TestCheckSchemaIntegration(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	if err := checkSchema(ctx, pool); err != nil {
+		t.Fatalf("checkSchema() on migrated schema error = %v", err)
+	}
+
+	// Измененная копия таблицы auth создается в отдельной схеме, которая становится текущей
+	// для соединений нового пула
+	schema := fmt.Sprintf("schema_check_%d", time.Now().UnixNano())
+	_, err := pool.Exec(ctx, fmt.Sprintf(`CREATE SCHEMA %[1]s;
+		CREATE TABLE %[1]s.auth (LIKE auth);
+		ALTER TABLE %[1]s.auth DROP COLUMN phone;
+		ALTER TABLE %[1]s.auth ALTER COLUMN email_verified TYPE text USING email_verified::text`, schema))
+	if err != nil {
+		t.Fatalf("unable to create tampered schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+	})
+
+	poolConfig, err := pgxpool.ParseConfig(os.Getenv(testDSNEnvName))
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig() error = %v", err)
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+
+	tamperedPool, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		t.Fatalf("pgxpool.ConnectConfig() error = %v", err)
+	}
+	defer tamperedPool.Close()
+
+	err = checkSchema(ctx, tamperedPool)
+	want := `auth table schema mismatch: column email_verified has type "text", expected "boolean"; column phone is missing`
+	if err == nil || err.Error() != want {
+		t.Errorf("checkSchema() on tampered schema error = %v, want %q", err, want)
+	}
+}
+
+func TestGetUserStatsIntegration(t *testing.T) {
+	pool := newTestPool(t)
+	s := &server{log: zaptest.NewLogger(t), dbPool: pool}
+	ctx := context.Background()
+	req := &desc.GetUserStatsRequest{}
+
+	before, err := s.GetUserStats(ctx, req)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+
+	userID := insertTestUser(t, pool)
+	_, err = pool.Exec(ctx, "UPDATE auth SET phone = $1, last_seen = now() WHERE id = $2", "+79991234567", userID)
+	if err != nil {
+		t.Fatalf("unable to update user: %v", err)
+	}
+
+	after, err := s.GetUserStats(ctx, req)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+
+	// Новый пользователь с ролью USER, телефоном и неподтвержденным email только что был в сети
+	diff := map[string][2]int64{
+		"total":          {after.Total - before.Total, 1},
+		"admins":         {after.Admins - before.Admins, 0},
+		"users":          {after.Users - before.Users, 1},
+		"email_verified": {after.EmailVerified - before.EmailVerified, 0},
+		"with_phone":     {after.WithPhone - before.WithPhone, 1},
+		"active":         {after.Active - before.Active, 1},
+	}
+	for name, d := range diff {
+		if d[0] != d[1] {
+			t.Errorf("%s changed by %d, want %d", name, d[0], d[1])
+		}
+	}
+}
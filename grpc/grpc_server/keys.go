@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// errSharedKey - access- и refresh-токены подписываются одним и тем же ключом.
+var errSharedKey = errors.New("access and refresh tokens must be signed with different keys")
+
+// newKeyrings создает наборы ключей подписи access- и refresh-токенов из конфига.
+//
+// Возвращает ошибку, если ключи некорректны или один и тот же ключ (в том числе из файлов ключей)
+// входит в оба набора.
+func newKeyrings(jwtConfig env.JWTConfig) (*token.Keyring, *token.Keyring, error) {
+	accessKeys, err := loadKeys(jwtConfig.AccessTokenKeysFile(), jwtConfig.AccessTokenPrivateKey(), jwtConfig.AccessTokenSecret())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "access token signing keys")
+	}
+
+	refreshKeys, err := loadKeys(jwtConfig.RefreshTokenKeysFile(), jwtConfig.RefreshTokenPrivateKey(), jwtConfig.RefreshTokenSecret())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "refresh token signing keys")
+	}
+
+	accessKeyring, err := token.NewKeyring(accessKeys, jwtConfig.AccessTokenKeyOverlap())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "access token signing keys")
+	}
+	if accessKeyring.SharesKey(refreshKeys) {
+		return nil, nil, errSharedKey
+	}
+
+	refreshKeyring, err := token.NewKeyring(refreshKeys, jwtConfig.RefreshTokenKeyOverlap())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "refresh token signing keys")
+	}
+
+	return accessKeyring, refreshKeyring, nil
+}
+
+// loadKeys возвращает ключи подписи токенов из файла ключей keysFile либо, если файл
+// не задан, один ключ: закрытый ключ privateKey в формате PEM ("kid" - его отпечаток
+// по RFC 7638) или секрет secret (токены без "kid").
+func loadKeys(keysFile string, privateKey, secret []byte) ([]token.Key, error) {
+	if len(keysFile) == 0 && len(privateKey) > 0 {
+		signer, err := token.ParsePrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		kid, err := token.Thumbprint(signer)
+		if err != nil {
+			return nil, err
+		}
+
+		return []token.Key{{ID: kid, PrivateKey: signer}}, nil
+	}
+
+	if len(keysFile) == 0 {
+		return []token.Key{{Secret: secret}}, nil
+	}
+
+	return token.LoadKeys(keysFile)
+}
+
+// reloadKeys перечитывает файл ключей keysFile раз в interval до отмены ctx, чтобы новые ключи,
+// добавленные при плановой ротации, применялись без перезапуска сервера.
+//
+// Если файл не удалось прочитать, ключи некорректны или один из них входит в набор ключей other
+// (ключи токенов другого вида), ошибка логируется и остаются прежние ключи.
+func reloadKeys(ctx context.Context, keyring, other *token.Keyring, keysFile string, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		keys, err := token.LoadKeys(keysFile)
+		if err == nil && other.SharesKey(keys) {
+			err = errSharedKey
+		}
+		if err == nil {
+			err = keyring.Replace(keys)
+		}
+		if err != nil {
+			logger.Error("Unable to reload signing keys", zap.String("File", keysFile), zap.Error(err))
+		}
+	}
+}
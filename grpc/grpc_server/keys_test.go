@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anton0701/auth/config/env"
+)
+
+// fakeJWTConfig - конфиг токенов с ключами из файлов accessKeysFile и refreshKeysFile.
+type fakeJWTConfig struct {
+	env.JWTConfig
+	accessKeysFile  string
+	refreshKeysFile string
+}
+
+func (c *fakeJWTConfig) AccessTokenKeysFile() string           { return c.accessKeysFile }
+func (c *fakeJWTConfig) AccessTokenPrivateKey() []byte         { return nil }
+func (c *fakeJWTConfig) AccessTokenSecret() []byte             { return nil }
+func (c *fakeJWTConfig) AccessTokenKeyOverlap() time.Duration  { return time.Minute }
+func (c *fakeJWTConfig) RefreshTokenKeysFile() string          { return c.refreshKeysFile }
+func (c *fakeJWTConfig) RefreshTokenPrivateKey() []byte        { return nil }
+func (c *fakeJWTConfig) RefreshTokenSecret() []byte            { return nil }
+func (c *fakeJWTConfig) RefreshTokenKeyOverlap() time.Duration { return time.Hour }
+
+func TestNewKeyringsSharedKey(t *testing.T) {
+	t.Setenv("JWT_TEST_KEY_1", "0123456789abcdef0123456789abcdef")
+	t.Setenv("JWT_TEST_KEY_2", "fedcba9876543210fedcba9876543210")
+	t.Setenv("JWT_TEST_KEY_3", "abcdef0123456789abcdef0123456789")
+
+	dir := t.TempDir()
+	writeKeysFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	accessKeysFile := writeKeysFile("access.json", `{"keys": [
+		{"kid": "access-1", "secret_env": "JWT_TEST_KEY_1"},
+		{"kid": "access-2", "secret_env": "JWT_TEST_KEY_2", "activates_at": "2024-12-01T00:00:00Z"}
+	]}`)
+
+	tests := []struct {
+		name            string
+		refreshKeysFile string
+		wantErr         error
+	}{
+		{
+			name:            "different keys",
+			refreshKeysFile: writeKeysFile("refresh.json", `{"keys": [{"kid": "refresh-1", "secret_env": "JWT_TEST_KEY_3"}]}`),
+		},
+		{
+			name:            "rotated key reused",
+			refreshKeysFile: writeKeysFile("refresh-shared.json", `{"keys": [{"kid": "refresh-1", "secret_env": "JWT_TEST_KEY_2"}]}`),
+			wantErr:         errSharedKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newKeyrings(&fakeJWTConfig{accessKeysFile: accessKeysFile, refreshKeysFile: tt.refreshKeysFile})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("newKeyrings() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+// userOrderExpressions - SQL-выражения для полей сортировки списка пользователей.
+//
+// В ORDER BY попадают только эти выражения, а не значение из запроса. Пользователи без изменений
+// (updated_at IS NULL) при сортировке по updated_at упорядочиваются по created_at: так в выражении
+// нет NULL и по нему работает keyset-пагинация.
+var userOrderExpressions = map[string]string{
+	desc.OrderByID:        "id",
+	desc.OrderByName:      "name",
+	desc.OrderByEmail:     "email",
+	desc.OrderByCreatedAt: "created_at",
+	desc.OrderByUpdatedAt: "coalesce(updated_at, created_at)",
+}
+
+// likeEscaper экранирует специальные символы шаблона LIKE, чтобы подстрока из запроса
+// искалась буквально (в Postgres символ экранирования по умолчанию - "\").
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// usersFilterCondition возвращает условие WHERE для списка пользователей по фильтру filter.
+// Для пустого фильтра возвращается пустое условие (без WHERE).
+func usersFilterCondition(filter *desc.ListUsersFilter) sq.And {
+	var conditions sq.And
+
+	if filter.GetRole() != desc.UserRole_UNKNOWN {
+		conditions = append(conditions, sq.Eq{"role": int32(filter.GetRole())})
+	}
+
+	if email := strings.TrimSpace(filter.GetEmail()); len(email) > 0 {
+		conditions = append(conditions, sq.ILike{"email": "%" + likeEscaper.Replace(email) + "%"})
+	}
+
+	if domain := desc.NormalizeEmail(filter.GetEmailDomain()); len(domain) > 0 {
+		conditions = append(conditions, sq.Eq{"lower(split_part(email, '@', 2))": domain})
+	}
+
+	if filter.GetCreatedAfter() != nil {
+		conditions = append(conditions, sq.GtOrEq{"created_at": filter.GetCreatedAfter().AsTime()})
+	}
+	if filter.GetCreatedBefore() != nil {
+		conditions = append(conditions, sq.Lt{"created_at": filter.GetCreatedBefore().AsTime()})
+	}
+
+	switch filter.GetStatus() {
+	case desc.UserStatus_EMAIL_VERIFIED:
+		conditions = append(conditions, sq.Eq{"email_verified": true})
+	case desc.UserStatus_EMAIL_UNVERIFIED:
+		conditions = append(conditions, sq.Eq{"email_verified": false})
+	}
+
+	return conditions
+}
+
+// usersOrderBy возвращает выражения ORDER BY для порядка order. Пользователи с равными значениями
+// поля упорядочиваются по ID в том же направлении, чтобы порядок был однозначным.
+func usersOrderBy(order desc.UserOrder) []string {
+	direction := " ASC"
+	if order.Descending {
+		direction = " DESC"
+	}
+
+	if order.Field == desc.OrderByID {
+		return []string{"id" + direction}
+	}
+
+	return []string{userOrderExpressions[order.Field] + direction, "id" + direction}
+}
+
+// usersAfterCursor возвращает условие WHERE для пользователей, следующих в порядке order
+// за последним пользователем предыдущей страницы cursor.
+//
+// Возвращает ошибку InvalidArgument, если значение поля сортировки в курсоре некорректное.
+func usersAfterCursor(order desc.UserOrder, cursor pageToken) (sq.Sqlizer, error) {
+	comparison := ">"
+	if order.Descending {
+		comparison = "<"
+	}
+
+	if order.Field == desc.OrderByID {
+		return sq.Expr("id "+comparison+" ?", cursor.LastID), nil
+	}
+
+	var lastValue interface{} = cursor.LastValue
+	if order.Field == desc.OrderByCreatedAt || order.Field == desc.OrderByUpdatedAt {
+		lastTime, err := time.Parse(time.RFC3339Nano, cursor.LastValue)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid page_token")
+		}
+		lastValue = lastTime
+	}
+
+	// Сравнение пар (значение поля, ID) соответствует порядку ORDER BY из usersOrderBy
+	return sq.Expr("("+userOrderExpressions[order.Field]+", id) "+comparison+" (?, ?)", lastValue, cursor.LastID), nil
+}
+
+// usersSortValue возвращает значение поля сортировки order пользователя для курсора. Время
+// берется из БД без округления до точности ответа, иначе курсор указывал бы на другую позицию.
+func usersSortValue(order desc.UserOrder, user *desc.User, createdAt time.Time, updatedAt sql.NullTime) string {
+	switch order.Field {
+	case desc.OrderByName:
+		return user.Name
+	case desc.OrderByEmail:
+		return user.Email
+	case desc.OrderByCreatedAt:
+		return createdAt.Format(time.RFC3339Nano)
+	case desc.OrderByUpdatedAt:
+		if updatedAt.Valid {
+			return updatedAt.Time.Format(time.RFC3339Nano)
+		}
+		return createdAt.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// listUsersCreatedAt - время создания первого пользователя в тестах списка пользователей.
+var listUsersCreatedAt = time.Date(2024, 11, 11, 10, 0, 0, 0, time.UTC)
+
+// listUsersRows возвращает строки пользователей с ID из ids для запроса списка пользователей.
+func listUsersRows(ids ...int64) []fakeRow {
+	rows := make([]fakeRow, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, fakeRow{values: []interface{}{
+			id, "User", "user@example.com", desc.UserRole_USER,
+			listUsersCreatedAt.Add(time.Duration(id) * time.Hour), sql.NullTime{}, sql.NullString{},
+		}})
+	}
+
+	return rows
+}
+
+// newListUsersClient запускает сервер с БД db, вызываемый администратором.
+func newListUsersClient(t *testing.T, db *fakeDB) desc.UserV1Client {
+	t.Helper()
+
+	return newTestServer(t,
+		withDB(db),
+		withCaller(&model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}),
+	)
+}
+
+func TestListUsersOffsetPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *desc.ListUsersRequest
+		rows          []fakeRow
+		wantIDs       []int64
+		wantSQL       string
+		wantNextToken bool
+	}{
+		{
+			name:          "first page",
+			req:           &desc.ListUsersRequest{PageSize: 2},
+			rows:          listUsersRows(1, 2, 3),
+			wantIDs:       []int64{1, 2},
+			wantSQL:       "ORDER BY id ASC LIMIT 3 OFFSET 0",
+			wantNextToken: true,
+		},
+		{
+			name:          "third page",
+			req:           &desc.ListUsersRequest{PageSize: 2, PageNumber: 3},
+			rows:          listUsersRows(5, 6, 7),
+			wantIDs:       []int64{5, 6},
+			wantSQL:       "ORDER BY id ASC LIMIT 3 OFFSET 4",
+			wantNextToken: true,
+		},
+		{
+			name:    "last page",
+			req:     &desc.ListUsersRequest{PageSize: 2, PageNumber: 5},
+			rows:    listUsersRows(9),
+			wantIDs: []int64{9},
+			wantSQL: "ORDER BY id ASC LIMIT 3 OFFSET 8",
+		},
+		{
+			name:    "page after last",
+			req:     &desc.ListUsersRequest{PageSize: 2, PageNumber: 6},
+			wantSQL: "ORDER BY id ASC LIMIT 3 OFFSET 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: fakeRow{values: []interface{}{uint64(9)}}, rows: tt.rows}
+			client := newListUsersClient(t, db)
+
+			resp, err := client.ListUsers(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+
+			var ids []int64
+			for _, user := range resp.GetUsers() {
+				ids = append(ids, user.GetId())
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("user ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if resp.GetTotalCount() != 9 {
+				t.Errorf("TotalCount = %d, want 9", resp.GetTotalCount())
+			}
+			if got := len(resp.GetNextPageToken()) > 0; got != tt.wantNextToken {
+				t.Errorf("NextPageToken = %q, want token %t", resp.GetNextPageToken(), tt.wantNextToken)
+			}
+
+			if len(db.queries) != 2 || db.queries[0] != "SELECT count(*) FROM auth" {
+				t.Fatalf("queries = %q, want count and select", db.queries)
+			}
+			if !strings.HasSuffix(db.queries[1], tt.wantSQL) {
+				t.Errorf("query = %q, want suffix %q", db.queries[1], tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestListUsersPageToken(t *testing.T) {
+	// Курсор после пользователя 2 выдается на первой странице списка
+	first := &fakeDB{row: fakeRow{values: []interface{}{uint64(9)}}, rows: listUsersRows(1, 2, 3)}
+	resp, err := newListUsersClient(t, first).ListUsers(context.Background(), &desc.ListUsersRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	pageToken := resp.GetNextPageToken()
+
+	tests := []struct {
+		name     string
+		req      *desc.ListUsersRequest
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode codes.Code
+	}{
+		{
+			name:     "next page",
+			req:      &desc.ListUsersRequest{PageSize: 2, PageToken: pageToken},
+			wantSQL:  "SELECT id, name, email, role, created_at, updated_at, phone FROM auth WHERE id > $1 ORDER BY id ASC LIMIT 3",
+			wantArgs: []interface{}{int64(2)},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "other page size",
+			req:      &desc.ListUsersRequest{PageSize: 5, PageToken: pageToken},
+			wantSQL:  "SELECT id, name, email, role, created_at, updated_at, phone FROM auth WHERE id > $1 ORDER BY id ASC LIMIT 6",
+			wantArgs: []interface{}{int64(2)},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "other order",
+			req:      &desc.ListUsersRequest{PageSize: 2, PageToken: pageToken, OrderBy: "id desc"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "other filter",
+			req:      &desc.ListUsersRequest{PageSize: 2, PageToken: pageToken, Filter: &desc.ListUsersFilter{Role: desc.UserRole_ADMIN}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "corrupted token",
+			req:      &desc.ListUsersRequest{PageSize: 2, PageToken: pageToken[1:]},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{rows: listUsersRows(3, 4)}
+
+			resp, err := newListUsersClient(t, db).ListUsers(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ListUsers() code = %s, want %s (error %v)", code, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				if len(db.queries) != 0 {
+					t.Errorf("queries = %q, want none", db.queries)
+				}
+				return
+			}
+
+			// Общее количество пользователей для следующих страниц не считается
+			if len(db.queries) != 1 || db.queries[0] != tt.wantSQL {
+				t.Fatalf("queries = %q, want [%q]", db.queries, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(db.args[0], tt.wantArgs) {
+				t.Errorf("args = %v, want %v", db.args[0], tt.wantArgs)
+			}
+			if resp.GetTotalCount() != 0 || len(resp.GetUsers()) != 2 {
+				t.Errorf("ListUsers() = %d users of %d, want 2 users without total count", len(resp.GetUsers()), resp.GetTotalCount())
+			}
+		})
+	}
+}
+
+func TestUsersFilterCondition(t *testing.T) {
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdBefore := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   *desc.ListUsersFilter
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{name: "without filter"},
+		{name: "empty filter", filter: &desc.ListUsersFilter{Email: "  "}},
+		{
+			name:     "role",
+			filter:   &desc.ListUsersFilter{Role: desc.UserRole_ADMIN},
+			wantSQL:  "(role = ?)",
+			wantArgs: []interface{}{int32(desc.UserRole_ADMIN)},
+		},
+		{
+			name:     "email substring",
+			filter:   &desc.ListUsersFilter{Email: " Alice "},
+			wantSQL:  "(email ILIKE ?)",
+			wantArgs: []interface{}{"%Alice%"},
+		},
+		{
+			name:     "email with like characters",
+			filter:   &desc.ListUsersFilter{Email: `a_b%c\d`},
+			wantSQL:  "(email ILIKE ?)",
+			wantArgs: []interface{}{`%a\_b\%c\\d%`},
+		},
+		{
+			name:     "email domain",
+			filter:   &desc.ListUsersFilter{EmailDomain: " Example.COM "},
+			wantSQL:  "(lower(split_part(email, '@', 2)) = ?)",
+			wantArgs: []interface{}{"example.com"},
+		},
+		{
+			name: "created range",
+			filter: &desc.ListUsersFilter{
+				CreatedAfter:  timestamppb.New(createdAfter),
+				CreatedBefore: timestamppb.New(createdBefore),
+			},
+			wantSQL:  "(created_at >= ? AND created_at < ?)",
+			wantArgs: []interface{}{createdAfter, createdBefore},
+		},
+		{
+			name:     "verified",
+			filter:   &desc.ListUsersFilter{Status: desc.UserStatus_EMAIL_VERIFIED},
+			wantSQL:  "(email_verified = ?)",
+			wantArgs: []interface{}{true},
+		},
+		{
+			name:     "unverified",
+			filter:   &desc.ListUsersFilter{Status: desc.UserStatus_EMAIL_UNVERIFIED},
+			wantSQL:  "(email_verified = ?)",
+			wantArgs: []interface{}{false},
+		},
+		{
+			name:     "all fields",
+			filter:   &desc.ListUsersFilter{Role: desc.UserRole_USER, Email: "bob", EmailDomain: "example.com", Status: desc.UserStatus_EMAIL_VERIFIED},
+			wantSQL:  "(role = ? AND email ILIKE ? AND lower(split_part(email, '@', 2)) = ? AND email_verified = ?)",
+			wantArgs: []interface{}{int32(desc.UserRole_USER), "%bob%", "example.com", true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := usersFilterCondition(tt.filter)
+			if len(tt.wantSQL) == 0 {
+				if len(condition) != 0 {
+					t.Errorf("usersFilterCondition() = %v, want empty condition", condition)
+				}
+				return
+			}
+
+			query, args, err := condition.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if query != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("usersFilterCondition() = %q %v, want %q %v", query, args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestListUsersFilter(t *testing.T) {
+	db := &fakeDB{row: fakeRow{values: []interface{}{uint64(1)}}, rows: listUsersRows(4)}
+
+	_, err := newListUsersClient(t, db).ListUsers(context.Background(), &desc.ListUsersRequest{
+		PageSize: 2,
+		Filter:   &desc.ListUsersFilter{Role: desc.UserRole_ADMIN, EmailDomain: "example.com"},
+	})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+
+	// Фильтр применяется и к подсчету пользователей, и к странице
+	wantQueries := []string{
+		"SELECT count(*) FROM auth WHERE (role = $1 AND lower(split_part(email, '@', 2)) = $2)",
+		"SELECT id, name, email, role, created_at, updated_at, phone FROM auth " +
+			"WHERE (role = $1 AND lower(split_part(email, '@', 2)) = $2) ORDER BY id ASC LIMIT 3 OFFSET 0",
+	}
+	if !slices.Equal(db.queries, wantQueries) {
+		t.Errorf("queries = %q, want %q", db.queries, wantQueries)
+	}
+	for _, args := range db.args {
+		if want := []interface{}{int32(desc.UserRole_ADMIN), "example.com"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %v, want %v", args, want)
+		}
+	}
+}
+
+func TestUsersOrderBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		order desc.UserOrder
+		want  []string
+	}{
+		{name: "id", order: desc.UserOrder{Field: desc.OrderByID}, want: []string{"id ASC"}},
+		{name: "id desc", order: desc.UserOrder{Field: desc.OrderByID, Descending: true}, want: []string{"id DESC"}},
+		{name: "name", order: desc.UserOrder{Field: desc.OrderByName}, want: []string{"name ASC", "id ASC"}},
+		{
+			name:  "updated_at desc",
+			order: desc.UserOrder{Field: desc.OrderByUpdatedAt, Descending: true},
+			want:  []string{"coalesce(updated_at, created_at) DESC", "id DESC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usersOrderBy(tt.order); !slices.Equal(got, tt.want) {
+				t.Errorf("usersOrderBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsersAfterCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    desc.UserOrder
+		cursor   pageToken
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode codes.Code
+	}{
+		{
+			name:     "id",
+			order:    desc.UserOrder{Field: desc.OrderByID},
+			cursor:   pageToken{LastID: 5},
+			wantSQL:  "id > ?",
+			wantArgs: []interface{}{int64(5)},
+		},
+		{
+			name:     "id desc",
+			order:    desc.UserOrder{Field: desc.OrderByID, Descending: true},
+			cursor:   pageToken{LastID: 5},
+			wantSQL:  "id < ?",
+			wantArgs: []interface{}{int64(5)},
+		},
+		{
+			name:     "name",
+			order:    desc.UserOrder{Field: desc.OrderByName},
+			cursor:   pageToken{LastID: 5, LastValue: "Alice"},
+			wantSQL:  "(name, id) > (?, ?)",
+			wantArgs: []interface{}{"Alice", int64(5)},
+		},
+		{
+			name:     "created_at desc",
+			order:    desc.UserOrder{Field: desc.OrderByCreatedAt, Descending: true},
+			cursor:   pageToken{LastID: 5, LastValue: "2024-11-11T10:00:00.123456Z"},
+			wantSQL:  "(created_at, id) < (?, ?)",
+			wantArgs: []interface{}{time.Date(2024, 11, 11, 10, 0, 0, 123456000, time.UTC), int64(5)},
+		},
+		{
+			name:     "updated_at",
+			order:    desc.UserOrder{Field: desc.OrderByUpdatedAt},
+			cursor:   pageToken{LastID: 5, LastValue: "2024-11-11T10:00:00Z"},
+			wantSQL:  "(coalesce(updated_at, created_at), id) > (?, ?)",
+			wantArgs: []interface{}{time.Date(2024, 11, 11, 10, 0, 0, 0, time.UTC), int64(5)},
+		},
+		{
+			name:     "invalid time",
+			order:    desc.UserOrder{Field: desc.OrderByCreatedAt},
+			cursor:   pageToken{LastID: 5, LastValue: "yesterday"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty time",
+			order:    desc.UserOrder{Field: desc.OrderByUpdatedAt},
+			cursor:   pageToken{LastID: 5},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := usersAfterCursor(tt.order, tt.cursor)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("usersAfterCursor() code = %s, want %s", code, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			query, args, err := condition.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if query != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("usersAfterCursor() = %q %v, want %q %v", query, args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestUsersSortValue(t *testing.T) {
+	user := &desc.User{Name: "Alice", Email: "alice@example.com"}
+	createdAt := time.Date(2024, 11, 11, 10, 0, 0, 123456789, time.UTC)
+	updatedAt := sql.NullTime{Time: time.Date(2024, 11, 12, 10, 0, 0, 0, time.UTC), Valid: true}
+
+	tests := []struct {
+		name      string
+		order     desc.UserOrder
+		updatedAt sql.NullTime
+		want      string
+	}{
+		{name: "id", order: desc.UserOrder{Field: desc.OrderByID}, want: ""},
+		{name: "name", order: desc.UserOrder{Field: desc.OrderByName}, want: "Alice"},
+		{name: "email", order: desc.UserOrder{Field: desc.OrderByEmail}, want: "alice@example.com"},
+		{name: "created_at", order: desc.UserOrder{Field: desc.OrderByCreatedAt}, want: "2024-11-11T10:00:00.123456789Z"},
+		{name: "updated_at", order: desc.UserOrder{Field: desc.OrderByUpdatedAt}, updatedAt: updatedAt, want: "2024-11-12T10:00:00Z"},
+		{name: "never updated", order: desc.UserOrder{Field: desc.OrderByUpdatedAt}, want: "2024-11-11T10:00:00.123456789Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usersSortValue(tt.order, user, createdAt, tt.updatedAt); got != tt.want {
+				t.Errorf("usersSortValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListUsersOrderPageToken(t *testing.T) {
+	// Курсор следующей страницы содержит значение поля сортировки последнего пользователя страницы
+	db := &fakeDB{row: fakeRow{values: []interface{}{uint64(3)}}, rows: listUsersRows(3, 2, 1)}
+	client := newListUsersClient(t, db)
+
+	resp, err := client.ListUsers(context.Background(), &desc.ListUsersRequest{PageSize: 2, OrderBy: "created_at desc"})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if want := "ORDER BY created_at DESC, id DESC LIMIT 3 OFFSET 0"; !strings.HasSuffix(db.queries[1], want) {
+		t.Errorf("query = %q, want suffix %q", db.queries[1], want)
+	}
+
+	next := &fakeDB{rows: listUsersRows(1)}
+	_, err = newListUsersClient(t, next).ListUsers(context.Background(), &desc.ListUsersRequest{
+		PageSize:  2,
+		OrderBy:   "CREATED_AT desc",
+		PageToken: resp.GetNextPageToken(),
+	})
+	if err != nil {
+		t.Fatalf("ListUsers() with page token error = %v", err)
+	}
+
+	wantSQL := "SELECT id, name, email, role, created_at, updated_at, phone FROM auth " +
+		"WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT 3"
+	wantArgs := []interface{}{listUsersCreatedAt.Add(2 * time.Hour), int64(2)}
+	if len(next.queries) != 1 || next.queries[0] != wantSQL {
+		t.Fatalf("queries = %q, want [%q]", next.queries, wantSQL)
+	}
+	if !reflect.DeepEqual(next.args[0], wantArgs) {
+		t.Errorf("args = %v, want %v", next.args[0], wantArgs)
+	}
+}
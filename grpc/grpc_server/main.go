@@ -3,18 +3,27 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -22,17 +31,81 @@ import (
 
 	config "github.com/anton0701/auth/config"
 	env "github.com/anton0701/auth/config/env"
+	accessDesc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	authDesc "github.com/anton0701/auth/grpc/pkg/auth_v1"
 	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	accessAPI "github.com/anton0701/auth/internal/api/access"
+	authAPI "github.com/anton0701/auth/internal/api/auth"
+	"github.com/anton0701/auth/internal/hasher"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/interceptor"
+	"github.com/anton0701/auth/internal/mail"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/oidc"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/redact"
+	accessRepository "github.com/anton0701/auth/internal/repository/access"
+	apiKeyRepository "github.com/anton0701/auth/internal/repository/apikey"
+	auditRepository "github.com/anton0701/auth/internal/repository/audit"
+	emailVerificationRepository "github.com/anton0701/auth/internal/repository/emailverification"
+	lockoutRepository "github.com/anton0701/auth/internal/repository/lockout"
+	passwordResetRepository "github.com/anton0701/auth/internal/repository/passwordreset"
+	recoveryCodeRepository "github.com/anton0701/auth/internal/repository/recoverycode"
+	revocationRepository "github.com/anton0701/auth/internal/repository/revocation"
+	serviceAccountRepository "github.com/anton0701/auth/internal/repository/serviceaccount"
+	totpRepository "github.com/anton0701/auth/internal/repository/totp"
+	userRepository "github.com/anton0701/auth/internal/repository/user"
+	"github.com/anton0701/auth/internal/secretbox"
+	"github.com/anton0701/auth/internal/service"
+	accessService "github.com/anton0701/auth/internal/service/access"
+	apiKeyService "github.com/anton0701/auth/internal/service/apikey"
+	auditService "github.com/anton0701/auth/internal/service/audit"
+	authService "github.com/anton0701/auth/internal/service/auth"
+	emailVerificationService "github.com/anton0701/auth/internal/service/emailverification"
+	lockoutService "github.com/anton0701/auth/internal/service/lockout"
+	passwordResetService "github.com/anton0701/auth/internal/service/passwordreset"
+	serviceAccountService "github.com/anton0701/auth/internal/service/serviceaccount"
+	twoFactorService "github.com/anton0701/auth/internal/service/twofactor"
+	userService "github.com/anton0701/auth/internal/service/user"
+	"github.com/anton0701/auth/internal/token"
 )
 
 const (
 	grpcUserAPIDesc = "User-API-v1"
+
+	// dbPingInterval - интервал проверки доступности БД для readiness.
+	dbPingInterval = 5 * time.Second
+
+	// httpReadHeaderTimeout - максимальное время чтения заголовков запроса к HTTP-серверу.
+	httpReadHeaderTimeout = 10 * time.Second
+	// httpShutdownTimeout - максимальное время завершения текущих запросов к HTTP-серверу при остановке.
+	httpShutdownTimeout = 10 * time.Second
+
+	// defaultActiveUsersWindow - за какой период пользователь, бывший в сети, считается активным
+	// в статистике пользователей, если начало периода не указано в запросе.
+	defaultActiveUsersWindow = 30 * 24 * time.Hour
 )
 
+// dbQuerier - методы пула соединений с БД, которыми обработчики запросов выполняют запросы.
+// Реализуется *pgxpool.Pool, в тестах подменяется.
+type dbQuerier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 type server struct {
 	desc.UnimplementedUserV1Server
-	dbPool *pgxpool.Pool
-	log    *zap.Logger
+	dbPool             dbQuerier
+	userService        service.UserService
+	emailVerification  service.EmailVerificationService
+	audit              service.AuditService
+	log                *zap.Logger
+	deadlockMaxRetries int
+	timestampPrecision time.Duration
+	warningsAsErrors   bool
+	activity           *activityRecorder
+	events             *eventBus
 }
 
 var configPath string
@@ -60,30 +133,343 @@ func main() {
 		logger.Fatal("Unable to get grpc config", zap.Error(err))
 	}
 
+	httpConfig, err := env.NewHTTPConfig()
+	if err != nil {
+		logger.Fatal("Unable to get http config", zap.Error(err))
+	}
+
 	pgConfig, err := env.NewPGConfig()
 	if err != nil {
 		logger.Fatal("Unable to get postgres config", zap.Error(err))
 	}
 
+	validationConfig, err := env.NewValidationConfig()
+	if err != nil {
+		logger.Fatal("Unable to get validation config", zap.Error(err))
+	}
+
+	activityConfig, err := env.NewActivityConfig()
+	if err != nil {
+		logger.Fatal("Unable to get activity config", zap.Error(err))
+	}
+
+	jwtConfig, err := env.NewJWTConfig()
+	if err != nil {
+		logger.Fatal("Unable to get jwt config", zap.Error(err))
+	}
+
+	accessConfig, err := env.NewAccessConfig()
+	if err != nil {
+		logger.Fatal("Unable to get access config", zap.Error(err))
+	}
+
+	passwordHashConfig, err := env.NewPasswordHashConfig()
+	if err != nil {
+		logger.Fatal("Unable to get password hash config", zap.Error(err))
+	}
+
+	passwordPolicyConfig, err := env.NewPasswordPolicyConfig()
+	if err != nil {
+		logger.Fatal("Unable to get password policy config", zap.Error(err))
+	}
+
+	mailConfig, err := env.NewMailConfig()
+	if err != nil {
+		logger.Fatal("Unable to get mail config", zap.Error(err))
+	}
+
+	passwordResetConfig, err := env.NewPasswordResetConfig()
+	if err != nil {
+		logger.Fatal("Unable to get password reset config", zap.Error(err))
+	}
+
+	emailVerificationConfig, err := env.NewEmailVerificationConfig()
+	if err != nil {
+		logger.Fatal("Unable to get email verification config", zap.Error(err))
+	}
+
+	totpConfig, err := env.NewTOTPConfig()
+	if err != nil {
+		logger.Fatal("Unable to get totp config", zap.Error(err))
+	}
+
+	lockoutConfig, err := env.NewLockoutConfig()
+	if err != nil {
+		logger.Fatal("Unable to get lockout config", zap.Error(err))
+	}
+
+	passwordHasher, err := hasher.New(passwordHashConfig.Algorithm(), passwordHashConfig.BcryptCost(), hasher.Argon2idParams{
+		Memory:      passwordHashConfig.Argon2idMemory(),
+		Iterations:  passwordHashConfig.Argon2idIterations(),
+		Parallelism: passwordHashConfig.Argon2idParallelism(),
+	})
+	if err != nil {
+		logger.Fatal("Unable to create password hasher", zap.Error(err))
+	}
+
+	passwordPolicy, err := passwordpolicy.New(passwordpolicy.Rules{
+		MinLength:      passwordPolicyConfig.MinLength(),
+		RequireLower:   passwordPolicyConfig.RequireLower(),
+		RequireUpper:   passwordPolicyConfig.RequireUpper(),
+		RequireDigit:   passwordPolicyConfig.RequireDigit(),
+		RequireSymbol:  passwordPolicyConfig.RequireSymbol(),
+		DenyCommon:     passwordPolicyConfig.DenyCommon(),
+		DenyListFile:   passwordPolicyConfig.DenyListFile(),
+		RejectIdentity: validationConfig.RejectPasswordIdentity(),
+	})
+	if err != nil {
+		logger.Fatal("Unable to create password policy", zap.Error(err))
+	}
+
+	mailSender, err := mail.New(mailConfig.Sender(), mail.SMTPParams{
+		Addr:     mailConfig.SMTPAddr(),
+		Username: mailConfig.SMTPUsername(),
+		Password: mailConfig.SMTPPassword(),
+		From:     mailConfig.From(),
+	}, logger)
+	if err != nil {
+		logger.Fatal("Unable to create mail sender", zap.Error(err))
+	}
+
+	totpSecretBox, err := secretbox.New(totpConfig.EncryptionKey())
+	if err != nil {
+		logger.Fatal("Unable to create totp secret box", zap.Error(err))
+	}
+
+	accessKeys, refreshKeys, err := newKeyrings(jwtConfig)
+	if err != nil {
+		logger.Fatal("Unable to load token signing keys", zap.Error(err))
+	}
+
+	// Ключи из файлов перечитываются, чтобы ротация не требовала перезапуска сервера
+	if keysFile := jwtConfig.AccessTokenKeysFile(); len(keysFile) > 0 {
+		go reloadKeys(ctx, accessKeys, refreshKeys, keysFile, jwtConfig.KeysReloadInterval(), logger)
+	}
+	if keysFile := jwtConfig.RefreshTokenKeysFile(); len(keysFile) > 0 {
+		go reloadKeys(ctx, refreshKeys, accessKeys, keysFile, jwtConfig.KeysReloadInterval(), logger)
+	}
+
 	lis, err := net.Listen("tcp", grpcConfig.Address())
 	if err != nil {
 		logger.Panic("Failed to listen", zap.Error(err))
 	}
 
-	pool, err := pgxpool.Connect(ctx, pgConfig.DSN())
+	poolConfig, err := newPoolConfig(pgConfig, logger)
+	if err != nil {
+		logger.Fatal("Unable to parse postgres config", zap.Error(err))
+	}
+
+	// В режиме eager подключение повторяется, пока БД не станет доступна или не кончатся попытки.
+	// В режиме lazy ConnectConfig не подключается к БД и не возвращает ошибку подключения
+	var pool *pgxpool.Pool
+	err = connectWithRetry(ctx, pgConfig.ConnectMaxRetries(), dbConnectBaseDelay, func(ctx context.Context) error {
+		var connectErr error
+		pool, connectErr = pgxpool.ConnectConfig(ctx, poolConfig)
+		return connectErr
+	}, logger)
 	if err != nil {
 		logger.Panic("Unable to connect to db", zap.Error(err))
 	}
 
-	s := grpc.NewServer()
+	// Сервер готов принимать запросы (readiness), пока доступна БД. В режиме lazy схема БД
+	// проверяется, когда БД впервые станет доступна, в режиме eager - до старта сервера
+	readiness := health.NewServer()
+	readiness.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	var onFirstReachable func()
+	if pgConfig.LazyConnect() {
+		onFirstReachable = func() { mustCheckSchema(ctx, pool, logger) }
+	} else {
+		mustCheckSchema(ctx, pool, logger)
+		readiness.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	}
+	dbWatchCtx, stopDBWatch := context.WithCancel(ctx)
+	go watchDB(dbWatchCtx, pool, dbPingInterval, readiness, onFirstReachable, logger)
+
+	// Учет активности пользователей работает до остановки сервера
+	activityCtx, stopActivity := context.WithCancel(ctx)
+	activity := newActivityRecorder(pool, logger, activityConfig.FlushInterval())
+	activityDone := make(chan struct{})
+	go func() {
+		defer close(activityDone)
+		activity.Run(activityCtx)
+	}()
+
+	events := newEventBus()
+
+	issuerParams := token.IssuerParams{
+		Issuer:   jwtConfig.Issuer(),
+		Audience: jwtConfig.Audience(),
+	}
+	auditServ := auditService.NewService(auditRepository.NewRepository(pool), logger)
+
+	users := userRepository.NewRepository(pool)
+	revokedTokens := revocationRepository.NewRepository(pool)
+	lockoutServ := lockoutService.NewService(lockoutRepository.NewRepository(pool), lockoutConfig)
+	userServ, err := userService.NewService(users, passwordHasher, passwordPolicy, lockoutServ, auditServ, logger)
+	if err != nil {
+		logger.Fatal("Unable to create user service", zap.Error(err))
+	}
+
+	emailVerificationServ := emailVerificationService.NewService(
+		emailVerificationRepository.NewRepository(pool),
+		mailSender,
+		emailVerificationConfig,
+		logger,
+	)
+
+	twoFactorServ := twoFactorService.NewService(
+		users,
+		totpRepository.NewRepository(pool),
+		recoveryCodeRepository.NewRepository(pool),
+		totpSecretBox,
+		totpConfig.Issuer(),
+	)
+
+	apiKeys := apiKeyRepository.NewRepository(pool)
+	serviceAccountServ := serviceAccountService.NewService(
+		serviceAccountRepository.NewRepository(pool),
+		auditServ,
+		accessKeys,
+		jwtConfig.AccessTokenTTL(),
+		issuerParams,
+	)
+	accessServ := accessService.NewService(
+		accessKeys,
+		issuerParams,
+		revokedTokens,
+		accessRepository.NewRepository(pool),
+		apiKeys,
+		accessConfig.PolicyCacheTTL(),
+		logger,
+	)
+
+	// Требования к ролям для методов сервера проверяются до вызова обработчика
+	accessInterceptor := interceptor.NewAccessInterceptor(accessServ, logger)
+	// Вызовы методов, объявленных до реализации, получают ответ с планируемой версией реализации
+	unimplementedInterceptor := interceptor.NewUnimplementedInterceptor(interceptor.PlannedMethods)
+
+	serverCredentials, err := newServerCredentials(grpcConfig)
+	if err != nil {
+		logger.Fatal("Unable to load grpc tls credentials", zap.Error(err))
+	}
+	if grpcConfig.Insecure() {
+		logger.Warn("GRPC server accepts connections without TLS")
+	}
+
+	s := grpc.NewServer(
+		grpc.Creds(serverCredentials),
+		grpc.ChainUnaryInterceptor(accessInterceptor.Unary, unimplementedInterceptor.Unary),
+		grpc.ChainStreamInterceptor(accessInterceptor.Stream, unimplementedInterceptor.Stream),
+	)
 	reflection.Register(s)
-	desc.RegisterUserV1Server(s, &server{dbPool: pool, log: logger})
+	healthpb.RegisterHealthServer(s, readiness)
+	authDesc.RegisterAuthV1Server(s, authAPI.NewImplementation(
+		authService.NewService(
+			userServ,
+			twoFactorServ,
+			lockoutServ,
+			auditServ,
+			users,
+			revokedTokens,
+			jwtConfig,
+			accessKeys,
+			refreshKeys,
+			emailVerificationConfig.RequiredForLogin(),
+		),
+		passwordResetService.NewService(
+			users,
+			passwordResetRepository.NewRepository(pool),
+			passwordHasher,
+			passwordPolicy,
+			auditServ,
+			mailSender,
+			passwordResetConfig,
+			logger,
+		),
+		emailVerificationServ,
+		twoFactorServ,
+		lockoutServ,
+		auditServ,
+		serviceAccountServ,
+		validationConfig.WarningsAsErrors(),
+		logger,
+	))
+	accessDesc.RegisterAccessV1Server(s, accessAPI.NewImplementation(
+		accessServ,
+		apiKeyService.NewService(apiKeys, auditServ),
+		serviceAccountServ,
+		logger,
+	))
+	desc.RegisterUserV1Server(s, &server{
+		dbPool:             pool,
+		userService:        userServ,
+		emailVerification:  emailVerificationServ,
+		audit:              auditServ,
+		log:                logger,
+		deadlockMaxRetries: pgConfig.DeadlockMaxRetries(),
+		timestampPrecision: grpcConfig.TimestampPrecision(),
+		warningsAsErrors:   validationConfig.WarningsAsErrors(),
+		activity:           activity,
+		events:             events,
+	})
+
+	// OIDC discovery и JWKS публикуются по HTTP, чтобы другие сервисы могли проверять токены
+	// без обращения к серверу
+	httpServer := &http.Server{
+		Addr:              httpConfig.Address(),
+		Handler:           oidc.NewHandler(jwtConfig.Issuer(), accessKeys, logger),
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+	go func() {
+		logger.Info("HTTP server listening at", zap.String("Address", httpServer.Addr))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Failed to serve http", zap.Error(err))
+		}
+	}()
+
+	// Остановка сервера по SIGINT/SIGTERM: дожидаемся завершения текущих запросов
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+
+		logger.Info("Shutting down server")
+		readiness.Shutdown()
+		events.Close()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Unable to shut down http server", zap.Error(err))
+		}
+
+		s.GracefulStop()
+	}()
 
 	logger.Info("Server listening at", zap.Any("Address", lis.Addr()))
 
 	if err = s.Serve(lis); err != nil {
 		logger.Panic("Failed to serve", zap.Error(err))
 	}
+
+	// Запись накопленной активности перед закрытием пула соединений
+	stopActivity()
+	<-activityDone
+	stopDBWatch()
+	pool.Close()
+}
+
+// mustCheckSchema останавливает сервер, если схема БД не соответствует ожидаемой.
+func mustCheckSchema(ctx context.Context, pool *pgxpool.Pool, logger *zap.Logger) {
+	if err := checkSchema(ctx, pool); err != nil {
+		logger.Fatal("Database schema does not match the server", zap.Error(err))
+	}
+}
+
+// toTimestampProto преобразует t во временную метку для ответа, округляя ее вниз
+// до точности s.timestampPrecision.
+func (s *server) toTimestampProto(t time.Time) *timestamppb.Timestamp {
+	return timestamppb.New(t.Truncate(s.timestampPrecision))
 }
 
 func initLogger() (*zap.Logger, error) {
@@ -111,7 +497,7 @@ func initLogger() (*zap.Logger, error) {
 //   - *GetUserInfoResponse - структура с данными о пользователе.
 //   - error - ошибка, если что-то пошло не так.
 func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest) (*desc.GetUserInfoResponse, error) {
-	s.log.Info("Method Get-User", zap.Any("Input params", req))
+	s.log.Info("Method Get-User", redact.Proto("Input params", req))
 
 	// Валидация запроса
 	if err := req.Validate(); err != nil {
@@ -119,8 +505,14 @@ func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest)
 		return nil, err
 	}
 
+	// Данные пользователя доступны только ему самому и администраторам
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Get-User. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+
 	builderSelect := sq.
-		Select("id", "name", "email", "role", "created_at", "updated_at").
+		Select("id", "name", "email", "role", "created_at", "updated_at", "phone").
 		From("auth").
 		PlaceholderFormat(sq.Dollar).
 		Where(sq.Eq{"id": req.Id})
@@ -137,11 +529,12 @@ func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest)
 		role        desc.UserRole
 		createdAt   time.Time
 		updatedAt   sql.NullTime
+		phone       sql.NullString
 	)
 
 	err = s.dbPool.
 		QueryRow(ctx, query, args...).
-		Scan(&id, &name, &email, &role, &createdAt, &updatedAt)
+		Scan(&id, &name, &email, &role, &createdAt, &updatedAt, &phone)
 	if err != nil {
 		s.log.Error("Method Get-User. Error while query row", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "Error while query row. Error info: %v", err)
@@ -149,7 +542,7 @@ func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest)
 
 	var updatedAtProto *timestamppb.Timestamp
 	if updatedAt.Valid {
-		updatedAtProto = timestamppb.New(updatedAt.Time)
+		updatedAtProto = s.toTimestampProto(updatedAt.Time)
 	}
 
 	return &desc.GetUserInfoResponse{
@@ -157,14 +550,17 @@ func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest)
 		Name:      name,
 		Email:     email,
 		Role:      role,
-		CreatedAt: timestamppb.New(createdAt),
+		CreatedAt: s.toTimestampProto(createdAt),
 		UpdatedAt: updatedAtProto,
+		Phone:     phone.String,
 	}, nil
 }
 
-// CreateUser создает нового пользователя.
+// CreateUser создает нового пользователя и отправляет ему письмо для подтверждения email.
 //
 // Запрос содержит данные об имени, email, роли юзера, пароле, повторе пароля (для валидации корректности ввода пароля).
+// Роль из запроса назначается только администратором: при самостоятельной регистрации создается
+// пользователь с ролью USER.
 //
 // Параметры:
 //   - ctx: контекст для выполнения операции.
@@ -174,43 +570,52 @@ func (s *server) GetUserInfo(ctx context.Context, req *desc.GetUserInfoRequest)
 //   - *CreateUserResponse: структура с ID созданного пользователя.
 //   - error: ошибка, если что-то пошло не так.
 func (s *server) CreateUser(ctx context.Context, req *desc.CreateUserRequest) (*desc.CreateUserResponse, error) {
-	s.log.Info("Method Create-User", zap.Any("Input params", req))
+	s.log.Info("Method Create-User", redact.Proto("Input params", req))
 
-	// Валидация запроса
-	if err := req.Validate(); err != nil {
-		s.log.Error("Method Create-User. Invalid input.", zap.Error(err))
+	// Валидация запроса. Предупреждения не блокируют создание пользователя, если это не включено в конфиге
+	user, warnings, err := s.userToCreate(ctx, req)
+	if err != nil {
+		s.log.Error("Method Create-User. Invalid input.", zap.Error(err), zap.Strings("Warnings", warnings))
 		return nil, err
 	}
+	if len(warnings) > 0 {
+		s.log.Warn("Method Create-User. Input has warnings", zap.Strings("Warnings", warnings))
+	}
 
-	builderInsert := sq.Insert("auth").
-		PlaceholderFormat(sq.Dollar).
-		Columns("name", "email", "password", "password_confirm", "role").
-		Values(req.Name, req.Email, req.Password, req.PasswordConfirm, int32(req.Role)).
-		Suffix("RETURNING id")
-
-	query, args, err := builderInsert.ToSql()
+	userID, err := s.userService.Create(ctx, user)
+	var violationErr *passwordpolicy.ViolationError
+	if errors.As(err, &violationErr) {
+		s.log.Error("Method Create-User. Password does not meet policy", zap.Strings("Violations", violationErr.Violations))
+		return nil, status.Error(codes.InvalidArgument, violationErr.Error())
+	}
+	if alreadyExistsErr := uniqueViolationError(err); alreadyExistsErr != nil {
+		s.log.Error("Method Create-User. User already exists", zap.Error(err))
+		return nil, alreadyExistsErr
+	}
 	if err != nil {
-		s.log.Error("Method Create-User. Unable to create SQL query from builder", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error: %#v", err)
+		s.log.Error("Method Create-User. Unable to create user", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create user, error: %#v", err)
 	}
 
-	var userID int64
-	err = s.dbPool.
-		QueryRow(ctx, query, args...).
-		Scan(&userID)
-
-	if err != nil {
-		s.log.Error("Method Create-User. Unable to get userID from created user", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "Unable to get userID from created user, error: %#v", err)
+	// Пользователь уже создан, поэтому ошибка отправки письма не отменяет регистрацию
+	if err = s.emailVerification.RequestVerification(ctx, userID, strings.TrimSpace(req.Email)); err != nil {
+		s.log.Error("Method Create-User. Unable to request email verification", zap.Error(err), zap.Int64("User-id", userID))
 	}
 
+	s.events.Publish(desc.UserEventType_CREATED, userID)
+
 	return &desc.CreateUserResponse{
-		Id: userID,
+		Id:       userID,
+		Warnings: warnings,
 	}, nil
 }
 
 // UpdateUser обновляет данные существующего пользователя.
 //
+// При смене email он становится неподтвержденным в том же запросе, а ранее выпущенные ссылки
+// подтверждения удаляются. Если после обновления email не подтвержден, на него отправляется
+// письмо с новой ссылкой подтверждения.
+//
 // Параметры:
 //   - ctx: контекст для выполнения операции.
 //   - req: запрос с данными пользователя для обновления.
@@ -219,7 +624,7 @@ func (s *server) CreateUser(ctx context.Context, req *desc.CreateUserRequest) (*
 //   - *emptypb.Empty - пустая структура, если метод выполнился корректно.
 //   - error - ошибка, если что-то пошло не так.
 func (s *server) UpdateUser(ctx context.Context, req *desc.UpdateUserRequest) (*emptypb.Empty, error) {
-	s.log.Info("Method Update-User", zap.Any("Input params", req))
+	s.log.Info("Method Update-User", redact.Proto("Input params", req))
 
 	// Валидация запроса
 	if err := req.Validate(); err != nil {
@@ -227,53 +632,115 @@ func (s *server) UpdateUser(ctx context.Context, req *desc.UpdateUserRequest) (*
 		return nil, err
 	}
 
+	// Пользователь может изменить свои данные, но не свою роль: роли назначают только администраторы
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Update-User. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+	if req.GetRole() != desc.UserRole_UNKNOWN && !callerIsAdmin(ctx) {
+		s.log.Error("Method Update-User. Only administrators can change role", zap.Int64("User-id", req.Id))
+		return nil, status.Error(codes.PermissionDenied, "Only administrators can change role")
+	}
+
 	builderUpdate := sq.
 		Update("auth").
 		PlaceholderFormat(sq.Dollar).
-		Set("role", int32(req.GetRole())).
-		Set("updated_at", time.Now()).
+		// Время берется из БД, как и для created_at, чтобы расхождение часов сервера и БД
+		// не давало updated_at раньше created_at
+		Set("updated_at", sq.Expr("now()")).
 		Where(sq.Eq{"id": req.Id})
 
-	if req.Name != nil {
-		trimmedName := strings.TrimSpace(req.Name.GetValue())
-		if len(trimmedName) > 0 {
-			builderUpdate.Set("name", trimmedName)
-		}
+	if req.GetRole() != desc.UserRole_UNKNOWN {
+		builderUpdate = builderUpdate.Set("role", int32(req.GetRole()))
+	}
+
+	if req.HasName() {
+		builderUpdate = builderUpdate.Set("name", strings.TrimSpace(req.Name.GetValue()))
 	}
 
-	if req.Email != nil {
-		trimmedEmail := strings.TrimSpace(req.Email.GetValue())
-		if len(trimmedEmail) > 0 {
-			builderUpdate.Set("name", trimmedEmail)
+	email := strings.TrimSpace(req.Email.GetValue())
+	if req.HasEmail() {
+		// Подтверждение старого адреса не распространяется на новый: флаг сбрасывается и ссылки
+		// из писем на старый адрес удаляются, если email меняется (без учета регистра).
+		// Выражения в SET и в CTE видят строку до обновления
+		builderUpdate = builderUpdate.
+			Prefix("WITH revoked_verification_tokens AS (DELETE FROM email_verification_tokens "+
+				"WHERE user_id = ? AND EXISTS (SELECT 1 FROM auth WHERE id = ? AND lower(email) <> lower(?)))",
+				req.Id, req.Id, email).
+			Set("email", email).
+			Set("email_verified", sq.Expr("email_verified AND lower(email) = lower(?)", email))
+	}
+
+	// Пустое значение телефона удаляет номер, непустое валидируется в req.Validate()
+	if req.Phone != nil {
+		var phone sql.NullString
+		if len(strings.TrimSpace(req.Phone.GetValue())) > 0 {
+			phone.String, _ = desc.NormalizePhone(req.Phone.GetValue())
+			phone.Valid = true
 		}
+		builderUpdate = builderUpdate.Set("phone", phone)
 	}
 
-	query, args, err := builderUpdate.ToSql()
+	query, args, err := builderUpdate.Suffix("RETURNING email_verified").ToSql()
 	if err != nil {
 		s.log.Error("Method Update-User. Unable to create SQL query from builder", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
 	}
 
-	_, err = s.dbPool.Exec(ctx, query, args...)
+	var emailVerified bool
+	err = s.withDeadlockRetry(ctx, func() error {
+		return s.dbPool.QueryRow(ctx, query, args...).Scan(&emailVerified)
+	})
+	// Обновление несуществующего пользователя не считается ошибкой
+	updated := err == nil
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if alreadyExistsErr := uniqueViolationError(err); alreadyExistsErr != nil {
+		s.log.Error("Method Update-User. User already exists", zap.Error(err))
+		return nil, alreadyExistsErr
+	}
+	if isCheckViolation(err, updatedAtCheckConstraint) {
+		s.log.Error("Method Update-User. Data integrity violation: updated_at is earlier than created_at", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, status.Error(codes.Internal, "Unable to update user: data integrity violation")
+	}
 	if err != nil {
 		s.log.Error("Method Update-User. Unable to execute SQL query", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
 	}
 
+	if updated && req.GetRole() != desc.UserRole_UNKNOWN {
+		s.audit.Record(ctx, model.AuditEventRoleChanged, req.Id, map[string]string{"role": req.GetRole().String()})
+	}
+
+	// Данные уже обновлены, поэтому ошибка отправки письма не отменяет изменение
+	if updated && req.HasEmail() && !emailVerified {
+		if err = s.emailVerification.RequestVerification(ctx, req.Id, email); err != nil {
+			s.log.Error("Method Update-User. Unable to request email verification", zap.Error(err), zap.Int64("User-id", req.Id))
+		}
+	}
+
+	if updated {
+		s.events.Publish(desc.UserEventType_UPDATED, req.Id)
+	}
+
 	return &emptypb.Empty{}, nil
 }
 
 // DeleteUser удаляет данные о существующем пользователе.
 //
+// Метод доступен только администратору (правило доступа в accessible_roles).
+//
 // Параметры:
 //   - ctx: контекст выполнения операции.
-//   - req: запрос с данными об удаляемом пользователе (содержит только ID пользователя).
+//   - req: запрос с данными об удаляемом пользователе (ID пользователя и признак return_summary).
 //
 // Возвращает:
-//   - *emptypb.Empty - пустая структура, если метод выполнился корректно.
+//   - *DeleteUserResponse - данные удаленного пользователя, если в запросе указан return_summary,
+//     иначе пустая структура.
 //   - error - если что-то пошло не так.
-func (s *server) DeleteUser(ctx context.Context, req *desc.DeleteUserRequest) (*emptypb.Empty, error) {
-	s.log.Info("Method Delete-User", zap.Any("Input params", req))
+func (s *server) DeleteUser(ctx context.Context, req *desc.DeleteUserRequest) (*desc.DeleteUserResponse, error) {
+	s.log.Info("Method Delete-User", redact.Proto("Input params", req))
 
 	// Валидация запроса
 	if err := req.Validate(); err != nil {
@@ -281,21 +748,894 @@ func (s *server) DeleteUser(ctx context.Context, req *desc.DeleteUserRequest) (*
 		return nil, err
 	}
 
+	// Вызов не администратором отклоняется политикой доступа. Проверка вызывающего остается на случай,
+	// если правило доступа изменят: тогда пользователь сможет удалить только себя
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Delete-User. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+
 	builderDelete := sq.Delete("auth").
 		PlaceholderFormat(sq.Dollar).
 		Where(sq.Eq{"id": req.Id})
 
-	query, args, err := builderDelete.ToSql()
+	if !req.ReturnSummary {
+		query, args, err := builderDelete.ToSql()
+		if err != nil {
+			s.log.Error("Method Delete-User. Unable to create SQL query from builder", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+		}
+
+		commandTag, err := s.dbPool.Exec(ctx, query, args...)
+		if err != nil {
+			s.log.Error("Method Delete-User. Unable to execute SQL query", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+		}
+
+		if commandTag.RowsAffected() > 0 {
+			s.audit.Record(ctx, model.AuditEventUserDeleted, req.Id, nil)
+			s.events.Publish(desc.UserEventType_DELETED, req.Id)
+		}
+
+		return &desc.DeleteUserResponse{}, nil
+	}
+
+	query, args, err := builderDelete.
+		Suffix("RETURNING id, name, email, role, created_at, updated_at").
+		ToSql()
 	if err != nil {
 		s.log.Error("Method Delete-User. Unable to create SQL query from builder", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
 	}
 
-	_, err = s.dbPool.Exec(ctx, query, args...)
+	var (
+		user      desc.UserSummary
+		createdAt time.Time
+		updatedAt sql.NullTime
+	)
+
+	err = s.dbPool.
+		QueryRow(ctx, query, args...).
+		Scan(&user.Id, &user.Name, &user.Email, &user.Role, &createdAt, &updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.log.Error("Method Delete-User. User not found", zap.Int64("User-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "User with id %d not found", req.Id)
+	}
 	if err != nil {
 		s.log.Error("Method Delete-User. Unable to execute SQL query", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
 	}
 
-	return &emptypb.Empty{}, nil
+	user.CreatedAt = s.toTimestampProto(createdAt)
+	if updatedAt.Valid {
+		user.UpdatedAt = s.toTimestampProto(updatedAt.Time)
+	}
+
+	s.audit.Record(ctx, model.AuditEventUserDeleted, req.Id, map[string]string{"email": user.Email})
+	s.events.Publish(desc.UserEventType_DELETED, req.Id)
+
+	return &desc.DeleteUserResponse{
+		User: &user,
+	}, nil
+}
+
+// ListEmailDomainStats возвращает список доменов email-адресов пользователей с количеством
+// пользователей в каждом домене.
+//
+// Список отсортирован по убыванию количества пользователей, при равенстве - по имени домена.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с параметрами пагинации (limit, offset).
+//
+// Возвращает:
+//   - *ListEmailDomainStatsResponse - структура со списком доменов и количеством пользователей.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) ListEmailDomainStats(ctx context.Context, req *desc.ListEmailDomainStatsRequest) (*desc.ListEmailDomainStatsResponse, error) {
+	s.log.Info("Method List-Email-Domain-Stats", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method List-Email-Domain-Stats. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	builderSelect := sq.
+		Select("lower(split_part(email, '@', 2)) AS domain", "count(*) AS users_count").
+		From("auth").
+		PlaceholderFormat(sq.Dollar).
+		GroupBy("domain").
+		OrderBy("users_count DESC", "domain").
+		Limit(req.Limit).
+		Offset(req.Offset)
+
+	query, args, err := builderSelect.ToSql()
+	if err != nil {
+		s.log.Error("Method List-Email-Domain-Stats. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	rows, err := s.dbPool.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Method List-Email-Domain-Stats. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*desc.EmailDomainStat, 0, req.Limit)
+	for rows.Next() {
+		stat := &desc.EmailDomainStat{}
+		if err = rows.Scan(&stat.Domain, &stat.Count); err != nil {
+			s.log.Error("Method List-Email-Domain-Stats. Unable to scan row", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to scan row, error info: %#v", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.log.Error("Method List-Email-Domain-Stats. Error while reading rows", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Error while reading rows, error info: %#v", err)
+	}
+
+	return &desc.ListEmailDomainStatsResponse{
+		Stats: stats,
+	}, nil
+}
+
+// GetSignupTimeSeries возвращает количество регистраций пользователей, сгруппированное по
+// интервалам (дням или неделям) в диапазоне [from, to).
+//
+// Интервалы упорядочены по времени, интервалы без регистраций возвращаются с нулевым количеством.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с точностью группировки и диапазоном времени.
+//
+// Возвращает:
+//   - *GetSignupTimeSeriesResponse - структура со списком интервалов.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) GetSignupTimeSeries(ctx context.Context, req *desc.GetSignupTimeSeriesRequest) (*desc.GetSignupTimeSeriesResponse, error) {
+	s.log.Info("Method Get-Signup-Time-Series", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Get-Signup-Time-Series. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	granularity := req.GetGranularity()
+	from, to := req.GetFrom().AsTime(), req.GetTo().AsTime()
+
+	// Интервалы отсчитываются от начала интервала, содержащего from (в UTC), и в БД вычисляется
+	// только номер интервала. Так границы интервалов не зависят от часового пояса сессии БД,
+	// в отличие от date_trunc по столбцу timestamp
+	origin := granularity.Truncate(from)
+
+	builderSelect := sq.
+		Select().
+		Column(sq.Expr("floor(extract(epoch FROM created_at - ?::timestamp) / ?::bigint)::bigint AS bucket",
+			origin, int64(granularity.Duration().Seconds()))).
+		Column("count(*)").
+		From("auth").
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.GtOrEq{"created_at": from}).
+		Where(sq.Lt{"created_at": to}).
+		GroupBy("bucket").
+		OrderBy("bucket")
+
+	query, args, err := builderSelect.ToSql()
+	if err != nil {
+		s.log.Error("Method Get-Signup-Time-Series. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	rows, err := s.dbPool.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Method Get-Signup-Time-Series. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var bucket, count int64
+		if err = rows.Scan(&bucket, &count); err != nil {
+			s.log.Error("Method Get-Signup-Time-Series. Unable to scan row", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to scan row, error info: %#v", err)
+		}
+		counts[bucket] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		s.log.Error("Method Get-Signup-Time-Series. Error while reading rows", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Error while reading rows, error info: %#v", err)
+	}
+
+	return &desc.GetSignupTimeSeriesResponse{
+		Buckets: s.signupBuckets(origin, to, granularity.Duration(), counts),
+	}, nil
+}
+
+// signupBuckets возвращает интервалы длиной step от origin до to с количеством регистраций
+// из counts (по номеру интервала). Интервалы без регистраций получают нулевое количество.
+func (s *server) signupBuckets(origin, to time.Time, step time.Duration, counts map[int64]int64) []*desc.SignupBucket {
+	var buckets []*desc.SignupBucket
+	for i, bucket := int64(0), origin; bucket.Before(to); i, bucket = i+1, bucket.Add(step) {
+		buckets = append(buckets, &desc.SignupBucket{
+			Start: s.toTimestampProto(bucket),
+			Count: counts[i],
+		})
+	}
+
+	return buckets
+}
+
+// GetUserStats возвращает количество пользователей: всего, по ролям, с подтвержденным email,
+// с телефоном и активных (были в сети не раньше active_since).
+//
+// Все показатели считаются одним запросом с агрегатами FILTER. Удаленные пользователи не хранятся
+// в таблице, поэтому не учитываются.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с началом периода активности (по умолчанию - последние 30 дней).
+//
+// Возвращает:
+//   - *GetUserStatsResponse - структура с количеством пользователей.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) GetUserStats(ctx context.Context, req *desc.GetUserStatsRequest) (*desc.GetUserStatsResponse, error) {
+	s.log.Info("Method Get-User-Stats", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Get-User-Stats. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	activeSince := time.Now().Add(-defaultActiveUsersWindow)
+	if req.ActiveSince != nil {
+		activeSince = req.ActiveSince.AsTime()
+	}
+
+	builderSelect := sq.
+		Select("count(*)").
+		Column("count(*) FILTER (WHERE role = ?)", int32(desc.UserRole_ADMIN)).
+		Column("count(*) FILTER (WHERE role = ?)", int32(desc.UserRole_USER)).
+		Column("count(*) FILTER (WHERE email_verified)").
+		Column("count(*) FILTER (WHERE phone IS NOT NULL)").
+		Column("count(*) FILTER (WHERE last_seen >= ?)", activeSince).
+		From("auth").
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builderSelect.ToSql()
+	if err != nil {
+		s.log.Error("Method Get-User-Stats. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	var stats desc.GetUserStatsResponse
+	err = s.dbPool.QueryRow(ctx, query, args...).
+		Scan(&stats.Total, &stats.Admins, &stats.Users, &stats.EmailVerified, &stats.WithPhone, &stats.Active)
+	if err != nil {
+		s.log.Error("Method Get-User-Stats. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+
+	return &stats, nil
+}
+
+// ValidateEmails проверяет список email-адресов перед импортом пользователей.
+//
+// Для каждого адреса возвращает, корректен ли его формат и свободен ли он (нет пользователя
+// с таким email без учета регистра). Занятость проверяется одним запросом к БД.
+// Доступен только администратору, иначе по нему можно было бы перебирать зарегистрированные адреса.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос со списком email-адресов.
+//
+// Возвращает:
+//   - *ValidateEmailsResponse - результаты проверки в порядке адресов из запроса.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) ValidateEmails(ctx context.Context, req *desc.ValidateEmailsRequest) (*desc.ValidateEmailsResponse, error) {
+	s.log.Info("Method Validate-Emails", zap.Int("Emails count", len(req.GetEmails())))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Validate-Emails. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	normalizedEmails := make([]string, 0, len(req.Emails))
+	for _, email := range req.Emails {
+		if desc.IsValidEmail(strings.TrimSpace(email)) {
+			normalizedEmails = append(normalizedEmails, desc.NormalizeEmail(email))
+		}
+	}
+
+	takenEmails := make(map[string]struct{})
+	if len(normalizedEmails) > 0 {
+		builderSelect := sq.
+			Select("lower(email)").
+			From("auth").
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Expr("lower(email) = ANY(?)", normalizedEmails))
+
+		query, args, err := builderSelect.ToSql()
+		if err != nil {
+			s.log.Error("Method Validate-Emails. Unable to create SQL query from builder", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+		}
+
+		rows, err := s.dbPool.Query(ctx, query, args...)
+		if err != nil {
+			s.log.Error("Method Validate-Emails. Unable to execute SQL query", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var email string
+			if err = rows.Scan(&email); err != nil {
+				s.log.Error("Method Validate-Emails. Unable to scan row", zap.Error(err))
+				return nil, status.Errorf(codes.Internal, "Unable to scan row, error info: %#v", err)
+			}
+			takenEmails[email] = struct{}{}
+		}
+
+		if err = rows.Err(); err != nil {
+			s.log.Error("Method Validate-Emails. Error while reading rows", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Error while reading rows, error info: %#v", err)
+		}
+	}
+
+	results := make([]*desc.EmailValidationResult, 0, len(req.Emails))
+	for _, email := range req.Emails {
+		validFormat := desc.IsValidEmail(strings.TrimSpace(email))
+		_, taken := takenEmails[desc.NormalizeEmail(email)]
+
+		results = append(results, &desc.EmailValidationResult{
+			Email:       email,
+			ValidFormat: validFormat,
+			Available:   validFormat && !taken,
+		})
+	}
+
+	return &desc.ValidateEmailsResponse{
+		Results: results,
+	}, nil
+}
+
+// FindDuplicateCandidates ищет группы пользователей, email-адреса которых совпадают после
+// приведения к каноническому виду (см. user_v1.CanonicalEmail), например "john.doe+news@gmail.com"
+// и "JohnDoe@gmail.com".
+//
+// Метод диагностический: он читает email всех пользователей и группирует их в памяти.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с максимальным количеством возвращаемых групп.
+//
+// Возвращает:
+//   - *FindDuplicateCandidatesResponse - группы возможных дубликатов, упорядоченные по ID первого пользователя.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) FindDuplicateCandidates(ctx context.Context, req *desc.FindDuplicateCandidatesRequest) (*desc.FindDuplicateCandidatesResponse, error) {
+	s.log.Info("Method Find-Duplicate-Candidates", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Find-Duplicate-Candidates. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	builderSelect := sq.
+		Select("id", "name", "email", "role", "created_at", "updated_at").
+		From("auth").
+		PlaceholderFormat(sq.Dollar).
+		OrderBy("id")
+
+	query, args, err := builderSelect.ToSql()
+	if err != nil {
+		s.log.Error("Method Find-Duplicate-Candidates. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	rows, err := s.dbPool.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Method Find-Duplicate-Candidates. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+	defer rows.Close()
+
+	var canonicalEmails []string
+	usersByCanonicalEmail := make(map[string][]*desc.UserSummary)
+	for rows.Next() {
+		var (
+			user      desc.UserSummary
+			createdAt time.Time
+			updatedAt sql.NullTime
+		)
+		if err = rows.Scan(&user.Id, &user.Name, &user.Email, &user.Role, &createdAt, &updatedAt); err != nil {
+			s.log.Error("Method Find-Duplicate-Candidates. Unable to scan row", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to scan row, error info: %#v", err)
+		}
+
+		user.CreatedAt = s.toTimestampProto(createdAt)
+		if updatedAt.Valid {
+			user.UpdatedAt = s.toTimestampProto(updatedAt.Time)
+		}
+
+		canonicalEmail := desc.CanonicalEmail(user.Email)
+		if _, ok := usersByCanonicalEmail[canonicalEmail]; !ok {
+			canonicalEmails = append(canonicalEmails, canonicalEmail)
+		}
+		usersByCanonicalEmail[canonicalEmail] = append(usersByCanonicalEmail[canonicalEmail], &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.log.Error("Method Find-Duplicate-Candidates. Error while reading rows", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Error while reading rows, error info: %#v", err)
+	}
+
+	var groups []*desc.DuplicateCandidateGroup
+	for _, canonicalEmail := range canonicalEmails {
+		users := usersByCanonicalEmail[canonicalEmail]
+		if len(users) < 2 {
+			continue
+		}
+
+		groups = append(groups, &desc.DuplicateCandidateGroup{
+			CanonicalEmail: canonicalEmail,
+			Users:          users,
+		})
+		if uint64(len(groups)) == req.Limit {
+			break
+		}
+	}
+
+	return &desc.FindDuplicateCandidatesResponse{
+		Groups: groups,
+	}, nil
+}
+
+// RecordActivity отмечает активность пользователя (heartbeat клиента).
+//
+// Время последней активности (last_seen) записывается в БД не сразу, а пакетно, раз в интервал
+// из конфига. Повторные вызовы для одного пользователя между записями схлопываются.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID пользователя.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если активность принята к записи.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) RecordActivity(ctx context.Context, req *desc.RecordActivityRequest) (*emptypb.Empty, error) {
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Record-Activity. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Активность отмечает сам пользователь, администратор - за любого пользователя
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Record-Activity. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+
+	s.activity.Record(req.Id)
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchUserEvents отправляет клиенту события о создании, изменении и удалении пользователей
+// по мере их появления, пока клиент не отменит запрос или сервер не остановится.
+//
+// Если клиент не успевает получать события, самые старые из них отбрасываются, а количество
+// отброшенных событий передается в поле dropped следующего отправленного события.
+//
+// Параметры:
+//   - req: запрос с фильтром по типам событий.
+//   - stream: поток для отправки событий клиенту.
+//
+// Возвращает:
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) WatchUserEvents(req *desc.WatchUserEventsRequest, stream desc.UserV1_WatchUserEventsServer) error {
+	s.log.Info("Method Watch-User-Events", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Watch-User-Events. Invalid input", zap.Error(err))
+		return err
+	}
+
+	eventTypes := make(map[desc.UserEventType]struct{}, len(req.Types))
+	for _, eventType := range req.Types {
+		eventTypes[eventType] = struct{}{}
+	}
+
+	subscription := s.events.Subscribe()
+	defer s.events.Unsubscribe(subscription)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.events.Done():
+			return status.Error(codes.Unavailable, "Server is shutting down")
+		case event := <-subscription.events:
+			if _, ok := eventTypes[event.eventType]; len(eventTypes) > 0 && !ok {
+				continue
+			}
+
+			err := stream.Send(&desc.UserEvent{
+				Type:       event.eventType,
+				UserId:     event.userID,
+				OccurredAt: s.toTimestampProto(event.occurredAt),
+				Dropped:    subscription.dropped.Swap(0),
+			})
+			if err != nil {
+				s.log.Error("Method Watch-User-Events. Unable to send event", zap.Error(err))
+				return err
+			}
+		}
+	}
+}
+
+// ClaimHandle атомарно закрепляет за пользователем уникальный handle.
+//
+// Если несколько запросов одновременно пытаются занять один и тот же handle, успешно
+// выполняется только один из них, остальные получают ошибку AlreadyExists.
+// Повторный запрос того же handle тем же пользователем завершается успешно.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID пользователя и желаемым handle.
+//
+// Возвращает:
+//   - *ClaimHandleResponse - структура с занятым handle в нормализованном виде.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) ClaimHandle(ctx context.Context, req *desc.ClaimHandleRequest) (*desc.ClaimHandleResponse, error) {
+	s.log.Info("Method Claim-Handle", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Claim-Handle. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Handle занимается для себя, администратор - для любого пользователя
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Claim-Handle. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+
+	handle, _ := desc.NormalizeHandle(req.Handle)
+
+	// При конфликте строка возвращается, только если handle уже принадлежит этому же пользователю
+	query, args, err := sq.Insert("user_handles").
+		PlaceholderFormat(sq.Dollar).
+		Columns("handle", "user_id").
+		Values(handle, req.Id).
+		Suffix("ON CONFLICT (handle) DO UPDATE SET handle = excluded.handle WHERE user_handles.user_id = excluded.user_id RETURNING user_id").
+		ToSql()
+	if err != nil {
+		s.log.Error("Method Claim-Handle. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	var userID int64
+	err = s.dbPool.QueryRow(ctx, query, args...).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.log.Error("Method Claim-Handle. Handle is already taken", zap.String("Handle", handle))
+		return nil, status.Errorf(codes.AlreadyExists, "Handle %q is already taken", handle)
+	}
+	if alreadyExistsErr := uniqueViolationError(err); alreadyExistsErr != nil {
+		s.log.Error("Method Claim-Handle. User already has a handle", zap.Error(err))
+		return nil, alreadyExistsErr
+	}
+	if isForeignKeyViolation(err) {
+		s.log.Error("Method Claim-Handle. User not found", zap.Int64("User-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "User with id %d not found", req.Id)
+	}
+	if err != nil {
+		s.log.Error("Method Claim-Handle. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+
+	return &desc.ClaimHandleResponse{
+		Handle: handle,
+	}, nil
+}
+
+// ChangeUserPassword меняет пароль пользователя после проверки текущего пароля.
+//
+// Менять пароль может сам пользователь или администратор. Новый пароль проверяется по тем же
+// правилам, что и при создании пользователя. Поле updated_at пользователя обновляется, выпущенные
+// ранее refresh-токены пользователя становятся недействительными.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID пользователя, текущим паролем и новым паролем с подтверждением.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если пароль изменен.
+//   - error - ошибка Unauthenticated, если текущий пароль неверный, PermissionDenied, если пароль
+//     меняет другой пользователь, ResourceExhausted, если проверка пароля заблокирована после
+//     неудачных попыток, NotFound, если пользователь не найден, либо другая ошибка, если что-то
+//     пошло не так.
+func (s *server) ChangeUserPassword(ctx context.Context, req *desc.ChangeUserPasswordRequest) (*emptypb.Empty, error) {
+	// Пароли не логируются
+	s.log.Info("Method Change-User-Password", zap.Int64("User-id", req.Id))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Change-User-Password. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	if err := authorizeUser(ctx, req.Id); err != nil {
+		s.log.Error("Method Change-User-Password. Access denied", zap.Error(err), zap.Int64("User-id", req.Id))
+		return nil, err
+	}
+
+	// Предупреждения не блокируют смену пароля, если это не включено в конфиге
+	warnings := req.Warnings()
+	if len(warnings) > 0 {
+		if s.warningsAsErrors {
+			s.log.Error("Method Change-User-Password. Invalid input", zap.Strings("Warnings", warnings))
+			return nil, status.Error(codes.InvalidArgument, strings.Join(warnings, ". "))
+		}
+		s.log.Warn("Method Change-User-Password. Input has warnings", zap.Strings("Warnings", warnings))
+	}
+
+	err := s.userService.ChangePassword(ctx, req.Id, req.OldPassword, req.NewPassword, identity.ClientIP(ctx))
+	if errors.Is(err, service.ErrLoginLocked) {
+		s.log.Error("Method Change-User-Password. Password check is locked", zap.Int64("User-id", req.Id), zap.String("Client-IP", identity.ClientIP(ctx)))
+		return nil, status.Error(codes.ResourceExhausted, "Too many failed password attempts, try again later")
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		s.log.Error("Method Change-User-Password. User not found", zap.Int64("User-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "User with id %d not found", req.Id)
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		s.log.Error("Method Change-User-Password. Invalid old password", zap.Int64("User-id", req.Id))
+		return nil, status.Error(codes.Unauthenticated, "Invalid old password")
+	}
+	var violationErr *passwordpolicy.ViolationError
+	if errors.As(err, &violationErr) {
+		s.log.Error("Method Change-User-Password. Password does not meet policy", zap.Strings("Violations", violationErr.Violations))
+		return nil, status.Error(codes.InvalidArgument, violationErr.Error())
+	}
+	if err != nil {
+		s.log.Error("Method Change-User-Password. Unable to change password", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to change password, error info: %#v", err)
+	}
+
+	s.events.Publish(desc.UserEventType_UPDATED, req.Id)
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListUsers возвращает страницу списка пользователей, подходящих под фильтр и отсортированных
+// в порядке order_by, и курсор следующей страницы.
+//
+// Страница задается номером (смещение OFFSET) либо курсором из предыдущего ответа: по курсору
+// выбираются пользователи, следующие в порядке сортировки за последним на предыдущей странице,
+// что на больших таблицах не замедляется с ростом номера страницы. Общее количество пользователей считается только
+// при запросе по номеру страницы.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с размером страницы, ее номером или курсором, необязательными фильтром и сортировкой.
+//
+// Возвращает:
+//   - *ListUsersResponse - структура с пользователями на странице, общим количеством пользователей
+//     и курсором следующей страницы.
+//   - error - ошибка, если что-то пошло не так.
+func (s *server) ListUsers(ctx context.Context, req *desc.ListUsersRequest) (*desc.ListUsersResponse, error) {
+	s.log.Info("Method List-Users", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method List-Users. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Порядок сортировки уже проверен в Validate
+	order, _ := desc.ParseUserOrder(req.OrderBy)
+	query := queryFingerprint(req.GetFilter(), order.String())
+
+	// Лишний пользователь показывает, что есть следующая страница
+	filter := usersFilterCondition(req.Filter)
+	builderSelect := sq.
+		Select("id", "name", "email", "role", "created_at", "updated_at", "phone").
+		From("auth").
+		PlaceholderFormat(sq.Dollar).
+		OrderBy(usersOrderBy(order)...).
+		Limit(req.PageSize + 1)
+	if len(filter) > 0 {
+		builderSelect = builderSelect.Where(filter)
+	}
+
+	var totalCount uint64
+	if len(req.PageToken) > 0 {
+		cursor, err := decodePageToken(req.PageToken, query)
+		if err != nil {
+			s.log.Error("Method List-Users. Invalid page token", zap.Error(err))
+			return nil, err
+		}
+		afterCursor, err := usersAfterCursor(order, cursor)
+		if err != nil {
+			s.log.Error("Method List-Users. Invalid page token", zap.Error(err))
+			return nil, err
+		}
+		builderSelect = builderSelect.Where(afterCursor)
+	} else {
+		builderSelect = builderSelect.Offset(req.Offset())
+
+		builderCount := sq.
+			Select("count(*)").
+			From("auth").
+			PlaceholderFormat(sq.Dollar)
+		if len(filter) > 0 {
+			builderCount = builderCount.Where(filter)
+		}
+
+		countQuery, countArgs, err := builderCount.ToSql()
+		if err != nil {
+			s.log.Error("Method List-Users. Unable to create SQL query from builder", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+		}
+
+		if err = s.dbPool.QueryRow(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
+			s.log.Error("Method List-Users. Unable to count users", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to count users, error info: %#v", err)
+		}
+	}
+
+	selectQuery, selectArgs, err := builderSelect.ToSql()
+	if err != nil {
+		s.log.Error("Method List-Users. Unable to create SQL query from builder", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create SQL query from builder, error info: %#v", err)
+	}
+
+	rows, err := s.dbPool.Query(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		s.log.Error("Method List-Users. Unable to execute SQL query", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to execute SQL query, error info: %#v", err)
+	}
+	defer rows.Close()
+
+	users := make([]*desc.User, 0, req.PageSize+1)
+	sortValues := make([]string, 0, req.PageSize+1)
+	for rows.Next() {
+		var (
+			user      desc.User
+			createdAt time.Time
+			updatedAt sql.NullTime
+			phone     sql.NullString
+		)
+		if err = rows.Scan(&user.Id, &user.Name, &user.Email, &user.Role, &createdAt, &updatedAt, &phone); err != nil {
+			s.log.Error("Method List-Users. Unable to scan row", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to scan row, error info: %#v", err)
+		}
+
+		user.CreatedAt = s.toTimestampProto(createdAt)
+		if updatedAt.Valid {
+			user.UpdatedAt = s.toTimestampProto(updatedAt.Time)
+		}
+		user.Phone = phone.String
+		users = append(users, &user)
+		sortValues = append(sortValues, usersSortValue(order, &user, createdAt, updatedAt))
+	}
+
+	if err = rows.Err(); err != nil {
+		s.log.Error("Method List-Users. Error while reading rows", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Error while reading rows, error info: %#v", err)
+	}
+
+	var nextPageToken string
+	if uint64(len(users)) > req.PageSize {
+		users = users[:req.PageSize]
+		nextPageToken = encodePageToken(users[len(users)-1].Id, sortValues[len(users)-1], query)
+	}
+
+	return &desc.ListUsersResponse{
+		Users:         users,
+		TotalCount:    totalCount,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// BatchCreateUsers создает пакет пользователей и возвращает результат для каждого из них,
+// чтобы при импорте не вызывать CreateUser для каждого пользователя.
+//
+// По умолчанию пользователи создаются в одной транзакции: если хотя бы один из них не прошел
+// проверку или не был сохранен, не создается никто. В режиме best_effort каждый пользователь
+// создается независимо. Созданным пользователям, как и в CreateUser, отправляется письмо
+// для подтверждения email.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос со списком пользователей и режимом создания.
+//
+// Возвращает:
+//   - *BatchCreateUsersResponse - результаты в порядке пользователей из запроса и количество созданных.
+//   - error - ошибка, если запрос некорректен или не удалось выполнить транзакцию.
+func (s *server) BatchCreateUsers(ctx context.Context, req *desc.BatchCreateUsersRequest) (*desc.BatchCreateUsersResponse, error) {
+	s.log.Info("Method Batch-Create-Users", zap.Int("Users count", len(req.GetUsers())), zap.Bool("Best effort", req.GetBestEffort()))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		s.log.Error("Method Batch-Create-Users. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Пользователи, прошедшие валидацию, и их индексы в запросе
+	results := make([]*desc.BatchCreateUserResult, len(req.Users))
+	users := make([]*model.UserToCreate, 0, len(req.Users))
+	indexes := make([]int, 0, len(req.Users))
+	for i, item := range req.Users {
+		results[i] = &desc.BatchCreateUserResult{Index: uint32(i)}
+
+		user, warnings, err := s.userToCreate(ctx, item)
+		results[i].Warnings = warnings
+		if err != nil {
+			st := status.Convert(err)
+			results[i].ErrorCode = uint32(st.Code())
+			results[i].ErrorMessage = st.Message()
+			continue
+		}
+
+		users = append(users, user)
+		indexes = append(indexes, i)
+	}
+
+	// В транзакционном режиме ошибка валидации любого пользователя отменяет весь пакет
+	var created []model.UserCreateResult
+	if len(users) == len(req.Users) || req.BestEffort {
+		var err error
+		created, err = s.userService.CreateBatch(ctx, users, req.BestEffort)
+		if err != nil {
+			s.log.Error("Method Batch-Create-Users. Unable to create users", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Unable to create users, error: %#v", err)
+		}
+	} else {
+		created = make([]model.UserCreateResult, len(users))
+		for i := range created {
+			created[i].Err = service.ErrBatchAborted
+		}
+	}
+
+	var createdCount uint32
+	for i, result := range created {
+		index := indexes[i]
+		if result.Err != nil {
+			st := createUserStatus(result.Err)
+			if st.Code() == codes.Internal {
+				s.log.Error("Method Batch-Create-Users. Unable to create user", zap.Error(result.Err), zap.Int("Index", index))
+			}
+			results[index].ErrorCode = uint32(st.Code())
+			results[index].ErrorMessage = st.Message()
+			continue
+		}
+
+		results[index].Id = result.ID
+		createdCount++
+
+		// Пользователь уже создан, поэтому ошибка отправки письма не отменяет регистрацию
+		if err := s.emailVerification.RequestVerification(ctx, result.ID, strings.TrimSpace(users[i].Email)); err != nil {
+			s.log.Error("Method Batch-Create-Users. Unable to request email verification", zap.Error(err), zap.Int64("User-id", result.ID))
+		}
+
+		s.events.Publish(desc.UserEventType_CREATED, result.ID)
+	}
+
+	s.log.Info("Method Batch-Create-Users. Users created", zap.Uint32("Created count", createdCount))
+
+	return &desc.BatchCreateUsersResponse{
+		Results:      results,
+		CreatedCount: createdCount,
+	}, nil
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSignupBuckets(t *testing.T) {
+	s := &server{log: zap.NewNop()}
+	origin := time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		to         time.Time
+		step       time.Duration
+		counts     map[int64]int64
+		wantCounts []int64
+	}{
+		{
+			name:       "days with gaps",
+			to:         origin.Add(4 * day),
+			step:       day,
+			counts:     map[int64]int64{0: 2, 2: 5},
+			wantCounts: []int64{2, 0, 5, 0},
+		},
+		{
+			name:       "partial last bucket",
+			to:         origin.Add(2*day + time.Hour),
+			step:       day,
+			counts:     map[int64]int64{2: 1},
+			wantCounts: []int64{0, 0, 1},
+		},
+		{
+			name:       "weeks",
+			to:         origin.Add(14 * day),
+			step:       7 * day,
+			counts:     map[int64]int64{1: 3},
+			wantCounts: []int64{0, 3},
+		},
+		{
+			name:       "empty range",
+			to:         origin,
+			step:       day,
+			wantCounts: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := s.signupBuckets(origin, tt.to, tt.step, tt.counts)
+			if len(buckets) != len(tt.wantCounts) {
+				t.Fatalf("len(buckets) = %d, want %d", len(buckets), len(tt.wantCounts))
+			}
+
+			for i, bucket := range buckets {
+				wantStart := origin.Add(time.Duration(i) * tt.step)
+				if !bucket.GetStart().AsTime().Equal(wantStart) {
+					t.Errorf("buckets[%d].Start = %s, want %s", i, bucket.GetStart().AsTime(), wantStart)
+				}
+				if bucket.GetCount() != tt.wantCounts[i] {
+					t.Errorf("buckets[%d].Count = %d, want %d", i, bucket.GetCount(), tt.wantCounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestToTimestampProto(t *testing.T) {
+	createdAt := time.Date(2024, 11, 11, 10, 0, 5, 123456789, time.UTC)
+
+	tests := []struct {
+		name      string
+		precision time.Duration
+		want      time.Time
+	}{
+		{name: "not set", precision: 0, want: createdAt},
+		{name: "nanoseconds", precision: time.Nanosecond, want: createdAt},
+		{name: "microseconds", precision: time.Microsecond, want: time.Date(2024, 11, 11, 10, 0, 5, 123456000, time.UTC)},
+		{name: "milliseconds", precision: time.Millisecond, want: time.Date(2024, 11, 11, 10, 0, 5, 123000000, time.UTC)},
+		{name: "seconds", precision: time.Second, want: time.Date(2024, 11, 11, 10, 0, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{log: zap.NewNop(), timestampPrecision: tt.precision}
+
+			if got := s.toTimestampProto(createdAt).AsTime(); !got.Equal(tt.want) {
+				t.Errorf("toTimestampProto() = %s, want %s", got.Format(time.RFC3339Nano), tt.want.Format(time.RFC3339Nano))
+			}
+		})
+	}
+}
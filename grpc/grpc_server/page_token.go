@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// pageTokenVersion - версия формата курсора списка пользователей. Курсоры другой версии
+// (например, выданные сервером до изменения формата) отклоняются.
+const pageTokenVersion = 1
+
+// pageToken - курсор страницы списка пользователей (keyset-пагинация): следующая страница
+// начинается после пользователя с ID LastID и значением поля сортировки LastValue.
+//
+// Клиенту курсор передается непрозрачной строкой (base64 от JSON), чтобы формат можно было
+// менять, не ломая клиентов.
+type pageToken struct {
+	Version int   `json:"v"`
+	LastID  int64 `json:"last_id"`
+	// LastValue - значение поля сортировки у последнего пользователя страницы. Пустое при сортировке по ID.
+	LastValue string `json:"last_value,omitempty"`
+	// Query - отпечаток параметров запроса (фильтра и сортировки), с которыми выдан курсор. С другими
+	// параметрами курсор не принимается: продолжение чужого списка вернуло бы пропуски и повторы.
+	Query string `json:"q"`
+}
+
+// queryFingerprint возвращает отпечаток параметров запроса списка для курсора: фильтра filter
+// и порядка сортировки orderBy в нормализованном виде.
+func queryFingerprint(filter proto.Message, orderBy string) string {
+	// Детерминированная сериализация дает одинаковые байты для одинаковых параметров
+	content, _ := proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+	hash := sha256.New()
+	hash.Write(content)
+	hash.Write([]byte(orderBy))
+
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// encodePageToken возвращает курсор страницы, начинающейся после пользователя lastID со значением
+// поля сортировки lastValue, для запроса с отпечатком параметров query (см. queryFingerprint).
+func encodePageToken(lastID int64, lastValue, query string) string {
+	// Структура из чисел и строк сериализуется без ошибок
+	content, _ := json.Marshal(pageToken{Version: pageTokenVersion, LastID: lastID, LastValue: lastValue, Query: query})
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// decodePageToken разбирает и проверяет курсор страницы для запроса с отпечатком параметров query.
+//
+// Возвращает:
+//   - pageToken: данные курсора.
+//   - error: ошибка InvalidArgument, если курсор поврежден, другой версии, содержит некорректный ID
+//     либо выдан для запроса с другими параметрами.
+func decodePageToken(value, query string) (pageToken, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return pageToken{}, status.Error(codes.InvalidArgument, "Invalid page_token")
+	}
+
+	var cursor pageToken
+	if err = json.Unmarshal(content, &cursor); err != nil {
+		return pageToken{}, status.Error(codes.InvalidArgument, "Invalid page_token")
+	}
+	if cursor.Version != pageTokenVersion || cursor.LastID <= 0 {
+		return pageToken{}, status.Error(codes.InvalidArgument, "Invalid page_token")
+	}
+	if cursor.Query != query {
+		return pageToken{}, status.Error(codes.InvalidArgument, "Page_token was issued for a different filter or order")
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		lastID    int64
+		lastValue string
+	}{
+		{name: "order by id", lastID: 42},
+		{name: "order by name", lastID: 7, lastValue: "Alice \"Smith\""},
+		{name: "order by created_at", lastID: 1, lastValue: "2024-11-11T10:00:00.123456Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := encodePageToken(tt.lastID, tt.lastValue, "fingerprint")
+
+			cursor, err := decodePageToken(value, "fingerprint")
+			if err != nil {
+				t.Fatalf("decodePageToken() error = %v", err)
+			}
+			if cursor.LastID != tt.lastID || cursor.LastValue != tt.lastValue {
+				t.Errorf("decodePageToken() = (%d, %q), want (%d, %q)", cursor.LastID, cursor.LastValue, tt.lastID, tt.lastValue)
+			}
+		})
+	}
+}
+
+func TestDecodePageTokenInvalid(t *testing.T) {
+	// rawToken возвращает курсор из произвольного JSON content.
+	rawToken := func(content string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(content))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "not a token!"},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte(`{"v":1,"last_id":1,"q":"fingerprint"}`))},
+		{name: "not json", value: rawToken("last_id=1")},
+		{name: "wrong version", value: rawToken(`{"v":2,"last_id":1,"q":"fingerprint"}`)},
+		{name: "without version", value: rawToken(`{"last_id":1,"q":"fingerprint"}`)},
+		{name: "zero id", value: rawToken(`{"v":1,"last_id":0,"q":"fingerprint"}`)},
+		{name: "negative id", value: rawToken(`{"v":1,"last_id":-1,"q":"fingerprint"}`)},
+		{name: "other query", value: encodePageToken(1, "", "other")},
+		{name: "without query", value: rawToken(`{"v":1,"last_id":1}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodePageToken(tt.value, "fingerprint")
+			if code := status.Code(err); code != codes.InvalidArgument {
+				t.Errorf("decodePageToken() code = %s, want %s", code, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestQueryFingerprint(t *testing.T) {
+	base := queryFingerprint(&desc.ListUsersFilter{Role: desc.UserRole_USER, Email: "alice"}, "name asc")
+
+	tests := []struct {
+		name      string
+		filter    *desc.ListUsersFilter
+		orderBy   string
+		wantEqual bool
+	}{
+		{
+			name:      "same parameters",
+			filter:    &desc.ListUsersFilter{Email: "alice", Role: desc.UserRole_USER},
+			orderBy:   "name asc",
+			wantEqual: true,
+		},
+		{name: "other order", filter: &desc.ListUsersFilter{Role: desc.UserRole_USER, Email: "alice"}, orderBy: "name desc"},
+		{name: "other role", filter: &desc.ListUsersFilter{Role: desc.UserRole_ADMIN, Email: "alice"}, orderBy: "name asc"},
+		{name: "other email", filter: &desc.ListUsersFilter{Role: desc.UserRole_USER, Email: "bob"}, orderBy: "name asc"},
+		{
+			name:    "extra field",
+			filter:  &desc.ListUsersFilter{Role: desc.UserRole_USER, Email: "alice", Status: desc.UserStatus_EMAIL_VERIFIED},
+			orderBy: "name asc",
+		},
+		{name: "without filter", orderBy: "name asc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryFingerprint(tt.filter, tt.orderBy); (got == base) != tt.wantEqual {
+				t.Errorf("queryFingerprint() = %q, base %q, want equal %t", got, base, tt.wantEqual)
+			}
+		})
+	}
+}
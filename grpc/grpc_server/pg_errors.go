@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// uniqueViolationCode - код ошибки Postgres "unique_violation".
+	uniqueViolationCode = "23505"
+
+	// checkViolationCode - код ошибки Postgres "check_violation".
+	checkViolationCode = "23514"
+
+	// foreignKeyViolationCode - код ошибки Postgres "foreign_key_violation".
+	foreignKeyViolationCode = "23503"
+
+	// updatedAtCheckConstraint - имя ограничения, требующего updated_at >= created_at.
+	updatedAtCheckConstraint = "auth_updated_at_check"
+)
+
+// uniqueConstraintMessages - тексты ошибок AlreadyExists для каждого уникального ограничения (индекса)
+// таблицы auth. Ключ - имя ограничения из миграций.
+var uniqueConstraintMessages = map[string]string{
+	"auth_email_unique_idx":    "User with this email already exists",
+	"user_handles_pkey":        "Handle is already taken",
+	"user_handles_user_id_key": "User already has a handle",
+}
+
+// uniqueViolationError преобразует ошибку Postgres о нарушении уникальности в ошибку gRPC
+// с кодом AlreadyExists и текстом, соответствующим нарушенному ограничению.
+//
+// Параметры:
+//   - err: ошибка выполнения запроса.
+//
+// Возвращает:
+//   - error с кодом AlreadyExists, если err - ошибка нарушения уникальности.
+//   - nil в остальных случаях.
+func uniqueViolationError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode {
+		return nil
+	}
+
+	message, ok := uniqueConstraintMessages[pgErr.ConstraintName]
+	if !ok {
+		message = "User already exists"
+	}
+
+	return status.Error(codes.AlreadyExists, message)
+}
+
+// isCheckViolation возвращает true, если err - ошибка Postgres о нарушении ограничения CHECK
+// с именем constraint.
+func isCheckViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == checkViolationCode && pgErr.ConstraintName == constraint
+}
+
+// isForeignKeyViolation возвращает true, если err - ошибка Postgres о нарушении внешнего ключа.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
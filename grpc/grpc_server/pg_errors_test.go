@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUniqueViolationError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "duplicate email",
+			err:         &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "auth_email_unique_idx"},
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "User with this email already exists",
+		},
+		{
+			name:        "duplicate handle",
+			err:         &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "user_handles_pkey"},
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "Handle is already taken",
+		},
+		{
+			name:        "second handle of user",
+			err:         &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "user_handles_user_id_key"},
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "User already has a handle",
+		},
+		{
+			name:        "unknown constraint",
+			err:         &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "auth_phone_key"},
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "User already exists",
+		},
+		{
+			name:        "wrapped error",
+			err:         fmt.Errorf("unable to insert user: %w", &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "auth_email_unique_idx"}),
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "User with this email already exists",
+		},
+		{name: "other pg error", err: &pgconn.PgError{Code: checkViolationCode, ConstraintName: "auth_email_unique_idx"}, wantCode: codes.OK},
+		{name: "not a pg error", err: errors.New("connection reset"), wantCode: codes.OK},
+		{name: "no error", wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uniqueViolationError(tt.err)
+
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Errorf("uniqueViolationError() = %s %q, want %s %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsCheckViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "constraint", err: &pgconn.PgError{Code: checkViolationCode, ConstraintName: updatedAtCheckConstraint}, want: true},
+		{
+			name: "wrapped error",
+			err:  fmt.Errorf("unable to update user: %w", &pgconn.PgError{Code: checkViolationCode, ConstraintName: updatedAtCheckConstraint}),
+			want: true,
+		},
+		{name: "other constraint", err: &pgconn.PgError{Code: checkViolationCode, ConstraintName: "auth_role_check"}},
+		{name: "other pg error", err: &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: updatedAtCheckConstraint}},
+		{name: "not a pg error", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCheckViolation(tt.err, updatedAtCheckConstraint); got != tt.want {
+				t.Errorf("isCheckViolation() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "foreign key violation", err: &pgconn.PgError{Code: foreignKeyViolationCode}, want: true},
+		{name: "wrapped error", err: fmt.Errorf("unable to record activity: %w", &pgconn.PgError{Code: foreignKeyViolationCode}), want: true},
+		{name: "other pg error", err: &pgconn.PgError{Code: uniqueViolationCode}},
+		{name: "not a pg error", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isForeignKeyViolation(tt.err); got != tt.want {
+				t.Errorf("isForeignKeyViolation() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
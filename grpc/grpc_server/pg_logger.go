@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// pgLogger - адаптер, реализующий интерфейс pgx.Logger поверх zap.Logger.
+//
+// Значения аргументов запросов не логируются, т.к. среди них могут быть пароли и
+// персональные данные пользователей.
+type pgLogger struct {
+	log *zap.Logger
+}
+
+// newPGLogger создает адаптер логгера pgx, пишущий события в log.
+func newPGLogger(log *zap.Logger) pgx.Logger {
+	return &pgLogger{log: log.With(zap.String("Component", "pgx"))}
+}
+
+// Log логирует событие pgx на уровне zap, соответствующем level.
+func (l *pgLogger) Log(_ context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	fields := make([]zap.Field, 0, len(data))
+	for key, value := range data {
+		if key == "args" {
+			continue
+		}
+		fields = append(fields, zap.Any(key, value))
+	}
+
+	switch level {
+	case pgx.LogLevelTrace, pgx.LogLevelDebug:
+		l.log.Debug(msg, fields...)
+	case pgx.LogLevelInfo:
+		l.log.Info(msg, fields...)
+	case pgx.LogLevelWarn:
+		l.log.Warn(msg, fields...)
+	default:
+		l.log.Error(msg, fields...)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPGLoggerLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     pgx.LogLevel
+		wantLevel zapcore.Level
+	}{
+		{name: "trace", level: pgx.LogLevelTrace, wantLevel: zapcore.DebugLevel},
+		{name: "debug", level: pgx.LogLevelDebug, wantLevel: zapcore.DebugLevel},
+		{name: "info", level: pgx.LogLevelInfo, wantLevel: zapcore.InfoLevel},
+		{name: "warn", level: pgx.LogLevelWarn, wantLevel: zapcore.WarnLevel},
+		{name: "error", level: pgx.LogLevelError, wantLevel: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			logger := newPGLogger(zap.New(core))
+
+			logger.Log(context.Background(), tt.level, "Query", map[string]interface{}{
+				"sql":  "SELECT id FROM users WHERE password_hash = $1",
+				"args": []interface{}{"secret-hash"},
+			})
+
+			entries := logs.AllUntimed()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Message != "Query" {
+				t.Errorf("entry = %s %q, want %s %q", entry.Level, entry.Message, tt.wantLevel, "Query")
+			}
+
+			fields := entry.ContextMap()
+			if fields["Component"] != "pgx" {
+				t.Errorf("Component = %v, want pgx", fields["Component"])
+			}
+			if _, ok := fields["sql"]; !ok {
+				t.Error("sql is not logged")
+			}
+			if _, ok := fields["args"]; ok {
+				t.Errorf("args are logged: %v", fields["args"])
+			}
+		})
+	}
+}
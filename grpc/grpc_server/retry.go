@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	// deadlockDetectedCode - код ошибки Postgres "deadlock_detected".
+	deadlockDetectedCode = "40P01"
+
+	// deadlockRetryBaseDelay - задержка перед первым повтором, каждый следующий повтор ждет вдвое дольше.
+	deadlockRetryBaseDelay = 50 * time.Millisecond
+)
+
+// withDeadlockRetry выполняет запрос функцией query и повторяет его с экспоненциальной задержкой,
+// если Postgres прервал транзакцию из-за взаимной блокировки (deadlock).
+//
+// Остальные ошибки не повторяются. Количество повторов ограничено s.deadlockMaxRetries.
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса, при отмене ожидание повтора прерывается.
+//   - query: функция, выполняющая запрос и возвращающая его ошибку.
+//
+// Возвращает:
+//   - error: ошибка последней попытки выполнения запроса либо ошибка контекста.
+func (s *server) withDeadlockRetry(ctx context.Context, query func() error) error {
+	delay := deadlockRetryBaseDelay
+
+	for attempt := 0; ; attempt++ {
+		err := query()
+		if !isDeadlock(err) || attempt >= s.deadlockMaxRetries {
+			return err
+		}
+
+		s.log.Warn("Deadlock detected, retrying query", zap.Int("Attempt", attempt+1), zap.Duration("Delay", delay))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// isDeadlock возвращает true, если err - ошибка Postgres о взаимной блокировке.
+func isDeadlock(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == deadlockDetectedCode
+}
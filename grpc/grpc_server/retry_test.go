@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"go.uber.org/zap"
+)
+
+func TestWithDeadlockRetry(t *testing.T) {
+	deadlockErr := &pgconn.PgError{Code: deadlockDetectedCode}
+	uniqueErr := &pgconn.PgError{Code: "23505"}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		maxRetries   int
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "success", errs: []error{nil}, maxRetries: 3, wantAttempts: 1},
+		{name: "deadlock then success", errs: []error{deadlockErr, deadlockErr, nil}, maxRetries: 3, wantAttempts: 3},
+		{name: "retries exhausted", errs: []error{deadlockErr, deadlockErr, deadlockErr}, maxRetries: 2, wantErr: deadlockErr, wantAttempts: 3},
+		{name: "retries disabled", errs: []error{deadlockErr, nil}, maxRetries: 0, wantErr: deadlockErr, wantAttempts: 1},
+		{name: "other errors are not retried", errs: []error{uniqueErr, nil}, maxRetries: 3, wantErr: uniqueErr, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{log: zap.NewNop(), deadlockMaxRetries: tt.maxRetries}
+
+			attempts := 0
+			err := s.withDeadlockRetry(context.Background(), func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("withDeadlockRetry() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWithDeadlockRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := &server{log: zap.NewNop(), deadlockMaxRetries: 3}
+	err := s.withDeadlockRetry(ctx, func() error {
+		return &pgconn.PgError{Code: deadlockDetectedCode}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("withDeadlockRetry() error = %v, want %v", err, context.Canceled)
+	}
+}
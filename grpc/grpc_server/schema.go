@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+// expectedAuthColumns - столбцы таблицы auth, которые использует сервер, и их типы
+// в формате information_schema.columns.data_type.
+var expectedAuthColumns = map[string]string{
+	"id":                 "integer",
+	"name":               "text",
+	"email":              "text",
+	"role":               "integer",
+	"password":           "text",
+	"created_at":         "timestamp without time zone",
+	"updated_at":         "timestamp without time zone",
+	"last_seen":          "timestamp without time zone",
+	"phone":              "text",
+	"email_verified":     "boolean",
+	"tokens_valid_after": "timestamp without time zone",
+}
+
+// checkSchema проверяет, что в таблице auth есть все столбцы, которые использует сервер,
+// и что их типы совпадают с ожидаемыми.
+//
+// Позволяет при старте сервера обнаружить расхождение схемы БД и кода (например, не
+// примененную миграцию), а не получать непонятные ошибки Scan при выполнении запросов.
+//
+// Параметры:
+//   - ctx: контекст выполнения запроса.
+//   - db: пул соединений с БД.
+//
+// Возвращает:
+//   - error: ошибка со списком всех расхождений либо ошибка выполнения запроса.
+func checkSchema(ctx context.Context, db dbQuerier) error {
+	query, args, err := sq.Select("column_name", "data_type").
+		PlaceholderFormat(sq.Dollar).
+		From("information_schema.columns").
+		Where("table_schema = current_schema()").
+		Where(sq.Eq{"table_name": "auth"}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "unable to create SQL query from builder")
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "unable to query auth table columns")
+	}
+	defer rows.Close()
+
+	actualColumns := make(map[string]string)
+	for rows.Next() {
+		var columnName, dataType string
+		if err = rows.Scan(&columnName, &dataType); err != nil {
+			return errors.Wrap(err, "unable to scan auth table column")
+		}
+		actualColumns[columnName] = dataType
+	}
+	if err = rows.Err(); err != nil {
+		return errors.Wrap(err, "unable to read auth table columns")
+	}
+
+	var mismatches []string
+	for columnName, expectedType := range expectedAuthColumns {
+		actualType, ok := actualColumns[columnName]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("column %s is missing", columnName))
+		case actualType != expectedType:
+			mismatches = append(mismatches, fmt.Sprintf("column %s has type %q, expected %q", columnName, actualType, expectedType))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return errors.Errorf("auth table schema mismatch: %s", strings.Join(mismatches, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+// schemaRows возвращает строки information_schema.columns для столбцов columns.
+func schemaRows(columns map[string]string) []fakeRow {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([]fakeRow, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, fakeRow{values: []interface{}{name, columns[name]}})
+	}
+
+	return rows
+}
+
+func TestCheckSchema(t *testing.T) {
+	// withColumns возвращает ожидаемые столбцы, измененные change
+	withColumns := func(change func(columns map[string]string)) map[string]string {
+		columns := make(map[string]string, len(expectedAuthColumns))
+		for name, dataType := range expectedAuthColumns {
+			columns[name] = dataType
+		}
+		change(columns)
+		return columns
+	}
+
+	tests := []struct {
+		name    string
+		columns map[string]string
+		wantErr string
+	}{
+		{name: "expected schema", columns: expectedAuthColumns},
+		{
+			name:    "extra column",
+			columns: withColumns(func(columns map[string]string) { columns["password_confirm"] = "text" }),
+		},
+		{
+			name:    "missing column",
+			columns: withColumns(func(columns map[string]string) { delete(columns, "phone") }),
+			wantErr: "auth table schema mismatch: column phone is missing",
+		},
+		{
+			name: "missing column and wrong type",
+			columns: withColumns(func(columns map[string]string) {
+				delete(columns, "tokens_valid_after")
+				columns["created_at"] = "timestamp with time zone"
+			}),
+			wantErr: `auth table schema mismatch: column created_at has type "timestamp with time zone", ` +
+				`expected "timestamp without time zone"; column tokens_valid_after is missing`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSchema(context.Background(), &fakeDB{rows: schemaRows(tt.columns)})
+
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("checkSchema() error = %q, want %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,851 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// testServerOption - настройка сервера, создаваемого newTestServer.
+type testServerOption func(s *server, serverOptions *[]grpc.ServerOption)
+
+// withDB подменяет БД сервера.
+func withDB(db dbQuerier) testServerOption {
+	return func(s *server, _ *[]grpc.ServerOption) {
+		s.dbPool = db
+	}
+}
+
+// withUserService подменяет сервис пользователей.
+func withUserService(userService service.UserService) testServerOption {
+	return func(s *server, _ *[]grpc.ServerOption) {
+		s.userService = userService
+	}
+}
+
+// withEmailVerification подменяет сервис подтверждения email.
+func withEmailVerification(emailVerification service.EmailVerificationService) testServerOption {
+	return func(s *server, _ *[]grpc.ServerOption) {
+		s.emailVerification = emailVerification
+	}
+}
+
+// withAudit подменяет журнал аудита.
+func withAudit(audit service.AuditService) testServerOption {
+	return func(s *server, _ *[]grpc.ServerOption) {
+		s.audit = audit
+	}
+}
+
+// withEvents подменяет шину событий, чтобы тест мог на нее подписаться.
+func withEvents(events *eventBus) testServerOption {
+	return func(s *server, _ *[]grpc.ServerOption) {
+		s.events = events
+	}
+}
+
+// withCaller добавляет перехватчики, которые, как проверка доступа (interceptor.AccessInterceptor),
+// сохраняют вызывающего caller в контексте каждого запроса.
+func withCaller(caller *model.Caller) testServerOption {
+	return func(_ *server, serverOptions *[]grpc.ServerOption) {
+		*serverOptions = append(*serverOptions,
+			grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				return handler(identity.WithCaller(ctx, caller), req)
+			}),
+		)
+	}
+}
+
+// newTestServer запускает сервер UserV1 на bufconn с зависимостями из opts и возвращает клиент к нему.
+//
+// Без опций у сервера нет БД и сервисов, а запросы выполняются анонимно. Сервер и соединение
+// закрываются по завершении теста.
+func newTestServer(t *testing.T, opts ...testServerOption) desc.UserV1Client {
+	t.Helper()
+
+	s := &server{
+		log:    zaptest.NewLogger(t),
+		events: newEventBus(),
+	}
+	var serverOptions []grpc.ServerOption
+	for _, opt := range opts {
+		opt(s, &serverOptions)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(serverOptions...)
+	desc.RegisterUserV1Server(grpcServer, s)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		s.events.Close()
+		grpcServer.Stop()
+	})
+
+	return desc.NewUserV1Client(conn)
+}
+
+// fakeDB - БД, которая возвращает на QueryRow строку row, на Query - строки rows
+// и запоминает выполненные запросы.
+type fakeDB struct {
+	row     fakeRow
+	rows    []fakeRow
+	queries []string
+	args    [][]interface{}
+}
+
+func (db *fakeDB) Exec(_ context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.record(query, args)
+	return pgconn.CommandTag("UPDATE 1"), nil
+}
+
+func (db *fakeDB) Query(_ context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	db.record(query, args)
+	return &fakeRows{rows: db.rows}, nil
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, query string, args ...interface{}) pgx.Row {
+	db.record(query, args)
+	return db.row
+}
+
+func (db *fakeDB) record(query string, args []interface{}) {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+}
+
+// fakeRow - строка результата запроса: значения values по порядку столбцов либо ошибка err.
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	for i := range dest {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+
+	return nil
+}
+
+// fakeRows - результат запроса из строк rows.
+type fakeRows struct {
+	pgx.Rows
+	rows []fakeRow
+	row  fakeRow
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+
+	r.row, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	return r.row.Scan(dest...)
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func (r *fakeRows) Close() {}
+
+// fakeEmailVerification - сервис подтверждения email, запоминающий адреса, на которые отправлены письма.
+type fakeEmailVerification struct {
+	service.EmailVerificationService
+	emails []string
+}
+
+func (f *fakeEmailVerification) RequestVerification(_ context.Context, _ int64, email string) error {
+	f.emails = append(f.emails, email)
+	return nil
+}
+
+func TestGetUserInfo(t *testing.T) {
+	createdAt := time.Date(2024, 11, 11, 10, 0, 0, 0, time.UTC)
+	userRow := fakeRow{values: []interface{}{
+		int64(5), "Alice", "alice@example.com", desc.UserRole_USER, createdAt, sql.NullTime{}, sql.NullString{},
+	}}
+
+	tests := []struct {
+		name        string
+		req         *desc.GetUserInfoRequest
+		caller      *model.Caller
+		wantCode    codes.Code
+		wantQueries int
+	}{
+		{
+			name:        "self",
+			req:         &desc.GetUserInfoRequest{Id: 5},
+			caller:      &model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)},
+			wantCode:    codes.OK,
+			wantQueries: 1,
+		},
+		{
+			name:        "admin",
+			req:         &desc.GetUserInfoRequest{Id: 5},
+			caller:      &model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)},
+			wantCode:    codes.OK,
+			wantQueries: 1,
+		},
+		{
+			name:     "other user",
+			req:      &desc.GetUserInfoRequest{Id: 5},
+			caller:   &model.Caller{UserID: 6, Role: int32(desc.UserRole_USER)},
+			wantCode: codes.PermissionDenied,
+		},
+		{name: "anonymous", req: &desc.GetUserInfoRequest{Id: 5}, wantCode: codes.Unauthenticated},
+		{
+			name:     "without id",
+			req:      &desc.GetUserInfoRequest{},
+			caller:   &model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: userRow}
+			opts := []testServerOption{withDB(db)}
+			if tt.caller != nil {
+				opts = append(opts, withCaller(tt.caller))
+			}
+			client := newTestServer(t, opts...)
+
+			resp, err := client.GetUserInfo(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("GetUserInfo() code = %s, want %s", code, tt.wantCode)
+			}
+			if len(db.queries) != tt.wantQueries {
+				t.Fatalf("queries = %q, want %d", db.queries, tt.wantQueries)
+			}
+			if err != nil {
+				return
+			}
+
+			if resp.GetId() != 5 || resp.GetName() != "Alice" || resp.GetEmail() != "alice@example.com" || resp.GetRole() != desc.UserRole_USER {
+				t.Errorf("GetUserInfo() = %v", resp)
+			}
+			if !resp.GetCreatedAt().AsTime().Equal(createdAt) {
+				t.Errorf("CreatedAt = %s, want %s", resp.GetCreatedAt().AsTime(), createdAt)
+			}
+			if resp.GetUpdatedAt() != nil {
+				t.Errorf("UpdatedAt = %s, want nil", resp.GetUpdatedAt().AsTime())
+			}
+		})
+	}
+}
+
+func TestUpdateUserEmailVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *desc.UpdateUserRequest
+		emailVerified bool
+		wantSQL       []string
+		wantEmails    []string
+	}{
+		{
+			name:          "email changed",
+			req:           &desc.UpdateUserRequest{Id: 5, Email: wrapperspb.String(" new@example.com ")},
+			emailVerified: false,
+			wantSQL:       []string{"DELETE FROM email_verification_tokens", "email_verified = email_verified AND lower(email) = lower("},
+			wantEmails:    []string{"new@example.com"},
+		},
+		{
+			name:          "same email already verified",
+			req:           &desc.UpdateUserRequest{Id: 5, Email: wrapperspb.String("alice@example.com")},
+			emailVerified: true,
+			wantSQL:       []string{"email_verified = email_verified AND lower(email) = lower("},
+		},
+		{
+			name:          "email not changed",
+			req:           &desc.UpdateUserRequest{Id: 5, Name: wrapperspb.String("Alice")},
+			emailVerified: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: fakeRow{values: []interface{}{tt.emailVerified}}}
+			emailVerification := &fakeEmailVerification{}
+			client := newTestServer(t,
+				withDB(db),
+				withEmailVerification(emailVerification),
+				withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+			)
+
+			if _, err := client.UpdateUser(context.Background(), tt.req); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if len(db.queries) != 1 {
+				t.Fatalf("queries = %d, want 1", len(db.queries))
+			}
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(db.queries[0], want) {
+					t.Errorf("query %q does not contain %q", db.queries[0], want)
+				}
+			}
+			if !tt.req.HasEmail() && strings.Contains(db.queries[0], "email_verified =") {
+				t.Errorf("query %q changes email_verified", db.queries[0])
+			}
+			if !reflect.DeepEqual(emailVerification.emails, tt.wantEmails) {
+				t.Errorf("verification emails = %v, want %v", emailVerification.emails, tt.wantEmails)
+			}
+		})
+	}
+}
+
+// fakeUserService - сервис пользователей, запоминающий созданных пользователей. Пользователь
+// с email duplicateEmail не создается из-за нарушения уникальности email.
+type fakeUserService struct {
+	service.UserService
+	created        []*model.UserToCreate
+	duplicateEmail string
+	batches        int
+}
+
+func (f *fakeUserService) Create(_ context.Context, user *model.UserToCreate) (int64, error) {
+	f.created = append(f.created, user)
+	return int64(len(f.created)), nil
+}
+
+func (f *fakeUserService) CreateBatch(_ context.Context, users []*model.UserToCreate, _ bool) ([]model.UserCreateResult, error) {
+	f.batches++
+
+	results := make([]model.UserCreateResult, len(users))
+	for i, user := range users {
+		if user.Email == f.duplicateEmail {
+			results[i].Err = &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "auth_email_unique_idx"}
+			continue
+		}
+		f.created = append(f.created, user)
+		results[i].ID = int64(len(f.created))
+	}
+
+	return results, nil
+}
+
+func TestCreateUserRoleOfNonAdminCaller(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *model.Caller
+		role     desc.UserRole
+		wantCode codes.Code
+	}{
+		{name: "anonymous requests user", role: desc.UserRole_USER, wantCode: codes.OK},
+		{name: "anonymous requests admin", role: desc.UserRole_ADMIN, wantCode: codes.PermissionDenied},
+		{
+			name:     "user requests admin",
+			caller:   &model.Caller{UserID: 2, Role: int32(desc.UserRole_USER)},
+			role:     desc.UserRole_ADMIN,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := &fakeUserService{}
+			opts := []testServerOption{withUserService(userService), withEmailVerification(&fakeEmailVerification{})}
+			if tt.caller != nil {
+				opts = append(opts, withCaller(tt.caller))
+			}
+			client := newTestServer(t, opts...)
+
+			_, err := client.CreateUser(context.Background(), &desc.CreateUserRequest{
+				Name:            "Mallory",
+				Email:           "mallory@example.com",
+				Password:        "Str0ng-password",
+				PasswordConfirm: "Str0ng-password",
+				Role:            tt.role,
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("CreateUser() code = %s, want %s", code, tt.wantCode)
+			}
+
+			if err != nil && len(userService.created) > 0 {
+				t.Errorf("created users = %v, want none", userService.created)
+			}
+			if err == nil && (len(userService.created) != 1 || userService.created[0].Role != int32(desc.UserRole_USER)) {
+				t.Errorf("created users = %v, want one user with role USER", userService.created)
+			}
+		})
+	}
+}
+
+func TestCreateUserOutOfRangeRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     desc.UserRole
+		wantCode codes.Code
+	}{
+		{name: "defined role", role: desc.UserRole_ADMIN, wantCode: codes.OK},
+		{name: "out of range role", role: desc.UserRole(42), wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := &fakeUserService{}
+			client := newTestServer(t,
+				withUserService(userService),
+				withEmailVerification(&fakeEmailVerification{}),
+				withCaller(&model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}),
+			)
+
+			_, err := client.CreateUser(context.Background(), &desc.CreateUserRequest{
+				Name:            "Alice",
+				Email:           "alice@example.com",
+				Password:        "Str0ng-password",
+				PasswordConfirm: "Str0ng-password",
+				Role:            tt.role,
+			})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("CreateUser() code = %s, want %s", code, tt.wantCode)
+			}
+
+			if err != nil && len(userService.created) > 0 {
+				t.Errorf("created users = %v, want none", userService.created)
+			}
+			if err == nil && (len(userService.created) != 1 || userService.created[0].Role != int32(tt.role)) {
+				t.Errorf("created users = %v, want one user with role %s", userService.created, tt.role)
+			}
+		})
+	}
+}
+
+// fakeAuditService - журнал аудита, запоминающий типы записанных событий.
+type fakeAuditService struct {
+	service.AuditService
+	events []model.AuditEventType
+}
+
+func (a *fakeAuditService) Record(_ context.Context, eventType model.AuditEventType, _ int64, _ map[string]string) {
+	a.events = append(a.events, eventType)
+}
+
+func TestDeleteUserReturnSummary(t *testing.T) {
+	createdAt := time.Date(2024, 11, 11, 10, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		returnSummary bool
+		want          *desc.UserSummary
+		wantSQL       string
+	}{
+		{name: "empty response by default", wantSQL: "DELETE FROM auth WHERE id = $1"},
+		{
+			name:          "summary",
+			returnSummary: true,
+			want: &desc.UserSummary{
+				Id:        5,
+				Name:      "Alice",
+				Email:     "alice@example.com",
+				Role:      desc.UserRole_ADMIN,
+				CreatedAt: timestamppb.New(createdAt),
+				UpdatedAt: timestamppb.New(updatedAt),
+			},
+			wantSQL: "DELETE FROM auth WHERE id = $1 RETURNING id, name, email, role, created_at, updated_at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: fakeRow{values: []interface{}{
+				int64(5), "Alice", "alice@example.com", desc.UserRole_ADMIN, createdAt, sql.NullTime{Time: updatedAt, Valid: true},
+			}}}
+			audit := &fakeAuditService{}
+			client := newTestServer(t,
+				withDB(db),
+				withAudit(audit),
+				withCaller(&model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}),
+			)
+
+			resp, err := client.DeleteUser(context.Background(), &desc.DeleteUserRequest{Id: 5, ReturnSummary: tt.returnSummary})
+			if err != nil {
+				t.Fatalf("DeleteUser() error = %v", err)
+			}
+
+			if !proto.Equal(resp.GetUser(), tt.want) {
+				t.Errorf("User = %v, want %v", resp.GetUser(), tt.want)
+			}
+			if len(db.queries) != 1 || db.queries[0] != tt.wantSQL {
+				t.Errorf("queries = %q, want %q", db.queries, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(audit.events, []model.AuditEventType{model.AuditEventUserDeleted}) {
+				t.Errorf("audit events = %v, want %v", audit.events, model.AuditEventUserDeleted)
+			}
+		})
+	}
+}
+
+func TestDeleteUserReturnSummaryNotFound(t *testing.T) {
+	client := newTestServer(t,
+		withDB(&fakeDB{row: fakeRow{err: pgx.ErrNoRows}}),
+		withAudit(&fakeAuditService{}),
+		withCaller(&model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}),
+	)
+
+	_, err := client.DeleteUser(context.Background(), &desc.DeleteUserRequest{Id: 5, ReturnSummary: true})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("DeleteUser() code = %s, want %s", code, codes.NotFound)
+	}
+}
+
+func TestUpdateUserNothingToUpdate(t *testing.T) {
+	db := &fakeDB{}
+	client := newTestServer(t,
+		withDB(db),
+		withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+	)
+
+	_, err := client.UpdateUser(context.Background(), &desc.UpdateUserRequest{Id: 5, Name: wrapperspb.String("  ")})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("UpdateUser() code = %s, want %s", code, codes.InvalidArgument)
+	}
+	if len(db.queries) > 0 {
+		t.Errorf("queries = %q, want none", db.queries)
+	}
+}
+
+func TestValidateEmails(t *testing.T) {
+	db := &fakeDB{rows: []fakeRow{{values: []interface{}{"taken@example.com"}}}}
+	client := newTestServer(t, withDB(db))
+
+	resp, err := client.ValidateEmails(context.Background(), &desc.ValidateEmailsRequest{
+		Emails: []string{"free@example.com", " Taken@Example.com ", "not an email", "taken@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateEmails() error = %v", err)
+	}
+
+	want := []*desc.EmailValidationResult{
+		{Email: "free@example.com", ValidFormat: true, Available: true},
+		{Email: " Taken@Example.com ", ValidFormat: true, Available: false},
+		{Email: "not an email", ValidFormat: false, Available: false},
+		{Email: "taken@example.com", ValidFormat: true, Available: false},
+	}
+	if len(resp.GetResults()) != len(want) {
+		t.Fatalf("results = %v, want %v", resp.GetResults(), want)
+	}
+	for i := range want {
+		if !proto.Equal(resp.GetResults()[i], want[i]) {
+			t.Errorf("results[%d] = %v, want %v", i, resp.GetResults()[i], want[i])
+		}
+	}
+
+	// Занятость всех корректных адресов проверяется одним запросом
+	if len(db.queries) != 1 {
+		t.Fatalf("queries = %d, want 1", len(db.queries))
+	}
+	wantArgs := []interface{}{[]string{"free@example.com", "taken@example.com", "taken@example.com"}}
+	if !reflect.DeepEqual(db.args[0], wantArgs) {
+		t.Errorf("args = %v, want %v", db.args[0], wantArgs)
+	}
+}
+
+func TestCreateUserWarnings(t *testing.T) {
+	userService := &fakeUserService{}
+	client := newTestServer(t, withUserService(userService), withEmailVerification(&fakeEmailVerification{}))
+
+	resp, err := client.CreateUser(context.Background(), &desc.CreateUserRequest{
+		Name:            "Alice",
+		Email:           "alice@localhost",
+		Password:        "password",
+		PasswordConfirm: "password",
+		Role:            desc.UserRole_USER,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	wantWarnings := []string{"Password should contain both letters and digits", "Email looks unusual"}
+	if !reflect.DeepEqual(resp.GetWarnings(), wantWarnings) {
+		t.Errorf("Warnings = %q, want %q", resp.GetWarnings(), wantWarnings)
+	}
+	if len(userService.created) != 1 || resp.GetId() != 1 {
+		t.Errorf("created users = %v, id = %d, want one user with id 1", userService.created, resp.GetId())
+	}
+}
+
+func TestFindDuplicateCandidates(t *testing.T) {
+	createdAt := time.Date(2024, 11, 11, 10, 0, 0, 0, time.UTC)
+	userRow := func(id int64, email string) fakeRow {
+		return fakeRow{values: []interface{}{id, "User", email, desc.UserRole_USER, createdAt, sql.NullTime{}}}
+	}
+
+	db := &fakeDB{rows: []fakeRow{
+		userRow(1, "john.doe@gmail.com"),
+		userRow(2, "alice@example.com"),
+		userRow(3, "JohnDoe+news@gmail.com"),
+		userRow(4, "alice+shop@example.com"),
+		userRow(5, "bob@example.com"),
+		userRow(6, "j.o.h.n.doe@googlemail.com"),
+	}}
+	client := newTestServer(t, withDB(db))
+
+	resp, err := client.FindDuplicateCandidates(context.Background(), &desc.FindDuplicateCandidatesRequest{Limit: 10})
+	if err != nil {
+		t.Fatalf("FindDuplicateCandidates() error = %v", err)
+	}
+
+	want := map[string][]int64{
+		"johndoe@gmail.com": {1, 3, 6},
+		"alice@example.com": {2, 4},
+	}
+	got := make(map[string][]int64)
+	for _, group := range resp.GetGroups() {
+		for _, user := range group.GetUsers() {
+			got[group.GetCanonicalEmail()] = append(got[group.GetCanonicalEmail()], user.GetId())
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+	if len(resp.GetGroups()) > 0 && resp.GetGroups()[0].GetCanonicalEmail() != "johndoe@gmail.com" {
+		t.Errorf("first group = %q, want group of the first user", resp.GetGroups()[0].GetCanonicalEmail())
+	}
+}
+
+func TestUpdateUserEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		row        fakeRow
+		wantEvents int
+	}{
+		{name: "existing user", row: fakeRow{values: []interface{}{true}}, wantEvents: 1},
+		{name: "missing user", row: fakeRow{err: pgx.ErrNoRows}, wantEvents: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := newEventBus()
+			subscription := events.Subscribe()
+			client := newTestServer(t,
+				withDB(&fakeDB{row: tt.row}),
+				withEvents(events),
+				withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+			)
+
+			if _, err := client.UpdateUser(context.Background(), &desc.UpdateUserRequest{Id: 5, Name: wrapperspb.String("Alice")}); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			if got := len(subscription.events); got != tt.wantEvents {
+				t.Fatalf("published %d events, want %d", got, tt.wantEvents)
+			}
+			if tt.wantEvents > 0 {
+				if event := <-subscription.events; event.eventType != desc.UserEventType_UPDATED || event.userID != 5 {
+					t.Errorf("event = %v %d, want %v 5", event.eventType, event.userID, desc.UserEventType_UPDATED)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateUserUpdatedAtCheckViolation(t *testing.T) {
+	db := &fakeDB{row: fakeRow{err: &pgconn.PgError{Code: checkViolationCode, ConstraintName: updatedAtCheckConstraint}}}
+	client := newTestServer(t,
+		withDB(db),
+		withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+	)
+
+	_, err := client.UpdateUser(context.Background(), &desc.UpdateUserRequest{Id: 5, Name: wrapperspb.String("Alice")})
+	if code := status.Code(err); code != codes.Internal {
+		t.Fatalf("UpdateUser() code = %s, want %s", code, codes.Internal)
+	}
+	if !strings.Contains(db.queries[0], "updated_at = now()") {
+		t.Errorf("query %q does not take updated_at from the database clock", db.queries[0])
+	}
+}
+
+func TestUpdateUserPhone(t *testing.T) {
+	e164 := sql.NullString{String: "+79991234567", Valid: true}
+
+	tests := []struct {
+		name      string
+		phone     string
+		wantCode  codes.Code
+		wantPhone sql.NullString
+	}{
+		{name: "e164", phone: "+79991234567", wantPhone: e164},
+		{name: "needs normalization", phone: "+7 (999) 123-45-67", wantPhone: e164},
+		{name: "international prefix", phone: "00 7 999 123 45 67", wantPhone: e164},
+		{name: "cleared", phone: " ", wantPhone: sql.NullString{}},
+		{name: "without country code", phone: "8 (999) 123-45-67", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: fakeRow{values: []interface{}{true}}}
+			client := newTestServer(t,
+				withDB(db),
+				withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+			)
+
+			_, err := client.UpdateUser(context.Background(), &desc.UpdateUserRequest{Id: 5, Phone: wrapperspb.String(tt.phone)})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("UpdateUser() code = %s, want %s", code, tt.wantCode)
+			}
+			if err != nil {
+				if len(db.queries) > 0 {
+					t.Errorf("queries = %q, want none", db.queries)
+				}
+				return
+			}
+
+			if len(db.args) != 1 || !containsArg(db.args[0], tt.wantPhone) {
+				t.Errorf("args = %v, want phone %v", db.args, tt.wantPhone)
+			}
+		})
+	}
+}
+
+// containsArg возвращает true, если среди аргументов запроса args есть want.
+func containsArg(args []interface{}, want interface{}) bool {
+	for _, arg := range args {
+		if reflect.DeepEqual(arg, want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestClaimHandle(t *testing.T) {
+	tests := []struct {
+		name       string
+		row        fakeRow
+		wantCode   codes.Code
+		wantHandle string
+	}{
+		{name: "claimed", row: fakeRow{values: []interface{}{int64(5)}}, wantCode: codes.OK, wantHandle: "alice_01"},
+		{name: "taken by other user", row: fakeRow{err: pgx.ErrNoRows}, wantCode: codes.AlreadyExists},
+		{
+			name:     "user already has a handle",
+			row:      fakeRow{err: &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "user_handles_user_id_key"}},
+			wantCode: codes.AlreadyExists,
+		},
+		{name: "user not found", row: fakeRow{err: &pgconn.PgError{Code: foreignKeyViolationCode}}, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: tt.row}
+			client := newTestServer(t,
+				withDB(db),
+				withCaller(&model.Caller{UserID: 5, Role: int32(desc.UserRole_USER)}),
+			)
+
+			resp, err := client.ClaimHandle(context.Background(), &desc.ClaimHandleRequest{Id: 5, Handle: " Alice_01 "})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ClaimHandle() code = %s, want %s", code, tt.wantCode)
+			}
+			if resp.GetHandle() != tt.wantHandle {
+				t.Errorf("Handle = %q, want %q", resp.GetHandle(), tt.wantHandle)
+			}
+			if !reflect.DeepEqual(db.args[0], []interface{}{"alice_01", int64(5)}) {
+				t.Errorf("args = %v, want normalized handle and user id", db.args[0])
+			}
+		})
+	}
+}
+
+func TestGetUserStats(t *testing.T) {
+	activeSince := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		activeSince     *timestamppb.Timestamp
+		wantActiveSince func(got time.Time) bool
+	}{
+		{
+			name:            "active since",
+			activeSince:     timestamppb.New(activeSince),
+			wantActiveSince: func(got time.Time) bool { return got.Equal(activeSince) },
+		},
+		{
+			name: "default active window",
+			wantActiveSince: func(got time.Time) bool {
+				return time.Since(got.Add(defaultActiveUsersWindow)).Abs() < time.Minute
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{row: fakeRow{values: []interface{}{int64(10), int64(2), int64(8), int64(7), int64(3), int64(5)}}}
+			client := newTestServer(t, withDB(db))
+
+			resp, err := client.GetUserStats(context.Background(), &desc.GetUserStatsRequest{ActiveSince: tt.activeSince})
+			if err != nil {
+				t.Fatalf("GetUserStats() error = %v", err)
+			}
+
+			want := &desc.GetUserStatsResponse{Total: 10, Admins: 2, Users: 8, EmailVerified: 7, WithPhone: 3, Active: 5}
+			if !proto.Equal(resp, want) {
+				t.Errorf("GetUserStats() = %v, want %v", resp, want)
+			}
+
+			// Все показатели считаются одним запросом по существующим столбцам таблицы auth
+			if len(db.queries) != 1 {
+				t.Fatalf("queries = %q, want one", db.queries)
+			}
+			for _, filter := range []string{"role = $1", "role = $2", "email_verified", "phone IS NOT NULL", "last_seen >= $3"} {
+				if !strings.Contains(db.queries[0], "FILTER (WHERE "+filter+")") {
+					t.Errorf("query %q does not count FILTER (WHERE %s)", db.queries[0], filter)
+				}
+			}
+
+			args := db.args[0]
+			if len(args) != 3 || args[0] != int32(desc.UserRole_ADMIN) || args[1] != int32(desc.UserRole_USER) {
+				t.Fatalf("args = %v, want admin role, user role and active since", args)
+			}
+			if got, ok := args[2].(time.Time); !ok || !tt.wantActiveSince(got) {
+				t.Errorf("active since = %v", args[2])
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/anton0701/auth/config/env"
+)
+
+// newServerCredentials создает учетные данные TLS для GRPC-сервера: сервер предъявляет сертификат
+// из конфига. Если в конфиге заданы УЦ клиентов, принимаются только клиенты с сертификатом,
+// подписанным одним из них (взаимный TLS).
+//
+// Возвращает insecure.NewCredentials(), если TLS отключен для локального запуска.
+func newServerCredentials(cfg env.GRPCConfig) (credentials.TransportCredentials, error) {
+	if cfg.Insecure() {
+		return insecure.NewCredentials(), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile(), cfg.TLSKeyFile())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load grpc server certificate")
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(cfg.TLSClientCAFile()) == 0 {
+		return credentials.NewTLS(tlsConfig), nil
+	}
+
+	clientCAs, err := os.ReadFile(cfg.TLSClientCAFile())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read grpc client ca file")
+	}
+
+	clientCAPool := x509.NewCertPool()
+	if !clientCAPool.AppendCertsFromPEM(clientCAs) {
+		return nil, errors.New("grpc client ca file contains no certificates")
+	}
+
+	tlsConfig.ClientCAs = clientCAPool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return credentials.NewTLS(tlsConfig), nil
+}
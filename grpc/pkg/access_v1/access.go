@@ -0,0 +1,190 @@
+package access_v1
+
+import (
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+var (
+	_ pkg.Validator = (*CheckRequest)(nil)
+	_ pkg.Validator = (*SetAccessibleRolesRequest)(nil)
+	_ pkg.Validator = (*IssueAPIKeyRequest)(nil)
+	_ pkg.Validator = (*RevokeAPIKeyRequest)(nil)
+	_ pkg.Validator = (*IntrospectTokenRequest)(nil)
+	_ pkg.Validator = (*CreateServiceAccountRequest)(nil)
+	_ pkg.Validator = (*RotateServiceAccountSecretRequest)(nil)
+	_ pkg.Validator = (*DisableServiceAccountRequest)(nil)
+	_ pkg.Validator = (*GetRolePermissionsRequest)(nil)
+)
+
+// Обязательные поля запросов к АПИ.
+var (
+	checkRequiredFields                      = pkg.RequiredFields{"endpoint_address"}
+	setAccessibleRolesRequiredFields         = pkg.RequiredFields{"endpoint_address"}
+	issueAPIKeyRequiredFields                = pkg.RequiredFields{"service_account"}
+	revokeAPIKeyRequiredFields               = pkg.RequiredFields{"id"}
+	introspectTokenRequiredFields            = pkg.RequiredFields{"token"}
+	createServiceAccountRequiredFields       = pkg.RequiredFields{"name"}
+	rotateServiceAccountSecretRequiredFields = pkg.RequiredFields{"id"}
+	disableServiceAccountRequiredFields      = pkg.RequiredFields{"id"}
+)
+
+// Validate
+//
+// Возвращает:
+//   - error, если Endpoint_address не указан или не является полным именем метода gRPC
+//     ("/package.Service/Method").
+//   - nil в остальных случаях.
+func (req *CheckRequest) Validate() error {
+	// Проверка, что Endpoint_address указан
+	if err := checkRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка формата Endpoint_address
+	return validateEndpointAddress(req.EndpointAddress)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Endpoint_address не указан или не является полным именем метода gRPC.
+//   - error, если какая-либо из Roles некорректная (UNKNOWN либо не объявлена в enum user_v1.UserRole).
+//   - nil в остальных случаях.
+func (req *SetAccessibleRolesRequest) Validate() error {
+	// Проверка, что Endpoint_address указан и корректный
+	if err := setAccessibleRolesRequiredFields.Validate(req); err != nil {
+		return err
+	}
+	if err := validateEndpointAddress(req.EndpointAddress); err != nil {
+		return err
+	}
+
+	// Проверка, что все роли корректные
+	for _, role := range req.Roles {
+		if !userDesc.UserRole(role).IsDefined() {
+			err := status.Errorf(codes.InvalidArgument, "Invalid role %d", role)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Service_account пустой.
+//   - error, если Role некорректная (UNKNOWN либо не объявлена в enum user_v1.UserRole).
+//   - error, если Expires_at указан и некорректный либо уже наступил.
+//   - nil в остальных случаях.
+func (req *IssueAPIKeyRequest) Validate() error {
+	// Проверка, что Service_account не пустой
+	if err := issueAPIKeyRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка, что Role корректная и входит в список объявленных ролей
+	if !userDesc.UserRole(req.Role).IsDefined() {
+		err := status.Errorf(codes.InvalidArgument, "Invalid role %d", req.Role)
+		return err
+	}
+
+	// Проверка, что Expires_at, если указан, корректный и еще не наступил
+	if req.ExpiresAt != nil {
+		if err := req.ExpiresAt.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid Expires_at: %v", err)
+		}
+		if !req.ExpiresAt.AsTime().After(time.Now()) {
+			return status.Error(codes.InvalidArgument, "Expires_at must be in the future")
+		}
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Id не указан.
+//   - nil в остальных случаях.
+func (req *RevokeAPIKeyRequest) Validate() error {
+	return revokeAPIKeyRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Token пустой.
+//   - nil в остальных случаях.
+func (req *IntrospectTokenRequest) Validate() error {
+	return introspectTokenRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Name пустой.
+//   - error, если Role не является ролью сервисного аккаунта (SERVICE или SERVICE_ADMIN).
+//   - nil в остальных случаях.
+func (req *CreateServiceAccountRequest) Validate() error {
+	// Проверка, что Name не пустой
+	if err := createServiceAccountRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка, что Role - роль сервисного аккаунта
+	if !userDesc.UserRole(req.Role).IsServiceRole() {
+		err := status.Errorf(codes.InvalidArgument, "Invalid role %d: service accounts must have role SERVICE or SERVICE_ADMIN", req.Role)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Id не указан.
+//   - nil в остальных случаях.
+func (req *RotateServiceAccountSecretRequest) Validate() error {
+	return rotateServiceAccountSecretRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Id не указан.
+//   - nil в остальных случаях.
+func (req *DisableServiceAccountRequest) Validate() error {
+	return disableServiceAccountRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Role некорректная (UNKNOWN либо не объявлена в enum user_v1.UserRole).
+//   - nil в остальных случаях.
+func (req *GetRolePermissionsRequest) Validate() error {
+	if !userDesc.UserRole(req.Role).IsDefined() {
+		return status.Errorf(codes.InvalidArgument, "Invalid role %d", req.Role)
+	}
+
+	return nil
+}
+
+// validateEndpointAddress проверяет, что endpointAddress - полное имя метода gRPC ("/package.Service/Method").
+func validateEndpointAddress(endpointAddress string) error {
+	parts := strings.Split(endpointAddress, "/")
+	if len(parts) != 3 || len(parts[0]) != 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return status.Errorf(codes.InvalidArgument, "Endpoint address %q must have format /package.Service/Method", endpointAddress)
+	}
+
+	return nil
+}
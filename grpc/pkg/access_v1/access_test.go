@@ -0,0 +1,108 @@
+package access_v1
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      pkg.Validator
+		wantCode codes.Code
+	}{
+		{name: "check", req: &CheckRequest{EndpointAddress: "/user_v1.UserV1/GetUserInfo"}, wantCode: codes.OK},
+		{name: "check without endpoint", req: &CheckRequest{}, wantCode: codes.InvalidArgument},
+		{name: "check endpoint without slash", req: &CheckRequest{EndpointAddress: "user_v1.UserV1/GetUserInfo"}, wantCode: codes.InvalidArgument},
+		{name: "check endpoint without method", req: &CheckRequest{EndpointAddress: "/user_v1.UserV1/"}, wantCode: codes.InvalidArgument},
+		{name: "check endpoint with extra part", req: &CheckRequest{EndpointAddress: "/user_v1.UserV1/GetUserInfo/1"}, wantCode: codes.InvalidArgument},
+		{
+			name:     "set accessible roles",
+			req:      &SetAccessibleRolesRequest{EndpointAddress: "/user_v1.UserV1/DeleteUser", Roles: []int32{int32(userDesc.UserRole_ADMIN)}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "set no accessible roles",
+			req:      &SetAccessibleRolesRequest{EndpointAddress: "/user_v1.UserV1/DeleteUser"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "set accessible roles with invalid endpoint",
+			req:      &SetAccessibleRolesRequest{EndpointAddress: "DeleteUser", Roles: []int32{int32(userDesc.UserRole_ADMIN)}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "set unknown accessible role",
+			req:      &SetAccessibleRolesRequest{EndpointAddress: "/user_v1.UserV1/DeleteUser", Roles: []int32{int32(userDesc.UserRole_UNKNOWN)}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "set undefined accessible role",
+			req:      &SetAccessibleRolesRequest{EndpointAddress: "/user_v1.UserV1/DeleteUser", Roles: []int32{10}},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "issue api key", req: &IssueAPIKeyRequest{ServiceAccount: "importer", Role: int32(userDesc.UserRole_USER)}, wantCode: codes.OK},
+		{
+			name: "issue api key with expiration",
+			req: &IssueAPIKeyRequest{
+				ServiceAccount: "importer",
+				Role:           int32(userDesc.UserRole_USER),
+				ExpiresAt:      timestamppb.New(time.Now().Add(time.Hour)),
+			},
+			wantCode: codes.OK,
+		},
+		{name: "issue api key without service account", req: &IssueAPIKeyRequest{Role: int32(userDesc.UserRole_USER)}, wantCode: codes.InvalidArgument},
+		{name: "issue api key without role", req: &IssueAPIKeyRequest{ServiceAccount: "importer"}, wantCode: codes.InvalidArgument},
+		{
+			name: "issue expired api key",
+			req: &IssueAPIKeyRequest{
+				ServiceAccount: "importer",
+				Role:           int32(userDesc.UserRole_USER),
+				ExpiresAt:      timestamppb.New(time.Now().Add(-time.Hour)),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "revoke api key", req: &RevokeAPIKeyRequest{Id: 1}, wantCode: codes.OK},
+		{name: "revoke api key without id", req: &RevokeAPIKeyRequest{}, wantCode: codes.InvalidArgument},
+		{name: "introspect token", req: &IntrospectTokenRequest{Token: "token"}, wantCode: codes.OK},
+		{name: "introspect blank token", req: &IntrospectTokenRequest{Token: " "}, wantCode: codes.InvalidArgument},
+		{name: "role permissions", req: &GetRolePermissionsRequest{Role: int32(userDesc.UserRole_USER)}, wantCode: codes.OK},
+		{name: "role permissions without role", req: &GetRolePermissionsRequest{}, wantCode: codes.InvalidArgument},
+		{name: "role permissions of undefined role", req: &GetRolePermissionsRequest{Role: 10}, wantCode: codes.InvalidArgument},
+		{
+			name:     "create service account",
+			req:      &CreateServiceAccountRequest{Name: "importer", Role: int32(userDesc.UserRole_SERVICE)},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "create service admin account",
+			req:      &CreateServiceAccountRequest{Name: "importer", Role: int32(userDesc.UserRole_SERVICE_ADMIN)},
+			wantCode: codes.OK,
+		},
+		{name: "create service account without name", req: &CreateServiceAccountRequest{Role: int32(userDesc.UserRole_SERVICE)}, wantCode: codes.InvalidArgument},
+		{
+			name:     "create service account with user role",
+			req:      &CreateServiceAccountRequest{Name: "importer", Role: int32(userDesc.UserRole_ADMIN)},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "rotate secret", req: &RotateServiceAccountSecretRequest{Id: 1}, wantCode: codes.OK},
+		{name: "rotate secret without id", req: &RotateServiceAccountSecretRequest{}, wantCode: codes.InvalidArgument},
+		{name: "disable service account", req: &DisableServiceAccountRequest{Id: 1}, wantCode: codes.OK},
+		{name: "disable service account without id", req: &DisableServiceAccountRequest{}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.req.Validate()); code != tt.wantCode {
+				t.Errorf("Validate() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
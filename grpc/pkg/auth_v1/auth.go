@@ -0,0 +1,224 @@
+package auth_v1
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+var (
+	_ pkg.Validator = (*LoginRequest)(nil)
+	_ pkg.Validator = (*GetRefreshTokenRequest)(nil)
+	_ pkg.Validator = (*GetAccessTokenRequest)(nil)
+	_ pkg.Validator = (*LogoutRequest)(nil)
+	_ pkg.Validator = (*RequestPasswordResetRequest)(nil)
+	_ pkg.Validator = (*ConfirmPasswordResetRequest)(nil)
+	_ pkg.Validator = (*VerifyEmailRequest)(nil)
+	_ pkg.Validator = (*ConfirmTOTPRequest)(nil)
+	_ pkg.Validator = (*ClearLockoutRequest)(nil)
+	_ pkg.Validator = (*ImpersonateUserRequest)(nil)
+	_ pkg.Validator = (*ListAuditEventsRequest)(nil)
+	_ pkg.Validator = (*GetServiceAccountTokenRequest)(nil)
+	_ pkg.Validator = (*VerifyPasswordRequest)(nil)
+
+	_ pkg.WarningsProvider = (*ConfirmPasswordResetRequest)(nil)
+)
+
+// Обязательные поля запросов к АПИ.
+var (
+	loginRequiredFields        = pkg.RequiredFields{"email", "password"}
+	refreshTokenRequiredFields = pkg.RequiredFields{"refresh_token"}
+
+	requestPasswordResetRequiredFields   = pkg.RequiredFields{"email"}
+	confirmPasswordResetRequiredFields   = pkg.RequiredFields{"token"}
+	verifyEmailRequiredFields            = pkg.RequiredFields{"token"}
+	confirmTOTPRequiredFields            = pkg.RequiredFields{"code"}
+	clearLockoutRequiredFields           = pkg.RequiredFields{"subject"}
+	impersonateUserRequiredFields        = pkg.RequiredFields{"target_user_id"}
+	getServiceAccountTokenRequiredFields = pkg.RequiredFields{"client_id", "client_secret"}
+	verifyPasswordRequiredFields         = pkg.RequiredFields{"password"}
+)
+
+// Префиксы subject блокировки входа.
+const (
+	LockoutSubjectEmailPrefix = "email:"
+	LockoutSubjectIPPrefix    = "ip:"
+)
+
+// Validate
+//
+// Возвращает:
+//   - error, если Email или Password не указаны.
+//   - nil в остальных случаях.
+func (req *LoginRequest) Validate() error {
+	// Проверка, что Email и Password указаны
+	return loginRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Refresh_token не указан.
+//   - nil в остальных случаях.
+func (req *GetRefreshTokenRequest) Validate() error {
+	// Проверка, что Refresh_token указан
+	return refreshTokenRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Refresh_token не указан.
+//   - nil в остальных случаях.
+func (req *GetAccessTokenRequest) Validate() error {
+	// Проверка, что Refresh_token указан
+	return refreshTokenRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Refresh_token не указан.
+//   - nil в остальных случаях.
+func (req *LogoutRequest) Validate() error {
+	// Проверка, что Refresh_token указан
+	return refreshTokenRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Email не указан.
+//   - nil в остальных случаях.
+func (req *RequestPasswordResetRequest) Validate() error {
+	// Проверка, что Email указан
+	return requestPasswordResetRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Token не указан.
+//   - error, если New_password не совпадает с New_password_confirm.
+//   - error, если New_password длиннее user_v1.MaxPasswordBytes байт.
+//   - nil в остальных случаях.
+func (req *ConfirmPasswordResetRequest) Validate() error {
+	// Проверка, что Token указан
+	if err := confirmPasswordResetRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка нового пароля по тем же правилам, что и при создании пользователя
+	return userDesc.ValidatePassword(req.NewPassword, req.NewPasswordConfirm, "New_password_confirm")
+}
+
+// Warnings
+//
+// Возвращает предупреждения о слабом New_password, не блокирующие сброс пароля.
+func (req *ConfirmPasswordResetRequest) Warnings() []string {
+	return userDesc.PasswordWarnings(req.NewPassword)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Token не указан.
+//   - nil в остальных случаях.
+func (req *VerifyEmailRequest) Validate() error {
+	// Проверка, что Token указан
+	return verifyEmailRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Code не указан.
+//   - nil в остальных случаях.
+func (req *ConfirmTOTPRequest) Validate() error {
+	// Проверка, что Code указан
+	return confirmTOTPRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Subject не указан.
+//   - error, если Subject не начинается с "email:" или "ip:" либо после префикса ничего нет.
+//   - nil в остальных случаях.
+func (req *ClearLockoutRequest) Validate() error {
+	// Проверка, что Subject указан
+	if err := clearLockoutRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка формата Subject
+	subject := strings.TrimSpace(req.Subject)
+	for _, prefix := range []string{LockoutSubjectEmailPrefix, LockoutSubjectIPPrefix} {
+		if value, ok := strings.CutPrefix(subject, prefix); ok && len(strings.TrimSpace(value)) > 0 {
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.InvalidArgument, "Subject must be %q or %q", LockoutSubjectEmailPrefix+"<email>", LockoutSubjectIPPrefix+"<ip>")
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Target_user_id не указан.
+//   - nil в остальных случаях.
+func (req *ImpersonateUserRequest) Validate() error {
+	return impersonateUserRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Limit не указан или больше MaxListLimit.
+//   - error, если From или To указаны и некорректны либо From не раньше To.
+//   - nil в остальных случаях.
+func (req *ListAuditEventsRequest) Validate() error {
+	// Проверка, что Limit указан и не превышает максимальный
+	if req.Limit == 0 || req.Limit > userDesc.MaxListLimit {
+		return status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", userDesc.MaxListLimit)
+	}
+
+	// Проверка, что границы интервала, если указаны, корректны
+	if req.From != nil {
+		if err := req.From.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid From: %v", err)
+		}
+	}
+	if req.To != nil {
+		if err := req.To.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid To: %v", err)
+		}
+	}
+	if req.From != nil && req.To != nil && !req.From.AsTime().Before(req.To.AsTime()) {
+		return status.Error(codes.InvalidArgument, "From must be earlier than To")
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Client_id или Client_secret пустой.
+//   - nil в остальных случаях.
+func (req *GetServiceAccountTokenRequest) Validate() error {
+	return getServiceAccountTokenRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Password не указан.
+//   - nil в остальных случаях.
+func (req *VerifyPasswordRequest) Validate() error {
+	return verifyPasswordRequiredFields.Validate(req)
+}
@@ -0,0 +1,98 @@
+package auth_v1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+func TestRequestValidate(t *testing.T) {
+	from := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	longPassword := strings.Repeat("x", userDesc.MaxPasswordBytes+1)
+
+	tests := []struct {
+		name     string
+		req      pkg.Validator
+		wantCode codes.Code
+	}{
+		{name: "login", req: &LoginRequest{Email: "alice@example.com", Password: "password"}, wantCode: codes.OK},
+		{name: "login without password", req: &LoginRequest{Email: "alice@example.com"}, wantCode: codes.InvalidArgument},
+		{name: "login with blank email", req: &LoginRequest{Email: " ", Password: "password"}, wantCode: codes.InvalidArgument},
+		{name: "refresh token", req: &GetRefreshTokenRequest{RefreshToken: "token"}, wantCode: codes.OK},
+		{name: "refresh token without token", req: &GetRefreshTokenRequest{}, wantCode: codes.InvalidArgument},
+		{name: "access token", req: &GetAccessTokenRequest{RefreshToken: "token"}, wantCode: codes.OK},
+		{name: "access token without token", req: &GetAccessTokenRequest{}, wantCode: codes.InvalidArgument},
+		{name: "logout", req: &LogoutRequest{RefreshToken: "token"}, wantCode: codes.OK},
+		{name: "logout without token", req: &LogoutRequest{}, wantCode: codes.InvalidArgument},
+		{name: "request password reset", req: &RequestPasswordResetRequest{Email: "alice@example.com"}, wantCode: codes.OK},
+		{name: "request password reset without email", req: &RequestPasswordResetRequest{}, wantCode: codes.InvalidArgument},
+		{
+			name:     "confirm password reset",
+			req:      &ConfirmPasswordResetRequest{Token: "token", NewPassword: "new-password", NewPasswordConfirm: "new-password"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "confirm password reset without token",
+			req:      &ConfirmPasswordResetRequest{NewPassword: "new-password", NewPasswordConfirm: "new-password"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "confirm password reset with different passwords",
+			req:      &ConfirmPasswordResetRequest{Token: "token", NewPassword: "new-password", NewPasswordConfirm: "other-password"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "confirm password reset with too long password",
+			req:      &ConfirmPasswordResetRequest{Token: "token", NewPassword: longPassword, NewPasswordConfirm: longPassword},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "verify email", req: &VerifyEmailRequest{Token: "token"}, wantCode: codes.OK},
+		{name: "verify email without token", req: &VerifyEmailRequest{}, wantCode: codes.InvalidArgument},
+		{name: "confirm totp", req: &ConfirmTOTPRequest{Code: "123456"}, wantCode: codes.OK},
+		{name: "confirm totp without code", req: &ConfirmTOTPRequest{}, wantCode: codes.InvalidArgument},
+		{name: "clear email lockout", req: &ClearLockoutRequest{Subject: "email:alice@example.com"}, wantCode: codes.OK},
+		{name: "clear ip lockout", req: &ClearLockoutRequest{Subject: " ip:10.0.0.1 "}, wantCode: codes.OK},
+		{name: "clear lockout without subject", req: &ClearLockoutRequest{}, wantCode: codes.InvalidArgument},
+		{name: "clear lockout with unknown prefix", req: &ClearLockoutRequest{Subject: "user:1"}, wantCode: codes.InvalidArgument},
+		{name: "clear lockout with empty value", req: &ClearLockoutRequest{Subject: "email: "}, wantCode: codes.InvalidArgument},
+		{name: "impersonate user", req: &ImpersonateUserRequest{TargetUserId: 1}, wantCode: codes.OK},
+		{name: "impersonate user without target", req: &ImpersonateUserRequest{}, wantCode: codes.InvalidArgument},
+		{name: "list audit events", req: &ListAuditEventsRequest{Limit: userDesc.MaxListLimit}, wantCode: codes.OK},
+		{
+			name:     "list audit events in interval",
+			req:      &ListAuditEventsRequest{Limit: 10, From: timestamppb.New(from), To: timestamppb.New(from.Add(time.Hour))},
+			wantCode: codes.OK,
+		},
+		{name: "list audit events without limit", req: &ListAuditEventsRequest{}, wantCode: codes.InvalidArgument},
+		{name: "list audit events with too large limit", req: &ListAuditEventsRequest{Limit: userDesc.MaxListLimit + 1}, wantCode: codes.InvalidArgument},
+		{
+			name:     "list audit events in empty interval",
+			req:      &ListAuditEventsRequest{Limit: 10, From: timestamppb.New(from), To: timestamppb.New(from)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "list audit events with invalid to",
+			req:      &ListAuditEventsRequest{Limit: 10, To: &timestamppb.Timestamp{Nanos: -1}},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "service account token", req: &GetServiceAccountTokenRequest{ClientId: "sa_importer", ClientSecret: "secret"}, wantCode: codes.OK},
+		{name: "service account token without secret", req: &GetServiceAccountTokenRequest{ClientId: "sa_importer"}, wantCode: codes.InvalidArgument},
+		{name: "verify password", req: &VerifyPasswordRequest{Password: "password"}, wantCode: codes.OK},
+		{name: "verify password without password", req: &VerifyPasswordRequest{}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.req.Validate()); code != tt.wantCode {
+				t.Errorf("Validate() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RequiredFields - набор имен обязательных полей proto-сообщения (имена как в .proto файле).
+//
+// Позволяет единообразно проверять, что обязательные поля запроса заполнены, вместо
+// повторения одинаковых проверок в каждом методе Validate.
+type RequiredFields []protoreflect.Name
+
+// Validate проверяет, что все обязательные поля сообщения msg заполнены.
+//
+// Поле считается незаполненным, если оно имеет значение по умолчанию (0, пустая строка,
+// nil для вложенных сообщений, первое значение enum). Строка из одних пробелов
+// также считается незаполненной.
+//
+// Параметры:
+//   - msg: проверяемое proto-сообщение.
+//
+// Возвращает:
+//   - error с кодом InvalidArgument, если какое-либо обязательное поле не заполнено.
+//   - error с кодом Internal, если в сообщении нет поля с указанным именем.
+//   - nil в остальных случаях.
+func (f RequiredFields) Validate(msg proto.Message) error {
+	message := msg.ProtoReflect()
+	fields := message.Descriptor().Fields()
+
+	for _, name := range f {
+		field := fields.ByName(name)
+		if field == nil {
+			return status.Errorf(codes.Internal, "Unknown required field %q in %s", name, message.Descriptor().FullName())
+		}
+
+		if !message.Has(field) || isBlankString(message, field) {
+			return status.Errorf(codes.InvalidArgument, "Field %q must be provided", name)
+		}
+	}
+
+	return nil
+}
+
+// isBlankString возвращает true, если field - строковое поле, состоящее только из пробелов.
+func isBlankString(message protoreflect.Message, field protoreflect.FieldDescriptor) bool {
+	if field.Kind() != protoreflect.StringKind || field.IsList() || field.IsMap() {
+		return false
+	}
+
+	return len(strings.TrimSpace(message.Get(field).String())) == 0
+}
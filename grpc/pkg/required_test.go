@@ -0,0 +1,90 @@
+package pkg_test
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+func TestRequiredFieldsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   pkg.RequiredFields
+		msg      proto.Message
+		wantCode codes.Code
+	}{
+		{
+			name:     "all fields set",
+			fields:   pkg.RequiredFields{"name", "email"},
+			msg:      &userDesc.CreateUserRequest{Name: "Alice", Email: "alice@example.com"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing string",
+			fields:   pkg.RequiredFields{"name", "email"},
+			msg:      &userDesc.CreateUserRequest{Name: "Alice"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "blank string",
+			fields:   pkg.RequiredFields{"name", "email"},
+			msg:      &userDesc.CreateUserRequest{Name: " \t\n", Email: "alice@example.com"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing number",
+			fields:   pkg.RequiredFields{"id"},
+			msg:      &userDesc.GetUserInfoRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "negative number",
+			fields:   pkg.RequiredFields{"id"},
+			msg:      &userDesc.GetUserInfoRequest{Id: -1},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing enum",
+			fields:   pkg.RequiredFields{"granularity"},
+			msg:      &userDesc.GetSignupTimeSeriesRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing message",
+			fields:   pkg.RequiredFields{"granularity", "from"},
+			msg:      &userDesc.GetSignupTimeSeriesRequest{Granularity: userDesc.SignupGranularity_DAY},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "zero message",
+			fields:   pkg.RequiredFields{"from"},
+			msg:      &userDesc.GetSignupTimeSeriesRequest{From: &timestamppb.Timestamp{}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "unknown field",
+			fields:   pkg.RequiredFields{"user_id"},
+			msg:      &userDesc.GetUserInfoRequest{Id: 1},
+			wantCode: codes.Internal,
+		},
+		{
+			name:     "no required fields",
+			msg:      &userDesc.GetUserInfoRequest{},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.fields.Validate(tt.msg)); code != tt.wantCode {
+				t.Errorf("Validate() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
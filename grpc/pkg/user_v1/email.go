@@ -0,0 +1,56 @@
+package user_v1
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// IsValidEmail возвращает true, если email - корректный адрес вида "local@domain"
+// без отображаемого имени и угловых скобок.
+func IsValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return false
+	}
+
+	return address.Address == email && strings.Contains(email[strings.LastIndex(email, "@")+1:], ".")
+}
+
+// NormalizeEmail приводит email к виду, в котором он сравнивается при проверке уникальности:
+// без пробелов по краям и в нижнем регистре.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// gmailDomains - домены Gmail, в которых точки в локальной части адреса не учитываются.
+var gmailDomains = map[string]struct{}{
+	"gmail.com":      {},
+	"googlemail.com": {},
+}
+
+// CanonicalEmail приводит email к каноническому виду для поиска дубликатов аккаунтов:
+//   - адрес приводится к нижнему регистру;
+//   - из локальной части удаляется суффикс "+tag" (plus-addressing);
+//   - для адресов Gmail из локальной части удаляются точки, а googlemail.com заменяется на gmail.com.
+//
+// Адреса без "@" возвращаются в виде NormalizeEmail.
+func CanonicalEmail(email string) string {
+	normalizedEmail := NormalizeEmail(email)
+
+	at := strings.LastIndex(normalizedEmail, "@")
+	if at < 0 {
+		return normalizedEmail
+	}
+	local, domain := normalizedEmail[:at], normalizedEmail[at+1:]
+
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+
+	if _, ok := gmailDomains[domain]; ok {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+
+	return local + "@" + domain
+}
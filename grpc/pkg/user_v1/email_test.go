@@ -0,0 +1,49 @@
+package user_v1
+
+import "testing"
+
+func TestCanonicalEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "alice@example.com", want: "alice@example.com"},
+		{email: " Alice@Example.COM ", want: "alice@example.com"},
+		{email: "alice+news@example.com", want: "alice@example.com"},
+		{email: "alice+news+weekly@example.com", want: "alice@example.com"},
+		{email: "alice.smith@example.com", want: "alice.smith@example.com"},
+		{email: "John.Doe+news@gmail.com", want: "johndoe@gmail.com"},
+		{email: "j.o.h.n.doe@googlemail.com", want: "johndoe@gmail.com"},
+		{email: "alice", want: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := CanonicalEmail(tt.email); got != tt.want {
+				t.Errorf("CanonicalEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "alice@example.com", want: true},
+		{email: "alice+news@example.com", want: true},
+		{email: "alice@localhost", want: false},
+		{email: "Alice <alice@example.com>", want: false},
+		{email: "alice", want: false},
+		{email: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := IsValidEmail(tt.email); got != tt.want {
+				t.Errorf("IsValidEmail(%q) = %t, want %t", tt.email, got, tt.want)
+			}
+		})
+	}
+}
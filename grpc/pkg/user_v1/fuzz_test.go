@@ -0,0 +1,197 @@
+package user_v1
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var (
+	// e164 - номер телефона в формате E.164.
+	e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	// normalizedHandle - handle в каноническом виде.
+	normalizedHandle = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+)
+
+// checkValidationError проверяет, что отклоненный запрос возвращает ошибку gRPC с кодом InvalidArgument.
+func checkValidationError(t *testing.T, err error) {
+	t.Helper()
+
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("Validate() code = %s, want %s (error %v)", code, codes.InvalidArgument, err)
+	}
+}
+
+func FuzzCreateUserValidate(f *testing.F) {
+	f.Add("Alice", "alice@example.com", "Str0ng-password", "Str0ng-password", int32(UserRole_USER), "")
+	f.Add("Alice", "alice@example.com", "Str0ng-password", "Str0ng-password", int32(UserRole_ADMIN), "+7 (999) 123-45-67")
+	f.Add("   ", "alice@example.com", "password", "password", int32(UserRole_USER), "")
+	f.Add("Alice", "\t", "password", "password", int32(UserRole_USER), "")
+	f.Add("Alice", "alice@example.com", "password", "Password", int32(UserRole_USER), "")
+	f.Add("Alice", "alice@example.com", strings.Repeat("x", MaxPasswordBytes+1), strings.Repeat("x", MaxPasswordBytes+1), int32(UserRole_USER), "")
+	f.Add("Alice", "alice@example.com", strings.Repeat("ж", MaxPasswordBytes/2), strings.Repeat("ж", MaxPasswordBytes/2), int32(UserRole_USER), "")
+	f.Add("Alice", "alice@example.com", "password", "password", int32(UserRole_UNKNOWN), "")
+	f.Add("Alice", "alice@example.com", "password", "password", int32(UserRole_SERVICE), "")
+	f.Add("Alice", "alice@example.com", "password", "password", int32(-1), "")
+	f.Add("Alice", "alice@example.com", "password", "password", int32(UserRole_USER), "0079991234567")
+	f.Add("Alice", "alice@example.com", "password", "password", int32(UserRole_USER), "not a phone")
+	f.Add(" ", "alice@example.com", "", "", int32(UserRole_USER), "\x00")
+
+	f.Fuzz(func(t *testing.T, name, email, password, passwordConfirm string, role int32, phone string) {
+		req := &CreateUserRequest{
+			Name:            name,
+			Email:           email,
+			Password:        password,
+			PasswordConfirm: passwordConfirm,
+			Role:            UserRole(role),
+			Phone:           phone,
+		}
+
+		if err := req.Validate(); err != nil {
+			checkValidationError(t, err)
+			return
+		}
+
+		if len(strings.TrimSpace(name)) == 0 || len(strings.TrimSpace(email)) == 0 {
+			t.Errorf("accepted blank name %q or email %q", name, email)
+		}
+		if password != passwordConfirm {
+			t.Errorf("accepted different passwords %q and %q", password, passwordConfirm)
+		}
+		if len(password) > MaxPasswordBytes {
+			t.Errorf("accepted password of %d bytes", len(password))
+		}
+		if !req.GetRole().IsDefined() || req.GetRole().IsServiceRole() {
+			t.Errorf("accepted role %d", role)
+		}
+		if len(strings.TrimSpace(phone)) > 0 {
+			if normalizedPhone, err := NormalizePhone(phone); err != nil || !e164.MatchString(normalizedPhone) {
+				t.Errorf("accepted phone %q normalized to %q", phone, normalizedPhone)
+			}
+		}
+	})
+}
+
+func FuzzUpdateUserValidate(f *testing.F) {
+	f.Add(int64(1), "Alice", true, "", false, int32(UserRole_UNKNOWN), "", false)
+	f.Add(int64(1), "", false, "alice@example.com", true, int32(UserRole_ADMIN), "", false)
+	f.Add(int64(1), "", false, "", false, int32(UserRole_UNKNOWN), "", true)
+	f.Add(int64(1), "", false, "", false, int32(UserRole_UNKNOWN), "", false)
+	f.Add(int64(0), "Alice", true, "", false, int32(UserRole_UNKNOWN), "", false)
+	f.Add(int64(1), "  ", true, " ", true, int32(UserRole_SERVICE_ADMIN), "", false)
+	f.Add(int64(1), "", false, "", false, int32(100), "+1 555 0100", true)
+
+	f.Fuzz(func(t *testing.T, id int64, name string, hasName bool, email string, hasEmail bool, role int32, phone string, hasPhone bool) {
+		req := &UpdateUserRequest{Id: id, Role: UserRole(role)}
+		if hasName {
+			req.Name = wrapperspb.String(name)
+		}
+		if hasEmail {
+			req.Email = wrapperspb.String(email)
+		}
+		if hasPhone {
+			req.Phone = wrapperspb.String(phone)
+		}
+
+		if err := req.Validate(); err != nil {
+			checkValidationError(t, err)
+			return
+		}
+
+		if id == 0 {
+			t.Error("accepted request without id")
+		}
+		if req.GetRole() != UserRole_UNKNOWN && (!req.GetRole().IsDefined() || req.GetRole().IsServiceRole()) {
+			t.Errorf("accepted role %d", role)
+		}
+		if !req.HasName() && !req.HasEmail() && req.GetRole() == UserRole_UNKNOWN && req.Phone == nil {
+			t.Error("accepted request without fields to update")
+		}
+	})
+}
+
+func FuzzChangeUserPasswordValidate(f *testing.F) {
+	f.Add(int64(1), "old-password", "new-password", "new-password")
+	f.Add(int64(1), "password", "password", "password")
+	f.Add(int64(1), "", "new-password", "new-password")
+	f.Add(int64(1), "old-password", "new-password", "other-password")
+	f.Add(int64(0), "old-password", "new-password", "new-password")
+
+	f.Fuzz(func(t *testing.T, id int64, oldPassword, newPassword, newPasswordConfirm string) {
+		req := &ChangeUserPasswordRequest{
+			Id:                 id,
+			OldPassword:        oldPassword,
+			NewPassword:        newPassword,
+			NewPasswordConfirm: newPasswordConfirm,
+		}
+
+		if err := req.Validate(); err != nil {
+			checkValidationError(t, err)
+			return
+		}
+
+		if id == 0 || len(strings.TrimSpace(oldPassword)) == 0 {
+			t.Errorf("accepted request without id or old password")
+		}
+		if newPassword != newPasswordConfirm || newPassword == oldPassword || len(newPassword) > MaxPasswordBytes {
+			t.Errorf("accepted new password %q (confirm %q, old %q)", newPassword, newPasswordConfirm, oldPassword)
+		}
+	})
+}
+
+func FuzzListUsersValidate(f *testing.F) {
+	f.Add(uint64(10), uint64(0), "", "", "")
+	f.Add(uint64(MaxListLimit), uint64(1<<63), "", "", "")
+	f.Add(uint64(10), uint64(2), "token", "", "")
+	f.Add(uint64(10), uint64(0), "", "created_at desc", "example.com")
+	f.Add(uint64(10), uint64(0), "", "password", "user@example.com")
+
+	f.Fuzz(func(t *testing.T, pageSize, pageNumber uint64, pageToken, orderBy, emailDomain string) {
+		req := &ListUsersRequest{
+			PageSize:   pageSize,
+			PageNumber: pageNumber,
+			PageToken:  pageToken,
+			OrderBy:    orderBy,
+			Filter:     &ListUsersFilter{EmailDomain: emailDomain},
+		}
+
+		if err := req.Validate(); err != nil {
+			checkValidationError(t, err)
+			return
+		}
+
+		if pageSize == 0 || pageSize > MaxListLimit {
+			t.Errorf("accepted page size %d", pageSize)
+		}
+		// Смещение страницы должно помещаться в OFFSET Postgres (bigint)
+		if req.Offset() > 1<<63-1 || (pageNumber > 1 && req.Offset()/pageSize != pageNumber-1) {
+			t.Errorf("accepted page number %d with page size %d", pageNumber, pageSize)
+		}
+	})
+}
+
+func FuzzClaimHandleValidate(f *testing.F) {
+	f.Add(int64(1), "alice_01")
+	f.Add(int64(1), "  Alice  ")
+	f.Add(int64(1), "al")
+	f.Add(int64(1), "alice-01")
+	f.Add(int64(1), "алиса")
+
+	f.Fuzz(func(t *testing.T, id int64, handle string) {
+		req := &ClaimHandleRequest{Id: id, Handle: handle}
+
+		if err := req.Validate(); err != nil {
+			checkValidationError(t, err)
+			return
+		}
+
+		normalized, err := NormalizeHandle(handle)
+		if err != nil || !normalizedHandle.MatchString(normalized) {
+			t.Errorf("accepted handle %q normalized to %q", handle, normalized)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package user_v1
+
+import (
+	"time"
+)
+
+// Duration возвращает длительность одного интервала (бакета) временного ряда.
+//
+// Возвращает 0 для GRANULARITY_UNKNOWN и неизвестных значений.
+func (g SignupGranularity) Duration() time.Duration {
+	switch g {
+	case SignupGranularity_DAY:
+		return 24 * time.Hour
+	case SignupGranularity_WEEK:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Truncate округляет t (в UTC) вниз до начала интервала так же, как это делает date_trunc в Postgres:
+// для DAY - до начала суток, для WEEK - до начала понедельника.
+func (g SignupGranularity) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch g {
+	case SignupGranularity_WEEK:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return day
+	}
+}
@@ -0,0 +1,56 @@
+package user_v1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignupGranularityTruncate(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name        string
+		granularity SignupGranularity
+		t           time.Time
+		want        time.Time
+	}{
+		{
+			name:        "day",
+			granularity: SignupGranularity_DAY,
+			t:           time.Date(2024, 11, 13, 17, 45, 0, 0, time.UTC),
+			want:        time.Date(2024, 11, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "day in other time zone",
+			granularity: SignupGranularity_DAY,
+			t:           time.Date(2024, 11, 14, 1, 0, 0, 0, moscow),
+			want:        time.Date(2024, 11, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "week from wednesday",
+			granularity: SignupGranularity_WEEK,
+			t:           time.Date(2024, 11, 13, 17, 45, 0, 0, time.UTC),
+			want:        time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "week from sunday",
+			granularity: SignupGranularity_WEEK,
+			t:           time.Date(2024, 11, 17, 23, 59, 0, 0, time.UTC),
+			want:        time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "week from monday",
+			granularity: SignupGranularity_WEEK,
+			t:           time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC),
+			want:        time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.granularity.Truncate(tt.t); !got.Equal(tt.want) {
+				t.Errorf("Truncate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
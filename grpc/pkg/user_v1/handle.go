@@ -0,0 +1,40 @@
+package user_v1
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MinHandleLength, MaxHandleLength - допустимая длина handle пользователя.
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+// NormalizeHandle приводит handle пользователя к каноническому виду и проверяет его.
+//
+// Handle не учитывает регистр и может содержать только латинские буквы, цифры и "_".
+//
+// Параметры:
+//   - handle: handle пользователя в произвольном регистре.
+//
+// Возвращает:
+//   - string: handle в нижнем регистре.
+//   - error: ошибка с кодом InvalidArgument, если handle некорректный.
+func NormalizeHandle(handle string) (string, error) {
+	normalizedHandle := strings.ToLower(strings.TrimSpace(handle))
+
+	if len(normalizedHandle) < MinHandleLength || len(normalizedHandle) > MaxHandleLength {
+		return "", status.Errorf(codes.InvalidArgument, "Handle must be between %d and %d characters long", MinHandleLength, MaxHandleLength)
+	}
+
+	for _, r := range normalizedHandle {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return "", status.Errorf(codes.InvalidArgument, "Handle %q may contain only latin letters, digits and underscores", handle)
+		}
+	}
+
+	return normalizedHandle, nil
+}
@@ -0,0 +1,80 @@
+package user_v1
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Поля, по которым можно сортировать список пользователей.
+const (
+	OrderByID        = "id"
+	OrderByName      = "name"
+	OrderByEmail     = "email"
+	OrderByCreatedAt = "created_at"
+	OrderByUpdatedAt = "updated_at"
+)
+
+// userOrderFields - поля, по которым разрешена сортировка списка пользователей. Другие значения
+// отклоняются, поэтому в ORDER BY не может попасть произвольный SQL.
+var userOrderFields = map[string]struct{}{
+	OrderByID:        {},
+	OrderByName:      {},
+	OrderByEmail:     {},
+	OrderByCreatedAt: {},
+	OrderByUpdatedAt: {},
+}
+
+// UserOrder - порядок сортировки списка пользователей.
+type UserOrder struct {
+	// Field - поле сортировки, одна из констант OrderBy*.
+	Field string
+	// Descending - true для сортировки по убыванию.
+	Descending bool
+}
+
+// ParseUserOrder разбирает порядок сортировки списка пользователей вида "<поле>" или "<поле> asc|desc",
+// например "created_at desc". Регистр и лишние пробелы не учитываются.
+//
+// Параметры:
+//   - orderBy: порядок сортировки из запроса. Пустая строка - сортировка по ID по возрастанию.
+//
+// Возвращает:
+//   - UserOrder: поле и направление сортировки.
+//   - error: ошибка InvalidArgument, если поле не входит в список разрешенных или направление некорректное.
+func ParseUserOrder(orderBy string) (UserOrder, error) {
+	parts := strings.Fields(strings.ToLower(orderBy))
+	if len(parts) == 0 {
+		return UserOrder{Field: OrderByID}, nil
+	}
+	if len(parts) > 2 {
+		return UserOrder{}, status.Errorf(codes.InvalidArgument, "Invalid order_by %q: expected \"<field> [asc|desc]\"", orderBy)
+	}
+
+	order := UserOrder{Field: parts[0]}
+	if _, ok := userOrderFields[order.Field]; !ok {
+		return UserOrder{}, status.Errorf(codes.InvalidArgument, "Invalid order_by field %q: must be one of id, name, email, created_at, updated_at", parts[0])
+	}
+
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "asc":
+		case "desc":
+			order.Descending = true
+		default:
+			return UserOrder{}, status.Errorf(codes.InvalidArgument, "Invalid order_by direction %q: must be asc or desc", parts[1])
+		}
+	}
+
+	return order, nil
+}
+
+// String возвращает порядок сортировки в нормализованном виде, например "created_at desc".
+func (o UserOrder) String() string {
+	if o.Descending {
+		return o.Field + " desc"
+	}
+
+	return o.Field + " asc"
+}
@@ -0,0 +1,66 @@
+package user_v1
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseUserOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		orderBy  string
+		want     UserOrder
+		wantCode codes.Code
+	}{
+		{name: "empty", orderBy: "", want: UserOrder{Field: OrderByID}},
+		{name: "blank", orderBy: " \t", want: UserOrder{Field: OrderByID}},
+		{name: "field only", orderBy: "name", want: UserOrder{Field: OrderByName}},
+		{name: "ascending", orderBy: "email asc", want: UserOrder{Field: OrderByEmail}},
+		{name: "descending", orderBy: "created_at desc", want: UserOrder{Field: OrderByCreatedAt, Descending: true}},
+		{name: "case and spaces", orderBy: "  Updated_At   DESC ", want: UserOrder{Field: OrderByUpdatedAt, Descending: true}},
+		{name: "unknown field", orderBy: "password", wantCode: codes.InvalidArgument},
+		{name: "expression", orderBy: "coalesce(updated_at, created_at)", wantCode: codes.InvalidArgument},
+		{name: "sql injection", orderBy: "id; DROP TABLE auth", wantCode: codes.InvalidArgument},
+		{name: "comment", orderBy: "id --", wantCode: codes.InvalidArgument},
+		{name: "unknown direction", orderBy: "id up", wantCode: codes.InvalidArgument},
+		{name: "nulls position", orderBy: "updated_at desc nulls last", wantCode: codes.InvalidArgument},
+		{name: "several fields", orderBy: "name, id", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUserOrder(tt.orderBy)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ParseUserOrder(%q) code = %s, want %s", tt.orderBy, code, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUserOrder(%q) = %+v, want %+v", tt.orderBy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserOrderString(t *testing.T) {
+	tests := []struct {
+		orderBy string
+		want    string
+	}{
+		{orderBy: "", want: "id asc"},
+		{orderBy: "NAME", want: "name asc"},
+		{orderBy: " created_at  Desc", want: "created_at desc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.orderBy, func(t *testing.T) {
+			order, err := ParseUserOrder(tt.orderBy)
+			if err != nil {
+				t.Fatalf("ParseUserOrder(%q) error = %v", tt.orderBy, err)
+			}
+			if got := order.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
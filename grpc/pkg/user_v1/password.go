@@ -0,0 +1,56 @@
+package user_v1
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ValidatePassword проверяет, что пароль не длиннее MaxPasswordBytes байт и совпадает
+// с подтверждением confirm.
+//
+// Используется при создании пользователя, смене и сбросе пароля, чтобы правила были одинаковыми.
+// Требования к сложности пароля (длина, классы символов, запрещенные пароли) задаются в конфиге
+// и проверяются политикой паролей на сервере.
+//
+// Параметры:
+//   - password: проверяемый пароль.
+//   - confirm: подтверждение пароля.
+//   - confirmField: имя поля подтверждения для текста ошибки.
+//
+// Возвращает:
+//   - error с кодом InvalidArgument, если пароль некорректный.
+//   - nil в остальных случаях.
+func ValidatePassword(password, confirm, confirmField string) error {
+	if password != confirm {
+		return status.Errorf(codes.InvalidArgument, "Password must be equal to %s", confirmField)
+	}
+
+	// Пароль не должен быть длиннее, чем может обработать bcrypt
+	if len(password) > MaxPasswordBytes {
+		return status.Errorf(codes.InvalidArgument, "Password must not be longer than %d bytes", MaxPasswordBytes)
+	}
+
+	return nil
+}
+
+// PasswordWarnings возвращает предупреждения о слабом пароле, не блокирующие запрос:
+//   - если пароль короче RecommendedPasswordLength символов.
+//   - если пароль не содержит одновременно букв и цифр.
+func PasswordWarnings(password string) []string {
+	var warnings []string
+
+	if utf8.RuneCountInString(password) < RecommendedPasswordLength {
+		warnings = append(warnings, fmt.Sprintf("Password is shorter than %d characters", RecommendedPasswordLength))
+	}
+
+	if !strings.ContainsFunc(password, unicode.IsLetter) || !strings.ContainsFunc(password, unicode.IsDigit) {
+		warnings = append(warnings, "Password should contain both letters and digits")
+	}
+
+	return warnings
+}
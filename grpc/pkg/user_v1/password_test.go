@@ -0,0 +1,66 @@
+package user_v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPasswordWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{name: "strong", password: "Str0ng-password"},
+		{name: "short", password: "abc123", want: []string{"Password is shorter than 8 characters"}},
+		{name: "letters only", password: "password-password", want: []string{"Password should contain both letters and digits"}},
+		{name: "digits only", password: "12345678", want: []string{"Password should contain both letters and digits"}},
+		{
+			name:     "short letters only",
+			password: "abc",
+			want:     []string{"Password is shorter than 8 characters", "Password should contain both letters and digits"},
+		},
+		{name: "multibyte", password: "пароль12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PasswordWarnings(tt.password); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PasswordWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateUserRequestWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *CreateUserRequest
+		want []string
+	}{
+		{name: "no warnings", req: &CreateUserRequest{Email: "alice@example.com", Password: "Str0ng-password"}},
+		{
+			name: "weak password",
+			req:  &CreateUserRequest{Email: "alice@example.com", Password: "password"},
+			want: []string{"Password should contain both letters and digits"},
+		},
+		{
+			name: "unusual email",
+			req:  &CreateUserRequest{Email: "Alice <alice@example.com>", Password: "Str0ng-password"},
+			want: []string{"Email looks unusual"},
+		},
+		{
+			name: "email without domain zone",
+			req:  &CreateUserRequest{Email: "alice@localhost", Password: "Str0ng-password"},
+			want: []string{"Email looks unusual"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.Warnings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
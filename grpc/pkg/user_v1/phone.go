@@ -0,0 +1,55 @@
+package user_v1
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// minPhoneDigits, maxPhoneDigits - допустимое количество цифр в номере формата E.164
+	// (вместе с кодом страны).
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// phoneSeparators - символы, которые допускаются во входном номере телефона для удобства
+// записи и удаляются при нормализации.
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// NormalizePhone приводит номер телефона к формату E.164 ("+79991234567").
+//
+// Допускает пробелы, дефисы, точки и скобки между цифрами, а также международный
+// префикс "00" вместо "+". Номер должен содержать код страны.
+//
+// Параметры:
+//   - phone: номер телефона в произвольной записи.
+//
+// Возвращает:
+//   - string: номер в формате E.164.
+//   - error: ошибка с кодом InvalidArgument, если номер некорректный.
+func NormalizePhone(phone string) (string, error) {
+	normalizedPhone := phoneSeparators.Replace(strings.TrimSpace(phone))
+
+	if strings.HasPrefix(normalizedPhone, "00") {
+		normalizedPhone = "+" + normalizedPhone[2:]
+	}
+
+	digits := strings.TrimPrefix(normalizedPhone, "+")
+	if len(digits) == len(normalizedPhone) {
+		return "", status.Errorf(codes.InvalidArgument, "Phone %q must start with a country code (+ or 00)", phone)
+	}
+
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits || digits[0] == '0' {
+		return "", status.Errorf(codes.InvalidArgument, "Phone %q must contain %d to %d digits and must not start with 0", phone, minPhoneDigits, maxPhoneDigits)
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", status.Errorf(codes.InvalidArgument, "Phone %q contains invalid characters", phone)
+		}
+	}
+
+	return "+" + digits, nil
+}
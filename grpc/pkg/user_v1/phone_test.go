@@ -0,0 +1,46 @@
+package user_v1
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{phone: "+79991234567", want: "+79991234567"},
+		{phone: " +7 (999) 123-45-67 ", want: "+79991234567"},
+		{phone: "0079991234567", want: "+79991234567"},
+		{phone: "+1.555.010.0100", want: "+15550100100"},
+		{phone: "+12345678", want: "+12345678"},
+		{phone: "+123456789012345", want: "+123456789012345"},
+		{phone: "89991234567", wantErr: true},
+		{phone: "+1234567", wantErr: true},
+		{phone: "+1234567890123456", wantErr: true},
+		{phone: "+07991234567", wantErr: true},
+		{phone: "+7 999 123-45-6x", wantErr: true},
+		{phone: "+", wantErr: true},
+		{phone: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got, err := NormalizePhone(tt.phone)
+			if tt.wantErr {
+				if code := status.Code(err); code != codes.InvalidArgument {
+					t.Errorf("NormalizePhone(%q) = %q, %v, want InvalidArgument", tt.phone, got, err)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, %v, want %q", tt.phone, got, err, tt.want)
+			}
+		})
+	}
+}
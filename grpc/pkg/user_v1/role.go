@@ -0,0 +1,16 @@
+package user_v1
+
+// IsDefined возвращает true, если значение роли соответствует одной из ролей, объявленных
+// в proto-файле, кроме UNKNOWN.
+//
+// Нужна, т.к. в proto3 в поле enum можно передать любое число, не только объявленные значения.
+func (r UserRole) IsDefined() bool {
+	_, ok := UserRole_name[int32(r)]
+	return ok && r != UserRole_UNKNOWN
+}
+
+// IsServiceRole возвращает true, если роль предназначена для сервисных аккаунтов (SERVICE или
+// SERVICE_ADMIN). Такие роли не назначаются пользователям.
+func (r UserRole) IsServiceRole() bool {
+	return r == UserRole_SERVICE || r == UserRole_SERVICE_ADMIN
+}
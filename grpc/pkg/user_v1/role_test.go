@@ -0,0 +1,30 @@
+package user_v1
+
+import "testing"
+
+func TestUserRole(t *testing.T) {
+	tests := []struct {
+		role            UserRole
+		wantDefined     bool
+		wantServiceRole bool
+	}{
+		{role: UserRole_UNKNOWN},
+		{role: UserRole_USER, wantDefined: true},
+		{role: UserRole_ADMIN, wantDefined: true},
+		{role: UserRole_SERVICE, wantDefined: true, wantServiceRole: true},
+		{role: UserRole_SERVICE_ADMIN, wantDefined: true, wantServiceRole: true},
+		{role: UserRole(5)},
+		{role: UserRole(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role.String(), func(t *testing.T) {
+			if got := tt.role.IsDefined(); got != tt.wantDefined {
+				t.Errorf("IsDefined() = %t, want %t", got, tt.wantDefined)
+			}
+			if got := tt.role.IsServiceRole(); got != tt.wantServiceRole {
+				t.Errorf("IsServiceRole() = %t, want %t", got, tt.wantServiceRole)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package user_v1
 
 import (
+	"math"
 	"strings"
+	"unicode/utf8"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -14,6 +16,55 @@ var (
 	_ pkg.Validator = (*CreateUserRequest)(nil)
 	_ pkg.Validator = (*UpdateUserRequest)(nil)
 	_ pkg.Validator = (*DeleteUserRequest)(nil)
+	_ pkg.Validator = (*ListEmailDomainStatsRequest)(nil)
+	_ pkg.Validator = (*GetSignupTimeSeriesRequest)(nil)
+	_ pkg.Validator = (*ValidateEmailsRequest)(nil)
+	_ pkg.Validator = (*FindDuplicateCandidatesRequest)(nil)
+	_ pkg.Validator = (*RecordActivityRequest)(nil)
+	_ pkg.Validator = (*WatchUserEventsRequest)(nil)
+	_ pkg.Validator = (*ClaimHandleRequest)(nil)
+	_ pkg.Validator = (*ChangeUserPasswordRequest)(nil)
+	_ pkg.Validator = (*ListUsersRequest)(nil)
+	_ pkg.Validator = (*BatchCreateUsersRequest)(nil)
+	_ pkg.Validator = (*GetUserStatsRequest)(nil)
+
+	_ pkg.WarningsProvider = (*CreateUserRequest)(nil)
+	_ pkg.WarningsProvider = (*ChangeUserPasswordRequest)(nil)
+)
+
+const (
+	// MaxListLimit - максимальное количество записей, возвращаемых за один запрос списка.
+	MaxListLimit = 1000
+
+	// MaxSignupBuckets - максимальное количество интервалов во временном ряде регистраций.
+	MaxSignupBuckets = 1000
+
+	// RecommendedPasswordLength - рекомендуемая минимальная длина пароля.
+	RecommendedPasswordLength = 8
+
+	// MaxPasswordBytes - максимальная длина пароля в байтах, которую учитывает bcrypt.
+	MaxPasswordBytes = 72
+
+	// MaxEmailFilterLength - максимальная длина подстроки email и домена в фильтре списка
+	// пользователей (максимальная длина email по RFC 5321).
+	MaxEmailFilterLength = 254
+
+	// MaxBatchCreateUsers - максимальное количество пользователей в одном запросе BatchCreateUsers.
+	// Меньше MaxListLimit, т.к. для каждого пользователя вычисляется хеш пароля.
+	MaxBatchCreateUsers = 100
+)
+
+// Обязательные поля запросов к АПИ.
+var (
+	getUserInfoRequiredFields = pkg.RequiredFields{"id"}
+	createUserRequiredFields  = pkg.RequiredFields{"name", "email"}
+	updateUserRequiredFields  = pkg.RequiredFields{"id"}
+	deleteUserRequiredFields  = pkg.RequiredFields{"id"}
+
+	getSignupTimeSeriesRequiredFields = pkg.RequiredFields{"granularity", "from", "to"}
+	recordActivityRequiredFields      = pkg.RequiredFields{"id"}
+	claimHandleRequiredFields         = pkg.RequiredFields{"id", "handle"}
+	changeUserPasswordRequiredFields  = pkg.RequiredFields{"id", "old_password"}
 )
 
 // Validate
@@ -22,14 +73,8 @@ var (
 //   - error, если User_id не указан.
 //   - nil в остальных случаях.
 func (req *GetUserInfoRequest) Validate() error {
-	// TODO: текст ошибки вынести в константу. Сделаю в рамках ДЗ №3 - слоистая архитектура
 	// В запросе должен быть ID (User_ID)
-	if req.Id == 0 {
-		err := status.Error(codes.InvalidArgument, "User-id must be provided")
-		return err
-	}
-
-	return nil
+	return getUserInfoRequiredFields.Validate(req)
 }
 
 // Validate
@@ -37,55 +82,111 @@ func (req *GetUserInfoRequest) Validate() error {
 // Возвращает:
 //   - error, если User_name пустой.
 //   - error, если Email пустой.
-//   - error, если Password пустой либо не совпадает с Password_confirm.
-//   - error, если Role некорректная.
+//   - error, если Password не совпадает с Password_confirm.
+//   - error, если Password длиннее MaxPasswordBytes байт.
+//   - error, если Role некорректная (UNKNOWN либо не объявлена в enum) или является ролью сервисного аккаунта.
+//   - error, если Phone указан и не приводится к формату E.164.
 //   - nil в остальных случаях.
 func (req *CreateUserRequest) Validate() error {
-	// Проверка, что User_name не пустой
-	trimmedNameFromRequest := strings.TrimSpace(req.Name)
-	if len(trimmedNameFromRequest) == 0 {
-		err := status.Error(codes.InvalidArgument, "User name must not be empty")
+	// Проверка, что User_name и Email не пустые
+	if err := createUserRequiredFields.Validate(req); err != nil {
 		return err
 	}
 
-	// Проверка, что Email не пустой
-	trimmedEmailFromRequest := strings.TrimSpace(req.Email)
-	if len(trimmedEmailFromRequest) == 0 {
-		err := status.Error(codes.InvalidArgument, "Email must not be empty")
+	// Проверка, что Password не слишком длинный и совпадает с Password_confirm.
+	// Сложность пароля проверяется политикой паролей при создании пользователя
+	if err := ValidatePassword(req.Password, req.PasswordConfirm, "Password_confirm"); err != nil {
 		return err
 	}
 
-	// Проверка, что Password не пустой и совпадает с Password_confirm
-	trimmedPasswordFromRequest := strings.TrimSpace(req.Password)
-	if (req.Password != req.PasswordConfirm) || len(trimmedPasswordFromRequest) == 0 {
-		err := status.Error(codes.InvalidArgument, "Password must not be empty. Password must be equal to Password_confirm")
+	// Проверка, что Role корректная и входит в список объявленных ролей
+	if !req.GetRole().IsDefined() {
+		err := status.Errorf(codes.InvalidArgument, "Invalid role %d", req.GetRole())
 		return err
 	}
-
-	// Проверка, что Role корректная
-	if req.GetRole() == UserRole_UNKNOWN {
-		err := status.Error(codes.InvalidArgument, "Invalid role")
+	if req.GetRole().IsServiceRole() {
+		err := status.Errorf(codes.InvalidArgument, "Role %s is reserved for service accounts", req.GetRole())
 		return err
 	}
 
+	// Проверка, что Phone, если указан, корректный
+	if len(strings.TrimSpace(req.Phone)) > 0 {
+		if _, err := NormalizePhone(req.Phone); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// Warnings
+//
+// Возвращает предупреждения, не блокирующие создание пользователя:
+//   - если Password короче RecommendedPasswordLength символов.
+//   - если Password не содержит одновременно букв и цифр.
+//   - если Email имеет необычный формат.
+func (req *CreateUserRequest) Warnings() []string {
+	warnings := PasswordWarnings(req.Password)
+
+	if !IsValidEmail(strings.TrimSpace(req.Email)) {
+		warnings = append(warnings, "Email looks unusual")
+	}
+
+	return warnings
+}
+
 // Validate
 //
+// Role == UNKNOWN означает, что роль не меняется.
+//
 // Возвращает:
-//   - error, если Role == UNKNOWN.
+//   - error, если User-id не указан.
+//   - error, если Role не объявлена в enum или является ролью сервисного аккаунта.
+//   - error, если Phone указан непустым и не приводится к формату E.164.
+//   - error, если не указано ни одно поле для обновления (Name, Email, Role, Phone).
 //   - nil в остальных случаях.
 func (req *UpdateUserRequest) Validate() error {
-	// Проверка, что Role корректная
-	if req.GetRole() == UserRole_UNKNOWN {
-		err := status.Error(codes.InvalidArgument, "Invalid role")
+	// Проверка, что User_id указан
+	if err := updateUserRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка, что Role, если указана, входит в список объявленных ролей
+	if req.GetRole() != UserRole_UNKNOWN && !req.GetRole().IsDefined() {
+		err := status.Errorf(codes.InvalidArgument, "Invalid role %d", req.GetRole())
+		return err
+	}
+	if req.GetRole().IsServiceRole() {
+		err := status.Errorf(codes.InvalidArgument, "Role %s is reserved for service accounts", req.GetRole())
+		return err
+	}
+
+	// Проверка, что Phone, если указан непустым, корректный
+	if len(strings.TrimSpace(req.GetPhone().GetValue())) > 0 {
+		if _, err := NormalizePhone(req.GetPhone().GetValue()); err != nil {
+			return err
+		}
+	}
+
+	// Проверка, что есть что обновлять
+	if !req.HasName() && !req.HasEmail() && req.GetRole() == UserRole_UNKNOWN && req.Phone == nil {
+		err := status.Error(codes.InvalidArgument, "Nothing to update: name, email, role or phone must be provided")
 		return err
 	}
 
 	return nil
 }
 
+// HasName возвращает true, если в запросе указано непустое имя пользователя.
+func (req *UpdateUserRequest) HasName() bool {
+	return len(strings.TrimSpace(req.GetName().GetValue())) > 0
+}
+
+// HasEmail возвращает true, если в запросе указан непустой email.
+func (req *UpdateUserRequest) HasEmail() bool {
+	return len(strings.TrimSpace(req.GetEmail().GetValue())) > 0
+}
+
 // Validate
 //
 // Возвращает:
@@ -93,10 +194,305 @@ func (req *UpdateUserRequest) Validate() error {
 //   - nil в остальных случаях.
 func (req *DeleteUserRequest) Validate() error {
 	// Проверка, что User_id указан
-	if req.Id == 0 {
-		err := status.Error(codes.InvalidArgument, "User-id must be provided")
+	return deleteUserRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Limit не указан или больше MaxListLimit.
+//   - nil в остальных случаях.
+func (req *ListEmailDomainStatsRequest) Validate() error {
+	// Проверка, что Limit указан и не превышает максимальный
+	if req.Limit == 0 || req.Limit > MaxListLimit {
+		err := status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", MaxListLimit)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Granularity, From или To не указаны.
+//   - error, если Granularity некорректная.
+//   - error, если From не раньше To.
+//   - error, если диапазон содержит больше MaxSignupBuckets интервалов.
+//   - nil в остальных случаях.
+func (req *GetSignupTimeSeriesRequest) Validate() error {
+	if err := getSignupTimeSeriesRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка, что Granularity корректная
+	bucketDuration := req.GetGranularity().Duration()
+	if bucketDuration == 0 {
+		err := status.Error(codes.InvalidArgument, "Invalid granularity")
+		return err
+	}
+
+	// Проверка, что диапазон корректный и не слишком большой
+	from, to := req.GetFrom().AsTime(), req.GetTo().AsTime()
+	if !from.Before(to) {
+		err := status.Error(codes.InvalidArgument, "From must be before To")
+		return err
+	}
+
+	if to.Sub(req.GetGranularity().Truncate(from)) > MaxSignupBuckets*bucketDuration {
+		err := status.Errorf(codes.InvalidArgument, "Range must contain at most %d buckets", MaxSignupBuckets)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если список Emails пустой или содержит больше MaxListLimit адресов.
+//   - nil в остальных случаях.
+func (req *ValidateEmailsRequest) Validate() error {
+	// Проверка, что количество адресов в допустимых пределах
+	if len(req.Emails) == 0 || len(req.Emails) > MaxListLimit {
+		err := status.Errorf(codes.InvalidArgument, "Emails count must be between 1 and %d", MaxListLimit)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Limit не указан или больше MaxListLimit.
+//   - nil в остальных случаях.
+func (req *FindDuplicateCandidatesRequest) Validate() error {
+	// Проверка, что Limit указан и не превышает максимальный
+	if req.Limit == 0 || req.Limit > MaxListLimit {
+		err := status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", MaxListLimit)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если User-id не указан.
+//   - nil в остальных случаях.
+func (req *RecordActivityRequest) Validate() error {
+	// Проверка, что User_id указан
+	return recordActivityRequiredFields.Validate(req)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если какой-либо из Types равен EVENT_TYPE_UNKNOWN или не объявлен в enum.
+//   - nil в остальных случаях.
+func (req *WatchUserEventsRequest) Validate() error {
+	// Проверка, что все типы событий корректные
+	for _, eventType := range req.Types {
+		if _, ok := UserEventType_name[int32(eventType)]; !ok || eventType == UserEventType_EVENT_TYPE_UNKNOWN {
+			err := status.Errorf(codes.InvalidArgument, "Invalid event type %d", eventType)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если User-id или Handle не указаны либо Handle некорректный.
+//   - nil в остальных случаях.
+func (req *ClaimHandleRequest) Validate() error {
+	// Проверка, что User_id и Handle указаны
+	if err := claimHandleRequiredFields.Validate(req); err != nil {
 		return err
 	}
 
+	// Проверка формата Handle
+	_, err := NormalizeHandle(req.Handle)
+	return err
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если User-id или Old_password не указаны.
+//   - error, если New_password не совпадает с New_password_confirm.
+//   - error, если New_password длиннее MaxPasswordBytes байт.
+//   - error, если New_password совпадает с Old_password.
+//   - nil в остальных случаях.
+func (req *ChangeUserPasswordRequest) Validate() error {
+	// Проверка, что User_id и Old_password указаны
+	if err := changeUserPasswordRequiredFields.Validate(req); err != nil {
+		return err
+	}
+
+	// Проверка, что New_password не слишком длинный и совпадает с New_password_confirm
+	if err := ValidatePassword(req.NewPassword, req.NewPasswordConfirm, "New_password_confirm"); err != nil {
+		return err
+	}
+
+	// Проверка, что пароль действительно меняется
+	if req.NewPassword == req.OldPassword {
+		err := status.Error(codes.InvalidArgument, "New password must differ from the old one")
+		return err
+	}
+
+	return nil
+}
+
+// Warnings
+//
+// Возвращает предупреждения, не блокирующие смену пароля:
+//   - если New_password короче RecommendedPasswordLength символов.
+//   - если New_password не содержит одновременно букв и цифр.
+func (req *ChangeUserPasswordRequest) Warnings() []string {
+	return PasswordWarnings(req.NewPassword)
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Page_size не указан или больше MaxListLimit.
+//   - error, если смещение страницы Page_number больше максимального OFFSET в Postgres (bigint).
+//   - error, если указаны и Page_token, и Page_number больше 1.
+//   - error, если Filter указан и некорректный (см. ListUsersFilter.Validate).
+//   - error, если Order_by некорректный (см. ParseUserOrder).
+//   - nil в остальных случаях.
+//
+// Содержимое Page_token проверяется при разборе курсора на сервере.
+func (req *ListUsersRequest) Validate() error {
+	// Проверка, что Page_size указан и не превышает максимальный
+	if req.PageSize == 0 || req.PageSize > MaxListLimit {
+		err := status.Errorf(codes.InvalidArgument, "Page_size must be between 1 and %d", MaxListLimit)
+		return err
+	}
+
+	// Проверка, что страница задана либо номером, либо курсором
+	if len(req.PageToken) > 0 && req.PageNumber > 1 {
+		err := status.Error(codes.InvalidArgument, "Page_token and Page_number must not be set together")
+		return err
+	}
+
+	// Проверка, что сортировка по разрешенному полю
+	if _, err := ParseUserOrder(req.OrderBy); err != nil {
+		return err
+	}
+
+	// Проверка фильтра, если он указан
+	if req.Filter != nil {
+		if err := req.Filter.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Проверка, что смещение страницы не переполняется
+	if req.PageNumber > 1 && req.PageNumber-1 > math.MaxInt64/req.PageSize {
+		err := status.Errorf(codes.InvalidArgument, "Page_number %d is too large", req.PageNumber)
+		return err
+	}
+
+	return nil
+}
+
+// Offset возвращает количество пользователей на предыдущих страницах.
+// Page_number 0 и 1 означают первую страницу.
+func (req *ListUsersRequest) Offset() uint64 {
+	if req.PageNumber <= 1 {
+		return 0
+	}
+
+	return (req.PageNumber - 1) * req.PageSize
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Role не объявлена в enum.
+//   - error, если Email или Email_domain длиннее MaxEmailFilterLength символов.
+//   - error, если Email_domain содержит "@".
+//   - error, если Created_after или Created_before указаны и некорректны либо Created_after не раньше Created_before.
+//   - error, если Status не объявлен в enum.
+//   - nil в остальных случаях.
+func (f *ListUsersFilter) Validate() error {
+	// Проверка, что Role, если указана, входит в список объявленных ролей
+	if f.GetRole() != UserRole_UNKNOWN && !f.GetRole().IsDefined() {
+		err := status.Errorf(codes.InvalidArgument, "Invalid role %d", f.GetRole())
+		return err
+	}
+
+	// Проверка длины Email и Email_domain
+	if utf8.RuneCountInString(f.Email) > MaxEmailFilterLength || utf8.RuneCountInString(f.EmailDomain) > MaxEmailFilterLength {
+		err := status.Errorf(codes.InvalidArgument, "Email and Email_domain must be at most %d characters long", MaxEmailFilterLength)
+		return err
+	}
+
+	// Проверка, что Email_domain - домен, а не адрес
+	if strings.Contains(f.EmailDomain, "@") {
+		err := status.Error(codes.InvalidArgument, "Email_domain must not contain @")
+		return err
+	}
+
+	// Проверка, что границы интервала, если указаны, корректны
+	if f.CreatedAfter != nil {
+		if err := f.CreatedAfter.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid Created_after: %v", err)
+		}
+	}
+	if f.CreatedBefore != nil {
+		if err := f.CreatedBefore.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid Created_before: %v", err)
+		}
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.AsTime().Before(f.CreatedBefore.AsTime()) {
+		err := status.Error(codes.InvalidArgument, "Created_after must be earlier than Created_before")
+		return err
+	}
+
+	// Проверка, что Status объявлен в enum
+	if _, ok := UserStatus_name[int32(f.GetStatus())]; !ok {
+		err := status.Errorf(codes.InvalidArgument, "Invalid status %d", f.GetStatus())
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Данные отдельных пользователей проверяются при создании, и ошибки возвращаются в результатах элементов.
+//
+// Возвращает:
+//   - error, если список Users пустой или содержит больше MaxBatchCreateUsers пользователей.
+//   - nil в остальных случаях.
+func (req *BatchCreateUsersRequest) Validate() error {
+	// Проверка, что количество пользователей в допустимых пределах
+	if len(req.Users) == 0 || len(req.Users) > MaxBatchCreateUsers {
+		err := status.Errorf(codes.InvalidArgument, "Users count must be between 1 and %d", MaxBatchCreateUsers)
+		return err
+	}
+
+	return nil
+}
+
+// Validate
+//
+// Возвращает:
+//   - error, если Active_since указан и некорректен.
+//   - nil в остальных случаях.
+func (req *GetUserStatsRequest) Validate() error {
+	if req.ActiveSince != nil {
+		if err := req.ActiveSince.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid Active_since: %v", err)
+		}
+	}
+
 	return nil
 }
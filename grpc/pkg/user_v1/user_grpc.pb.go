@@ -26,7 +26,18 @@ type UserV1Client interface {
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*CreateUserResponse, error)
 	GetUserInfo(ctx context.Context, in *GetUserInfoRequest, opts ...grpc.CallOption) (*GetUserInfoResponse, error)
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
+	ListEmailDomainStats(ctx context.Context, in *ListEmailDomainStatsRequest, opts ...grpc.CallOption) (*ListEmailDomainStatsResponse, error)
+	GetSignupTimeSeries(ctx context.Context, in *GetSignupTimeSeriesRequest, opts ...grpc.CallOption) (*GetSignupTimeSeriesResponse, error)
+	ValidateEmails(ctx context.Context, in *ValidateEmailsRequest, opts ...grpc.CallOption) (*ValidateEmailsResponse, error)
+	FindDuplicateCandidates(ctx context.Context, in *FindDuplicateCandidatesRequest, opts ...grpc.CallOption) (*FindDuplicateCandidatesResponse, error)
+	RecordActivity(ctx context.Context, in *RecordActivityRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	WatchUserEvents(ctx context.Context, in *WatchUserEventsRequest, opts ...grpc.CallOption) (UserV1_WatchUserEventsClient, error)
+	ClaimHandle(ctx context.Context, in *ClaimHandleRequest, opts ...grpc.CallOption) (*ClaimHandleResponse, error)
+	ChangeUserPassword(ctx context.Context, in *ChangeUserPasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	BatchCreateUsers(ctx context.Context, in *BatchCreateUsersRequest, opts ...grpc.CallOption) (*BatchCreateUsersResponse, error)
+	GetUserStats(ctx context.Context, in *GetUserStatsRequest, opts ...grpc.CallOption) (*GetUserStatsResponse, error)
 }
 
 type userV1Client struct {
@@ -64,8 +75,8 @@ func (c *userV1Client) UpdateUser(ctx context.Context, in *UpdateUserRequest, op
 	return out, nil
 }
 
-func (c *userV1Client) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *userV1Client) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
+	out := new(DeleteUserResponse)
 	err := c.cc.Invoke(ctx, "/user_v1.UserV1/DeleteUser", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -73,6 +84,128 @@ func (c *userV1Client) DeleteUser(ctx context.Context, in *DeleteUserRequest, op
 	return out, nil
 }
 
+func (c *userV1Client) ListEmailDomainStats(ctx context.Context, in *ListEmailDomainStatsRequest, opts ...grpc.CallOption) (*ListEmailDomainStatsResponse, error) {
+	out := new(ListEmailDomainStatsResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/ListEmailDomainStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) GetSignupTimeSeries(ctx context.Context, in *GetSignupTimeSeriesRequest, opts ...grpc.CallOption) (*GetSignupTimeSeriesResponse, error) {
+	out := new(GetSignupTimeSeriesResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/GetSignupTimeSeries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) ValidateEmails(ctx context.Context, in *ValidateEmailsRequest, opts ...grpc.CallOption) (*ValidateEmailsResponse, error) {
+	out := new(ValidateEmailsResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/ValidateEmails", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) FindDuplicateCandidates(ctx context.Context, in *FindDuplicateCandidatesRequest, opts ...grpc.CallOption) (*FindDuplicateCandidatesResponse, error) {
+	out := new(FindDuplicateCandidatesResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/FindDuplicateCandidates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) RecordActivity(ctx context.Context, in *RecordActivityRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/RecordActivity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) WatchUserEvents(ctx context.Context, in *WatchUserEventsRequest, opts ...grpc.CallOption) (UserV1_WatchUserEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserV1_ServiceDesc.Streams[0], "/user_v1.UserV1/WatchUserEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userV1WatchUserEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserV1_WatchUserEventsClient interface {
+	Recv() (*UserEvent, error)
+	grpc.ClientStream
+}
+
+type userV1WatchUserEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *userV1WatchUserEventsClient) Recv() (*UserEvent, error) {
+	m := new(UserEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userV1Client) ClaimHandle(ctx context.Context, in *ClaimHandleRequest, opts ...grpc.CallOption) (*ClaimHandleResponse, error) {
+	out := new(ClaimHandleResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/ClaimHandle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) ChangeUserPassword(ctx context.Context, in *ChangeUserPasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/ChangeUserPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/ListUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) BatchCreateUsers(ctx context.Context, in *BatchCreateUsersRequest, opts ...grpc.CallOption) (*BatchCreateUsersResponse, error) {
+	out := new(BatchCreateUsersResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/BatchCreateUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userV1Client) GetUserStats(ctx context.Context, in *GetUserStatsRequest, opts ...grpc.CallOption) (*GetUserStatsResponse, error) {
+	out := new(GetUserStatsResponse)
+	err := c.cc.Invoke(ctx, "/user_v1.UserV1/GetUserStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserV1Server is the server API for UserV1 service.
 // All implementations must embed UnimplementedUserV1Server
 // for forward compatibility
@@ -80,7 +213,18 @@ type UserV1Server interface {
 	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
 	GetUserInfo(context.Context, *GetUserInfoRequest) (*GetUserInfoResponse, error)
 	UpdateUser(context.Context, *UpdateUserRequest) (*emptypb.Empty, error)
-	DeleteUser(context.Context, *DeleteUserRequest) (*emptypb.Empty, error)
+	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
+	ListEmailDomainStats(context.Context, *ListEmailDomainStatsRequest) (*ListEmailDomainStatsResponse, error)
+	GetSignupTimeSeries(context.Context, *GetSignupTimeSeriesRequest) (*GetSignupTimeSeriesResponse, error)
+	ValidateEmails(context.Context, *ValidateEmailsRequest) (*ValidateEmailsResponse, error)
+	FindDuplicateCandidates(context.Context, *FindDuplicateCandidatesRequest) (*FindDuplicateCandidatesResponse, error)
+	RecordActivity(context.Context, *RecordActivityRequest) (*emptypb.Empty, error)
+	WatchUserEvents(*WatchUserEventsRequest, UserV1_WatchUserEventsServer) error
+	ClaimHandle(context.Context, *ClaimHandleRequest) (*ClaimHandleResponse, error)
+	ChangeUserPassword(context.Context, *ChangeUserPasswordRequest) (*emptypb.Empty, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	BatchCreateUsers(context.Context, *BatchCreateUsersRequest) (*BatchCreateUsersResponse, error)
+	GetUserStats(context.Context, *GetUserStatsRequest) (*GetUserStatsResponse, error)
 	mustEmbedUnimplementedUserV1Server()
 }
 
@@ -97,9 +241,42 @@ func (UnimplementedUserV1Server) GetUserInfo(context.Context, *GetUserInfoReques
 func (UnimplementedUserV1Server) UpdateUser(context.Context, *UpdateUserRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
 }
-func (UnimplementedUserV1Server) DeleteUser(context.Context, *DeleteUserRequest) (*emptypb.Empty, error) {
+func (UnimplementedUserV1Server) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
 }
+func (UnimplementedUserV1Server) ListEmailDomainStats(context.Context, *ListEmailDomainStatsRequest) (*ListEmailDomainStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmailDomainStats not implemented")
+}
+func (UnimplementedUserV1Server) GetSignupTimeSeries(context.Context, *GetSignupTimeSeriesRequest) (*GetSignupTimeSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSignupTimeSeries not implemented")
+}
+func (UnimplementedUserV1Server) ValidateEmails(context.Context, *ValidateEmailsRequest) (*ValidateEmailsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateEmails not implemented")
+}
+func (UnimplementedUserV1Server) FindDuplicateCandidates(context.Context, *FindDuplicateCandidatesRequest) (*FindDuplicateCandidatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicateCandidates not implemented")
+}
+func (UnimplementedUserV1Server) RecordActivity(context.Context, *RecordActivityRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordActivity not implemented")
+}
+func (UnimplementedUserV1Server) WatchUserEvents(*WatchUserEventsRequest, UserV1_WatchUserEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUserEvents not implemented")
+}
+func (UnimplementedUserV1Server) ClaimHandle(context.Context, *ClaimHandleRequest) (*ClaimHandleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimHandle not implemented")
+}
+func (UnimplementedUserV1Server) ChangeUserPassword(context.Context, *ChangeUserPasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeUserPassword not implemented")
+}
+func (UnimplementedUserV1Server) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedUserV1Server) BatchCreateUsers(context.Context, *BatchCreateUsersRequest) (*BatchCreateUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateUsers not implemented")
+}
+func (UnimplementedUserV1Server) GetUserStats(context.Context, *GetUserStatsRequest) (*GetUserStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserStats not implemented")
+}
 func (UnimplementedUserV1Server) mustEmbedUnimplementedUserV1Server() {}
 
 // UnsafeUserV1Server may be embedded to opt out of forward compatibility for this service.
@@ -185,6 +362,207 @@ func _UserV1_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserV1_ListEmailDomainStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEmailDomainStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).ListEmailDomainStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/ListEmailDomainStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).ListEmailDomainStats(ctx, req.(*ListEmailDomainStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_GetSignupTimeSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSignupTimeSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).GetSignupTimeSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/GetSignupTimeSeries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).GetSignupTimeSeries(ctx, req.(*GetSignupTimeSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_ValidateEmails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateEmailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).ValidateEmails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/ValidateEmails",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).ValidateEmails(ctx, req.(*ValidateEmailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_FindDuplicateCandidates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindDuplicateCandidatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).FindDuplicateCandidates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/FindDuplicateCandidates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).FindDuplicateCandidates(ctx, req.(*FindDuplicateCandidatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_RecordActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).RecordActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/RecordActivity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).RecordActivity(ctx, req.(*RecordActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_WatchUserEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUserEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserV1Server).WatchUserEvents(m, &userV1WatchUserEventsServer{stream})
+}
+
+type UserV1_WatchUserEventsServer interface {
+	Send(*UserEvent) error
+	grpc.ServerStream
+}
+
+type userV1WatchUserEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *userV1WatchUserEventsServer) Send(m *UserEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UserV1_ClaimHandle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimHandleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).ClaimHandle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/ClaimHandle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).ClaimHandle(ctx, req.(*ClaimHandleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_ChangeUserPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeUserPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).ChangeUserPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/ChangeUserPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).ChangeUserPassword(ctx, req.(*ChangeUserPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/ListUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_BatchCreateUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).BatchCreateUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/BatchCreateUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).BatchCreateUsers(ctx, req.(*BatchCreateUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserV1_GetUserStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserV1Server).GetUserStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user_v1.UserV1/GetUserStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserV1Server).GetUserStats(ctx, req.(*GetUserStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserV1_ServiceDesc is the grpc.ServiceDesc for UserV1 service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -208,7 +586,53 @@ var UserV1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUser",
 			Handler:    _UserV1_DeleteUser_Handler,
 		},
+		{
+			MethodName: "ListEmailDomainStats",
+			Handler:    _UserV1_ListEmailDomainStats_Handler,
+		},
+		{
+			MethodName: "GetSignupTimeSeries",
+			Handler:    _UserV1_GetSignupTimeSeries_Handler,
+		},
+		{
+			MethodName: "ValidateEmails",
+			Handler:    _UserV1_ValidateEmails_Handler,
+		},
+		{
+			MethodName: "FindDuplicateCandidates",
+			Handler:    _UserV1_FindDuplicateCandidates_Handler,
+		},
+		{
+			MethodName: "RecordActivity",
+			Handler:    _UserV1_RecordActivity_Handler,
+		},
+		{
+			MethodName: "ClaimHandle",
+			Handler:    _UserV1_ClaimHandle_Handler,
+		},
+		{
+			MethodName: "ChangeUserPassword",
+			Handler:    _UserV1_ChangeUserPassword_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _UserV1_ListUsers_Handler,
+		},
+		{
+			MethodName: "BatchCreateUsers",
+			Handler:    _UserV1_BatchCreateUsers_Handler,
+		},
+		{
+			MethodName: "GetUserStats",
+			Handler:    _UserV1_GetUserStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUserEvents",
+			Handler:       _UserV1_WatchUserEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
 }
@@ -0,0 +1,322 @@
+package user_v1
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/anton0701/auth/grpc/pkg"
+)
+
+// validateTest - запрос и ожидаемый код ошибки его проверки.
+type validateTest struct {
+	name     string
+	req      pkg.Validator
+	wantCode codes.Code
+}
+
+// runValidateTests проверяет, что Validate каждого запроса из tests возвращает ожидаемый код.
+func runValidateTests(t *testing.T, tests []validateTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.req.Validate()); code != tt.wantCode {
+				t.Errorf("Validate() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCreateUserRequestValidate(t *testing.T) {
+	valid := func() *CreateUserRequest {
+		return &CreateUserRequest{
+			Name:            "Alice",
+			Email:           "alice@example.com",
+			Password:        "Str0ng-password",
+			PasswordConfirm: "Str0ng-password",
+			Role:            UserRole_USER,
+		}
+	}
+	with := func(change func(req *CreateUserRequest)) *CreateUserRequest {
+		req := valid()
+		change(req)
+		return req
+	}
+
+	runValidateTests(t, []validateTest{
+		{name: "valid", req: valid(), wantCode: codes.OK},
+		{name: "admin", req: with(func(req *CreateUserRequest) { req.Role = UserRole_ADMIN }), wantCode: codes.OK},
+		{name: "with phone", req: with(func(req *CreateUserRequest) { req.Phone = "+7 999 123-45-67" }), wantCode: codes.OK},
+		{name: "blank phone", req: with(func(req *CreateUserRequest) { req.Phone = "  " }), wantCode: codes.OK},
+		{name: "missing name", req: with(func(req *CreateUserRequest) { req.Name = "" }), wantCode: codes.InvalidArgument},
+		{name: "blank email", req: with(func(req *CreateUserRequest) { req.Email = " " }), wantCode: codes.InvalidArgument},
+		{
+			name:     "passwords differ",
+			req:      with(func(req *CreateUserRequest) { req.PasswordConfirm = "str0ng-password" }),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "password too long",
+			req: with(func(req *CreateUserRequest) {
+				req.Password = strings.Repeat("x", MaxPasswordBytes+1)
+				req.PasswordConfirm = req.Password
+			}),
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "unknown role", req: with(func(req *CreateUserRequest) { req.Role = UserRole_UNKNOWN }), wantCode: codes.InvalidArgument},
+		{name: "service role", req: with(func(req *CreateUserRequest) { req.Role = UserRole_SERVICE }), wantCode: codes.InvalidArgument},
+		{name: "out of range role", req: with(func(req *CreateUserRequest) { req.Role = UserRole(42) }), wantCode: codes.InvalidArgument},
+		{name: "negative role", req: with(func(req *CreateUserRequest) { req.Role = UserRole(-1) }), wantCode: codes.InvalidArgument},
+		{name: "invalid phone", req: with(func(req *CreateUserRequest) { req.Phone = "89991234567" }), wantCode: codes.InvalidArgument},
+	})
+}
+
+func TestUpdateUserRequestValidate(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "name", req: &UpdateUserRequest{Id: 1, Name: wrapperspb.String("Alice")}, wantCode: codes.OK},
+		{name: "email", req: &UpdateUserRequest{Id: 1, Email: wrapperspb.String("alice@example.com")}, wantCode: codes.OK},
+		{name: "role", req: &UpdateUserRequest{Id: 1, Role: UserRole_ADMIN}, wantCode: codes.OK},
+		{name: "phone", req: &UpdateUserRequest{Id: 1, Phone: wrapperspb.String("+79991234567")}, wantCode: codes.OK},
+		{name: "missing id", req: &UpdateUserRequest{Name: wrapperspb.String("Alice")}, wantCode: codes.InvalidArgument},
+		{name: "service role", req: &UpdateUserRequest{Id: 1, Role: UserRole_SERVICE_ADMIN}, wantCode: codes.InvalidArgument},
+		{name: "out of range role", req: &UpdateUserRequest{Id: 1, Role: UserRole(42)}, wantCode: codes.InvalidArgument},
+		{name: "nothing to update", req: &UpdateUserRequest{Id: 1}, wantCode: codes.InvalidArgument},
+		{
+			name:     "blank name and email",
+			req:      &UpdateUserRequest{Id: 1, Name: wrapperspb.String(" "), Email: wrapperspb.String("")},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "phone cleared", req: &UpdateUserRequest{Id: 1, Phone: wrapperspb.String("")}, wantCode: codes.OK},
+		{name: "invalid phone", req: &UpdateUserRequest{Id: 1, Phone: wrapperspb.String("phone")}, wantCode: codes.InvalidArgument},
+	})
+}
+
+func TestChangeUserPasswordRequestValidate(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{
+			name:     "valid",
+			req:      &ChangeUserPasswordRequest{Id: 1, OldPassword: "old-password", NewPassword: "new-password", NewPasswordConfirm: "new-password"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing id",
+			req:      &ChangeUserPasswordRequest{OldPassword: "old-password", NewPassword: "new-password", NewPasswordConfirm: "new-password"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing old password",
+			req:      &ChangeUserPasswordRequest{Id: 1, NewPassword: "new-password", NewPasswordConfirm: "new-password"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "passwords differ",
+			req:      &ChangeUserPasswordRequest{Id: 1, OldPassword: "old-password", NewPassword: "new-password", NewPasswordConfirm: "other-password"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "same password",
+			req:      &ChangeUserPasswordRequest{Id: 1, OldPassword: "password", NewPassword: "password", NewPasswordConfirm: "password"},
+			wantCode: codes.InvalidArgument,
+		},
+	})
+}
+
+func TestGetSignupTimeSeriesRequestValidate(t *testing.T) {
+	from := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	runValidateTests(t, []validateTest{
+		{
+			name: "valid",
+			req: &GetSignupTimeSeriesRequest{
+				Granularity: SignupGranularity_DAY,
+				From:        timestamppb.New(from),
+				To:          timestamppb.New(from.AddDate(0, 1, 0)),
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing granularity",
+			req:      &GetSignupTimeSeriesRequest{From: timestamppb.New(from), To: timestamppb.New(from.AddDate(0, 1, 0))},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing to",
+			req:      &GetSignupTimeSeriesRequest{Granularity: SignupGranularity_DAY, From: timestamppb.New(from)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "undefined granularity",
+			req: &GetSignupTimeSeriesRequest{
+				Granularity: SignupGranularity(10),
+				From:        timestamppb.New(from),
+				To:          timestamppb.New(from.AddDate(0, 1, 0)),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "empty range",
+			req: &GetSignupTimeSeriesRequest{
+				Granularity: SignupGranularity_DAY,
+				From:        timestamppb.New(from),
+				To:          timestamppb.New(from),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "max buckets",
+			req: &GetSignupTimeSeriesRequest{
+				Granularity: SignupGranularity_DAY,
+				From:        timestamppb.New(from),
+				To:          timestamppb.New(from.AddDate(0, 0, MaxSignupBuckets)),
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "too many buckets",
+			req: &GetSignupTimeSeriesRequest{
+				Granularity: SignupGranularity_DAY,
+				From:        timestamppb.New(from),
+				To:          timestamppb.New(from.AddDate(0, 0, MaxSignupBuckets).Add(time.Second)),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	})
+}
+
+func TestListUsersFilterValidate(t *testing.T) {
+	createdAt := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	runValidateTests(t, []validateTest{
+		{name: "empty", req: &ListUsersFilter{}, wantCode: codes.OK},
+		{name: "email domain", req: &ListUsersFilter{EmailDomain: "example.com"}, wantCode: codes.OK},
+		{name: "email domain with @", req: &ListUsersFilter{EmailDomain: "@example.com"}, wantCode: codes.InvalidArgument},
+		{
+			name:     "email too long",
+			req:      &ListUsersFilter{Email: strings.Repeat("ж", MaxEmailFilterLength+1)},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "undefined role", req: &ListUsersFilter{Role: UserRole(10)}, wantCode: codes.InvalidArgument},
+		{name: "undefined status", req: &ListUsersFilter{Status: UserStatus(10)}, wantCode: codes.InvalidArgument},
+		{
+			name:     "created interval",
+			req:      &ListUsersFilter{CreatedAfter: timestamppb.New(createdAt), CreatedBefore: timestamppb.New(createdAt.Add(time.Hour))},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "empty created interval",
+			req:      &ListUsersFilter{CreatedAfter: timestamppb.New(createdAt), CreatedBefore: timestamppb.New(createdAt)},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid created after",
+			req:      &ListUsersFilter{CreatedAfter: &timestamppb.Timestamp{Nanos: -1}},
+			wantCode: codes.InvalidArgument,
+		},
+	})
+}
+
+func TestClaimHandleRequestValidate(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "valid", req: &ClaimHandleRequest{Id: 1, Handle: "Alice_01"}, wantCode: codes.OK},
+		{name: "missing id", req: &ClaimHandleRequest{Handle: "alice"}, wantCode: codes.InvalidArgument},
+		{name: "missing handle", req: &ClaimHandleRequest{Id: 1}, wantCode: codes.InvalidArgument},
+		{name: "too short", req: &ClaimHandleRequest{Id: 1, Handle: "al"}, wantCode: codes.InvalidArgument},
+		{name: "too long", req: &ClaimHandleRequest{Id: 1, Handle: strings.Repeat("a", MaxHandleLength+1)}, wantCode: codes.InvalidArgument},
+		{name: "invalid characters", req: &ClaimHandleRequest{Id: 1, Handle: "alice-01"}, wantCode: codes.InvalidArgument},
+	})
+}
+
+func TestRequestValidate(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "get user info", req: &GetUserInfoRequest{Id: 1}, wantCode: codes.OK},
+		{name: "get user info without id", req: &GetUserInfoRequest{}, wantCode: codes.InvalidArgument},
+		{name: "delete user", req: &DeleteUserRequest{Id: 1}, wantCode: codes.OK},
+		{name: "delete user without id", req: &DeleteUserRequest{}, wantCode: codes.InvalidArgument},
+		{name: "record activity", req: &RecordActivityRequest{Id: 1}, wantCode: codes.OK},
+		{name: "record activity without id", req: &RecordActivityRequest{}, wantCode: codes.InvalidArgument},
+		{name: "email domain stats", req: &ListEmailDomainStatsRequest{Limit: MaxListLimit}, wantCode: codes.OK},
+		{name: "email domain stats without limit", req: &ListEmailDomainStatsRequest{}, wantCode: codes.InvalidArgument},
+		{name: "email domain stats limit too large", req: &ListEmailDomainStatsRequest{Limit: MaxListLimit + 1}, wantCode: codes.InvalidArgument},
+		{name: "duplicate candidates", req: &FindDuplicateCandidatesRequest{Limit: 10}, wantCode: codes.OK},
+		{name: "duplicate candidates without limit", req: &FindDuplicateCandidatesRequest{}, wantCode: codes.InvalidArgument},
+		{name: "validate emails", req: &ValidateEmailsRequest{Emails: []string{"alice@example.com"}}, wantCode: codes.OK},
+		{name: "validate no emails", req: &ValidateEmailsRequest{}, wantCode: codes.InvalidArgument},
+		{
+			name:     "validate too many emails",
+			req:      &ValidateEmailsRequest{Emails: make([]string, MaxListLimit+1)},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "watch all events", req: &WatchUserEventsRequest{}, wantCode: codes.OK},
+		{name: "watch events", req: &WatchUserEventsRequest{Types: []UserEventType{UserEventType_CREATED}}, wantCode: codes.OK},
+		{
+			name:     "watch unknown event",
+			req:      &WatchUserEventsRequest{Types: []UserEventType{UserEventType_CREATED, UserEventType_EVENT_TYPE_UNKNOWN}},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "watch undefined event", req: &WatchUserEventsRequest{Types: []UserEventType{UserEventType(10)}}, wantCode: codes.InvalidArgument},
+		{name: "batch create", req: &BatchCreateUsersRequest{Users: make([]*CreateUserRequest, MaxBatchCreateUsers)}, wantCode: codes.OK},
+		{name: "batch create without users", req: &BatchCreateUsersRequest{}, wantCode: codes.InvalidArgument},
+		{
+			name:     "batch create too many users",
+			req:      &BatchCreateUsersRequest{Users: make([]*CreateUserRequest, MaxBatchCreateUsers+1)},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "user stats", req: &GetUserStatsRequest{}, wantCode: codes.OK},
+		{name: "user stats active since", req: &GetUserStatsRequest{ActiveSince: timestamppb.Now()}, wantCode: codes.OK},
+		{
+			name:     "user stats invalid active since",
+			req:      &GetUserStatsRequest{ActiveSince: &timestamppb.Timestamp{Nanos: -1}},
+			wantCode: codes.InvalidArgument,
+		},
+	})
+}
+
+func TestListUsersRequestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *ListUsersRequest
+		wantCode codes.Code
+	}{
+		{name: "first page", req: &ListUsersRequest{PageSize: 10}, wantCode: codes.OK},
+		{name: "page number", req: &ListUsersRequest{PageSize: 10, PageNumber: 3}, wantCode: codes.OK},
+		{name: "missing page size", req: &ListUsersRequest{}, wantCode: codes.InvalidArgument},
+		{name: "page size too large", req: &ListUsersRequest{PageSize: MaxListLimit + 1}, wantCode: codes.InvalidArgument},
+		{
+			name:     "page token with page number",
+			req:      &ListUsersRequest{PageSize: 10, PageNumber: 2, PageToken: "token"},
+			wantCode: codes.InvalidArgument,
+		},
+		{name: "unknown order", req: &ListUsersRequest{PageSize: 10, OrderBy: "password"}, wantCode: codes.InvalidArgument},
+		{name: "valid filter", req: &ListUsersRequest{PageSize: 10, Filter: &ListUsersFilter{Role: UserRole_ADMIN}}, wantCode: codes.OK},
+		{
+			name:     "invalid filter",
+			req:      &ListUsersRequest{PageSize: 10, Filter: &ListUsersFilter{EmailDomain: "user@example.com"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "page number overflow",
+			req:      &ListUsersRequest{PageSize: MaxListLimit, PageNumber: math.MaxUint64},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "page number overflow with valid filter",
+			req:      &ListUsersRequest{PageSize: MaxListLimit, PageNumber: math.MaxUint64, Filter: &ListUsersFilter{Role: UserRole_USER}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.req.Validate()); code != tt.wantCode {
+				t.Errorf("Validate() code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
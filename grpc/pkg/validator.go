@@ -14,3 +14,15 @@ package pkg
 type Validator interface {
 	Validate() error
 }
+
+// WarningsProvider - интерфейс для запросов к АПИ, которые помимо блокирующих ошибок (Validator)
+// могут содержать некритичные замечания: слабый, но допустимый пароль, необычный email и т.п.
+//
+// Методы:
+//
+//   - Warnings() []string:
+//
+//     Возвращает список предупреждений. Пустой список - предупреждений нет.
+type WarningsProvider interface {
+	Warnings() []string
+}
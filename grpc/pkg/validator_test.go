@@ -0,0 +1,61 @@
+package pkg_test
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/anton0701/auth/grpc/pkg"
+	accessDesc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	authDesc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	userDesc "github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+// TestRequestsImplementValidator проверяет, что каждый запрос АПИ (сообщение с суффиксом Request
+// и входное сообщение каждого метода) реализует pkg.Validator, чтобы новый метод нельзя было
+// добавить без валидации запроса.
+func TestRequestsImplementValidator(t *testing.T) {
+	files := []protoreflect.FileDescriptor{
+		userDesc.File_user_proto,
+		authDesc.File_auth_proto,
+		accessDesc.File_access_proto,
+	}
+
+	for _, file := range files {
+		requests := make(map[protoreflect.FullName]struct{})
+
+		messages := file.Messages()
+		for i := 0; i < messages.Len(); i++ {
+			if strings.HasSuffix(string(messages.Get(i).Name()), "Request") {
+				requests[messages.Get(i).FullName()] = struct{}{}
+			}
+		}
+
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				input := methods.Get(j).Input()
+				// Общие сообщения (например, google.protobuf.Empty) валидировать нечего
+				if input.ParentFile() == file {
+					requests[input.FullName()] = struct{}{}
+				}
+			}
+		}
+
+		for name := range requests {
+			t.Run(string(name), func(t *testing.T) {
+				messageType, err := protoregistry.GlobalTypes.FindMessageByName(name)
+				if err != nil {
+					t.Fatalf("FindMessageByName() error = %v", err)
+				}
+
+				if _, ok := messageType.New().Interface().(pkg.Validator); !ok {
+					t.Errorf("%s does not implement pkg.Validator", name)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// Check проверяет, может ли вызывающий пользователь обращаться к методу из запроса.
+//
+// Access-токен пользователя передается в метаданных запроса: "authorization: Bearer <token>",
+// API-ключ сервиса - "x-api-key: <key>". Используется другими сервисами для ограничения доступа
+// к своим методам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с метаданными запроса.
+//   - req: запрос с полным именем проверяемого метода.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если доступ разрешен.
+//   - error - ошибка Unauthenticated, если токен или API-ключ не передан либо недействителен,
+//     PermissionDenied, если роли пользователя или ключа недостаточно.
+func (i *Implementation) Check(ctx context.Context, req *desc.CheckRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Check", zap.String("Endpoint", req.EndpointAddress))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Check. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	var err error
+	if apiKey, keyErr := token.APIKeyFromIncomingContext(ctx); keyErr == nil {
+		_, err = i.accessService.CheckAPIKey(ctx, apiKey, req.EndpointAddress)
+	} else {
+		accessToken, tokenErr := token.FromIncomingContext(ctx)
+		if tokenErr != nil {
+			i.log.Error("Method Check. Access token not provided", zap.Error(tokenErr))
+			return nil, status.Error(codes.Unauthenticated, "Access token is not provided")
+		}
+		_, err = i.accessService.Check(ctx, accessToken, req.EndpointAddress)
+	}
+
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		i.log.Error("Method Check. Invalid access token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Invalid access token")
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		i.log.Error("Method Check. Invalid API key")
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	case errors.Is(err, service.ErrAccessDenied):
+		i.log.Error("Method Check. Access denied", zap.String("Endpoint", req.EndpointAddress))
+		return nil, status.Error(codes.PermissionDenied, "Access denied")
+	case err != nil:
+		i.log.Error("Method Check. Unable to check access", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to check access, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,55 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// CreateServiceAccount создает сервисный аккаунт для вызовов от другого сервиса.
+//
+// Метод доступен только администраторам. Секрет возвращается только в ответе на этот запрос,
+// в БД хранится лишь его хеш.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с именем сервиса и ролью аккаунта.
+//
+// Возвращает:
+//   - *ServiceAccountCredentials - ID, client_id и секрет аккаунта.
+//   - error - ошибка AlreadyExists, если имя занято, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) CreateServiceAccount(ctx context.Context, req *desc.CreateServiceAccountRequest) (*desc.ServiceAccountCredentials, error) {
+	i.log.Info("Method Create-Service-Account", zap.String("Name", req.Name), zap.Int32("Role", req.Role))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Create-Service-Account. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	credentials, err := i.serviceAccountService.Create(ctx, &model.ServiceAccountToCreate{
+		Name: req.Name,
+		Role: req.Role,
+	})
+	if errors.Is(err, service.ErrServiceAccountExists) {
+		i.log.Error("Method Create-Service-Account. Service account already exists", zap.String("Name", req.Name))
+		return nil, status.Errorf(codes.AlreadyExists, "Service account %q already exists", req.Name)
+	}
+	if err != nil {
+		i.log.Error("Method Create-Service-Account. Unable to create service account", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to create service account, error info: %#v", err)
+	}
+
+	return &desc.ServiceAccountCredentials{
+		Id:           credentials.ID,
+		ClientId:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// DisableServiceAccount отключает сервисный аккаунт. Новые токены аккаунту не выпускаются,
+// выпущенные действуют до истечения.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID аккаунта.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если аккаунт отключен.
+//   - error - ошибка NotFound, если аккаунта нет или он уже отключен, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) DisableServiceAccount(ctx context.Context, req *desc.DisableServiceAccountRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Disable-Service-Account", zap.Int64("Service-account-id", req.Id))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Disable-Service-Account. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	err := i.serviceAccountService.Disable(ctx, req.Id)
+	if errors.Is(err, service.ErrServiceAccountNotFound) {
+		i.log.Error("Method Disable-Service-Account. Service account not found", zap.Int64("Service-account-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "Service account with id %d not found or already disabled", req.Id)
+	}
+	if err != nil {
+		i.log.Error("Method Disable-Service-Account. Unable to disable service account", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to disable service account, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
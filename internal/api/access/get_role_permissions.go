@@ -0,0 +1,58 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// GetRolePermissions возвращает методы, вызов которых разрешен роли, по правилам доступа из кода и БД.
+// Используется клиентами, чтобы скрыть недоступные пользователю действия.
+//
+// Администратор может запросить права любой роли, остальные вызывающие - только своей.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном или API-ключом вызывающего в метаданных.
+//   - req: запрос с ролью.
+//
+// Возвращает:
+//   - *GetRolePermissionsResponse - методы с требованиями к роли, в которые входит роль, по алфавиту.
+//   - error - ошибка InvalidArgument, если роль некорректная, Unauthenticated, если вызывающий
+//     не определен, PermissionDenied, если запрошены права чужой роли, либо другая ошибка,
+//     если что-то пошло не так.
+func (i *Implementation) GetRolePermissions(ctx context.Context, req *desc.GetRolePermissionsRequest) (*desc.GetRolePermissionsResponse, error) {
+	i.log.Info("Method Get-Role-Permissions", zap.Int32("Role", req.Role))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Get-Role-Permissions. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	caller, ok := identity.CallerFromContext(ctx)
+	if !ok {
+		i.log.Error("Method Get-Role-Permissions. Caller not found")
+		return nil, status.Error(codes.Unauthenticated, "Access token is required")
+	}
+
+	endpoints, err := i.accessService.GetRolePermissions(ctx, caller, req.Role)
+	if errors.Is(err, service.ErrAccessDenied) {
+		i.log.Error("Method Get-Role-Permissions. Access denied", zap.Int32("Role", req.Role), zap.Int32("Caller-role", caller.Role))
+		return nil, status.Error(codes.PermissionDenied, "Access denied")
+	}
+	if err != nil {
+		i.log.Error("Method Get-Role-Permissions. Unable to get role permissions", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to get role permissions, error info: %#v", err)
+	}
+
+	return &desc.GetRolePermissionsResponse{
+		EndpointAddresses: endpoints,
+	}, nil
+}
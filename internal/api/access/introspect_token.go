@@ -0,0 +1,68 @@
+package access
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/identity"
+)
+
+// IntrospectToken проверяет access-токен из запроса и возвращает его данные. Используется шлюзами,
+// которые не могут проверить JWT сами, и клиентами для проверки своего токена. Отозванный токен
+// считается недействительным.
+//
+// Администратор может проверить любой токен, остальные вызывающие - только свой.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном или API-ключом вызывающего в метаданных.
+//   - req: запрос с проверяемым access-токеном.
+//
+// Возвращает:
+//   - *IntrospectTokenResponse - active = false, если токен недействителен, истек, отозван или
+//     выпущен другому пользователю, а вызывающий не администратор, иначе ID пользователя, роль,
+//     разрешенные методы и время выпуска и истечения токена.
+//   - error - ошибка Unauthenticated, если вызывающий не определен, InvalidArgument, если токен
+//     не передан, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) IntrospectToken(ctx context.Context, req *desc.IntrospectTokenRequest) (*desc.IntrospectTokenResponse, error) {
+	// Токен не логируется: с ним можно обращаться к методам от имени пользователя
+	i.log.Info("Method Introspect-Token")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Introspect-Token. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	caller, ok := identity.CallerFromContext(ctx)
+	if !ok {
+		i.log.Error("Method Introspect-Token. Caller not found")
+		return nil, status.Error(codes.Unauthenticated, "Access token is required")
+	}
+
+	introspection, err := i.accessService.IntrospectToken(ctx, caller, req.Token)
+	if err != nil {
+		i.log.Error("Method Introspect-Token. Unable to introspect token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to introspect token, error info: %#v", err)
+	}
+
+	if !introspection.Active {
+		return &desc.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &desc.IntrospectTokenResponse{
+		Active:         true,
+		Subject:        strconv.FormatInt(introspection.UserID, 10),
+		Role:           introspection.Role,
+		Scopes:         introspection.Scopes,
+		ExpiresAt:      timestamppb.New(introspection.ExpiresAt),
+		IssuedAt:       timestamppb.New(introspection.IssuedAt),
+		ImpersonatedBy: introspection.ImpersonatedBy,
+		ClientId:       introspection.ClientID,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package access
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// IssueAPIKey выпускает API-ключ для вызовов от другого сервиса.
+//
+// Метод доступен только администраторам. Ключ возвращается только в ответе на этот запрос,
+// в БД хранится лишь его хеш.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с именем сервиса, ролью ключа и необязательным временем окончания действия.
+//
+// Возвращает:
+//   - *IssueAPIKeyResponse - ID и сам ключ.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) IssueAPIKey(ctx context.Context, req *desc.IssueAPIKeyRequest) (*desc.IssueAPIKeyResponse, error) {
+	i.log.Info("Method Issue-API-Key", zap.String("Service-account", req.ServiceAccount), zap.Int32("Role", req.Role))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Issue-API-Key. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Время окончания действия необязательно, без него ключ действует до отзыва
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		expiresAt.Time = req.ExpiresAt.AsTime()
+		expiresAt.Valid = true
+	}
+
+	id, apiKey, err := i.apiKeyService.Issue(ctx, &model.APIKeyToCreate{
+		ServiceAccount: req.ServiceAccount,
+		Role:           req.Role,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		i.log.Error("Method Issue-API-Key. Unable to issue API key", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to issue API key, error info: %#v", err)
+	}
+
+	return &desc.IssueAPIKeyResponse{
+		Id:  id,
+		Key: apiKey,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package access
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+)
+
+// ListAccessibleRoles возвращает правила доступа: для каждого метода с требованиями к роли -
+// список ролей, которым разрешен его вызов.
+//
+// Список отсортирован по имени метода.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//
+// Возвращает:
+//   - *ListAccessibleRolesResponse - структура со списком правил доступа.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ListAccessibleRoles(ctx context.Context, _ *emptypb.Empty) (*desc.ListAccessibleRolesResponse, error) {
+	i.log.Info("Method List-Accessible-Roles")
+
+	accessibleRoles, err := i.accessService.ListAccessibleRoles(ctx)
+	if err != nil {
+		i.log.Error("Method List-Accessible-Roles. Unable to get accessible roles", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to get accessible roles, error info: %#v", err)
+	}
+
+	endpoints := make([]*desc.EndpointRoles, 0, len(accessibleRoles))
+	for endpoint, roles := range accessibleRoles {
+		endpoints = append(endpoints, &desc.EndpointRoles{
+			EndpointAddress: endpoint,
+			Roles:           roles,
+		})
+	}
+	sort.Slice(endpoints, func(a, b int) bool {
+		return endpoints[a].EndpointAddress < endpoints[b].EndpointAddress
+	})
+
+	return &desc.ListAccessibleRolesResponse{
+		Endpoints: endpoints,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package access
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+)
+
+// ListAPIKeys возвращает все выпущенные API-ключи, включая отозванные и истекшие. Сами ключи не возвращаются.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//
+// Возвращает:
+//   - *ListAPIKeysResponse - ключи, начиная с самых новых.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ListAPIKeys(ctx context.Context, _ *emptypb.Empty) (*desc.ListAPIKeysResponse, error) {
+	i.log.Info("Method List-API-Keys")
+
+	keys, err := i.apiKeyService.List(ctx)
+	if err != nil {
+		i.log.Error("Method List-API-Keys. Unable to list API keys", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to list API keys, error info: %#v", err)
+	}
+
+	response := &desc.ListAPIKeysResponse{
+		ApiKeys: make([]*desc.APIKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.ApiKeys = append(response.ApiKeys, &desc.APIKey{
+			Id:             key.ID,
+			ServiceAccount: key.ServiceAccount,
+			Role:           key.Role,
+			CreatedAt:      timestamppb.New(key.CreatedAt),
+			ExpiresAt:      nullTimestamp(key.ExpiresAt),
+			LastUsedAt:     nullTimestamp(key.LastUsedAt),
+			RevokedAt:      nullTimestamp(key.RevokedAt),
+		})
+	}
+
+	return response, nil
+}
+
+// nullTimestamp возвращает nil, если время не задано (NULL).
+func nullTimestamp(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+
+	return timestamppb.New(t.Time)
+}
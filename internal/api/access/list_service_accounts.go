@@ -0,0 +1,51 @@
+package access
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+)
+
+// ListServiceAccounts возвращает все сервисные аккаунты, включая отключенные. Секреты не возвращаются.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//
+// Возвращает:
+//   - *ListServiceAccountsResponse - аккаунты, начиная с самых новых.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ListServiceAccounts(ctx context.Context, _ *emptypb.Empty) (*desc.ListServiceAccountsResponse, error) {
+	i.log.Info("Method List-Service-Accounts")
+
+	accounts, err := i.serviceAccountService.List(ctx)
+	if err != nil {
+		i.log.Error("Method List-Service-Accounts. Unable to list service accounts", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to list service accounts, error info: %#v", err)
+	}
+
+	response := &desc.ListServiceAccountsResponse{
+		ServiceAccounts: make([]*desc.ServiceAccount, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		response.ServiceAccounts = append(response.ServiceAccounts, &desc.ServiceAccount{
+			Id:              account.ID,
+			Name:            account.Name,
+			ClientId:        account.ClientID,
+			Role:            account.Role,
+			CreatedAt:       timestamppb.New(account.CreatedAt),
+			SecretRotatedAt: nullTimestamp(account.SecretRotatedAt),
+			LastTokenAt:     nullTimestamp(account.LastTokenAt),
+			DisabledAt:      nullTimestamp(account.DisabledAt),
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,47 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// RevokeAPIKey отзывает API-ключ. Запросы с отозванным ключом сразу перестают проходить проверку доступа.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID ключа.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если ключ отозван.
+//   - error - ошибка NotFound, если ключа нет или он уже отозван, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) RevokeAPIKey(ctx context.Context, req *desc.RevokeAPIKeyRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Revoke-API-Key", zap.Int64("API-key-id", req.Id))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Revoke-API-Key. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	err := i.apiKeyService.Revoke(ctx, req.Id)
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		i.log.Error("Method Revoke-API-Key. API key not found", zap.Int64("API-key-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "API key with id %d not found or already revoked", req.Id)
+	}
+	if err != nil {
+		i.log.Error("Method Revoke-API-Key. Unable to revoke API key", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to revoke API key, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
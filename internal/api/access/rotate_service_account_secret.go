@@ -0,0 +1,51 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// RotateServiceAccountSecret заменяет секрет сервисного аккаунта. Прежний секрет сразу перестает
+// действовать, выпущенные по нему токены действуют до истечения.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с ID аккаунта.
+//
+// Возвращает:
+//   - *ServiceAccountCredentials - ID, client_id и новый секрет аккаунта.
+//   - error - ошибка NotFound, если аккаунта нет или он отключен, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) RotateServiceAccountSecret(ctx context.Context, req *desc.RotateServiceAccountSecretRequest) (*desc.ServiceAccountCredentials, error) {
+	i.log.Info("Method Rotate-Service-Account-Secret", zap.Int64("Service-account-id", req.Id))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Rotate-Service-Account-Secret. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	credentials, err := i.serviceAccountService.RotateSecret(ctx, req.Id)
+	if errors.Is(err, service.ErrServiceAccountNotFound) {
+		i.log.Error("Method Rotate-Service-Account-Secret. Service account not found", zap.Int64("Service-account-id", req.Id))
+		return nil, status.Errorf(codes.NotFound, "Service account with id %d not found or disabled", req.Id)
+	}
+	if err != nil {
+		i.log.Error("Method Rotate-Service-Account-Secret. Unable to rotate secret", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to rotate service account secret, error info: %#v", err)
+	}
+
+	return &desc.ServiceAccountCredentials{
+		Id:           credentials.ID,
+		ClientId:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+	}, nil
+}
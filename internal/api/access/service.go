@@ -0,0 +1,33 @@
+package access
+
+import (
+	"go.uber.org/zap"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// Implementation - реализация gRPC-сервиса AccessV1.
+type Implementation struct {
+	desc.UnimplementedAccessV1Server
+
+	accessService         service.AccessService
+	apiKeyService         service.APIKeyService
+	serviceAccountService service.ServiceAccountService
+	log                   *zap.Logger
+}
+
+// NewImplementation создает реализацию gRPC-сервиса AccessV1.
+func NewImplementation(
+	accessService service.AccessService,
+	apiKeyService service.APIKeyService,
+	serviceAccountService service.ServiceAccountService,
+	log *zap.Logger,
+) *Implementation {
+	return &Implementation{
+		accessService:         accessService,
+		apiKeyService:         apiKeyService,
+		serviceAccountService: serviceAccountService,
+		log:                   log,
+	}
+}
@@ -0,0 +1,49 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/internal/redact"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// SetAccessibleRoles заменяет список ролей, которым разрешен вызов метода.
+//
+// Пустой список снимает требования к роли. Правила доступа к методам управления правилами
+// заданы в коде и не могут быть изменены.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с полным именем метода и новым списком ролей.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если правила изменены.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) SetAccessibleRoles(ctx context.Context, req *desc.SetAccessibleRolesRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Set-Accessible-Roles", redact.Proto("Input params", req))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Set-Accessible-Roles. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	err := i.accessService.SetAccessibleRoles(ctx, req.EndpointAddress, req.Roles)
+	if errors.Is(err, service.ErrProtectedEndpoint) {
+		i.log.Error("Method Set-Accessible-Roles. Endpoint is protected", zap.String("Endpoint", req.EndpointAddress))
+		return nil, status.Errorf(codes.FailedPrecondition, "Access policy of %s cannot be changed", req.EndpointAddress)
+	}
+	if err != nil {
+		i.log.Error("Method Set-Accessible-Roles. Unable to set accessible roles", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to set accessible roles, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
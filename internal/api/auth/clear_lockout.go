@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+)
+
+// ClearLockout снимает блокировку входа с email или IP-адреса и сбрасывает неудачные попытки.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с subject блокировки ("email:<email>" или "ip:<IP-адрес>").
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если блокировка снята или ее не было.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ClearLockout(ctx context.Context, req *desc.ClearLockoutRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Clear-Lockout", zap.String("Subject", req.Subject))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Clear-Lockout. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	if err := i.lockoutService.ClearLockout(ctx, req.Subject); err != nil {
+		i.log.Error("Method Clear-Lockout. Unable to clear lockout", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to clear lockout, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
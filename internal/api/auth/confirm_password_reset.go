@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// ConfirmPasswordReset заменяет пароль пользователя по токену из письма сброса пароля.
+//
+// Токен одноразовый: после успешного сброса он и остальные выпущенные пользователю токены
+// перестают действовать.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с токеном и новым паролем с подтверждением.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если пароль изменен.
+//   - error - ошибка InvalidArgument, если токен не найден, истек или уже использован либо
+//     новый пароль не соответствует политике паролей, или другая ошибка, если что-то пошло не так.
+func (i *Implementation) ConfirmPasswordReset(ctx context.Context, req *desc.ConfirmPasswordResetRequest) (*emptypb.Empty, error) {
+	// Токен и пароли не логируются
+	i.log.Info("Method Confirm-Password-Reset")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Confirm-Password-Reset. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Предупреждения не блокируют сброс пароля, если это не включено в конфиге
+	warnings := req.Warnings()
+	if len(warnings) > 0 {
+		if i.warningsAsErrors {
+			i.log.Error("Method Confirm-Password-Reset. Invalid input", zap.Strings("Warnings", warnings))
+			return nil, status.Error(codes.InvalidArgument, strings.Join(warnings, ". "))
+		}
+		i.log.Warn("Method Confirm-Password-Reset. Input has warnings", zap.Strings("Warnings", warnings))
+	}
+
+	err := i.passwordResetService.ConfirmReset(ctx, req.Token, req.NewPassword)
+	if errors.Is(err, service.ErrInvalidPasswordResetToken) {
+		i.log.Error("Method Confirm-Password-Reset. Invalid token")
+		return nil, status.Error(codes.InvalidArgument, "Password reset token is invalid, expired or already used")
+	}
+	var violationErr *passwordpolicy.ViolationError
+	if errors.As(err, &violationErr) {
+		i.log.Error("Method Confirm-Password-Reset. Password does not meet policy", zap.Strings("Violations", violationErr.Violations))
+		return nil, status.Error(codes.InvalidArgument, violationErr.Error())
+	}
+	if err != nil {
+		i.log.Error("Method Confirm-Password-Reset. Unable to reset password", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to reset password, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// ConfirmTOTP включает двухфакторную аутентификацию для пользователя, вошедшего в систему,
+// после проверки кода из приложения-аутентификатора и выдает коды восстановления.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном в метаданных.
+//   - req: запрос с кодом из приложения.
+//
+// Возвращает:
+//   - *ConfirmTOTPResponse - одноразовые коды восстановления для входа без приложения.
+//   - error - ошибка Unauthenticated, если access-токен не передан или недействителен,
+//     InvalidArgument, если код неверный, FailedPrecondition, если подключение не начато
+//     или уже подтверждено, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) ConfirmTOTP(ctx context.Context, req *desc.ConfirmTOTPRequest) (*desc.ConfirmTOTPResponse, error) {
+	userID, err := i.currentUserID(ctx)
+	if err != nil {
+		i.log.Error("Method Confirm-TOTP. Unauthenticated", zap.Error(err))
+		return nil, err
+	}
+
+	// Код не логируется
+	i.log.Info("Method Confirm-TOTP", zap.Int64("User-id", userID))
+
+	// Валидация запроса
+	if err = req.Validate(); err != nil {
+		i.log.Error("Method Confirm-TOTP. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	recoveryCodes, err := i.twoFactorService.ConfirmTOTP(ctx, userID, req.Code)
+	if errors.Is(err, service.ErrInvalidSecondFactor) {
+		i.log.Error("Method Confirm-TOTP. Invalid code", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.InvalidArgument, "Invalid TOTP code")
+	}
+	if errors.Is(err, service.ErrTOTPNotEnrolled) {
+		i.log.Error("Method Confirm-TOTP. TOTP enrollment is not started", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.FailedPrecondition, "TOTP enrollment is not started, call EnableTOTP first")
+	}
+	if errors.Is(err, service.ErrTOTPAlreadyEnabled) {
+		i.log.Error("Method Confirm-TOTP. TOTP is already enabled", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.FailedPrecondition, "TOTP is already enabled")
+	}
+	if err != nil {
+		i.log.Error("Method Confirm-TOTP. Unable to confirm TOTP", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to confirm TOTP, error info: %#v", err)
+	}
+
+	return &desc.ConfirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
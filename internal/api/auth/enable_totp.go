@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// EnableTOTP начинает подключение двухфакторной аутентификации для пользователя, вошедшего в систему.
+//
+// Возвращает секрет и URI для приложения-аутентификатора. Код при входе требуется только после
+// подтверждения через ConfirmTOTP.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном в метаданных.
+//
+// Возвращает:
+//   - *EnableTOTPResponse - секрет и URI otpauth://totp/... для QR-кода.
+//   - error - ошибка Unauthenticated, если access-токен не передан или недействителен,
+//     FailedPrecondition, если двухфакторная аутентификация уже включена,
+//     либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) EnableTOTP(ctx context.Context, _ *emptypb.Empty) (*desc.EnableTOTPResponse, error) {
+	userID, err := i.currentUserID(ctx)
+	if err != nil {
+		i.log.Error("Method Enable-TOTP. Unauthenticated", zap.Error(err))
+		return nil, err
+	}
+
+	// Секрет не логируется
+	i.log.Info("Method Enable-TOTP", zap.Int64("User-id", userID))
+
+	enrollment, err := i.twoFactorService.EnableTOTP(ctx, userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		i.log.Error("Method Enable-TOTP. User not found", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.Unauthenticated, "Invalid access token")
+	}
+	if errors.Is(err, service.ErrTOTPAlreadyEnabled) {
+		i.log.Error("Method Enable-TOTP. TOTP is already enabled", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.FailedPrecondition, "TOTP is already enabled")
+	}
+	if err != nil {
+		i.log.Error("Method Enable-TOTP. Unable to enable TOTP", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to enable TOTP, error info: %#v", err)
+	}
+
+	return &desc.EnableTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// GetAccessToken выпускает новый access-токен по действующему refresh-токену.
+//
+// Роль в новом токене берется из БД, поэтому изменение роли пользователя вступает в силу
+// при следующем обновлении access-токена.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с действующим refresh-токеном.
+//
+// Возвращает:
+//   - *GetAccessTokenResponse - структура с access-токеном и временем его истечения.
+//   - error - ошибка Unauthenticated, если токен недействителен либо пользователь удален.
+func (i *Implementation) GetAccessToken(ctx context.Context, req *desc.GetAccessTokenRequest) (*desc.GetAccessTokenResponse, error) {
+	// Токен не логируется
+	i.log.Info("Method Get-Access-Token")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Get-Access-Token. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	accessToken, err := i.authService.GetAccessToken(ctx, req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		i.log.Error("Method Get-Access-Token. Invalid refresh token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Invalid refresh token")
+	}
+	if err != nil {
+		i.log.Error("Method Get-Access-Token. Unable to generate access token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to generate access token, error info: %#v", err)
+	}
+
+	return &desc.GetAccessTokenResponse{
+		AccessToken:          accessToken.Value,
+		AccessTokenExpiresAt: timestamppb.New(accessToken.ExpiresAt),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// GetRefreshToken выпускает новый refresh-токен взамен действующего.
+//
+// Позволяет клиенту продлевать сессию без повторного ввода пароля.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с действующим refresh-токеном.
+//
+// Возвращает:
+//   - *GetRefreshTokenResponse - структура с новым refresh-токеном и временем его истечения.
+//   - error - ошибка Unauthenticated, если токен недействителен либо пользователь удален.
+func (i *Implementation) GetRefreshToken(ctx context.Context, req *desc.GetRefreshTokenRequest) (*desc.GetRefreshTokenResponse, error) {
+	// Токен не логируется
+	i.log.Info("Method Get-Refresh-Token")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Get-Refresh-Token. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	refreshToken, err := i.authService.GetRefreshToken(ctx, req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		i.log.Error("Method Get-Refresh-Token. Invalid refresh token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Invalid refresh token")
+	}
+	if err != nil {
+		i.log.Error("Method Get-Refresh-Token. Unable to generate refresh token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to generate refresh token, error info: %#v", err)
+	}
+
+	return &desc.GetRefreshTokenResponse{
+		RefreshToken:          refreshToken.Value,
+		RefreshTokenExpiresAt: timestamppb.New(refreshToken.ExpiresAt),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// GetServiceAccountToken выпускает access-токен сервисному аккаунту по client_id и секрету
+// (аналог OAuth 2.0 client credentials grant).
+//
+// Пароль и двухфакторная аутентификация для сервисных аккаунтов не используются, refresh-токен
+// не выпускается: по истечении access-токена сервис запрашивает новый.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с client_id и секретом сервисного аккаунта.
+//
+// Возвращает:
+//   - *GetServiceAccountTokenResponse - структура с access-токеном и временем его истечения.
+//   - error - ошибка Unauthenticated, если client_id или секрет неверны либо аккаунт отключен.
+func (i *Implementation) GetServiceAccountToken(ctx context.Context, req *desc.GetServiceAccountTokenRequest) (*desc.GetServiceAccountTokenResponse, error) {
+	// Секрет не логируется
+	i.log.Info("Method Get-Service-Account-Token", zap.String("Client-id", req.ClientId))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Get-Service-Account-Token. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	accessToken, err := i.serviceAccountService.IssueToken(ctx, req.ClientId, req.ClientSecret)
+	if errors.Is(err, service.ErrInvalidClientCredentials) {
+		i.log.Error("Method Get-Service-Account-Token. Invalid client credentials", zap.String("Client-id", req.ClientId))
+		return nil, status.Error(codes.Unauthenticated, "Invalid client credentials")
+	}
+	if err != nil {
+		i.log.Error("Method Get-Service-Account-Token. Unable to generate access token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to generate access token, error info: %#v", err)
+	}
+
+	return &desc.GetServiceAccountTokenResponse{
+		AccessToken:          accessToken.Value,
+		AccessTokenExpiresAt: timestamppb.New(accessToken.ExpiresAt),
+	}, nil
+}
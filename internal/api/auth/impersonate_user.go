@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// ImpersonateUser выпускает администратору короткоживущий access-токен от имени пользователя,
+// например для воспроизведения проблемы, на которую жалуется пользователь.
+//
+// Метод доступен только администраторам. Каждый выпуск и каждая отклоненная попытка логируются
+// с ID администратора, пользователя и адресом клиента; выпущенный токен - с его jti.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном администратора в метаданных.
+//   - req: запрос с ID пользователя.
+//
+// Возвращает:
+//   - *ImpersonateUserResponse - access-токен с claim "impersonated_by" и время его истечения.
+//   - error - ошибка Unauthenticated, если access-токен не передан или недействителен,
+//     NotFound, если пользователь не найден, FailedPrecondition, если администратор указал себя
+//     или уже действует от имени другого пользователя, либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) ImpersonateUser(ctx context.Context, req *desc.ImpersonateUserRequest) (*desc.ImpersonateUserResponse, error) {
+	auditFields := []zap.Field{
+		zap.Int64("Target-user-id", req.TargetUserId),
+		zap.String("Client-IP", identity.ClientIP(ctx)),
+	}
+	if client, ok := identity.ClientFromContext(ctx); ok {
+		auditFields = append(auditFields, zap.String("Client", client.Name()))
+	}
+
+	i.log.Info("Method Impersonate-User", auditFields...)
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Impersonate-User. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	accessToken, err := token.FromIncomingContext(ctx)
+	if err != nil {
+		i.log.Error("Method Impersonate-User. Access token not provided", auditFields...)
+		return nil, status.Error(codes.Unauthenticated, "Access token is required")
+	}
+
+	impersonation, err := i.authService.ImpersonateUser(ctx, accessToken, req.TargetUserId)
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		i.log.Error("Method Impersonate-User. Invalid access token", auditFields...)
+		return nil, status.Error(codes.Unauthenticated, "Invalid access token")
+	case errors.Is(err, service.ErrUserNotFound):
+		i.log.Error("Method Impersonate-User. User not found", auditFields...)
+		return nil, status.Errorf(codes.NotFound, "User with id %d not found", req.TargetUserId)
+	case errors.Is(err, service.ErrImpersonationNotAllowed):
+		i.log.Error("Method Impersonate-User. Impersonation not allowed", auditFields...)
+		return nil, status.Error(codes.FailedPrecondition, "Impersonation of yourself or from an impersonation token is not allowed")
+	case err != nil:
+		i.log.Error("Method Impersonate-User. Unable to impersonate user", append(auditFields, zap.Error(err))...)
+		return nil, status.Errorf(codes.Internal, "Unable to impersonate user, error info: %#v", err)
+	}
+
+	// Сам токен не логируется, только его jti
+	i.log.Warn("Method Impersonate-User. Impersonation token issued", append(auditFields,
+		zap.Int64("Admin-id", impersonation.AdminID),
+		zap.String("Token-id", impersonation.TokenID),
+		zap.Time("Expires-at", impersonation.ExpiresAt),
+	)...)
+
+	return &desc.ImpersonateUserResponse{
+		AccessToken:          impersonation.Value,
+		AccessTokenExpiresAt: timestamppb.New(impersonation.ExpiresAt),
+	}, nil
+}
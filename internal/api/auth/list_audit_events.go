@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// ListAuditEvents возвращает события безопасности из журнала аудита: входы, неудачные попытки
+// входа, смены паролей и ролей, удаления пользователей, отзывы токенов и API-ключей и выпуск
+// токенов от имени пользователя.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с необязательными ID пользователя и интервалом времени и параметрами пагинации.
+//
+// Возвращает:
+//   - *ListAuditEventsResponse - события, начиная с самых новых.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ListAuditEvents(ctx context.Context, req *desc.ListAuditEventsRequest) (*desc.ListAuditEventsResponse, error) {
+	i.log.Info("Method List-Audit-Events", zap.Int64("User-id", req.UserId), zap.Uint64("Limit", req.Limit), zap.Uint64("Offset", req.Offset))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method List-Audit-Events. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	filter := &model.AuditFilter{
+		UserID: req.UserId,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	if req.From != nil {
+		filter.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		filter.To = req.To.AsTime()
+	}
+
+	events, err := i.auditService.List(ctx, filter)
+	if err != nil {
+		i.log.Error("Method List-Audit-Events. Unable to list audit events", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to list audit events, error info: %#v", err)
+	}
+
+	response := &desc.ListAuditEventsResponse{
+		Events: make([]*desc.AuditEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, &desc.AuditEvent{
+			Id:        event.ID,
+			EventType: string(event.Type),
+			UserId:    event.UserID.Int64,
+			ActorId:   event.ActorID.Int64,
+			ClientIp:  event.ClientIP,
+			Details:   event.Details,
+			CreatedAt: timestamppb.New(event.CreatedAt),
+		})
+	}
+
+	return response, nil
+}
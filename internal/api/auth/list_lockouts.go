@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+)
+
+// ListLockouts возвращает действующие блокировки входа после неудачных попыток.
+//
+// Метод доступен только администраторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//
+// Возвращает:
+//   - *ListLockoutsResponse - блокировки email и IP-адресов, начиная с самых поздних.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) ListLockouts(ctx context.Context, _ *emptypb.Empty) (*desc.ListLockoutsResponse, error) {
+	i.log.Info("Method List-Lockouts")
+
+	lockouts, err := i.lockoutService.ListLockouts(ctx)
+	if err != nil {
+		i.log.Error("Method List-Lockouts. Unable to list lockouts", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to list lockouts, error info: %#v", err)
+	}
+
+	response := &desc.ListLockoutsResponse{
+		Lockouts: make([]*desc.Lockout, 0, len(lockouts)),
+	}
+	for _, lockout := range lockouts {
+		response.Lockouts = append(response.Lockouts, &desc.Lockout{
+			Subject:      lockout.Subject,
+			LockedUntil:  timestamppb.New(lockout.LockedUntil),
+			LockoutCount: int32(lockout.LockoutCount),
+		})
+	}
+
+	return response, nil
+}
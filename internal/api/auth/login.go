@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// Login проверяет email и пароль пользователя (и одноразовый код, если у пользователя включена
+// двухфакторная аутентификация) и выпускает для него access-токен и refresh-токен (JWT).
+//
+// Если пользователь с таким email не найден или пароль неверный, возвращается одна и та же
+// ошибка Unauthenticated, чтобы по ответу нельзя было узнать, зарегистрирован ли email.
+//
+// После нескольких неудачных попыток подряд вход с этим email или IP-адреса временно блокируется.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с email, паролем пользователя и, если нужно, кодом TOTP или кодом восстановления.
+//
+// Возвращает:
+//   - *LoginResponse - структура с access-токеном, refresh-токеном и временем их истечения.
+//   - error - ошибка Unauthenticated, если email, пароль или второй фактор неверный либо второй фактор не передан,
+//     ResourceExhausted, если вход временно заблокирован,
+//     FailedPrecondition, если email не подтвержден, а вход без подтверждения запрещен,
+//     либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) Login(ctx context.Context, req *desc.LoginRequest) (*desc.LoginResponse, error) {
+	// Пароль не логируется
+	i.log.Info("Method Login", zap.String("Email", req.Email))
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Login. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := i.authService.Login(ctx, req.Email, req.Password, identity.ClientIP(ctx), &model.SecondFactor{
+		TOTPCode:     req.TotpCode,
+		RecoveryCode: req.RecoveryCode,
+	})
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		i.log.Error("Method Login. Invalid email or password", zap.String("Email", req.Email))
+		return nil, status.Error(codes.Unauthenticated, "Invalid email or password")
+	}
+	if errors.Is(err, service.ErrLoginLocked) {
+		i.log.Error("Method Login. Login is locked", zap.String("Email", req.Email), zap.String("Client-IP", identity.ClientIP(ctx)))
+		return nil, status.Error(codes.ResourceExhausted, "Too many failed login attempts, try again later")
+	}
+	if errors.Is(err, service.ErrSecondFactorRequired) {
+		i.log.Error("Method Login. TOTP code or recovery code is required", zap.String("Email", req.Email))
+		return nil, status.Error(codes.Unauthenticated, "TOTP code or recovery code is required")
+	}
+	if errors.Is(err, service.ErrInvalidSecondFactor) {
+		i.log.Error("Method Login. Invalid TOTP code or recovery code", zap.String("Email", req.Email))
+		return nil, status.Error(codes.Unauthenticated, "Invalid TOTP code or recovery code")
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		i.log.Error("Method Login. Email is not verified", zap.String("Email", req.Email))
+		return nil, status.Error(codes.FailedPrecondition, "Email is not verified")
+	}
+	if err != nil {
+		i.log.Error("Method Login. Unable to login", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to login, error info: %#v", err)
+	}
+
+	return &desc.LoginResponse{
+		AccessToken:           accessToken.Value,
+		AccessTokenExpiresAt:  timestamppb.New(accessToken.ExpiresAt),
+		RefreshToken:          refreshToken.Value,
+		RefreshTokenExpiresAt: timestamppb.New(refreshToken.ExpiresAt),
+	}, nil
+}
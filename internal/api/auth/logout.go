@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// Logout завершает сессию пользователя: отзывает refresh-токен из запроса и access-токен
+// из метаданных запроса, если он передан.
+//
+// Отозванные токены перестают приниматься сразу, не дожидаясь истечения срока действия.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с метаданными запроса.
+//   - req: запрос с refresh-токеном.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если токены отозваны.
+//   - error - ошибка Unauthenticated, если refresh-токен недействителен или уже отозван.
+func (i *Implementation) Logout(ctx context.Context, req *desc.LogoutRequest) (*emptypb.Empty, error) {
+	// Токен не логируется
+	i.log.Info("Method Logout")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Logout. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	// Access-токен необязателен
+	accessToken, err := token.FromIncomingContext(ctx)
+	if err != nil && !errors.Is(err, token.ErrNoToken) {
+		i.log.Error("Method Logout. Invalid authorization header", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Invalid authorization header")
+	}
+
+	err = i.authService.Logout(ctx, req.RefreshToken, accessToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		i.log.Error("Method Logout. Invalid refresh token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Invalid refresh token")
+	}
+	if err != nil {
+		i.log.Error("Method Logout. Unable to revoke tokens", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to revoke tokens, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
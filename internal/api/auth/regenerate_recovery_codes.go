@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// RegenerateRecoveryCodes выдает пользователю, вошедшему в систему, новый набор кодов восстановления.
+//
+// Старые коды, в том числе неиспользованные, перестают действовать.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном в метаданных.
+//
+// Возвращает:
+//   - *RegenerateRecoveryCodesResponse - новые одноразовые коды восстановления.
+//   - error - ошибка Unauthenticated, если access-токен не передан или недействителен,
+//     FailedPrecondition, если двухфакторная аутентификация не включена,
+//     либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) RegenerateRecoveryCodes(ctx context.Context, _ *emptypb.Empty) (*desc.RegenerateRecoveryCodesResponse, error) {
+	userID, err := i.currentUserID(ctx)
+	if err != nil {
+		i.log.Error("Method Regenerate-Recovery-Codes. Unauthenticated", zap.Error(err))
+		return nil, err
+	}
+
+	// Коды не логируются
+	i.log.Info("Method Regenerate-Recovery-Codes", zap.Int64("User-id", userID))
+
+	recoveryCodes, err := i.twoFactorService.RegenerateRecoveryCodes(ctx, userID)
+	if errors.Is(err, service.ErrTOTPNotEnabled) {
+		i.log.Error("Method Regenerate-Recovery-Codes. TOTP is not enabled", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.FailedPrecondition, "TOTP is not enabled")
+	}
+	if err != nil {
+		i.log.Error("Method Regenerate-Recovery-Codes. Unable to regenerate recovery codes", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to regenerate recovery codes, error info: %#v", err)
+	}
+
+	return &desc.RegenerateRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
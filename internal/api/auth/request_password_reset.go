@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+)
+
+// RequestPasswordReset отправляет пользователю с указанным email письмо со ссылкой для сброса пароля.
+//
+// Ответ одинаковый для зарегистрированных и незарегистрированных email, чтобы по нему нельзя
+// было проверить, есть ли пользователь.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с email пользователя.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если запрос принят.
+//   - error - ошибка, если что-то пошло не так.
+func (i *Implementation) RequestPasswordReset(ctx context.Context, req *desc.RequestPasswordResetRequest) (*emptypb.Empty, error) {
+	i.log.Info("Method Request-Password-Reset")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Request-Password-Reset. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	if err := i.passwordResetService.RequestReset(ctx, req.Email); err != nil {
+		i.log.Error("Method Request-Password-Reset. Unable to request password reset", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to request password reset, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
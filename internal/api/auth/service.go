@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// Implementation - реализация gRPC-сервиса AuthV1.
+type Implementation struct {
+	desc.UnimplementedAuthV1Server
+
+	authService              service.AuthService
+	passwordResetService     service.PasswordResetService
+	emailVerificationService service.EmailVerificationService
+	twoFactorService         service.TwoFactorService
+	lockoutService           service.LockoutService
+	auditService             service.AuditService
+	serviceAccountService    service.ServiceAccountService
+	log                      *zap.Logger
+
+	// warningsAsErrors - true, если предупреждения валидации блокируют запрос.
+	warningsAsErrors bool
+}
+
+// NewImplementation создает реализацию gRPC-сервиса AuthV1.
+func NewImplementation(
+	authService service.AuthService,
+	passwordResetService service.PasswordResetService,
+	emailVerificationService service.EmailVerificationService,
+	twoFactorService service.TwoFactorService,
+	lockoutService service.LockoutService,
+	auditService service.AuditService,
+	serviceAccountService service.ServiceAccountService,
+	warningsAsErrors bool,
+	log *zap.Logger,
+) *Implementation {
+	return &Implementation{
+		authService:              authService,
+		passwordResetService:     passwordResetService,
+		emailVerificationService: emailVerificationService,
+		twoFactorService:         twoFactorService,
+		lockoutService:           lockoutService,
+		auditService:             auditService,
+		serviceAccountService:    serviceAccountService,
+		log:                      log,
+		warningsAsErrors:         warningsAsErrors,
+	}
+}
+
+// currentUserID возвращает ID пользователя, вошедшего в систему, по access-токену из метаданных запроса.
+//
+// Возвращает ошибку Unauthenticated, если токен не передан, недействителен или отозван.
+func (i *Implementation) currentUserID(ctx context.Context) (int64, error) {
+	accessToken, err := token.FromIncomingContext(ctx)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, "Access token is required")
+	}
+
+	userID, err := i.authService.VerifyAccessToken(ctx, accessToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return 0, status.Error(codes.Unauthenticated, "Invalid access token")
+	}
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "Unable to verify access token, error info: %#v", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// VerifyEmail подтверждает email пользователя по токену из письма, отправленного при регистрации.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции.
+//   - req: запрос с токеном подтверждения email.
+//
+// Возвращает:
+//   - *emptypb.Empty - пустая структура, если email подтвержден.
+//   - error - ошибка InvalidArgument, если токен не найден или истек,
+//     либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) VerifyEmail(ctx context.Context, req *desc.VerifyEmailRequest) (*emptypb.Empty, error) {
+	// Токен не логируется
+	i.log.Info("Method Verify-Email")
+
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Verify-Email. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	err := i.emailVerificationService.Verify(ctx, req.Token)
+	if errors.Is(err, service.ErrInvalidEmailVerificationToken) {
+		i.log.Error("Method Verify-Email. Invalid token")
+		return nil, status.Error(codes.InvalidArgument, "Email verification token is invalid or expired")
+	}
+	if err != nil {
+		i.log.Error("Method Verify-Email. Unable to verify email", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to verify email, error info: %#v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
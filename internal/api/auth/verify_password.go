@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// VerifyPassword повторно проверяет пароль пользователя, вошедшего в систему, перед чувствительной
+// операцией и выпускает короткоживущий токен повторной аутентификации.
+//
+// Новые access- и refresh-токены не выпускаются. Неверный пароль учитывается как неудачная
+// попытка входа, поэтому подбор пароля по украденному access-токену блокируется так же, как вход.
+//
+// Параметры:
+//   - ctx: контекст выполнения операции с access-токеном в метаданных.
+//   - req: запрос с паролем пользователя.
+//
+// Возвращает:
+//   - *VerifyPasswordResponse - токен повторной аутентификации и время его истечения.
+//   - error - ошибка Unauthenticated, если access-токен не передан или недействителен либо пароль
+//     неверный, ResourceExhausted, если проверка пароля временно заблокирована,
+//     либо другая ошибка, если что-то пошло не так.
+func (i *Implementation) VerifyPassword(ctx context.Context, req *desc.VerifyPasswordRequest) (*desc.VerifyPasswordResponse, error) {
+	// Валидация запроса
+	if err := req.Validate(); err != nil {
+		i.log.Error("Method Verify-Password. Invalid input", zap.Error(err))
+		return nil, err
+	}
+
+	userID, err := i.currentUserID(ctx)
+	if err != nil {
+		i.log.Error("Method Verify-Password. Unauthenticated", zap.Error(err))
+		return nil, err
+	}
+
+	// Пароль не логируется
+	i.log.Info("Method Verify-Password", zap.Int64("User-id", userID))
+
+	reauthToken, err := i.authService.VerifyPassword(ctx, userID, req.Password, identity.ClientIP(ctx))
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		i.log.Error("Method Verify-Password. Invalid password", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.Unauthenticated, "Invalid password")
+	}
+	if errors.Is(err, service.ErrLoginLocked) {
+		i.log.Error("Method Verify-Password. Password verification is locked", zap.Int64("User-id", userID),
+			zap.String("Client-IP", identity.ClientIP(ctx)))
+		return nil, status.Error(codes.ResourceExhausted, "Too many failed attempts, try again later")
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		i.log.Error("Method Verify-Password. User not found", zap.Int64("User-id", userID))
+		return nil, status.Error(codes.Unauthenticated, "Invalid access token")
+	}
+	if err != nil {
+		i.log.Error("Method Verify-Password. Unable to verify password", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Unable to verify password, error info: %#v", err)
+	}
+
+	return &desc.VerifyPasswordResponse{
+		ReauthToken:          reauthToken.Value,
+		ReauthTokenExpiresAt: timestamppb.New(reauthToken.ExpiresAt),
+	}, nil
+}
@@ -0,0 +1,122 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// argon2idPrefix - тег алгоритма, с которого начинаются argon2id-хеши.
+	argon2idPrefix = "$argon2id$"
+
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// Argon2idParams - параметры алгоритма argon2id.
+type Argon2idParams struct {
+	// Memory - объем памяти в КиБ.
+	Memory uint32
+	// Iterations - количество проходов.
+	Iterations uint32
+	// Parallelism - количество потоков.
+	Parallelism uint8
+}
+
+// argon2idHasher - алгоритм argon2id.
+//
+// Хеш хранится в формате PHC: "$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>",
+// соль и ключ закодированы в base64 без выравнивания.
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// newArgon2id создает алгоритм argon2id с параметрами params.
+func newArgon2id(params Argon2idParams) (*argon2idHasher, error) {
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, errors.New("argon2id memory, iterations and parallelism must be positive")
+	}
+
+	return &argon2idHasher{params: params}, nil
+}
+
+// Hash вычисляет argon2id-хеш пароля со случайной солью.
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("unable to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify проверяет пароль по argon2id-хешу с параметрами, записанными в самом хеше.
+func (h *argon2idHasher) Verify(encodedHash, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+// NeedsRehash возвращает true, если параметры хеша отличаются от текущих.
+func (h *argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, _, _, err := decodeArgon2id(encodedHash)
+	return err != nil || params != h.params
+}
+
+// Recognizes возвращает true, если хеш начинается с тега argon2id.
+func (h *argon2idHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, argon2idPrefix)
+}
+
+// decodeArgon2id разбирает argon2id-хеш в формате PHC.
+func decodeArgon2id(encodedHash string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", соль, ключ
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %q", parts[2])
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id params %q: %w", parts[3], err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id key")
+	}
+
+	return params, salt, key, nil
+}
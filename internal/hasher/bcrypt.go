@@ -0,0 +1,66 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptPrefixes - теги версий, с которых начинаются bcrypt-хеши.
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// bcryptHasher - алгоритм bcrypt.
+type bcryptHasher struct {
+	cost int
+}
+
+// newBcrypt создает алгоритм bcrypt со стоимостью cost.
+func newBcrypt(cost int) (*bcryptHasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	return &bcryptHasher{cost: cost}, nil
+}
+
+// Hash вычисляет bcrypt-хеш пароля.
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash password with bcrypt: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// Verify проверяет пароль по bcrypt-хешу.
+func (h *bcryptHasher) Verify(encodedHash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to compare bcrypt hash: %w", err)
+	}
+
+	return true, nil
+}
+
+// NeedsRehash возвращает true, если стоимость хеша отличается от текущей.
+func (h *bcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != h.cost
+}
+
+// Recognizes возвращает true, если хеш начинается с тега версии bcrypt.
+func (h *bcryptHasher) Recognizes(encodedHash string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(encodedHash, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
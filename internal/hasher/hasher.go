@@ -0,0 +1,107 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Названия поддерживаемых алгоритмов хеширования паролей.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrUnknownHashFormat - хеш не соответствует ни одному из поддерживаемых форматов.
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher - интерфейс хеширования паролей.
+//
+// Хеш хранится в виде строки, которая начинается с тега алгоритма и содержит его параметры
+// ("$2a$10$..." для bcrypt, "$argon2id$v=19$m=65536,t=3,p=2$..." для argon2id). Поэтому хеши,
+// вычисленные разными алгоритмами или с разными параметрами, можно хранить в одном столбце.
+//
+// Методы:
+//   - Hash: вычисляет хеш пароля.
+//   - Verify: возвращает true, если пароль соответствует хешу.
+//   - NeedsRehash: возвращает true, если хеш вычислен другим алгоритмом или с другими
+//     параметрами, чем используются сейчас, и его нужно пересчитать.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(encodedHash, password string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// algorithm - реализация конкретного алгоритма хеширования.
+type algorithm interface {
+	Hasher
+
+	// Recognizes возвращает true, если хеш вычислен этим алгоритмом.
+	Recognizes(encodedHash string) bool
+}
+
+// hasher - реализация Hasher, которая вычисляет хеши текущим алгоритмом, а проверяет
+// хеши любого из поддерживаемых алгоритмов.
+type hasher struct {
+	current    algorithm
+	algorithms []algorithm
+}
+
+// New создает Hasher, вычисляющий новые хеши алгоритмом name.
+//
+// Параметры:
+//   - name: название алгоритма (AlgorithmBcrypt или AlgorithmArgon2id).
+//   - bcryptCost: стоимость bcrypt.
+//   - argon2idParams: параметры argon2id.
+//
+// Возвращает:
+//   - Hasher: созданный объект.
+//   - error: ошибка, если алгоритм неизвестен или параметры некорректны.
+func New(name string, bcryptCost int, argon2idParams Argon2idParams) (Hasher, error) {
+	bcryptHasher, err := newBcrypt(bcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	argon2idHasher, err := newArgon2id(argon2idParams)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hasher{
+		algorithms: []algorithm{bcryptHasher, argon2idHasher},
+	}
+
+	switch strings.ToLower(name) {
+	case AlgorithmBcrypt:
+		h.current = bcryptHasher
+	case AlgorithmArgon2id:
+		h.current = argon2idHasher
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", name)
+	}
+
+	return h, nil
+}
+
+// Hash вычисляет хеш пароля текущим алгоритмом.
+func (h *hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify проверяет пароль по хешу, вычисленному любым из поддерживаемых алгоритмов.
+func (h *hasher) Verify(encodedHash, password string) (bool, error) {
+	for _, a := range h.algorithms {
+		if a.Recognizes(encodedHash) {
+			return a.Verify(encodedHash, password)
+		}
+	}
+
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash возвращает true, если хеш вычислен не текущим алгоритмом либо
+// с устаревшими параметрами.
+func (h *hasher) NeedsRehash(encodedHash string) bool {
+	return !h.current.Recognizes(encodedHash) || h.current.NeedsRehash(encodedHash)
+}
@@ -0,0 +1,168 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testArgon2idParams - параметры argon2id, при которых тесты выполняются быстро.
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+// newTestHasher создает хешер с алгоритмом name и быстрыми параметрами.
+func newTestHasher(t *testing.T, name string, bcryptCost int, argon2idParams Argon2idParams) Hasher {
+	t.Helper()
+
+	h, err := New(name, bcryptCost, argon2idParams)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", name, err)
+	}
+
+	return h
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name           string
+		algorithm      string
+		bcryptCost     int
+		argon2idParams Argon2idParams
+		wantErr        bool
+	}{
+		{name: "bcrypt", algorithm: "bcrypt", bcryptCost: 4, argon2idParams: testArgon2idParams},
+		{name: "argon2id in upper case", algorithm: "ARGON2ID", bcryptCost: 4, argon2idParams: testArgon2idParams},
+		{name: "unknown algorithm", algorithm: "md5", bcryptCost: 4, argon2idParams: testArgon2idParams, wantErr: true},
+		{name: "bcrypt cost too low", algorithm: "bcrypt", bcryptCost: 3, argon2idParams: testArgon2idParams, wantErr: true},
+		{name: "bcrypt cost too high", algorithm: "bcrypt", bcryptCost: 32, argon2idParams: testArgon2idParams, wantErr: true},
+		{name: "argon2id without memory", algorithm: "argon2id", bcryptCost: 4, argon2idParams: Argon2idParams{Iterations: 1, Parallelism: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.algorithm, tt.bcryptCost, tt.argon2idParams)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHashVerify(t *testing.T) {
+	tests := []struct {
+		algorithm  string
+		wantPrefix string
+	}{
+		{algorithm: AlgorithmBcrypt, wantPrefix: "$2a$04$"},
+		{algorithm: AlgorithmArgon2id, wantPrefix: "$argon2id$v=19$m=64,t=1,p=1$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			h := newTestHasher(t, tt.algorithm, 4, testArgon2idParams)
+
+			hash, err := h.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("Hash() = %q, want prefix %q", hash, tt.wantPrefix)
+			}
+
+			otherHash, err := h.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if otherHash == hash {
+				t.Error("Hash() returned equal hashes for one password: salt is not random")
+			}
+
+			if ok, err := h.Verify(hash, "correct horse"); !ok || err != nil {
+				t.Errorf("Verify() with correct password = %t, %v, want true", ok, err)
+			}
+			if ok, err := h.Verify(hash, "wrong horse"); ok || err != nil {
+				t.Errorf("Verify() with wrong password = %t, %v, want false", ok, err)
+			}
+			if h.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for hash with current params")
+			}
+		})
+	}
+}
+
+func TestVerifyOtherAlgorithm(t *testing.T) {
+	bcryptHasher := newTestHasher(t, AlgorithmBcrypt, 4, testArgon2idParams)
+	argon2idHasher := newTestHasher(t, AlgorithmArgon2id, 4, testArgon2idParams)
+
+	bcryptHash, err := bcryptHasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	// Хеши прежнего алгоритма проверяются, но требуют перехеширования
+	if ok, err := argon2idHasher.Verify(bcryptHash, "correct horse"); !ok || err != nil {
+		t.Errorf("Verify() of bcrypt hash = %t, %v, want true", ok, err)
+	}
+	if !argon2idHasher.NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash() of bcrypt hash = false, want true")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	oldBcryptHash, err := newTestHasher(t, AlgorithmBcrypt, 5, testArgon2idParams).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	oldArgon2idHash, err := newTestHasher(t, AlgorithmArgon2id, 4, Argon2idParams{Memory: 32, Iterations: 1, Parallelism: 1}).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		hash      string
+		want      bool
+	}{
+		{name: "bcrypt with other cost", algorithm: AlgorithmBcrypt, hash: oldBcryptHash, want: true},
+		{name: "argon2id with other params", algorithm: AlgorithmArgon2id, hash: oldArgon2idHash, want: true},
+		{name: "unknown format", algorithm: AlgorithmBcrypt, hash: "plain-text-password", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHasher(t, tt.algorithm, 4, testArgon2idParams)
+
+			if got := h.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	h := newTestHasher(t, AlgorithmArgon2id, 4, testArgon2idParams)
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr error
+	}{
+		{name: "unknown format", hash: "plain-text-password", wantErr: ErrUnknownHashFormat},
+		{name: "argon2id without key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{name: "argon2id with other version", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5"},
+		{name: "argon2id with invalid params", hash: "$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5"},
+		{name: "truncated bcrypt", hash: "$2a$04$short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := h.Verify(tt.hash, "correct horse")
+			if ok || err == nil {
+				t.Fatalf("Verify() = %t, %v, want error", ok, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
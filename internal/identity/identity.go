@@ -0,0 +1,115 @@
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/anton0701/auth/internal/model"
+)
+
+// callerKey - ключ контекста, по которому хранится вызывающий пользователь или сервис.
+type callerKey struct{}
+
+// Client - сервис, подключившийся к серверу по взаимному TLS, по данным его сертификата.
+type Client struct {
+	// CommonName - CN из subject сертификата.
+	CommonName string
+	// DNSNames - DNS-имена из subjectAltName.
+	DNSNames []string
+	// URIs - URI из subjectAltName, например SPIFFE ID ("spiffe://cluster.local/ns/default/sa/gateway").
+	URIs []string
+	// SerialNumber - серийный номер сертификата в десятичной записи.
+	SerialNumber string
+	// Issuer - subject УЦ, выпустившего сертификат.
+	Issuer string
+}
+
+// Name возвращает имя клиента для логов: первый URI, иначе CN, иначе первое DNS-имя.
+func (c *Client) Name() string {
+	switch {
+	case len(c.URIs) > 0:
+		return c.URIs[0]
+	case len(c.CommonName) > 0:
+		return c.CommonName
+	case len(c.DNSNames) > 0:
+		return c.DNSNames[0]
+	default:
+		return ""
+	}
+}
+
+// ClientFromContext возвращает клиента, от которого пришел запрос, по проверенному сертификату
+// TLS-соединения.
+//
+// Данные берутся из соединения, а не из метаданных запроса, поэтому клиент не может их подменить.
+//
+// Возвращает:
+//   - *Client: данные сертификата клиента.
+//   - bool: false, если соединение без TLS или клиент не предъявил проверенный сертификат.
+func ClientFromContext(ctx context.Context) (*Client, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return clientFromCertificate(tlsInfo.State.VerifiedChains[0][0]), true
+}
+
+// clientFromCertificate возвращает данные клиента из его сертификата.
+func clientFromCertificate(cert *x509.Certificate) *Client {
+	client := &Client{
+		CommonName:   cert.Subject.CommonName,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Issuer:       cert.Issuer.String(),
+	}
+	for _, uri := range cert.URIs {
+		client.URIs = append(client.URIs, uri.String())
+	}
+
+	return client
+}
+
+// ClientIP возвращает IP-адрес клиента, от которого пришел запрос, либо пустую строку, если его
+// не удалось определить.
+//
+// Используется адрес TCP-соединения: если сервер стоит за прокси или балансировщиком, это адрес прокси.
+func ClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// WithCaller возвращает контекст с вызывающим пользователем или сервисом caller.
+//
+// Вызывается проверкой доступа после проверки access-токена или API-ключа.
+func WithCaller(ctx context.Context, caller *model.Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext возвращает вызывающего пользователя или сервис, сохраненного WithCaller.
+//
+// Возвращает:
+//   - *model.Caller: данные проверенного access-токена или API-ключа.
+//   - bool: false, если метод вызван без токена и ключа (публичный метод).
+func CallerFromContext(ctx context.Context) (*model.Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*model.Caller)
+	return caller, ok && caller != nil
+}
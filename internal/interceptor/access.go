@@ -0,0 +1,148 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// errNoCredentials - в метаданных запроса нет access-токена или API-ключа.
+var errNoCredentials = errors.New("access token or api key is not provided")
+
+// AccessInterceptor - проверка доступа к методам сервера по роли пользователя.
+//
+// Требования к ролям задаются в сервисе проверки доступа, а не в обработчиках. Без access-токена
+// можно вызывать только публичные методы (вход, регистрация и т.п.), остальные методы требуют
+// действующего токена, даже если для них не заданы требования к роли.
+//
+// Другие сервисы вместо access-токена могут передать API-ключ в метаданных "x-api-key".
+//
+// Проверенный пользователь или сервис передается обработчику в контексте (identity.CallerFromContext).
+type AccessInterceptor struct {
+	accessService service.AccessService
+	log           *zap.Logger
+}
+
+// NewAccessInterceptor создает проверку доступа, использующую accessService.
+func NewAccessInterceptor(accessService service.AccessService, log *zap.Logger) *AccessInterceptor {
+	return &AccessInterceptor{
+		accessService: accessService,
+		log:           log,
+	}
+}
+
+// Unary - interceptor для unary-методов.
+func (i *AccessInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := i.check(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// Stream - interceptor для потоковых методов.
+func (i *AccessInterceptor) Stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.check(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &callerStream{ServerStream: stream, ctx: ctx})
+}
+
+// callerStream - поток с контекстом, в котором сохранен вызывающий.
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока с вызывающим.
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+// check проверяет, может ли вызывающий пользователь или сервис обращаться к методу fullMethod,
+// и возвращает контекст с вызывающим.
+//
+// Для публичного метода токен или API-ключ необязателен: если он передан и действителен, вызывающий
+// сохраняется в контексте (например, чтобы администратор мог создать пользователя с любой ролью),
+// иначе метод вызывается анонимно.
+//
+// Возвращает:
+//   - error с кодом Unauthenticated, если токен или API-ключ не передан либо недействителен.
+//   - error с кодом PermissionDenied, если роли пользователя или ключа недостаточно.
+//   - nil, если метод публичный или доступ разрешен.
+func (i *AccessInterceptor) check(ctx context.Context, fullMethod string) (context.Context, error) {
+	public, err := i.accessService.IsPublic(ctx, fullMethod)
+	if err != nil {
+		i.log.Error("Access interceptor. Unable to get endpoint policy", zap.String("Endpoint", fullMethod), clientField(ctx), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Unable to check access")
+	}
+
+	caller, err := i.authorize(ctx, fullMethod)
+	if public {
+		if err == nil {
+			ctx = identity.WithCaller(ctx, caller)
+		}
+		return ctx, nil
+	}
+
+	switch {
+	case errors.Is(err, errNoCredentials):
+		i.log.Error("Access interceptor. Access token not provided", zap.String("Endpoint", fullMethod), clientField(ctx))
+		return nil, status.Error(codes.Unauthenticated, "Access token is not provided")
+	case errors.Is(err, service.ErrInvalidToken):
+		i.log.Error("Access interceptor. Invalid access token", zap.String("Endpoint", fullMethod), clientField(ctx))
+		return nil, status.Error(codes.Unauthenticated, "Invalid access token")
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		i.log.Error("Access interceptor. Invalid API key", zap.String("Endpoint", fullMethod), clientField(ctx))
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	case errors.Is(err, service.ErrAccessDenied):
+		i.log.Error("Access interceptor. Access denied", zap.String("Endpoint", fullMethod), clientField(ctx))
+		return nil, status.Error(codes.PermissionDenied, "Access denied")
+	case err != nil:
+		i.log.Error("Access interceptor. Unable to check access", zap.String("Endpoint", fullMethod), clientField(ctx), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Unable to check access")
+	}
+
+	return identity.WithCaller(ctx, caller), nil
+}
+
+// authorize проверяет API-ключ или access-токен из метаданных запроса и доступ к методу fullMethod
+// и возвращает вызывающего. Если передан API-ключ, access-токен не проверяется.
+//
+// Возвращает errNoCredentials, если не переданы ни ключ, ни токен, либо заголовок с токеном
+// имеет неверный формат.
+func (i *AccessInterceptor) authorize(ctx context.Context, fullMethod string) (*model.Caller, error) {
+	if apiKey, err := token.APIKeyFromIncomingContext(ctx); err == nil {
+		return i.accessService.CheckAPIKey(ctx, apiKey, fullMethod)
+	}
+
+	accessToken, err := token.FromIncomingContext(ctx)
+	if err != nil {
+		return nil, errNoCredentials
+	}
+
+	return i.accessService.Check(ctx, accessToken, fullMethod)
+}
+
+// clientField возвращает поле лога с именем клиента из сертификата взаимного TLS,
+// если соединение установлено с ним.
+func clientField(ctx context.Context) zap.Field {
+	client, ok := identity.ClientFromContext(ctx)
+	if !ok {
+		return zap.Skip()
+	}
+
+	return zap.String("Client", client.Name())
+}
@@ -0,0 +1,201 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+)
+
+const (
+	publicMethod    = "/auth_v1.AuthV1/Login"
+	protectedMethod = "/user_v1.UserV1/GetUserInfo"
+
+	validToken  = "valid-token"
+	adminToken  = "admin-token"
+	validAPIKey = "valid-api-key"
+)
+
+// fakeAccessService - сервис проверки доступа, в котором публичен только publicMethod,
+// а protectedMethod доступен только администратору.
+type fakeAccessService struct {
+	service.AccessService
+}
+
+func (f *fakeAccessService) IsPublic(_ context.Context, endpoint string) (bool, error) {
+	return endpoint == publicMethod, nil
+}
+
+func (f *fakeAccessService) Check(_ context.Context, accessToken, endpoint string) (*model.Caller, error) {
+	switch accessToken {
+	case adminToken:
+		return &model.Caller{UserID: 1, Role: 2}, nil
+	case validToken:
+		if endpoint == protectedMethod {
+			return nil, service.ErrAccessDenied
+		}
+		return &model.Caller{UserID: 2, Role: 1}, nil
+	default:
+		return nil, service.ErrInvalidToken
+	}
+}
+
+func (f *fakeAccessService) CheckAPIKey(_ context.Context, apiKey, _ string) (*model.Caller, error) {
+	if apiKey != validAPIKey {
+		return nil, service.ErrInvalidAPIKey
+	}
+
+	return &model.Caller{Role: 2, APIKeyID: 7}, nil
+}
+
+func TestAccessInterceptorUnary(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		metadata   []string
+		wantCode   codes.Code
+		wantCaller *model.Caller
+	}{
+		{
+			name:     "public method without token",
+			method:   publicMethod,
+			wantCode: codes.OK,
+		},
+		{
+			name:       "public method with valid token keeps caller",
+			method:     publicMethod,
+			metadata:   []string{"authorization", "Bearer " + adminToken},
+			wantCode:   codes.OK,
+			wantCaller: &model.Caller{UserID: 1, Role: 2},
+		},
+		{
+			name:     "public method with invalid token is anonymous",
+			method:   publicMethod,
+			metadata: []string{"authorization", "Bearer expired"},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "unlisted method without token is denied",
+			method:   "/user_v1.UserV1/ListUsers",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:       "unlisted method with valid token",
+			method:     "/user_v1.UserV1/ListUsers",
+			metadata:   []string{"authorization", "Bearer " + validToken},
+			wantCode:   codes.OK,
+			wantCaller: &model.Caller{UserID: 2, Role: 1},
+		},
+		{
+			name:     "malformed authorization header",
+			method:   protectedMethod,
+			metadata: []string{"authorization", validToken},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "invalid token",
+			method:   protectedMethod,
+			metadata: []string{"authorization", "Bearer expired"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "insufficient role",
+			method:   protectedMethod,
+			metadata: []string{"authorization", "Bearer " + validToken},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:       "sufficient role",
+			method:     protectedMethod,
+			metadata:   []string{"authorization", "Bearer " + adminToken},
+			wantCode:   codes.OK,
+			wantCaller: &model.Caller{UserID: 1, Role: 2},
+		},
+		{
+			name:       "api key",
+			method:     protectedMethod,
+			metadata:   []string{"x-api-key", validAPIKey},
+			wantCode:   codes.OK,
+			wantCaller: &model.Caller{Role: 2, APIKeyID: 7},
+		},
+		{
+			name:     "invalid api key",
+			method:   protectedMethod,
+			metadata: []string{"x-api-key", "revoked"},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	interceptor := NewAccessInterceptor(&fakeAccessService{}, zap.NewNop())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(tt.metadata) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.metadata...))
+			}
+
+			var (
+				called bool
+				caller *model.Caller
+			)
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				called = true
+				caller, _ = identity.CallerFromContext(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptor.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s (err: %v)", code, tt.wantCode, err)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Fatalf("handler called = %t, want %t", called, tt.wantCode == codes.OK)
+			}
+
+			switch {
+			case tt.wantCaller == nil && caller != nil:
+				t.Errorf("caller = %+v, want none", caller)
+			case tt.wantCaller != nil && (caller == nil || *caller != *tt.wantCaller):
+				t.Errorf("caller = %+v, want %+v", caller, tt.wantCaller)
+			}
+		})
+	}
+}
+
+// fakeServerStream - поток с заданным контекстом.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAccessInterceptorStreamPassesCaller(t *testing.T) {
+	interceptor := NewAccessInterceptor(&fakeAccessService{}, zap.NewNop())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+adminToken))
+
+	var caller *model.Caller
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		caller, _ = identity.CallerFromContext(stream.Context())
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/user_v1.UserV1/WatchUserEvents"}
+	if err := interceptor.Stream(nil, &fakeServerStream{ctx: ctx}, info, handler); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if caller == nil || caller.UserID != 1 {
+		t.Errorf("caller = %+v, want user 1", caller)
+	}
+}
@@ -0,0 +1,84 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PlannedMethod - метод, который объявлен в proto-файле, но еще не реализован.
+type PlannedMethod struct {
+	// Version - версия сервера, в которой метод планируется реализовать, например "v1.5".
+	// Пустая строка - версия еще не определена.
+	Version string
+	// Replacement - полное имя метода, которым можно пользоваться до реализации. Необязательное.
+	Replacement string
+}
+
+// PlannedMethods - реестр заглушек: методы, объявленные в API до реализации, по полному имени
+// ("/user_v1.UserV1/GetUserInfo").
+//
+// Метод добавляется в реестр вместе с объявлением в proto-файле и удаляется из него вместе
+// с реализацией. Пока метод в реестре, его обработчик не вызывается.
+var PlannedMethods = map[string]PlannedMethod{}
+
+// UnimplementedInterceptor - ответ на вызов нереализованного метода.
+//
+// Вместо стандартной ошибки Unimplemented сгенерированного кода клиент получает сообщение
+// с планируемой версией реализации и методом, которым можно пользоваться до нее.
+type UnimplementedInterceptor struct {
+	planned map[string]PlannedMethod
+}
+
+// NewUnimplementedInterceptor создает ответ на вызов методов из реестра planned.
+func NewUnimplementedInterceptor(planned map[string]PlannedMethod) *UnimplementedInterceptor {
+	return &UnimplementedInterceptor{
+		planned: planned,
+	}
+}
+
+// Unary - interceptor для unary-методов.
+func (i *UnimplementedInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := i.check(info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// Stream - interceptor для потоковых методов.
+func (i *UnimplementedInterceptor) Stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := i.check(info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// check возвращает ошибку Unimplemented, если метод fullMethod есть в реестре заглушек.
+func (i *UnimplementedInterceptor) check(fullMethod string) error {
+	method, ok := i.planned[fullMethod]
+	if !ok {
+		return nil
+	}
+
+	return status.Error(codes.Unimplemented, method.message(fullMethod))
+}
+
+// message возвращает сообщение об ошибке для вызова нереализованного метода fullMethod.
+func (m PlannedMethod) message(fullMethod string) string {
+	version := "a future version"
+	if len(m.Version) > 0 {
+		version = m.Version
+	}
+
+	msg := fmt.Sprintf("Method %s is not implemented yet, planned for %s", fullMethod, version)
+	if len(m.Replacement) > 0 {
+		msg += fmt.Sprintf(", use %s until then", m.Replacement)
+	}
+
+	return msg
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const plannedMethod = "/user_v1.UserV1/ExportUsers"
+
+func TestUnimplementedInterceptorUnary(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		planned     PlannedMethod
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "planned method",
+			method:      plannedMethod,
+			planned:     PlannedMethod{Version: "v1.5", Replacement: "/user_v1.UserV1/ListUsers"},
+			wantCode:    codes.Unimplemented,
+			wantMessage: "Method /user_v1.UserV1/ExportUsers is not implemented yet, planned for v1.5, use /user_v1.UserV1/ListUsers until then",
+		},
+		{
+			name:        "planned method without version",
+			method:      plannedMethod,
+			wantCode:    codes.Unimplemented,
+			wantMessage: "Method /user_v1.UserV1/ExportUsers is not implemented yet, planned for a future version",
+		},
+		{
+			name:     "implemented method",
+			method:   protectedMethod,
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewUnimplementedInterceptor(map[string]PlannedMethod{plannedMethod: tt.planned})
+
+			var called bool
+			handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+				called = true
+				return nil, nil
+			}
+
+			_, err := interceptor.Unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("Unary() code = %s, want %s", st.Code(), tt.wantCode)
+			}
+			if tt.wantCode != codes.OK && st.Message() != tt.wantMessage {
+				t.Errorf("Unary() message = %q, want %q", st.Message(), tt.wantMessage)
+			}
+			// Обработчик заглушки не вызывается
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %t, want %t", called, tt.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
+func TestUnimplementedInterceptorStream(t *testing.T) {
+	interceptor := NewUnimplementedInterceptor(map[string]PlannedMethod{plannedMethod: {Version: "v1.5"}})
+
+	handler := func(_ interface{}, _ grpc.ServerStream) error {
+		t.Error("handler of planned method called")
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: plannedMethod}
+	err := interceptor.Stream(nil, &fakeServerStream{ctx: context.Background()}, info, handler)
+	if st := status.Convert(err); st.Code() != codes.Unimplemented || st.Message() != "Method /user_v1.UserV1/ExportUsers is not implemented yet, planned for v1.5" {
+		t.Errorf("Stream() error = %v, want Unimplemented with planned version", err)
+	}
+}
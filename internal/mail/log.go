@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// logSender - Sender, который не отправляет письма, а пишет их в лог.
+//
+// Предназначен для локальной разработки: письмо со ссылкой можно взять из лога сервера.
+// Тело письма содержит секреты (токен сброса пароля), поэтому в проде не используется.
+type logSender struct {
+	log *zap.Logger
+}
+
+// newLogSender создает Sender, пишущий письма в лог log.
+func newLogSender(log *zap.Logger) *logSender {
+	return &logSender{log: log}
+}
+
+// Send пишет письмо msg в лог.
+func (s *logSender) Send(_ context.Context, msg *Message) error {
+	s.log.Info("Mail is not sent, mail sender is log",
+		zap.String("To", msg.To),
+		zap.String("Subject", msg.Subject),
+		zap.String("Body", msg.Body),
+	)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Названия поддерживаемых способов отправки писем.
+const (
+	SenderLog  = "log"
+	SenderSMTP = "smtp"
+)
+
+// Message - письмо в виде простого текста.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender - интерфейс отправки писем пользователям.
+//
+// Методы:
+//   - Send: отправляет письмо msg.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New создает Sender, отправляющий письма способом name.
+//
+// Параметры:
+//   - name: способ отправки (SenderLog или SenderSMTP).
+//   - smtpParams: параметры SMTP-сервера, используются только для SenderSMTP.
+//   - log: логгер, в который SenderLog пишет письма.
+//
+// Возвращает:
+//   - Sender: созданный объект.
+//   - error: ошибка, если способ отправки неизвестен.
+func New(name string, smtpParams SMTPParams, log *zap.Logger) (Sender, error) {
+	switch name {
+	case SenderLog:
+		return newLogSender(log), nil
+	case SenderSMTP:
+		return newSMTPSender(smtpParams), nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", name)
+	}
+}
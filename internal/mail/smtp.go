@@ -0,0 +1,123 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPParams - параметры SMTP-сервера.
+type SMTPParams struct {
+	// Addr - адрес сервера в формате "host:port".
+	Addr string
+	// Username и Password - учетные данные для аутентификации PLAIN. Если Username пустой,
+	// письма отправляются без аутентификации.
+	Username string
+	Password string
+	// From - адрес отправителя.
+	From string
+}
+
+// smtpSender - Sender, отправляющий письма через SMTP-сервер.
+//
+// Если сервер поддерживает STARTTLS, соединение шифруется до аутентификации.
+type smtpSender struct {
+	params SMTPParams
+}
+
+// newSMTPSender создает Sender, отправляющий письма через SMTP-сервер с параметрами params.
+func newSMTPSender(params SMTPParams) *smtpSender {
+	return &smtpSender{params: params}
+}
+
+// Send отправляет письмо msg.
+//
+// net/smtp не принимает контекст, поэтому отмена и дедлайн ctx ограничивают подключение
+// и, через дедлайн соединения, весь обмен с сервером.
+func (s *smtpSender) Send(ctx context.Context, msg *Message) error {
+	// Перевод строки в адресе позволил бы дописать в письмо произвольные заголовки
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("invalid mail recipient %q", msg.To)
+	}
+
+	host, _, err := net.SplitHostPort(s.params.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp address: %w", err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.params.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("unable to set smtp connection deadline: %w", err)
+		}
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("unable to start smtp session: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("unable to start tls: %w", err)
+		}
+	}
+
+	if len(s.params.Username) > 0 {
+		if err = client.Auth(smtp.PlainAuth("", s.params.Username, s.params.Password, host)); err != nil {
+			return fmt.Errorf("unable to authenticate on smtp server: %w", err)
+		}
+	}
+
+	if err = client.Mail(s.params.From); err != nil {
+		return fmt.Errorf("unable to set mail sender: %w", err)
+	}
+	if err = client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("unable to set mail recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("unable to start mail data: %w", err)
+	}
+	if _, err = writer.Write(s.compose(msg)); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("unable to write mail data: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("unable to send mail: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// compose формирует письмо msg в формате RFC 5322.
+func (s *smtpSender) compose(msg *Message) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + s.params.From + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIKeyToCreate - данные для выпуска API-ключа.
+type APIKeyToCreate struct {
+	// ServiceAccount - имя сервиса, которому выпускается ключ.
+	ServiceAccount string
+	// Role - роль, с которой выполняются запросы с ключом.
+	Role int32
+	// ExpiresAt - время окончания действия ключа, NULL если ключ действует до отзыва.
+	ExpiresAt sql.NullTime
+}
+
+// APIKey - выпущенный API-ключ. Сам ключ не хранится, только его хеш.
+type APIKey struct {
+	ID             int64
+	ServiceAccount string
+	Role           int32
+	CreatedAt      time.Time
+	// ExpiresAt - время окончания действия ключа, NULL если ключ действует до отзыва.
+	ExpiresAt sql.NullTime
+	// LastUsedAt - время последнего запроса с ключом, NULL если ключ еще не использовался.
+	LastUsedAt sql.NullTime
+	// RevokedAt - время отзыва ключа, NULL если ключ не отозван.
+	RevokedAt sql.NullTime
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuditEventType - тип события безопасности в журнале аудита.
+type AuditEventType string
+
+// Типы событий безопасности.
+const (
+	// AuditEventLogin - успешный вход.
+	AuditEventLogin AuditEventType = "login"
+	// AuditEventLoginFailed - неудачная попытка входа.
+	AuditEventLoginFailed AuditEventType = "login_failed"
+	// AuditEventPasswordChanged - смена пароля пользователем либо по ссылке сброса пароля.
+	AuditEventPasswordChanged AuditEventType = "password_changed"
+	// AuditEventRoleChanged - изменение роли пользователя.
+	AuditEventRoleChanged AuditEventType = "role_changed"
+	// AuditEventUserDeleted - удаление пользователя.
+	AuditEventUserDeleted AuditEventType = "user_deleted"
+	// AuditEventTokenRevoked - отзыв токенов пользователя при выходе.
+	AuditEventTokenRevoked AuditEventType = "token_revoked"
+	// AuditEventAPIKeyRevoked - отзыв API-ключа.
+	AuditEventAPIKeyRevoked AuditEventType = "api_key_revoked"
+	// AuditEventImpersonation - выпуск администратору токена от имени пользователя.
+	AuditEventImpersonation AuditEventType = "impersonation"
+	// AuditEventServiceAccountToken - выпуск access-токена сервисному аккаунту.
+	AuditEventServiceAccountToken AuditEventType = "service_account_token"
+	// AuditEventServiceAccountTokenFailed - неудачная попытка получить токен сервисного аккаунта.
+	AuditEventServiceAccountTokenFailed AuditEventType = "service_account_token_failed"
+	// AuditEventServiceAccountDisabled - отключение сервисного аккаунта.
+	AuditEventServiceAccountDisabled AuditEventType = "service_account_disabled"
+)
+
+// AuditEventToCreate - данные события безопасности для записи в журнал аудита.
+type AuditEventToCreate struct {
+	Type AuditEventType
+	// UserID - пользователь, которого касается событие, NULL если он неизвестен
+	// (например, вход с незарегистрированным email).
+	UserID sql.NullInt64
+	// ActorID - пользователь, выполнивший действие, NULL если запрос без access-токена.
+	ActorID sql.NullInt64
+	// ClientIP - IP-адрес клиента, пустая строка если его не удалось определить.
+	ClientIP string
+	// Details - дополнительные данные события (email, причина, новая роль и т.п.).
+	Details map[string]string
+}
+
+// AuditEvent - событие безопасности из журнала аудита.
+type AuditEvent struct {
+	ID int64
+	AuditEventToCreate
+	CreatedAt time.Time
+}
+
+// AuditFilter - условия выборки событий из журнала аудита.
+type AuditFilter struct {
+	// UserID - только события пользователя, 0 - события всех пользователей.
+	UserID int64
+	// From, To - только события в интервале [From, To), нулевое время - без ограничения.
+	From time.Time
+	To   time.Time
+	// Limit, Offset - пагинация.
+	Limit  uint64
+	Offset uint64
+}
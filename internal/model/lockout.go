@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Lockout - блокировка входа после неудачных попыток.
+type Lockout struct {
+	// Subject - что заблокировано: "email:<email>" либо "ip:<IP-адрес>".
+	Subject string
+	// LockedUntil - время окончания блокировки.
+	LockedUntil time.Time
+	// LockoutCount - количество блокировок подряд, от него зависит длительность следующей.
+	LockoutCount int
+}
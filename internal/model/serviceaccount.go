@@ -0,0 +1,38 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ServiceAccountToCreate - данные для создания сервисного аккаунта.
+type ServiceAccountToCreate struct {
+	// Name - уникальное имя сервиса, которому создается аккаунт.
+	Name string
+	// Role - роль сервисного аккаунта (SERVICE или SERVICE_ADMIN из enum UserRole).
+	Role int32
+}
+
+// ServiceAccount - сервисный аккаунт для вызовов от других сервисов без участия пользователя.
+// Секрет не хранится, только его хеш.
+type ServiceAccount struct {
+	ID        int64
+	Name      string
+	ClientID  string
+	Role      int32
+	CreatedAt time.Time
+	// SecretRotatedAt - время последней замены секрета, NULL если секрет не менялся.
+	SecretRotatedAt sql.NullTime
+	// LastTokenAt - время последнего выпуска токена, NULL если токены еще не выпускались.
+	LastTokenAt sql.NullTime
+	// DisabledAt - время отключения аккаунта, NULL если аккаунт действует.
+	DisabledAt sql.NullTime
+}
+
+// ServiceAccountCredentials - учетные данные сервисного аккаунта. Секрет возвращается только
+// при создании аккаунта и замене секрета.
+type ServiceAccountCredentials struct {
+	ID           int64
+	ClientID     string
+	ClientSecret string
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Token - подписанный JWT-токен и время его истечения.
+type Token struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// TokenIntrospection - результат проверки access-токена по запросу другого сервиса.
+//
+// Если Active == false, остальные поля не заполняются.
+type TokenIntrospection struct {
+	Active bool
+	UserID int64
+	Role   int32
+	// Scopes - методы с требованиями к роли, которые разрешено вызывать с токеном.
+	Scopes []string
+	// ImpersonatedBy - ID администратора, если токен выпущен ему от имени пользователя, иначе 0.
+	ImpersonatedBy int64
+	// ClientID - client_id сервисного аккаунта, если токен выпущен ему, иначе пустая строка.
+	ClientID  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Impersonation - access-токен, выпущенный администратору от имени другого пользователя.
+type Impersonation struct {
+	Token
+	// TokenID - идентификатор токена (jti), по которому его можно найти в логах и отозвать.
+	TokenID string
+	AdminID int64
+	UserID  int64
+}
+
+// Caller - пользователь, сервисный аккаунт или сервис с API-ключом, вызывающий метод сервера,
+// по проверенному access-токену или API-ключу.
+type Caller struct {
+	// UserID - ID пользователя, 0 для сервисного аккаунта и API-ключа.
+	UserID int64
+	Role   int32
+	// ClientID - client_id сервисного аккаунта, пустая строка для пользователя и API-ключа.
+	ClientID string
+	// APIKeyID - ID API-ключа, 0 для access-токена.
+	APIKeyID int64
+	// ImpersonatedBy - ID администратора, действующего от имени пользователя UserID,
+	// 0 если токен выпущен самому пользователю.
+	ImpersonatedBy int64
+}
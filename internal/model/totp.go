@@ -0,0 +1,27 @@
+package model
+
+// TOTP - сохраненный секрет двухфакторной аутентификации пользователя.
+type TOTP struct {
+	// EncryptedSecret - секрет в кодировке base32, зашифрованный ключом из конфига.
+	EncryptedSecret string
+	// Confirmed - true, если пользователь подтвердил подключение кодом из приложения
+	// и код требуется при входе.
+	Confirmed bool
+	// LastUsedStep - номер шага последнего принятого кода. Коды этого и предыдущих шагов
+	// повторно не принимаются.
+	LastUsedStep int64
+}
+
+// TOTPEnrollment - данные для добавления секрета в приложение-аутентификатор.
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// SecondFactor - второй фактор, переданный при входе. Достаточно одного из полей.
+type SecondFactor struct {
+	// TOTPCode - код из приложения-аутентификатора.
+	TOTPCode string
+	// RecoveryCode - одноразовый код восстановления на случай, если приложение недоступно.
+	RecoveryCode string
+}
@@ -0,0 +1,39 @@
+package model
+
+import "database/sql"
+
+// UserToCreate - данные для создания пользователя.
+type UserToCreate struct {
+	Name  string
+	Email string
+	// Password - пароль в открытом виде, в БД сохраняется только его хеш.
+	Password string
+	Role     int32
+	// Phone - номер телефона в формате E.164, NULL если не указан.
+	Phone sql.NullString
+}
+
+// UserCreateResult - результат создания пользователя из пакета: ID созданного пользователя
+// либо ошибка, из-за которой он не создан.
+type UserCreateResult struct {
+	ID  int64
+	Err error
+}
+
+// UserCredentials - данные пользователя, необходимые для проверки пароля и выпуска токенов.
+type UserCredentials struct {
+	ID            int64
+	Name          string
+	Email         string
+	Role          int32
+	PasswordHash  string
+	EmailVerified bool
+}
+
+// UserTokenState - данные пользователя, необходимые для обновления токенов.
+type UserTokenState struct {
+	Role int32
+	// TokensValidAfter - время (UTC), раньше которого выпущенные пользователю refresh-токены
+	// недействительны, NULL если токены не отзывались.
+	TokensValidAfter sql.NullTime
+}
@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DiscoveryPath - путь документа OIDC discovery (OpenID Connect Discovery 1.0).
+	DiscoveryPath = "/.well-known/openid-configuration"
+	// JWKSPath - путь набора открытых ключей подписи токенов (JWKS).
+	JWKSPath = "/.well-known/jwks.json"
+
+	// cacheControl - заголовок Cache-Control ответов. Ключи меняются редко, но после смены ключа
+	// другие сервисы должны получить новый JWKS за разумное время.
+	cacheControl = "public, max-age=300"
+)
+
+// supportedClaims - claims, которые содержат выпускаемые токены.
+var supportedClaims = []string{"iss", "sub", "aud", "exp", "iat", "jti", "uid", "role", "impersonated_by", "client_id", "token_use"}
+
+// discovery - документ OIDC discovery.
+//
+// Токены выпускаются через gRPC-метод Login, а не через authorization endpoint, поэтому документ
+// нужен в первую очередь для получения jwks_uri и проверки токенов без обращения к серверу.
+type discovery struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
+}
+
+// jwks - набор открытых ключей (RFC 7517).
+type jwks struct {
+	Keys []JWK `json:"keys"`
+}
+
+// handler - HTTP-обработчик OIDC discovery и JWKS.
+type handler struct {
+	issuer string
+	keys   KeySet
+	log    *zap.Logger
+}
+
+// NewHandler создает HTTP-обработчик, публикующий OIDC discovery по DiscoveryPath
+// и JWKS по JWKSPath.
+//
+// Параметры:
+//   - issuer: URL издателя токенов, тот же, что в claim "iss". Адреса в документе discovery
+//     строятся от него, поэтому по этому URL должен быть доступен HTTP-сервер.
+//   - keys: ключи подписи токенов.
+//   - log: логгер.
+func NewHandler(issuer string, keys KeySet, log *zap.Logger) http.Handler {
+	h := &handler{
+		issuer: issuer,
+		keys:   keys,
+		log:    log,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, h.discovery)
+	mux.HandleFunc(JWKSPath, h.jwks)
+
+	return mux
+}
+
+// discovery отдает документ OIDC discovery.
+func (h *handler) discovery(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, r, &discovery{
+		Issuer:                           h.issuer,
+		JWKSURI:                          h.issuer + JWKSPath,
+		ResponseTypesSupported:           []string{"token"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: h.keys.SigningAlgorithms(),
+		ClaimsSupported:                  supportedClaims,
+	})
+}
+
+// jwks отдает открытые ключи подписи токенов.
+func (h *handler) jwks(w http.ResponseWriter, r *http.Request) {
+	keys := h.keys.PublicKeys()
+	if keys == nil {
+		keys = []JWK{}
+	}
+
+	h.writeJSON(w, r, &jwks{Keys: keys})
+}
+
+// writeJSON отдает body в формате JSON на GET- и HEAD-запросы.
+func (h *handler) writeJSON(w http.ResponseWriter, r *http.Request, body interface{}) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.log.Error("OIDC handler. Unable to write response", zap.String("Path", r.URL.Path), zap.Error(err))
+	}
+}
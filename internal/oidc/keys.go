@@ -0,0 +1,34 @@
+package oidc
+
+// JWK - открытый ключ подписи токенов в формате JSON Web Key (RFC 7517).
+//
+// Заполняются только поля, относящиеся к типу ключа Kty.
+type JWK struct {
+	// Kty - тип ключа: "RSA" или "OKP" (Ed25519).
+	Kty string `json:"kty"`
+	// Use - назначение ключа, для ключей подписи - "sig".
+	Use string `json:"use"`
+	// Alg - алгоритм подписи ("RS256", "EdDSA").
+	Alg string `json:"alg"`
+	// Kid - идентификатор ключа, совпадает с заголовком "kid" подписанных им токенов.
+	Kid string `json:"kid,omitempty"`
+
+	// N, E - модуль и открытая экспонента RSA-ключа в base64url.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Crv, X - кривая и открытый ключ OKP-ключа в base64url.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// KeySet - ключи, которыми сервер подписывает токены.
+//
+// Методы:
+//   - SigningAlgorithms: алгоритмы подписи выпускаемых токенов.
+//   - PublicKeys: открытые ключи для проверки подписи. Секреты симметричных алгоритмов (HS256)
+//     не публикуются, поэтому для них список пуст.
+type KeySet interface {
+	SigningAlgorithms() []string
+	PublicKeys() []JWK
+}
@@ -0,0 +1,197 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minIdentityPartLength - минимальная длина части имени или email, которая ищется в пароле.
+// Более короткие части (инициалы, "al") дают слишком много ложных срабатываний.
+const minIdentityPartLength = 3
+
+// commonPasswords - встроенный список распространенных паролей.
+//
+//go:embed common_passwords.txt
+var commonPasswords []byte
+
+// Rules - правила политики паролей.
+type Rules struct {
+	// MinLength - минимальная длина пароля в символах.
+	MinLength int
+	// RequireLower - пароль должен содержать строчную букву.
+	RequireLower bool
+	// RequireUpper - пароль должен содержать заглавную букву.
+	RequireUpper bool
+	// RequireDigit - пароль должен содержать цифру.
+	RequireDigit bool
+	// RequireSymbol - пароль должен содержать символ, не являющийся буквой или цифрой.
+	RequireSymbol bool
+	// DenyCommon - запрещены пароли из встроенного списка распространенных паролей.
+	DenyCommon bool
+	// DenyListFile - путь к файлу с дополнительным списком запрещенных паролей, по одному в строке.
+	// Пустая строка - дополнительного списка нет.
+	DenyListFile string
+	// RejectIdentity - запрещены пароли, совпадающие с email или именем пользователя либо содержащие их.
+	RejectIdentity bool
+}
+
+// ViolationError - ошибка проверки пароля, содержащая все нарушенные правила политики.
+type ViolationError struct {
+	Violations []string
+}
+
+// Error возвращает нарушенные правила одной строкой.
+func (e *ViolationError) Error() string {
+	return strings.Join(e.Violations, ". ")
+}
+
+// Policy - политика паролей: проверяет пароль при создании пользователя, смене и сбросе пароля.
+type Policy struct {
+	rules    Rules
+	denyList map[string]struct{}
+}
+
+// New создает политику паролей с правилами rules.
+//
+// Возвращает:
+//   - *Policy: созданная политика.
+//   - error: ошибка, если минимальная длина отрицательная либо файл со списком запрещенных паролей не прочитан.
+func New(rules Rules) (*Policy, error) {
+	if rules.MinLength < 0 {
+		return nil, errors.New("password min length must not be negative")
+	}
+
+	denyList := make(map[string]struct{})
+	if rules.DenyCommon {
+		if err := readDenyList(bytes.NewReader(commonPasswords), denyList); err != nil {
+			return nil, fmt.Errorf("unable to read common passwords: %w", err)
+		}
+	}
+
+	if len(rules.DenyListFile) > 0 {
+		file, err := os.Open(rules.DenyListFile) // #nosec G304 -- путь к списку задается в конфиге
+		if err != nil {
+			return nil, fmt.Errorf("unable to open password deny list: %w", err)
+		}
+		defer file.Close()
+
+		if err = readDenyList(file, denyList); err != nil {
+			return nil, fmt.Errorf("unable to read password deny list: %w", err)
+		}
+	}
+
+	return &Policy{
+		rules:    rules,
+		denyList: denyList,
+	}, nil
+}
+
+// Validate проверяет пароль пользователя с email и именем name по всем правилам политики.
+//
+// Email и имя нужны только для правила RejectIdentity и могут быть пустыми.
+//
+// Возвращает:
+//   - *ViolationError со списком всех нарушенных правил, если пароль им не соответствует.
+//   - nil в остальных случаях.
+func (p *Policy) Validate(password, email, name string) error {
+	var violations []string
+
+	if len(strings.TrimSpace(password)) == 0 {
+		violations = append(violations, "Password must not be empty")
+	}
+
+	if utf8.RuneCountInString(password) < p.rules.MinLength {
+		violations = append(violations, fmt.Sprintf("Password must be at least %d characters long", p.rules.MinLength))
+	}
+
+	if p.rules.RequireLower && !strings.ContainsFunc(password, unicode.IsLower) {
+		violations = append(violations, "Password must contain a lowercase letter")
+	}
+
+	if p.rules.RequireUpper && !strings.ContainsFunc(password, unicode.IsUpper) {
+		violations = append(violations, "Password must contain an uppercase letter")
+	}
+
+	if p.rules.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		violations = append(violations, "Password must contain a digit")
+	}
+
+	if p.rules.RequireSymbol && !strings.ContainsFunc(password, isSymbol) {
+		violations = append(violations, "Password must contain a symbol")
+	}
+
+	if _, ok := p.denyList[strings.ToLower(password)]; ok {
+		violations = append(violations, "Password is too common")
+	}
+
+	if p.rules.RejectIdentity && containsIdentity(password, email, name) {
+		violations = append(violations, "Password must not contain email or user name")
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+
+	return nil
+}
+
+// containsIdentity возвращает true, если пароль (без учета регистра) совпадает с email или
+// именем пользователя либо содержит локальную часть email, имя или одно из слов имени.
+func containsIdentity(password, email, name string) bool {
+	password = strings.ToLower(password)
+
+	var identityParts []string
+	if email = strings.ToLower(strings.TrimSpace(email)); len(email) > 0 {
+		identityParts = append(identityParts, email)
+		if at := strings.LastIndex(email, "@"); at >= 0 {
+			identityParts = append(identityParts, email[:at])
+		}
+	}
+
+	if name = strings.ToLower(strings.TrimSpace(name)); len(name) > 0 {
+		identityParts = append(identityParts, name)
+		identityParts = append(identityParts, strings.Fields(name)...)
+	}
+
+	for _, part := range identityParts {
+		if len(part) == 0 {
+			continue
+		}
+		if password == part {
+			return true
+		}
+		if utf8.RuneCountInString(part) >= minIdentityPartLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSymbol возвращает true, если r - не буква, не цифра и не пробельный символ.
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+// readDenyList добавляет в denyList пароли из r (по одному в строке, в нижнем регистре).
+// Пустые строки и строки, начинающиеся с "#", пропускаются.
+func readDenyList(r io.Reader, denyList map[string]struct{}) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denyList[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,154 @@
+package passwordpolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	denyListFile := filepath.Join(t.TempDir(), "deny_list.txt")
+	if err := os.WriteFile(denyListFile, []byte("# company passwords\n\nAcme2024\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rules   Rules
+		wantErr bool
+	}{
+		{name: "defaults", rules: Rules{}},
+		{name: "common passwords and deny list file", rules: Rules{DenyCommon: true, DenyListFile: denyListFile}},
+		{name: "negative min length", rules: Rules{MinLength: -1}, wantErr: true},
+		{name: "missing deny list file", rules: Rules{DenyListFile: filepath.Join(t.TempDir(), "missing.txt")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.rules); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	denyListFile := filepath.Join(t.TempDir(), "deny_list.txt")
+	if err := os.WriteFile(denyListFile, []byte("# company passwords\n\nAcme2024!\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		rules          Rules
+		password       string
+		wantViolations []string
+	}{
+		{name: "no rules", rules: Rules{}, password: "x"},
+		{name: "empty", rules: Rules{}, password: "  ", wantViolations: []string{"Password must not be empty"}},
+		{name: "min length", rules: Rules{MinLength: 8}, password: "пароль12"},
+		{name: "too short", rules: Rules{MinLength: 8}, password: "пароль1", wantViolations: []string{"Password must be at least 8 characters long"}},
+		{
+			name:     "character classes",
+			rules:    Rules{RequireLower: true, RequireUpper: true, RequireDigit: true, RequireSymbol: true},
+			password: "Str0ng-password",
+		},
+		{
+			name:     "missing character classes",
+			rules:    Rules{RequireLower: true, RequireUpper: true, RequireDigit: true, RequireSymbol: true},
+			password: "lower case",
+			wantViolations: []string{
+				"Password must contain an uppercase letter",
+				"Password must contain a digit",
+				"Password must contain a symbol",
+			},
+		},
+		{name: "common password", rules: Rules{DenyCommon: true}, password: "QWERTY", wantViolations: []string{"Password is too common"}},
+		{name: "uncommon password", rules: Rules{DenyCommon: true}, password: "correct horse battery"},
+		{name: "common password allowed", rules: Rules{}, password: "qwerty"},
+		{name: "deny list file", rules: Rules{DenyListFile: denyListFile}, password: "acme2024!", wantViolations: []string{"Password is too common"}},
+		{
+			name:     "all violations",
+			rules:    Rules{MinLength: 10, RequireDigit: true, DenyCommon: true},
+			password: "password",
+			wantViolations: []string{
+				"Password must be at least 10 characters long",
+				"Password must contain a digit",
+				"Password is too common",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := New(tt.rules)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = policy.Validate(tt.password, "", "")
+
+			var violations []string
+			var violationErr *ViolationError
+			if errors.As(err, &violationErr) {
+				violations = violationErr.Violations
+			} else if err != nil {
+				t.Fatalf("Validate() error = %T, want *ViolationError", err)
+			}
+			if !reflect.DeepEqual(violations, tt.wantViolations) {
+				t.Errorf("violations = %q, want %q", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestPolicyValidateIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		userName string
+		wantErr  bool
+	}{
+		{name: "password equals email", password: "Alice@Example.com", email: "alice@example.com", userName: "Alice", wantErr: true},
+		{name: "password contains email local part", password: "alice.smith2024", email: "alice.smith@example.com", wantErr: true},
+		{name: "password contains name", password: "iamAlice!", email: "a@example.com", userName: "Alice", wantErr: true},
+		{name: "password contains word of name", password: "smith-forever", userName: "Alice Smith", wantErr: true},
+		{name: "password equals short name", password: "Al", userName: "al", wantErr: true},
+		{name: "password contains short name", password: "correct horse al", userName: "Al"},
+		{name: "acceptable password", password: "correct horse battery", email: "alice@example.com", userName: "Alice Smith"},
+		{name: "no email and name", password: "correct horse battery"},
+	}
+
+	policy, err := New(Rules{RejectIdentity: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.email, tt.userName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			var violationErr *ViolationError
+			if err != nil && !errors.As(err, &violationErr) {
+				t.Errorf("Validate() error = %T, want *ViolationError", err)
+			}
+		})
+	}
+}
+
+func TestPolicyValidateIdentityDisabled(t *testing.T) {
+	policy, err := New(Rules{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err = policy.Validate("alice@example.com", "alice@example.com", "Alice"); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,109 @@
+package redact
+
+import (
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Mask - значение, которым в логах заменяются секретные поля.
+const Mask = "[REDACTED]"
+
+// sensitiveFields - имена полей proto-сообщений, значения которых не должны попадать в логи:
+// пароли, одноразовые коды, токены, секреты и API-ключи.
+var sensitiveFields = map[protoreflect.Name]struct{}{
+	"password":             {},
+	"password_confirm":     {},
+	"old_password":         {},
+	"new_password":         {},
+	"new_password_confirm": {},
+	"totp_code":            {},
+	"recovery_code":        {},
+	"recovery_codes":       {},
+	"code":                 {},
+	"secret":               {},
+	"client_secret":        {},
+	"provisioning_uri":     {},
+	"token":                {},
+	"access_token":         {},
+	"refresh_token":        {},
+	"reauth_token":         {},
+	"key":                  {},
+}
+
+// Proto возвращает поле лога key с содержимым сообщения msg в формате JSON, в котором значения
+// секретных полей (на любом уровне вложенности) заменены на Mask.
+//
+// Используется вместо zap.Any при логировании запросов и ответов gRPC: исходное сообщение
+// не изменяется.
+func Proto(key string, msg proto.Message) zap.Field {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return zap.Skip()
+	}
+
+	clone := proto.Clone(msg)
+	scrub(clone.ProtoReflect())
+
+	content, err := protojson.Marshal(clone)
+	if err != nil {
+		return zap.String(key, Mask)
+	}
+
+	return zap.Reflect(key, json.RawMessage(content))
+}
+
+// scrub заменяет на Mask непустые секретные поля сообщения m и вложенных в него сообщений.
+func scrub(m protoreflect.Message) {
+	// Поля маскируются после обхода: изменять сообщение во время Range нельзя
+	var sensitive []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if _, ok := sensitiveFields[fd.Name()]; ok {
+			sensitive = append(sensitive, fd)
+			return true
+		}
+
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				value.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					scrub(v.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Kind() == protoreflect.MessageKind {
+				list := value.List()
+				for i := 0; i < list.Len(); i++ {
+					scrub(list.Get(i).Message())
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			scrub(value.Message())
+		}
+
+		return true
+	})
+
+	for _, fd := range sensitive {
+		mask(m, fd)
+	}
+}
+
+// mask заменяет значение секретного поля fd сообщения m на Mask. Поля, которые не являются
+// строками или списками строк, очищаются.
+func mask(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.Kind() == protoreflect.StringKind && fd.IsList():
+		list := m.Mutable(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, protoreflect.ValueOfString(Mask))
+		}
+	case fd.Kind() == protoreflect.StringKind && !fd.IsMap():
+		m.Set(fd, protoreflect.ValueOfString(Mask))
+	default:
+		m.Clear(fd)
+	}
+}
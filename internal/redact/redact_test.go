@@ -0,0 +1,330 @@
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/anton0701/auth/grpc/pkg/access_v1"
+	"github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/grpc/pkg/user_v1"
+)
+
+// render возвращает JSON, который Proto записывает в лог для сообщения msg.
+func render(t *testing.T, msg proto.Message) string {
+	t.Helper()
+
+	content, ok := Proto("msg", msg).Interface.(json.RawMessage)
+	if !ok {
+		t.Fatalf("Proto() did not render %T", msg)
+	}
+
+	return string(content)
+}
+
+func TestProto(t *testing.T) {
+	req := &auth_v1.LoginRequest{
+		Email:        "user@example.com",
+		Password:     "p4ssw0rd",
+		TotpCode:     "123456",
+		RecoveryCode: "abcd-efgh",
+	}
+
+	got := render(t, req)
+	for _, secret := range []string{"p4ssw0rd", "123456", "abcd-efgh"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("Proto() = %s, contains %q", got, secret)
+		}
+	}
+	if !strings.Contains(got, "user@example.com") || !strings.Contains(got, Mask) {
+		t.Errorf("Proto() = %s, want email and %q", got, Mask)
+	}
+
+	// Исходное сообщение не изменяется
+	if req.Password != "p4ssw0rd" {
+		t.Errorf("Proto() changed the message: password = %q", req.Password)
+	}
+}
+
+func TestProtoNil(t *testing.T) {
+	var req *auth_v1.LoginRequest
+
+	if field := Proto("msg", req); field.Interface != nil {
+		t.Errorf("Proto(nil) = %v, want skipped field", field.Interface)
+	}
+}
+
+func TestProtoRepeatedMessages(t *testing.T) {
+	req := &user_v1.BatchCreateUsersRequest{
+		Users: []*user_v1.CreateUserRequest{
+			{Name: "first", Password: "first-password", PasswordConfirm: "first-password"},
+			{Name: "second", Password: "second-password", PasswordConfirm: "second-password"},
+		},
+	}
+
+	got := render(t, req)
+	if strings.Contains(got, "-password") {
+		t.Errorf("Proto() = %s, contains a password", got)
+	}
+	if !strings.Contains(got, "first") || !strings.Contains(got, "second") {
+		t.Errorf("Proto() = %s, want user names", got)
+	}
+}
+
+func TestProtoRepeatedStrings(t *testing.T) {
+	resp := &auth_v1.RegenerateRecoveryCodesResponse{RecoveryCodes: []string{"aaaa-bbbb", "cccc-dddd"}}
+
+	got := render(t, resp)
+	if strings.Contains(got, "aaaa-bbbb") || strings.Contains(got, "cccc-dddd") {
+		t.Errorf("Proto() = %s, contains a recovery code", got)
+	}
+	// Количество кодов сохраняется
+	if strings.Count(got, Mask) != 2 {
+		t.Errorf("Proto() = %s, want 2 masked codes", got)
+	}
+}
+
+// testMessage строит сообщение с полями всех видов, которые обходит scrub:
+//
+//	message Credential { string token = 1; string name = 2; }
+//	message Envelope {
+//	  Credential nested = 1;
+//	  repeated Credential items = 2;
+//	  map<string, Credential> by_name = 3;
+//	  oneof auth { string password = 4; Credential holder = 5; }
+//	  google.protobuf.StringValue secret = 6;
+//	  google.protobuf.StringValue comment = 7;
+//	}
+func testMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label,
+		kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     kind.Enum(),
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		str      = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		message  = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	password := field("password", 4, optional, str, "")
+	password.OneofIndex = proto.Int32(0)
+	holder := field("holder", 5, optional, message, ".redact.test.Credential")
+	holder.OneofIndex = proto.Int32(0)
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("redact_test.proto"),
+		Package:    proto.String("redact.test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/wrappers.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Credential"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("token", 1, optional, str, ""),
+					field("name", 2, optional, str, ""),
+				},
+			},
+			{
+				Name: proto.String("Envelope"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("nested", 1, optional, message, ".redact.test.Credential"),
+					field("items", 2, repeated, message, ".redact.test.Credential"),
+					field("by_name", 3, repeated, message, ".redact.test.Envelope.ByNameEntry"),
+					password,
+					holder,
+					field("secret", 6, optional, message, ".google.protobuf.StringValue"),
+					field("comment", 7, optional, message, ".google.protobuf.StringValue"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("ByNameEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, optional, str, ""),
+						field("value", 2, optional, message, ".redact.test.Credential"),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("auth")}},
+			},
+		},
+	}
+
+	// Регистрирует wrappers.proto в protoregistry.GlobalFiles
+	_ = wrapperspb.String("")
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile() error = %v", err)
+	}
+
+	return fd.Messages().ByName("Envelope")
+}
+
+func TestProtoFieldKinds(t *testing.T) {
+	envelope := testMessage(t)
+	credential := envelope.Fields().ByName("nested").Message()
+
+	newCredential := func(token, name string) protoreflect.Value {
+		m := dynamicpb.NewMessage(credential)
+		m.Set(credential.Fields().ByName("token"), protoreflect.ValueOfString(token))
+		m.Set(credential.Fields().ByName("name"), protoreflect.ValueOfString(name))
+		return protoreflect.ValueOfMessage(m)
+	}
+
+	newEnvelope := func() *dynamicpb.Message {
+		m := dynamicpb.NewMessage(envelope)
+		fields := envelope.Fields()
+
+		m.Set(fields.ByName("nested"), newCredential("nested-token", "nested-name"))
+
+		items := m.Mutable(fields.ByName("items")).List()
+		items.Append(newCredential("item-token-1", "item-name-1"))
+		items.Append(newCredential("item-token-2", "item-name-2"))
+
+		byName := m.Mutable(fields.ByName("by_name")).Map()
+		byName.Set(protoreflect.ValueOfString("map-key").MapKey(), newCredential("map-token", "map-name"))
+
+		m.Set(fields.ByName("secret"), protoreflect.ValueOfMessage(wrapperspb.String("wrapped-secret").ProtoReflect()))
+		m.Set(fields.ByName("comment"), protoreflect.ValueOfMessage(wrapperspb.String("wrapped-comment").ProtoReflect()))
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		oneof  func(m *dynamicpb.Message)
+		hidden []string
+		shown  []string
+	}{
+		{
+			name: "oneof string",
+			oneof: func(m *dynamicpb.Message) {
+				m.Set(envelope.Fields().ByName("password"), protoreflect.ValueOfString("oneof-password"))
+			},
+			hidden: []string{"oneof-password"},
+		},
+		{
+			name: "oneof message",
+			oneof: func(m *dynamicpb.Message) {
+				m.Set(envelope.Fields().ByName("holder"), newCredential("holder-token", "holder-name"))
+			},
+			hidden: []string{"holder-token"},
+			shown:  []string{"holder-name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newEnvelope()
+			tt.oneof(m)
+
+			got := render(t, m)
+
+			hidden := append([]string{"nested-token", "item-token-1", "item-token-2", "map-token", "wrapped-secret"}, tt.hidden...)
+			for _, value := range hidden {
+				if strings.Contains(got, value) {
+					t.Errorf("Proto() = %s, contains %q", got, value)
+				}
+			}
+
+			shown := append([]string{"nested-name", "item-name-1", "item-name-2", "map-key", "map-name", "wrapped-comment"}, tt.shown...)
+			for _, value := range shown {
+				if !strings.Contains(got, value) {
+					t.Errorf("Proto() = %s, does not contain %q", got, value)
+				}
+			}
+		})
+	}
+}
+
+// publicFields - поля, имена которых похожи на секретные, но значения которых не являются секретами.
+var publicFields = map[protoreflect.Name]struct{}{
+	// Курсоры постраничной выдачи
+	"page_token":      {},
+	"next_page_token": {},
+}
+
+var secretName = regexp.MustCompile(`password|token|secret|code|key`)
+
+// TestProtoAPIMessages проверяет, что в каждом сообщении API все строковые поля с паролями, токенами,
+// секретами и кодами маскируются: новое такое поле должно попасть в sensitiveFields или в publicFields.
+func TestProtoAPIMessages(t *testing.T) {
+	files := []protoreflect.FileDescriptor{
+		user_v1.File_user_proto,
+		auth_v1.File_auth_proto,
+		access_v1.File_access_proto,
+	}
+
+	var check func(t *testing.T, md protoreflect.MessageDescriptor)
+	check = func(t *testing.T, md protoreflect.MessageDescriptor) {
+		for i := 0; i < md.Messages().Len(); i++ {
+			if nested := md.Messages().Get(i); !nested.IsMapEntry() {
+				check(t, nested)
+			}
+		}
+
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+		if err != nil {
+			t.Fatalf("FindMessageByName(%s) error = %v", md.FullName(), err)
+		}
+		m := mt.New()
+
+		var names []protoreflect.Name
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if fd.Kind() != protoreflect.StringKind || fd.IsMap() || !secretName.MatchString(string(fd.Name())) {
+				continue
+			}
+			if _, ok := publicFields[fd.Name()]; ok {
+				continue
+			}
+
+			value := "value-of-" + string(fd.Name())
+			if fd.IsList() {
+				m.Mutable(fd).List().Append(protoreflect.ValueOfString(value))
+			} else {
+				m.Set(fd, protoreflect.ValueOfString(value))
+			}
+			names = append(names, fd.Name())
+		}
+
+		if len(names) == 0 {
+			return
+		}
+
+		got := render(t, m.Interface())
+		for _, name := range names {
+			if strings.Contains(got, "value-of-"+string(name)) {
+				t.Errorf("%s.%s is not redacted: %s", md.FullName(), name, got)
+			}
+		}
+	}
+
+	for _, file := range files {
+		for i := 0; i < file.Messages().Len(); i++ {
+			check(t, file.Messages().Get(i))
+		}
+	}
+}
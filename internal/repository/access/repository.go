@@ -0,0 +1,100 @@
+package access
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "accessible_roles"
+
+// repo - хранилище правил доступа в таблице accessible_roles, реализующее интерфейс
+// repository.AccessRepository.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище правил доступа, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.AccessRepository {
+	return &repo{db: db}
+}
+
+// GetAccessibleRoles возвращает роли, которым разрешен вызов метода, для всех методов из таблицы.
+func (r *repo) GetAccessibleRoles(ctx context.Context) (map[string][]int32, error) {
+	query, args, err := sq.Select("endpoint_address", "role").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy("endpoint_address", "role").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select accessible roles: %w", err)
+	}
+	defer rows.Close()
+
+	accessibleRoles := make(map[string][]int32)
+	for rows.Next() {
+		var (
+			endpoint string
+			role     int32
+		)
+		if err = rows.Scan(&endpoint, &role); err != nil {
+			return nil, fmt.Errorf("unable to scan accessible role: %w", err)
+		}
+		accessibleRoles[endpoint] = append(accessibleRoles[endpoint], role)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read accessible roles: %w", err)
+	}
+
+	return accessibleRoles, nil
+}
+
+// SetAccessibleRoles заменяет список ролей метода endpoint в одной транзакции.
+func (r *repo) SetAccessibleRoles(ctx context.Context, endpoint string, roles []int32) error {
+	return r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query, args, err := sq.Delete(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"endpoint_address": endpoint}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to delete accessible roles: %w", err)
+		}
+
+		if len(roles) == 0 {
+			return nil
+		}
+
+		builderInsert := sq.Insert(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Columns("endpoint_address", "role").
+			Suffix("ON CONFLICT DO NOTHING")
+		for _, role := range roles {
+			builderInsert = builderInsert.Values(endpoint, role)
+		}
+
+		query, args, err = builderInsert.ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to insert accessible roles: %w", err)
+		}
+
+		return nil
+	})
+}
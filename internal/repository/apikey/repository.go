@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "api_keys"
+
+// repo - хранилище API-ключей в таблице api_keys, реализующее интерфейс repository.APIKeyRepository.
+//
+// Время хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище API-ключей, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.APIKeyRepository {
+	return &repo{db: db}
+}
+
+// Create сохраняет хеш нового API-ключа и возвращает ID ключа.
+func (r *repo) Create(ctx context.Context, key *model.APIKeyToCreate, keyHash string) (int64, error) {
+	expiresAt := key.ExpiresAt
+	expiresAt.Time = expiresAt.Time.UTC()
+
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("key_hash", "service_account", "role", "created_at", "expires_at").
+		Values(keyHash, key.ServiceAccount, key.Role, time.Now().UTC(), expiresAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var id int64
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to insert api key: %w", err)
+	}
+
+	return id, nil
+}
+
+// Use возвращает действующий (не отозванный и не истекший) ключ с хешем keyHash
+// и отмечает время его использования.
+//
+// Проверка и отметка выполняются одним запросом, поэтому отозванный ключ не пройдет проверку
+// даже при одновременном отзыве.
+func (r *repo) Use(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	now := time.Now().UTC()
+
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("last_used_at", now).
+		Where(sq.Eq{"key_hash": keyHash, "revoked_at": nil}).
+		Where(sq.Or{sq.Eq{"expires_at": nil}, sq.Gt{"expires_at": now}}).
+		Suffix("RETURNING id, service_account, role, created_at, expires_at, last_used_at, revoked_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to use api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Revoke отмечает ключ с ID id отозванным.
+func (r *repo) Revoke(ctx context.Context, id int64) error {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("revoked_at", time.Now().UTC()).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to revoke api key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// List возвращает все выпущенные ключи, включая отозванные и истекшие, начиная с самых новых.
+func (r *repo) List(ctx context.Context) ([]*model.APIKey, error) {
+	query, args, err := sq.Select("id", "service_account", "role", "created_at", "expires_at", "last_used_at", "revoked_at").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// scanAPIKey читает ключ из строки результата запроса.
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	err := row.Scan(&key.ID, &key.ServiceAccount, &key.Role, &key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "audit_log"
+
+// repo - хранилище журнала аудита в таблице audit_log, реализующее интерфейс repository.AuditRepository.
+//
+// Время хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище журнала аудита, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.AuditRepository {
+	return &repo{db: db}
+}
+
+// Create записывает событие со временем записи.
+func (r *repo) Create(ctx context.Context, event *model.AuditEventToCreate) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("unable to marshal audit event details: %w", err)
+	}
+
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("event_type", "user_id", "actor_id", "client_ip", "details", "created_at").
+		Values(string(event.Type), event.UserID, event.ActorID, event.ClientIP, detailsJSON, time.Now().UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to insert audit event: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает события, подходящие под filter, начиная с самых новых.
+func (r *repo) List(ctx context.Context, filter *model.AuditFilter) ([]*model.AuditEvent, error) {
+	builder := sq.Select("id", "event_type", "user_id", "actor_id", "client_ip", "details", "created_at").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.UserID != 0 {
+		builder = builder.Where(sq.Eq{"user_id": filter.UserID})
+	}
+	if !filter.From.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"created_at": filter.From.UTC()})
+	}
+	if !filter.To.IsZero() {
+		builder = builder.Where(sq.Lt{"created_at": filter.To.UTC()})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*model.AuditEvent
+	for rows.Next() {
+		var (
+			event       model.AuditEvent
+			eventType   string
+			detailsJSON []byte
+		)
+		err = rows.Scan(&event.ID, &eventType, &event.UserID, &event.ActorID, &event.ClientIP, &detailsJSON, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan audit event: %w", err)
+		}
+
+		event.Type = model.AuditEventType(eventType)
+		if err = json.Unmarshal(detailsJSON, &event.Details); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal audit event details: %w", err)
+		}
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read audit events: %w", err)
+	}
+
+	return events, nil
+}
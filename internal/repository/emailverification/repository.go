@@ -0,0 +1,123 @@
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const (
+	tableName     = "email_verification_tokens"
+	userTableName = "auth"
+)
+
+// repo - хранилище токенов подтверждения email в таблице email_verification_tokens,
+// реализующее интерфейс repository.EmailVerificationRepository.
+//
+// Время истечения хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище токенов подтверждения email, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.EmailVerificationRepository {
+	return &repo{db: db}
+}
+
+// Create сохраняет хеш токена подтверждения email и удаляет из таблицы истекшие токены.
+func (r *repo) Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("token_hash", "user_id", "expires_at").
+		Values(tokenHash, userID, expiresAt.UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to insert email verification token: %w", err)
+	}
+
+	// Истекшие токены уже нельзя использовать
+	query, args, err = sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Lt{"expires_at": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to delete expired email verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Verify в одной транзакции удаляет действующий токен с хешем tokenHash и остальные токены
+// того же пользователя и отмечает email пользователя подтвержденным.
+func (r *repo) Verify(ctx context.Context, tokenHash string) (int64, error) {
+	var userID int64
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query, args, err := sq.Delete(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"token_hash": tokenHash}).
+			Where(sq.Gt{"expires_at": time.Now().UTC()}).
+			Suffix("RETURNING user_id").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		err = tx.QueryRow(ctx, query, args...).Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrEmailVerificationTokenNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("unable to delete email verification token: %w", err)
+		}
+
+		// Ссылки из остальных писем после подтверждения не нужны
+		query, args, err = sq.Delete(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"user_id": userID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to delete email verification tokens: %w", err)
+		}
+
+		query, args, err = sq.Update(userTableName).
+			PlaceholderFormat(sq.Dollar).
+			Set("email_verified", true).
+			Set("updated_at", sq.Expr("now()")).
+			Where(sq.Eq{"id": userID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to mark email verified: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
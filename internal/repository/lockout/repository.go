@@ -0,0 +1,219 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const (
+	failuresTableName = "login_failures"
+	lockoutsTableName = "login_lockouts"
+)
+
+// repo - хранилище неудачных попыток входа и блокировок в таблицах login_failures и login_lockouts,
+// реализующее интерфейс repository.LockoutRepository.
+//
+// Время хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище блокировок входа, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.LockoutRepository {
+	return &repo{db: db}
+}
+
+// GetLockedUntil возвращает самое позднее время окончания действующих блокировок subjects.
+func (r *repo) GetLockedUntil(ctx context.Context, subjects []string) (time.Time, error) {
+	query, args, err := sq.Select("max(locked_until)").
+		PlaceholderFormat(sq.Dollar).
+		From(lockoutsTableName).
+		Where(sq.Eq{"subject": subjects}).
+		Where(sq.Gt{"locked_until": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var lockedUntil sql.NullTime
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&lockedUntil); err != nil {
+		return time.Time{}, fmt.Errorf("unable to select lockout: %w", err)
+	}
+	if !lockedUntil.Valid {
+		return time.Time{}, nil
+	}
+
+	return lockedUntil.Time, nil
+}
+
+// AddFailure сохраняет неудачную попытку входа subject и возвращает количество попыток за window.
+func (r *repo) AddFailure(ctx context.Context, subject string, window time.Duration) (int, error) {
+	now := time.Now().UTC()
+
+	query, args, err := sq.Insert(failuresTableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("subject", "failed_at").
+		Values(subject, now).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("unable to insert login failure: %w", err)
+	}
+
+	// Попытки за пределами окна не учитываются
+	query, args, err = sq.Delete(failuresTableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"subject": subject}).
+		Where(sq.LtOrEq{"failed_at": now.Add(-window)}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("unable to delete old login failures: %w", err)
+	}
+
+	query, args, err = sq.Select("count(*)").
+		PlaceholderFormat(sq.Dollar).
+		From(failuresTableName).
+		Where(sq.Eq{"subject": subject}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var count int
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("unable to count login failures: %w", err)
+	}
+
+	return count, nil
+}
+
+// Lock в одной транзакции блокирует subject на время duration(количество блокировок подряд)
+// и удаляет его неудачные попытки, чтобы после блокировки они считались заново.
+func (r *repo) Lock(ctx context.Context, subject string, duration func(lockoutCount int) time.Duration) (*model.Lockout, error) {
+	lockout := &model.Lockout{Subject: subject}
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query, args, err := sq.Select("lockout_count").
+			PlaceholderFormat(sq.Dollar).
+			From(lockoutsTableName).
+			Where(sq.Eq{"subject": subject}).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		var lockoutCount int
+		err = tx.QueryRow(ctx, query, args...).Scan(&lockoutCount)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("unable to select lockout: %w", err)
+		}
+
+		lockout.LockoutCount = lockoutCount + 1
+		lockout.LockedUntil = time.Now().UTC().Add(duration(lockout.LockoutCount))
+
+		query, args, err = sq.Insert(lockoutsTableName).
+			PlaceholderFormat(sq.Dollar).
+			Columns("subject", "locked_until", "lockout_count").
+			Values(lockout.Subject, lockout.LockedUntil, lockout.LockoutCount).
+			Suffix("ON CONFLICT (subject) DO UPDATE SET locked_until = excluded.locked_until, lockout_count = excluded.lockout_count").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to save lockout: %w", err)
+		}
+
+		query, args, err = sq.Delete(failuresTableName).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"subject": subject}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to delete login failures: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lockout, nil
+}
+
+// Reset в одной транзакции удаляет неудачные попытки и блокировку subject.
+func (r *repo) Reset(ctx context.Context, subject string) error {
+	return r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for _, tableName := range []string{failuresTableName, lockoutsTableName} {
+			query, args, err := sq.Delete(tableName).
+				PlaceholderFormat(sq.Dollar).
+				Where(sq.Eq{"subject": subject}).
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("unable to create SQL query from builder: %w", err)
+			}
+
+			if _, err = tx.Exec(ctx, query, args...); err != nil {
+				return fmt.Errorf("unable to delete from %s: %w", tableName, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListActive возвращает действующие блокировки, начиная с самых поздних.
+func (r *repo) ListActive(ctx context.Context) ([]*model.Lockout, error) {
+	query, args, err := sq.Select("subject", "locked_until", "lockout_count").
+		PlaceholderFormat(sq.Dollar).
+		From(lockoutsTableName).
+		Where(sq.Gt{"locked_until": time.Now().UTC()}).
+		OrderBy("locked_until DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select lockouts: %w", err)
+	}
+	defer rows.Close()
+
+	var lockouts []*model.Lockout
+	for rows.Next() {
+		var lockout model.Lockout
+		if err = rows.Scan(&lockout.Subject, &lockout.LockedUntil, &lockout.LockoutCount); err != nil {
+			return nil, fmt.Errorf("unable to scan lockout: %w", err)
+		}
+		lockouts = append(lockouts, &lockout)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read lockouts: %w", err)
+	}
+
+	return lockouts, nil
+}
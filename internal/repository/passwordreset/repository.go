@@ -0,0 +1,127 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "password_reset_tokens"
+
+// repo - хранилище токенов сброса пароля в таблице password_reset_tokens,
+// реализующее интерфейс repository.PasswordResetRepository.
+//
+// Время истечения хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище токенов сброса пароля, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.PasswordResetRepository {
+	return &repo{db: db}
+}
+
+// Create сохраняет хеш токена сброса пароля и удаляет из таблицы истекшие токены.
+func (r *repo) Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("token_hash", "user_id", "expires_at").
+		Values(tokenHash, userID, expiresAt.UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to insert password reset token: %w", err)
+	}
+
+	// Истекшие токены уже нельзя использовать
+	query, args, err = sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Lt{"expires_at": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to delete expired password reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserID возвращает ID пользователя, которому выпущен токен с хешем tokenHash, если токен
+// еще действует. Токен при этом не используется.
+func (r *repo) GetUserID(ctx context.Context, tokenHash string) (int64, error) {
+	query, args, err := sq.Select("user_id").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{"token_hash": tokenHash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var userID int64
+	err = r.db.QueryRow(ctx, query, args...).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, repository.ErrPasswordResetTokenNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to get password reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// Consume помечает токен с хешем tokenHash использованным, если он еще действует,
+// и удаляет остальные неиспользованные токены того же пользователя.
+//
+// Проверка и пометка выполняются одним запросом, поэтому токен нельзя использовать дважды
+// даже при одновременных запросах.
+func (r *repo) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", time.Now().UTC()).
+		Where(sq.Eq{"token_hash": tokenHash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": time.Now().UTC()}).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var userID int64
+	err = r.db.QueryRow(ctx, query, args...).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, repository.ErrPasswordResetTokenNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to consume password reset token: %w", err)
+	}
+
+	// Ссылки из более ранних писем после сброса пароля больше не действуют
+	query, args, err = sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"user_id": userID, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("unable to delete unused password reset tokens: %w", err)
+	}
+
+	return userID, nil
+}
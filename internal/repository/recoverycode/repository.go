@@ -0,0 +1,88 @@
+package recoverycode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "recovery_codes"
+
+// repo - хранилище кодов восстановления в таблице recovery_codes,
+// реализующее интерфейс repository.RecoveryCodeRepository.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище кодов восстановления, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.RecoveryCodeRepository {
+	return &repo{db: db}
+}
+
+// Replace в одной транзакции удаляет все коды пользователя userID, в том числе использованные,
+// и сохраняет новые.
+func (r *repo) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+	return r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query, args, err := sq.Delete(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"user_id": userID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to delete recovery codes: %w", err)
+		}
+
+		if len(codeHashes) == 0 {
+			return nil
+		}
+
+		builderInsert := sq.Insert(tableName).
+			PlaceholderFormat(sq.Dollar).
+			Columns("user_id", "code_hash")
+		for _, codeHash := range codeHashes {
+			builderInsert = builderInsert.Values(userID, codeHash)
+		}
+
+		query, args, err = builderInsert.ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to create SQL query from builder: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("unable to insert recovery codes: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Use помечает неиспользованный код с хешем codeHash использованным.
+//
+// Проверка и пометка выполняются одним запросом, поэтому код нельзя использовать дважды
+// даже при одновременных запросах.
+func (r *repo) Use(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", time.Now().UTC()).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	commandTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("unable to use recovery code: %w", err)
+	}
+
+	return commandTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anton0701/auth/internal/model"
+)
+
+var (
+	// ErrUserNotFound - пользователь не найден.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrPasswordResetTokenNotFound - токен сброса пароля не найден, истек либо уже использован.
+	ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+
+	// ErrEmailVerificationTokenNotFound - токен подтверждения email не найден либо истек.
+	ErrEmailVerificationTokenNotFound = errors.New("email verification token not found")
+
+	// ErrTOTPNotFound - пользователь не начинал подключение TOTP.
+	ErrTOTPNotFound = errors.New("totp not found")
+
+	// ErrAPIKeyNotFound - API-ключ не найден, отозван либо истек.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrServiceAccountNotFound - сервисный аккаунт не найден либо отключен.
+	ErrServiceAccountNotFound = errors.New("service account not found")
+
+	// ErrServiceAccountExists - сервисный аккаунт с таким именем уже существует.
+	ErrServiceAccountExists = errors.New("service account already exists")
+)
+
+// BatchItemError - ошибка сохранения элемента пакета с индексом Index.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+// Error возвращает ошибку с индексом элемента.
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку элемента.
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// UserRepository - интерфейс хранилища пользователей.
+//
+// Методы:
+//   - Create: сохраняет пользователя с уже вычисленным хешем пароля и возвращает его ID.
+//   - CreateBatch: сохраняет пользователей с хешами паролей passwordHashes (в том же порядке)
+//     в одной транзакции и возвращает их ID. Если пользователя сохранить не удалось, транзакция
+//     откатывается и возвращается *BatchItemError с его индексом.
+//   - GetCredentialsByEmail: возвращает ID, роль и хеш пароля пользователя по email (без учета регистра)
+//     либо ErrUserNotFound.
+//   - GetCredentials: возвращает ID, имя, email, роль и хеш пароля пользователя по ID либо ErrUserNotFound.
+//   - UpdatePasswordHash: заменяет хеш пароля пользователя, не изменяя updated_at.
+//   - UpdatePassword: заменяет хеш пароля пользователя при смене пароля, обновляет updated_at
+//     и отзывает ранее выпущенные refresh-токены пользователя либо возвращает ErrUserNotFound.
+//   - GetRole: возвращает роль пользователя либо ErrUserNotFound.
+//   - GetTokenState: возвращает роль пользователя и время отзыва его refresh-токенов либо ErrUserNotFound.
+type UserRepository interface {
+	Create(ctx context.Context, user *model.UserToCreate, passwordHash string) (int64, error)
+	CreateBatch(ctx context.Context, users []*model.UserToCreate, passwordHashes []string) ([]int64, error)
+	GetCredentialsByEmail(ctx context.Context, email string) (*model.UserCredentials, error)
+	GetCredentials(ctx context.Context, id int64) (*model.UserCredentials, error)
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	GetRole(ctx context.Context, id int64) (int32, error)
+	GetTokenState(ctx context.Context, id int64) (*model.UserTokenState, error)
+}
+
+// RevocationRepository - интерфейс хранилища отозванных токенов.
+//
+// Токен хранится до истечения срока его действия: после этого он недействителен и без записи в списке.
+//
+// Методы:
+//   - Revoke: добавляет идентификатор токена (jti) в список отозванных.
+//   - IsRevoked: возвращает true, если токен с идентификатором jti отозван.
+type RevocationRepository interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// AccessRepository - интерфейс хранилища правил доступа к методам.
+//
+// Методы:
+//   - GetAccessibleRoles: возвращает роли, которым разрешен вызов метода, для всех методов
+//     с требованиями к роли. Ключ - полное имя метода gRPC.
+//   - SetAccessibleRoles: заменяет список ролей, которым разрешен вызов метода endpoint.
+//     Пустой список снимает требования к роли (метод доступен с любым действующим токеном).
+type AccessRepository interface {
+	GetAccessibleRoles(ctx context.Context) (map[string][]int32, error)
+	SetAccessibleRoles(ctx context.Context, endpoint string, roles []int32) error
+}
+
+// PasswordResetRepository - интерфейс хранилища одноразовых токенов сброса пароля.
+//
+// Хранится только хеш токена, сам токен известен лишь получателю письма.
+//
+// Методы:
+//   - Create: сохраняет хеш нового токена пользователя userID со сроком действия до expiresAt.
+//   - GetUserID: возвращает ID пользователя, которому выпущен действующий токен, не используя токен,
+//     либо ErrPasswordResetTokenNotFound.
+//   - Consume: помечает действующий токен использованным и возвращает ID пользователя
+//     либо ErrPasswordResetTokenNotFound. Остальные неиспользованные токены пользователя удаляются.
+type PasswordResetRepository interface {
+	Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	GetUserID(ctx context.Context, tokenHash string) (int64, error)
+	Consume(ctx context.Context, tokenHash string) (int64, error)
+}
+
+// EmailVerificationRepository - интерфейс хранилища одноразовых токенов подтверждения email.
+//
+// Хранится только хеш токена, сам токен известен лишь получателю письма.
+//
+// Методы:
+//   - Create: сохраняет хеш нового токена пользователя userID со сроком действия до expiresAt.
+//   - Verify: отмечает email пользователя, которому выпущен действующий токен, подтвержденным,
+//     удаляет все его токены и возвращает ID пользователя либо ErrEmailVerificationTokenNotFound.
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	Verify(ctx context.Context, tokenHash string) (int64, error)
+}
+
+// TOTPRepository - интерфейс хранилища секретов двухфакторной аутентификации (TOTP).
+//
+// Методы:
+//   - Get: возвращает секрет пользователя либо ErrTOTPNotFound.
+//   - SavePending: сохраняет новый неподтвержденный секрет пользователя, заменяя неподтвержденный
+//     секрет, если он есть. Подтвержденный секрет не изменяется.
+//   - Confirm: отмечает секрет пользователя подтвержденным и запоминает шаг принятого кода.
+//   - UseStep: запоминает шаг принятого кода подтвержденного секрета и возвращает false,
+//     если код этого или более позднего шага уже был принят.
+type TOTPRepository interface {
+	Get(ctx context.Context, userID int64) (*model.TOTP, error)
+	SavePending(ctx context.Context, userID int64, encryptedSecret string) error
+	Confirm(ctx context.Context, userID, step int64) error
+	UseStep(ctx context.Context, userID, step int64) (bool, error)
+}
+
+// RecoveryCodeRepository - интерфейс хранилища одноразовых кодов восстановления доступа
+// для двухфакторной аутентификации.
+//
+// Хранятся только хеши кодов.
+//
+// Методы:
+//   - Replace: заменяет все коды пользователя новым набором хешей.
+//   - Use: помечает неиспользованный код пользователя с хешем codeHash использованным
+//     и возвращает false, если такого кода нет.
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID int64, codeHashes []string) error
+	Use(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// LockoutRepository - интерфейс хранилища неудачных попыток входа и блокировок входа.
+//
+// Попытки и блокировки относятся к subject - email или IP-адресу.
+//
+// Методы:
+//   - GetLockedUntil: возвращает самое позднее время окончания действующих блокировок subjects
+//     либо нулевое время, если действующих блокировок нет.
+//   - AddFailure: сохраняет неудачную попытку subject, удаляет попытки старше window и возвращает
+//     количество попыток за window.
+//   - Lock: блокирует subject на время, зависящее от количества блокировок подряд, и удаляет его
+//     неудачные попытки. Возвращает блокировку.
+//   - Reset: удаляет неудачные попытки и блокировку subject.
+//   - ListActive: возвращает действующие блокировки.
+type LockoutRepository interface {
+	GetLockedUntil(ctx context.Context, subjects []string) (time.Time, error)
+	AddFailure(ctx context.Context, subject string, window time.Duration) (int, error)
+	Lock(ctx context.Context, subject string, duration func(lockoutCount int) time.Duration) (*model.Lockout, error)
+	Reset(ctx context.Context, subject string) error
+	ListActive(ctx context.Context) ([]*model.Lockout, error)
+}
+
+// APIKeyRepository - интерфейс хранилища API-ключей для вызовов от других сервисов.
+//
+// Хранится только хеш ключа, сам ключ известен лишь сервису, которому он выпущен.
+//
+// Методы:
+//   - Create: сохраняет хеш нового ключа и возвращает ID ключа.
+//   - Use: возвращает действующий ключ с хешем keyHash и отмечает время его использования
+//     либо возвращает ErrAPIKeyNotFound.
+//   - Revoke: отмечает ключ отозванным либо возвращает ErrAPIKeyNotFound, если ключа нет
+//     или он уже отозван.
+//   - List: возвращает все выпущенные ключи.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKeyToCreate, keyHash string) (int64, error)
+	Use(ctx context.Context, keyHash string) (*model.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*model.APIKey, error)
+}
+
+// AuditRepository - интерфейс хранилища журнала аудита событий безопасности.
+//
+// Методы:
+//   - Create: записывает событие.
+//   - List: возвращает события, подходящие под filter, начиная с самых новых.
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEventToCreate) error
+	List(ctx context.Context, filter *model.AuditFilter) ([]*model.AuditEvent, error)
+}
+
+// ServiceAccountRepository - интерфейс хранилища сервисных аккаунтов.
+//
+// Хранится только хеш секрета, сам секрет известен лишь сервису, которому выдан аккаунт.
+//
+// Методы:
+//   - Create: сохраняет аккаунт с client_id и хешем секрета и возвращает ID аккаунта
+//     либо ErrServiceAccountExists, если имя уже занято.
+//   - UseCredentials: возвращает действующий аккаунт с clientID и хешем секрета secretHash и отмечает
+//     время выпуска токена либо возвращает ErrServiceAccountNotFound.
+//   - UpdateSecretHash: заменяет хеш секрета действующего аккаунта и возвращает аккаунт
+//     либо ErrServiceAccountNotFound.
+//   - Disable: отмечает аккаунт отключенным либо возвращает ErrServiceAccountNotFound, если аккаунта нет
+//     или он уже отключен.
+//   - List: возвращает все аккаунты.
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, account *model.ServiceAccountToCreate, clientID, secretHash string) (int64, error)
+	UseCredentials(ctx context.Context, clientID, secretHash string) (*model.ServiceAccount, error)
+	UpdateSecretHash(ctx context.Context, id int64, secretHash string) (*model.ServiceAccount, error)
+	Disable(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*model.ServiceAccount, error)
+}
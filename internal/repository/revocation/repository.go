@@ -0,0 +1,80 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "revoked_tokens"
+
+// repo - хранилище отозванных токенов в таблице revoked_tokens,
+// реализующее интерфейс repository.RevocationRepository.
+//
+// Время истечения хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище отозванных токенов, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.RevocationRepository {
+	return &repo{db: db}
+}
+
+// Revoke добавляет токен в список отозванных и удаляет из списка истекшие токены.
+func (r *repo) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("jti", "expires_at").
+		Values(jti, expiresAt.UTC()).
+		Suffix("ON CONFLICT (jti) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to revoke token: %w", err)
+	}
+
+	// Истекшие токены недействительны и без записи в списке
+	query, args, err = sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Lt{"expires_at": time.Now().UTC()}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to delete expired revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked возвращает true, если токен с идентификатором jti отозван.
+func (r *repo) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	query, args, err := sq.Select("1").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{"jti": jti}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var revoked bool
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("unable to check revoked token: %w", err)
+	}
+
+	return revoked, nil
+}
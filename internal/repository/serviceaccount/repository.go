@@ -0,0 +1,195 @@
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const (
+	tableName = "service_accounts"
+
+	// uniqueViolationCode - код ошибки Postgres "unique_violation".
+	uniqueViolationCode = "23505"
+)
+
+// columns - колонки таблицы service_accounts в порядке, в котором их читает scanServiceAccount.
+var columns = []string{"id", "name", "client_id", "role", "created_at", "secret_rotated_at", "last_token_at", "disabled_at"}
+
+// repo - хранилище сервисных аккаунтов в таблице service_accounts, реализующее интерфейс
+// repository.ServiceAccountRepository.
+//
+// Время хранится в UTC.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище сервисных аккаунтов, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.ServiceAccountRepository {
+	return &repo{db: db}
+}
+
+// Create сохраняет новый аккаунт с client_id clientID и хешем секрета secretHash и возвращает ID аккаунта.
+func (r *repo) Create(ctx context.Context, account *model.ServiceAccountToCreate, clientID, secretHash string) (int64, error) {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("name", "client_id", "client_secret_hash", "role", "created_at").
+		Values(account.Name, clientID, secretHash, account.Role, time.Now().UTC()).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var id int64
+	err = r.db.QueryRow(ctx, query, args...).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return 0, repository.ErrServiceAccountExists
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert service account: %w", err)
+	}
+
+	return id, nil
+}
+
+// UseCredentials возвращает действующий (не отключенный) аккаунт с clientID и хешем секрета secretHash
+// и отмечает время выпуска токена.
+//
+// Проверка и отметка выполняются одним запросом, поэтому отключенный аккаунт не получит токен
+// даже при одновременном отключении.
+func (r *repo) UseCredentials(ctx context.Context, clientID, secretHash string) (*model.ServiceAccount, error) {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("last_token_at", time.Now().UTC()).
+		Where(sq.Eq{"client_id": clientID, "client_secret_hash": secretHash, "disabled_at": nil}).
+		Suffix("RETURNING " + joinColumns()).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	account, err := scanServiceAccount(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to use service account credentials: %w", err)
+	}
+
+	return account, nil
+}
+
+// UpdateSecretHash заменяет хеш секрета действующего аккаунта с ID id и возвращает аккаунт.
+func (r *repo) UpdateSecretHash(ctx context.Context, id int64, secretHash string) (*model.ServiceAccount, error) {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("client_secret_hash", secretHash).
+		Set("secret_rotated_at", time.Now().UTC()).
+		Where(sq.Eq{"id": id, "disabled_at": nil}).
+		Suffix("RETURNING " + joinColumns()).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	account, err := scanServiceAccount(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to update service account secret: %w", err)
+	}
+
+	return account, nil
+}
+
+// Disable отмечает аккаунт с ID id отключенным.
+func (r *repo) Disable(ctx context.Context, id int64) error {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("disabled_at", time.Now().UTC()).
+		Where(sq.Eq{"id": id, "disabled_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to disable service account: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrServiceAccountNotFound
+	}
+
+	return nil
+}
+
+// List возвращает все аккаунты, включая отключенные, начиная с самых новых.
+func (r *repo) List(ctx context.Context) ([]*model.ServiceAccount, error) {
+	query, args, err := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*model.ServiceAccount
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan service account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read service accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// joinColumns возвращает колонки для RETURNING через запятую.
+func joinColumns() string {
+	return strings.Join(columns, ", ")
+}
+
+// scanServiceAccount читает аккаунт из строки результата запроса.
+func scanServiceAccount(row pgx.Row) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	err := row.Scan(
+		&account.ID,
+		&account.Name,
+		&account.ClientID,
+		&account.Role,
+		&account.CreatedAt,
+		&account.SecretRotatedAt,
+		&account.LastTokenAt,
+		&account.DisabledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
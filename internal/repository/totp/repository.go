@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "user_totp"
+
+// repo - хранилище секретов TOTP в таблице user_totp, реализующее интерфейс repository.TOTPRepository.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище секретов TOTP, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.TOTPRepository {
+	return &repo{db: db}
+}
+
+// Get возвращает секрет TOTP пользователя userID.
+func (r *repo) Get(ctx context.Context, userID int64) (*model.TOTP, error) {
+	query, args, err := sq.Select("encrypted_secret", "confirmed_at IS NOT NULL", "last_used_step").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var totp model.TOTP
+	err = r.db.QueryRow(ctx, query, args...).Scan(&totp.EncryptedSecret, &totp.Confirmed, &totp.LastUsedStep)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrTOTPNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to select totp: %w", err)
+	}
+
+	return &totp, nil
+}
+
+// SavePending сохраняет неподтвержденный секрет TOTP пользователя userID.
+func (r *repo) SavePending(ctx context.Context, userID int64, encryptedSecret string) error {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("user_id", "encrypted_secret").
+		Values(userID, encryptedSecret).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET encrypted_secret = excluded.encrypted_secret, " +
+			"last_used_step = 0, created_at = now() WHERE user_totp.confirmed_at IS NULL").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to save totp: %w", err)
+	}
+
+	return nil
+}
+
+// Confirm отмечает неподтвержденный секрет TOTP пользователя userID подтвержденным.
+func (r *repo) Confirm(ctx context.Context, userID, step int64) error {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("confirmed_at", sq.Expr("now()")).
+		Set("last_used_step", step).
+		Where(sq.Eq{"user_id": userID, "confirmed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	commandTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to confirm totp: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return repository.ErrTOTPNotFound
+	}
+
+	return nil
+}
+
+// UseStep запоминает шаг step принятого кода, если он больше последнего принятого.
+//
+// Проверка и обновление выполняются одним запросом, поэтому один код нельзя использовать
+// дважды даже при одновременных запросах.
+func (r *repo) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("last_used_step", step).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"confirmed_at": nil}).
+		Where(sq.Lt{"last_used_step": step}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	commandTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("unable to update totp last used step: %w", err)
+	}
+
+	return commandTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,213 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+const tableName = "auth"
+
+// repo - хранилище пользователей в таблице auth, реализующее интерфейс repository.UserRepository.
+//
+// Ошибки Postgres возвращаются обернутыми через %w, чтобы вызывающий код мог проверить
+// нарушение ограничений через errors.As.
+type repo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository создает хранилище пользователей, работающее через пул соединений db.
+func NewRepository(db *pgxpool.Pool) repository.UserRepository {
+	return &repo{db: db}
+}
+
+// Create сохраняет пользователя с хешем пароля passwordHash и возвращает его ID.
+func (r *repo) Create(ctx context.Context, user *model.UserToCreate, passwordHash string) (int64, error) {
+	query, args, err := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns("name", "email", "password", "role", "phone").
+		Values(user.Name, user.Email, passwordHash, user.Role, user.Phone).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var id int64
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to insert user: %w", err)
+	}
+
+	return id, nil
+}
+
+// CreateBatch сохраняет пользователей users с хешами паролей passwordHashes в одной транзакции
+// и возвращает их ID в том же порядке.
+func (r *repo) CreateBatch(ctx context.Context, users []*model.UserToCreate, passwordHashes []string) ([]int64, error) {
+	if len(users) != len(passwordHashes) {
+		return nil, errors.New("users and password hashes count mismatch")
+	}
+
+	ids := make([]int64, len(users))
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for i, user := range users {
+			query, args, err := sq.Insert(tableName).
+				PlaceholderFormat(sq.Dollar).
+				Columns("name", "email", "password", "role", "phone").
+				Values(user.Name, user.Email, passwordHashes[i], user.Role, user.Phone).
+				Suffix("RETURNING id").
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("unable to create SQL query from builder: %w", err)
+			}
+
+			// После ошибки транзакция откатывается, поэтому остальные пользователи не сохраняются
+			if err = tx.QueryRow(ctx, query, args...).Scan(&ids[i]); err != nil {
+				return &repository.BatchItemError{Index: i, Err: fmt.Errorf("unable to insert user: %w", err)}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetCredentialsByEmail возвращает данные для проверки пароля пользователя с email без учета регистра.
+func (r *repo) GetCredentialsByEmail(ctx context.Context, email string) (*model.UserCredentials, error) {
+	return r.getCredentials(ctx, sq.Expr("lower(email) = lower(?)", strings.TrimSpace(email)))
+}
+
+// GetCredentials возвращает данные для проверки пароля пользователя с ID id.
+func (r *repo) GetCredentials(ctx context.Context, id int64) (*model.UserCredentials, error) {
+	return r.getCredentials(ctx, sq.Eq{"id": id})
+}
+
+// getCredentials возвращает ID, имя, email, роль, хеш пароля и признак подтверждения email пользователя, удовлетворяющего условию where.
+func (r *repo) getCredentials(ctx context.Context, where sq.Sqlizer) (*model.UserCredentials, error) {
+	query, args, err := sq.Select("id", "name", "email", "coalesce(role, 0)", "password", "email_verified").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var credentials model.UserCredentials
+	err = r.db.QueryRow(ctx, query, args...).Scan(&credentials.ID, &credentials.Name, &credentials.Email, &credentials.Role, &credentials.PasswordHash, &credentials.EmailVerified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to select user credentials: %w", err)
+	}
+
+	return &credentials, nil
+}
+
+// UpdatePasswordHash заменяет хеш пароля пользователя с ID id.
+//
+// Используется при пересчете хеша устаревшим алгоритмом, поэтому updated_at не изменяется:
+// данные пользователя остаются прежними.
+func (r *repo) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("password", passwordHash).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to update password hash: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword заменяет хеш пароля пользователя с ID id и обновляет updated_at.
+//
+// Время отзыва токенов tokens_valid_after берется из часов сервера, а не БД: с ним сравнивается
+// время выпуска токенов, которое тоже записывает сервер.
+func (r *repo) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	query, args, err := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set("password", passwordHash).
+		Set("updated_at", sq.Expr("now()")).
+		Set("tokens_valid_after", time.Now().UTC().Truncate(time.Microsecond)).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	commandTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to update password: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// GetRole возвращает роль пользователя с ID id.
+func (r *repo) GetRole(ctx context.Context, id int64) (int32, error) {
+	query, args, err := sq.Select("coalesce(role, 0)").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var role int32
+	err = r.db.QueryRow(ctx, query, args...).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to select user role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetTokenState возвращает роль пользователя с ID id и время отзыва его refresh-токенов.
+func (r *repo) GetTokenState(ctx context.Context, id int64) (*model.UserTokenState, error) {
+	query, args, err := sq.Select("coalesce(role, 0)", "tokens_valid_after").
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SQL query from builder: %w", err)
+	}
+
+	var state model.UserTokenState
+	err = r.db.QueryRow(ctx, query, args...).Scan(&state.Role, &state.TokensValidAfter)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to select user token state: %w", err)
+	}
+
+	return &state, nil
+}
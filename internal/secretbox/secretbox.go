@@ -0,0 +1,71 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+// KeyLength - длина ключа шифрования в байтах (AES-256).
+const KeyLength = 32
+
+// SecretBox шифрует секреты перед сохранением в БД алгоритмом AES-256-GCM.
+//
+// Зашифрованное значение - base64 от случайного nonce и шифротекста с тегом аутентификации,
+// поэтому подмена значения в БД обнаруживается при расшифровке.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// New создает SecretBox с ключом key длиной KeyLength байт.
+func New(key []byte) (*SecretBox, error) {
+	if len(key) != KeyLength {
+		return nil, errors.Errorf("secretbox key must be %d bytes", KeyLength)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create gcm")
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal шифрует plaintext.
+func (b *SecretBox) Seal(plaintext []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "unable to generate nonce")
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open расшифровывает значение, полученное из Seal.
+func (b *SecretBox) Open(sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sealed value")
+	}
+
+	nonceSize := b.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("sealed value is too short")
+	}
+
+	plaintext, err := b.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decrypt sealed value")
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,144 @@
+package access
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/model"
+)
+
+// publicEndpoints - методы, которые можно вызывать без access-токена и API-ключа: вход и обновление
+// токенов, регистрация, сброс пароля и подтверждение email, проверка готовности сервера, а также
+// методы, которые сами проверяют переданный в запросе токен или ключ. Остальные методы требуют действующего токена или ключа,
+// даже если для них не заданы требования к роли.
+//
+// Если для публичного метода задать роли через SetAccessibleRoles (например, чтобы закрыть
+// самостоятельную регистрацию), он перестает быть публичным.
+var publicEndpoints = map[string]struct{}{
+	"/auth_v1.AuthV1/Login":                  {},
+	"/auth_v1.AuthV1/GetRefreshToken":        {},
+	"/auth_v1.AuthV1/GetAccessToken":         {},
+	"/auth_v1.AuthV1/Logout":                 {},
+	"/auth_v1.AuthV1/RequestPasswordReset":   {},
+	"/auth_v1.AuthV1/ConfirmPasswordReset":   {},
+	"/auth_v1.AuthV1/VerifyEmail":            {},
+	"/auth_v1.AuthV1/GetServiceAccountToken": {},
+	"/access_v1.AccessV1/Check":              {},
+	"/user_v1.UserV1/CreateUser":             {},
+	"/grpc.health.v1.Health/Check":           {},
+	"/grpc.health.v1.Health/Watch":           {},
+}
+
+// builtinEndpointRoles - правила доступа, которые заданы в коде и не могут быть изменены через АПИ.
+//
+// Защищают методы управления правилами, блокировками входа, API-ключами, сервисными аккаунтами,
+// вход от имени пользователя, журнал аудита, массовое создание пользователей и проверку email-адресов
+// перед импортом: иначе администратор мог бы случайно открыть их для всех.
+var builtinEndpointRoles = map[string][]int32{
+	"/access_v1.AccessV1/ListAccessibleRoles":        {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/SetAccessibleRoles":         {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/IssueAPIKey":                {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/RevokeAPIKey":               {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/ListAPIKeys":                {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/CreateServiceAccount":       {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/RotateServiceAccountSecret": {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/DisableServiceAccount":      {int32(desc.UserRole_ADMIN)},
+	"/access_v1.AccessV1/ListServiceAccounts":        {int32(desc.UserRole_ADMIN)},
+	"/auth_v1.AuthV1/ListLockouts":                   {int32(desc.UserRole_ADMIN)},
+	"/auth_v1.AuthV1/ClearLockout":                   {int32(desc.UserRole_ADMIN)},
+	"/auth_v1.AuthV1/ImpersonateUser":                {int32(desc.UserRole_ADMIN)},
+	"/auth_v1.AuthV1/ListAuditEvents":                {int32(desc.UserRole_ADMIN)},
+	"/user_v1.UserV1/BatchCreateUsers":               {int32(desc.UserRole_ADMIN)},
+	"/user_v1.UserV1/ValidateEmails":                 {int32(desc.UserRole_ADMIN)},
+}
+
+// rolesFor возвращает роли, которым разрешен вызов метода endpoint, и false, если для метода
+// не заданы требования к роли.
+func (s *serv) rolesFor(ctx context.Context, endpoint string) ([]int32, bool, error) {
+	if roles, ok := builtinEndpointRoles[endpoint]; ok {
+		return roles, true, nil
+	}
+
+	accessibleRoles, err := s.accessibleRoles(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	roles, ok := accessibleRoles[endpoint]
+	return roles, ok, nil
+}
+
+// roleEndpoints возвращает методы с требованиями к роли, в которые входит роль role, по алфавиту.
+//
+// Для методов с правилами в коде (builtinEndpointRoles) правила из БД не учитываются.
+func (s *serv) roleEndpoints(ctx context.Context, role int32) ([]string, error) {
+	accessibleRoles, err := s.accessibleRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+	addEndpoint := func(endpoint string, roles []int32) {
+		for _, r := range roles {
+			if r == role {
+				endpoints = append(endpoints, endpoint)
+				return
+			}
+		}
+	}
+	for endpoint, roles := range accessibleRoles {
+		if _, ok := builtinEndpointRoles[endpoint]; !ok {
+			addEndpoint(endpoint, roles)
+		}
+	}
+	for endpoint, roles := range builtinEndpointRoles {
+		addEndpoint(endpoint, roles)
+	}
+	sort.Strings(endpoints)
+
+	return endpoints, nil
+}
+
+// isAdmin возвращает true, если вызывающий - пользователь или API-ключ с ролью ADMIN либо
+// сервисный аккаунт с ролью SERVICE_ADMIN.
+func isAdmin(caller *model.Caller) bool {
+	switch desc.UserRole(caller.Role) {
+	case desc.UserRole_ADMIN:
+		return true
+	case desc.UserRole_SERVICE_ADMIN:
+		return len(caller.ClientID) > 0
+	default:
+		return false
+	}
+}
+
+// accessibleRoles возвращает правила доступа из БД, перечитывая их не чаще, чем раз в policyCacheTTL.
+func (s *serv) accessibleRoles(ctx context.Context) (map[string][]int32, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.cachedAt) < s.policyCacheTTL {
+		defer s.mu.RUnlock()
+		return s.cache, nil
+	}
+	s.mu.RUnlock()
+
+	accessibleRoles, err := s.accessRepository.GetAccessibleRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = accessibleRoles
+	s.cachedAt = time.Now()
+	s.mu.Unlock()
+
+	return accessibleRoles, nil
+}
+
+// invalidateCache сбрасывает кеш правил доступа, чтобы изменения применились сразу.
+func (s *serv) invalidateCache() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,259 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// serv - сервис проверки доступа, реализующий интерфейс service.AccessService.
+type serv struct {
+	accessKeys           *token.Keyring
+	issuer               token.IssuerParams
+	revocationRepository repository.RevocationRepository
+	accessRepository     repository.AccessRepository
+	apiKeyRepository     repository.APIKeyRepository
+	policyCacheTTL       time.Duration
+	log                  *zap.Logger
+
+	mu       sync.RWMutex
+	cache    map[string][]int32
+	cachedAt time.Time
+}
+
+// NewService создает сервис проверки доступа.
+//
+// Параметры:
+//   - accessKeys: ключи, которыми подписаны access-токены.
+//   - issuer: ожидаемые издатель и получатели access-токенов.
+//   - revocationRepository: хранилище отозванных токенов.
+//   - accessRepository: хранилище правил доступа к методам.
+//   - apiKeyRepository: хранилище API-ключей других сервисов.
+//   - policyCacheTTL: время кеширования правил доступа.
+//   - log: логгер, в который записываются запросы с токенами, выпущенными от имени пользователя.
+func NewService(
+	accessKeys *token.Keyring,
+	issuer token.IssuerParams,
+	revocationRepository repository.RevocationRepository,
+	accessRepository repository.AccessRepository,
+	apiKeyRepository repository.APIKeyRepository,
+	policyCacheTTL time.Duration,
+	log *zap.Logger,
+) service.AccessService {
+	return &serv{
+		accessKeys:           accessKeys,
+		issuer:               issuer,
+		revocationRepository: revocationRepository,
+		accessRepository:     accessRepository,
+		apiKeyRepository:     apiKeyRepository,
+		policyCacheTTL:       policyCacheTTL,
+		log:                  log,
+	}
+}
+
+// Check проверяет access-токен и наличие у пользователя роли, которой разрешен вызов метода endpoint,
+// и возвращает вызывающего пользователя или сервисный аккаунт.
+//
+// Для методов без требований к роли достаточно действующего access-токена. Каждый запрос
+// с токеном, выпущенным администратору от имени пользователя, логируется.
+func (s *serv) Check(ctx context.Context, accessToken, endpoint string) (*model.Caller, error) {
+	claims, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ImpersonatedBy != 0 {
+		s.log.Warn("Access service. Request on behalf of user",
+			zap.String("Endpoint", endpoint),
+			zap.Int64("Admin-id", claims.ImpersonatedBy),
+			zap.Int64("User-id", claims.UserID),
+			zap.String("Token-id", claims.ID),
+		)
+	}
+
+	if err = s.checkRole(ctx, claims.Role, endpoint); err != nil {
+		return nil, err
+	}
+
+	return &model.Caller{
+		UserID:         claims.UserID,
+		Role:           claims.Role,
+		ClientID:       claims.ClientID,
+		ImpersonatedBy: claims.ImpersonatedBy,
+	}, nil
+}
+
+// IntrospectToken проверяет access-токен так же, как Check, и возвращает его данные.
+//
+// Администратор (в том числе API-ключ с ролью ADMIN и сервисный аккаунт с ролью SERVICE_ADMIN)
+// может проверить любой токен, остальные вызывающие - только токен, выпущенный им самим. Чужой
+// токен считается недействительным, чтобы по ответу нельзя было узнать, действует ли он.
+//
+// Scopes - методы, для которых заданы требования к роли и роль токена в них входит. Методы без
+// требований к роли в Scopes не попадают: их можно вызывать с любым действующим токеном.
+func (s *serv) IntrospectToken(ctx context.Context, caller *model.Caller, accessToken string) (*model.TokenIntrospection, error) {
+	claims, err := s.verify(ctx, accessToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return &model.TokenIntrospection{Active: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !canIntrospect(caller, claims) {
+		return &model.TokenIntrospection{Active: false}, nil
+	}
+
+	scopes, err := s.roleEndpoints(ctx, claims.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenIntrospection{
+		Active:         true,
+		UserID:         claims.UserID,
+		Role:           claims.Role,
+		Scopes:         scopes,
+		ImpersonatedBy: claims.ImpersonatedBy,
+		ClientID:       claims.ClientID,
+		IssuedAt:       claims.IssuedAt.Time,
+		ExpiresAt:      claims.ExpiresAt.Time,
+	}, nil
+}
+
+// canIntrospect возвращает true, если вызывающий caller - администратор (см. isAdmin) или тот же
+// пользователь либо сервисный аккаунт, которому выпущен токен с данными claims.
+func canIntrospect(caller *model.Caller, claims *token.UserClaims) bool {
+	switch {
+	case caller == nil:
+		return false
+	case isAdmin(caller):
+		return true
+	case len(claims.ClientID) > 0:
+		return caller.ClientID == claims.ClientID
+	default:
+		return caller.UserID != 0 && caller.UserID == claims.UserID
+	}
+}
+
+// GetRolePermissions возвращает методы с требованиями к роли, вызов которых разрешен роли role,
+// по алфавиту. Методы без требований к роли разрешены любой роли и в список не входят.
+//
+// Администратор может запросить права любой роли, остальные вызывающие - только своей.
+func (s *serv) GetRolePermissions(ctx context.Context, caller *model.Caller, role int32) ([]string, error) {
+	if caller == nil || (!isAdmin(caller) && caller.Role != role) {
+		return nil, service.ErrAccessDenied
+	}
+
+	return s.roleEndpoints(ctx, role)
+}
+
+// verify проверяет подпись и срок действия access-токена и что токен не отозван.
+func (s *serv) verify(ctx context.Context, accessToken string) (*token.UserClaims, error) {
+	claims, err := token.Verify(accessToken, token.UseAccess, s.accessKeys, s.issuer)
+	if err != nil {
+		return nil, service.ErrInvalidToken
+	}
+
+	revoked, err := s.revocationRepository.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, service.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// CheckAPIKey проверяет API-ключ другого сервиса и наличие у ключа роли, которой разрешен
+// вызов метода endpoint, и возвращает вызывающий сервис.
+//
+// Для методов без требований к роли достаточно действующего ключа.
+func (s *serv) CheckAPIKey(ctx context.Context, apiKey, endpoint string) (*model.Caller, error) {
+	key, err := s.apiKeyRepository.Use(ctx, token.HashOpaque(apiKey))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, service.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.checkRole(ctx, key.Role, endpoint); err != nil {
+		return nil, err
+	}
+
+	return &model.Caller{
+		Role:     key.Role,
+		APIKeyID: key.ID,
+	}, nil
+}
+
+// checkRole возвращает ErrAccessDenied, если роли role не разрешен вызов метода endpoint.
+func (s *serv) checkRole(ctx context.Context, role int32, endpoint string) error {
+	allowedRoles, ok, err := s.rolesFor(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	for _, allowedRole := range allowedRoles {
+		if role == allowedRole {
+			return nil
+		}
+	}
+
+	return service.ErrAccessDenied
+}
+
+// IsPublic возвращает true, если метод endpoint входит в publicEndpoints и для него не заданы
+// требования к роли. Методы, которых нет в publicEndpoints, публичными не бывают.
+func (s *serv) IsPublic(ctx context.Context, endpoint string) (bool, error) {
+	if _, ok := publicEndpoints[endpoint]; !ok {
+		return false, nil
+	}
+
+	_, ok, err := s.rolesFor(ctx, endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
+
+// ListAccessibleRoles возвращает правила доступа из БД вместе с правилами, заданными в коде.
+func (s *serv) ListAccessibleRoles(ctx context.Context) (map[string][]int32, error) {
+	accessibleRoles, err := s.accessRepository.GetAccessibleRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for endpoint, roles := range builtinEndpointRoles {
+		accessibleRoles[endpoint] = roles
+	}
+
+	return accessibleRoles, nil
+}
+
+// SetAccessibleRoles заменяет список ролей метода endpoint и сбрасывает кеш правил доступа.
+func (s *serv) SetAccessibleRoles(ctx context.Context, endpoint string, roles []int32) error {
+	if _, ok := builtinEndpointRoles[endpoint]; ok {
+		return service.ErrProtectedEndpoint
+	}
+
+	if err := s.accessRepository.SetAccessibleRoles(ctx, endpoint, roles); err != nil {
+		return err
+	}
+
+	s.invalidateCache()
+	return nil
+}
@@ -0,0 +1,481 @@
+package access
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	desc "github.com/anton0701/auth/grpc/pkg/user_v1"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// fakeAccessRepository - хранилище правил доступа в памяти.
+type fakeAccessRepository struct {
+	repository.AccessRepository
+	roles map[string][]int32
+}
+
+func (r *fakeAccessRepository) GetAccessibleRoles(_ context.Context) (map[string][]int32, error) {
+	roles := make(map[string][]int32, len(r.roles))
+	for endpoint, endpointRoles := range r.roles {
+		roles[endpoint] = endpointRoles
+	}
+
+	return roles, nil
+}
+
+// fakeRevocationRepository - хранилище отозванных токенов revoked.
+type fakeRevocationRepository struct {
+	repository.RevocationRepository
+	revoked map[string]bool
+}
+
+func (r *fakeRevocationRepository) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return r.revoked[jti], nil
+}
+
+// fakeAPIKeyRepository - хранилище API-ключей в памяти. Ключ - хеш API-ключа.
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys map[string]*model.APIKey
+}
+
+func (r *fakeAPIKeyRepository) Use(_ context.Context, keyHash string) (*model.APIKey, error) {
+	key, ok := r.keys[keyHash]
+	if !ok || key.RevokedAt.Valid || (key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now())) {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+func TestCheckValidateEmails(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	s := NewService(keys, token.IssuerParams{}, &fakeRevocationRepository{}, &fakeAccessRepository{}, nil, 0, zap.NewNop())
+
+	tests := []struct {
+		name    string
+		role    desc.UserRole
+		wantErr error
+	}{
+		{name: "admin", role: desc.UserRole_ADMIN},
+		{name: "user", role: desc.UserRole_USER, wantErr: service.ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken, _, err := token.Generate(1, int32(tt.role), token.UseAccess, keys, time.Minute, token.IssuerParams{})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			_, err = s.Check(context.Background(), accessToken, "/user_v1.UserV1/ValidateEmails")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	accessRepository := &fakeAccessRepository{roles: map[string][]int32{
+		"/user_v1.UserV1/DeleteUser": {2},
+		// Администратор закрыл самостоятельную регистрацию
+		"/user_v1.UserV1/CreateUser": {2},
+	}}
+	s := NewService(nil, token.IssuerParams{}, nil, accessRepository, nil, 0, zap.NewNop())
+
+	tests := []struct {
+		endpoint string
+		want     bool
+	}{
+		{endpoint: "/auth_v1.AuthV1/Login", want: true},
+		{endpoint: "/auth_v1.AuthV1/ConfirmPasswordReset", want: true},
+		{endpoint: "/user_v1.UserV1/CreateUser", want: false},
+		{endpoint: "/user_v1.UserV1/DeleteUser", want: false},
+		{endpoint: "/user_v1.UserV1/UpdateUser", want: false},
+		{endpoint: "/user_v1.UserV1/ListUsers", want: false},
+		{endpoint: "/auth_v1.AuthV1/ListAuditEvents", want: false},
+		{endpoint: "/access_v1.AccessV1/IntrospectToken", want: false},
+		{endpoint: "/unknown.Service/Method", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := s.IsPublic(context.Background(), tt.endpoint)
+			if err != nil {
+				t.Fatalf("IsPublic() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsPublic() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicEndpointsHaveNoBuiltinRoles(t *testing.T) {
+	for endpoint := range publicEndpoints {
+		if _, ok := builtinEndpointRoles[endpoint]; ok {
+			t.Errorf("public endpoint %s has builtin roles", endpoint)
+		}
+	}
+}
+
+func TestCheckAPIKey(t *testing.T) {
+	past := sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	future := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+
+	apiKeyRepository := &fakeAPIKeyRepository{keys: map[string]*model.APIKey{
+		token.HashOpaque("ak_user"):    {ID: 1, Role: int32(desc.UserRole_USER)},
+		token.HashOpaque("ak_admin"):   {ID: 2, Role: int32(desc.UserRole_ADMIN), ExpiresAt: future},
+		token.HashOpaque("ak_revoked"): {ID: 3, Role: int32(desc.UserRole_ADMIN), RevokedAt: past},
+		token.HashOpaque("ak_expired"): {ID: 4, Role: int32(desc.UserRole_ADMIN), ExpiresAt: past},
+	}}
+	s := NewService(nil, token.IssuerParams{}, nil, &fakeAccessRepository{}, apiKeyRepository, 0, zap.NewNop())
+
+	const adminEndpoint = "/access_v1.AccessV1/ListAPIKeys"
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		endpoint  string
+		wantErr   error
+		wantKeyID int64
+	}{
+		{name: "valid key", apiKey: "ak_user", endpoint: "/user_v1.UserV1/GetUserInfo", wantKeyID: 1},
+		{name: "valid key with role", apiKey: "ak_admin", endpoint: adminEndpoint, wantKeyID: 2},
+		{name: "insufficient role", apiKey: "ak_user", endpoint: adminEndpoint, wantErr: service.ErrAccessDenied},
+		{name: "revoked key", apiKey: "ak_revoked", endpoint: adminEndpoint, wantErr: service.ErrInvalidAPIKey},
+		{name: "expired key", apiKey: "ak_expired", endpoint: adminEndpoint, wantErr: service.ErrInvalidAPIKey},
+		{name: "unknown key", apiKey: "ak_unknown", endpoint: adminEndpoint, wantErr: service.ErrInvalidAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := s.CheckAPIKey(context.Background(), tt.apiKey, tt.endpoint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckAPIKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (caller.APIKeyID != tt.wantKeyID || caller.UserID != 0) {
+				t.Errorf("CheckAPIKey() caller = %+v, want API key %d", caller, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+func TestIntrospectToken(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	generate := func(role desc.UserRole, use token.Use, ttl time.Duration) (string, *token.UserClaims) {
+		value, claims, err := token.Generate(1, int32(role), use, keys, ttl, token.IssuerParams{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		return value, claims
+	}
+
+	userToken, _ := generate(desc.UserRole_USER, token.UseAccess, time.Hour)
+	adminToken, _ := generate(desc.UserRole_ADMIN, token.UseAccess, time.Hour)
+	expiredToken, _ := generate(desc.UserRole_USER, token.UseAccess, -time.Hour)
+	refreshToken, _ := generate(desc.UserRole_USER, token.UseRefresh, time.Hour)
+	revokedToken, revokedClaims := generate(desc.UserRole_USER, token.UseAccess, time.Hour)
+
+	impersonationToken, _, err := token.GenerateImpersonation(1, int32(desc.UserRole_USER), 2, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("GenerateImpersonation() error = %v", err)
+	}
+
+	accessRepository := &fakeAccessRepository{roles: map[string][]int32{
+		"/user_v1.UserV1/ListUsers":  {int32(desc.UserRole_USER), int32(desc.UserRole_ADMIN)},
+		"/user_v1.UserV1/DeleteUser": {int32(desc.UserRole_ADMIN)},
+	}}
+	revocationRepository := &fakeRevocationRepository{revoked: map[string]bool{revokedClaims.ID: true}}
+	s := NewService(keys, token.IssuerParams{}, revocationRepository, accessRepository, nil, 0, zap.NewNop())
+
+	tests := []struct {
+		name               string
+		token              string
+		wantActive         bool
+		wantRole           desc.UserRole
+		wantScope          string
+		wantNoScope        string
+		wantImpersonatedBy int64
+	}{
+		{
+			name:        "user token",
+			token:       userToken,
+			wantActive:  true,
+			wantRole:    desc.UserRole_USER,
+			wantScope:   "/user_v1.UserV1/ListUsers",
+			wantNoScope: "/user_v1.UserV1/DeleteUser",
+		},
+		{
+			name:       "admin token",
+			token:      adminToken,
+			wantActive: true,
+			wantRole:   desc.UserRole_ADMIN,
+			wantScope:  "/auth_v1.AuthV1/ImpersonateUser",
+		},
+		{
+			name:               "impersonation token",
+			token:              impersonationToken,
+			wantActive:         true,
+			wantRole:           desc.UserRole_USER,
+			wantNoScope:        "/auth_v1.AuthV1/ImpersonateUser",
+			wantImpersonatedBy: 2,
+		},
+		{name: "expired token", token: expiredToken},
+		{name: "revoked token", token: revokedToken},
+		{name: "refresh token", token: refreshToken},
+		{name: "malformed token", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.IntrospectToken(context.Background(), &model.Caller{UserID: 5, Role: int32(desc.UserRole_ADMIN)}, tt.token)
+			if err != nil {
+				t.Fatalf("IntrospectToken() error = %v", err)
+			}
+			if got.Active != tt.wantActive {
+				t.Fatalf("IntrospectToken() active = %t, want %t", got.Active, tt.wantActive)
+			}
+			if !got.Active {
+				if got.UserID != 0 || len(got.Scopes) > 0 {
+					t.Errorf("IntrospectToken() of inactive token = %+v, want only active = false", got)
+				}
+				return
+			}
+
+			if got.UserID != 1 || got.Role != int32(tt.wantRole) || got.ImpersonatedBy != tt.wantImpersonatedBy {
+				t.Errorf("IntrospectToken() = %+v, want user 1 with role %s impersonated by %d",
+					got, tt.wantRole, tt.wantImpersonatedBy)
+			}
+			if got.IssuedAt.IsZero() || !got.ExpiresAt.After(time.Now()) {
+				t.Errorf("IntrospectToken() = %+v, want issue time and future expiry time", got)
+			}
+
+			scopes := make(map[string]bool, len(got.Scopes))
+			for _, scope := range got.Scopes {
+				scopes[scope] = true
+			}
+			if tt.wantScope != "" && !scopes[tt.wantScope] {
+				t.Errorf("IntrospectToken() scopes = %v, want %s", got.Scopes, tt.wantScope)
+			}
+			if tt.wantNoScope != "" && scopes[tt.wantNoScope] {
+				t.Errorf("IntrospectToken() scopes = %v, contain %s", got.Scopes, tt.wantNoScope)
+			}
+		})
+	}
+
+}
+
+func TestIntrospectTokenScope(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	userToken, _, err := token.Generate(1, int32(desc.UserRole_USER), token.UseAccess, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	expiredToken, _, err := token.Generate(1, int32(desc.UserRole_USER), token.UseAccess, keys, -time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	serviceAccountToken, _, err := token.GenerateServiceAccount("sa_importer", int32(desc.UserRole_SERVICE), keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("GenerateServiceAccount() error = %v", err)
+	}
+
+	s := NewService(keys, token.IssuerParams{}, &fakeRevocationRepository{}, &fakeAccessRepository{}, nil, 0, zap.NewNop())
+
+	tests := []struct {
+		name       string
+		caller     *model.Caller
+		token      string
+		wantActive bool
+	}{
+		{name: "own token", caller: &model.Caller{UserID: 1, Role: int32(desc.UserRole_USER)}, token: userToken, wantActive: true},
+		{name: "own expired token", caller: &model.Caller{UserID: 1, Role: int32(desc.UserRole_USER)}, token: expiredToken},
+		{name: "own invalid token", caller: &model.Caller{UserID: 1, Role: int32(desc.UserRole_USER)}, token: "not-a-token"},
+		// Чужой действующий токен неотличим от недействительного
+		{name: "token of another user", caller: &model.Caller{UserID: 2, Role: int32(desc.UserRole_USER)}, token: userToken},
+		{name: "admin", caller: &model.Caller{UserID: 2, Role: int32(desc.UserRole_ADMIN)}, token: userToken, wantActive: true},
+		{name: "admin api key", caller: &model.Caller{APIKeyID: 7, Role: int32(desc.UserRole_ADMIN)}, token: userToken, wantActive: true},
+		{name: "non-admin api key", caller: &model.Caller{APIKeyID: 7, Role: int32(desc.UserRole_USER)}, token: userToken},
+		{
+			name:       "admin service account",
+			caller:     &model.Caller{ClientID: "sa_gateway", Role: int32(desc.UserRole_SERVICE_ADMIN)},
+			token:      userToken,
+			wantActive: true,
+		},
+		{
+			name:       "own service account token",
+			caller:     &model.Caller{ClientID: "sa_importer", Role: int32(desc.UserRole_SERVICE)},
+			token:      serviceAccountToken,
+			wantActive: true,
+		},
+		{
+			name:   "token of another service account",
+			caller: &model.Caller{ClientID: "sa_gateway", Role: int32(desc.UserRole_SERVICE)},
+			token:  serviceAccountToken,
+		},
+		{name: "no caller", token: userToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.IntrospectToken(context.Background(), tt.caller, tt.token)
+			if err != nil {
+				t.Fatalf("IntrospectToken() error = %v", err)
+			}
+			if got.Active != tt.wantActive {
+				t.Errorf("IntrospectToken() active = %t, want %t", got.Active, tt.wantActive)
+			}
+			if !got.Active && (got.UserID != 0 || len(got.ClientID) > 0 || got.Role != 0) {
+				t.Errorf("IntrospectToken() of inactive token = %+v, want only active = false", got)
+			}
+		})
+	}
+}
+
+func TestGetRolePermissions(t *testing.T) {
+	const (
+		listUsers  = "/user_v1.UserV1/ListUsers"
+		deleteUser = "/user_v1.UserV1/DeleteUser"
+		// Правило из БД для метода с правилом в коде не учитывается
+		setAccessibleRoles = "/access_v1.AccessV1/SetAccessibleRoles"
+	)
+
+	accessRepository := &fakeAccessRepository{roles: map[string][]int32{
+		listUsers:          {int32(desc.UserRole_USER), int32(desc.UserRole_ADMIN)},
+		deleteUser:         {int32(desc.UserRole_ADMIN)},
+		setAccessibleRoles: {int32(desc.UserRole_USER)},
+	}}
+	s := NewService(nil, token.IssuerParams{}, &fakeRevocationRepository{}, accessRepository, nil, 0, zap.NewNop())
+
+	// Методы с правилами в коде, разрешенные роли
+	builtinEndpoints := func(role desc.UserRole) []string {
+		var endpoints []string
+		for endpoint, roles := range builtinEndpointRoles {
+			for _, r := range roles {
+				if r == int32(role) {
+					endpoints = append(endpoints, endpoint)
+				}
+			}
+		}
+		return endpoints
+	}
+	sorted := func(endpoints ...string) []string {
+		sort.Strings(endpoints)
+		return endpoints
+	}
+
+	admin := &model.Caller{UserID: 1, Role: int32(desc.UserRole_ADMIN)}
+	user := &model.Caller{UserID: 2, Role: int32(desc.UserRole_USER)}
+
+	tests := []struct {
+		name    string
+		caller  *model.Caller
+		role    desc.UserRole
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "admin",
+			caller: admin,
+			role:   desc.UserRole_ADMIN,
+			want:   sorted(append(builtinEndpoints(desc.UserRole_ADMIN), listUsers, deleteUser)...),
+		},
+		{
+			name:   "user",
+			caller: user,
+			role:   desc.UserRole_USER,
+			want:   sorted(append(builtinEndpoints(desc.UserRole_USER), listUsers)...),
+		},
+		{
+			name:   "admin asks for user role",
+			caller: admin,
+			role:   desc.UserRole_USER,
+			want:   sorted(append(builtinEndpoints(desc.UserRole_USER), listUsers)...),
+		},
+		{name: "user asks for admin role", caller: user, role: desc.UserRole_ADMIN, wantErr: service.ErrAccessDenied},
+		{name: "no caller", role: desc.UserRole_USER, wantErr: service.ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetRolePermissions(context.Background(), tt.caller, int32(tt.role))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetRolePermissions() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRolePermissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckImpersonateUser(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	s := NewService(keys, token.IssuerParams{}, &fakeRevocationRepository{}, &fakeAccessRepository{}, nil, 0, zap.NewNop())
+
+	const endpoint = "/auth_v1.AuthV1/ImpersonateUser"
+
+	adminToken, _, err := token.Generate(1, int32(desc.UserRole_ADMIN), token.UseAccess, keys, time.Minute, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	userToken, _, err := token.Generate(2, int32(desc.UserRole_USER), token.UseAccess, keys, time.Minute, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// Администратор, действующий от имени пользователя, получает права пользователя
+	impersonationToken, _, err := token.GenerateImpersonation(2, int32(desc.UserRole_USER), 1, keys, time.Minute, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("GenerateImpersonation() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "admin", token: adminToken},
+		{name: "user", token: userToken, wantErr: service.ErrAccessDenied},
+		{name: "impersonation", token: impersonationToken, wantErr: service.ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Check(context.Background(), tt.token, endpoint); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Правило задано в коде, открыть метод другим ролям нельзя
+	err = s.SetAccessibleRoles(context.Background(), endpoint, []int32{int32(desc.UserRole_USER)})
+	if !errors.Is(err, service.ErrProtectedEndpoint) {
+		t.Errorf("SetAccessibleRoles() error = %v, want %v", err, service.ErrProtectedEndpoint)
+	}
+}
@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// keyPrefix - префикс API-ключей, по которому их легко отличить от других секретов
+// (например, при поиске утекших ключей в логах и репозиториях).
+const keyPrefix = "ak_"
+
+// serv - сервис API-ключей, реализующий интерфейс service.APIKeyService.
+type serv struct {
+	apiKeyRepository repository.APIKeyRepository
+	auditService     service.AuditService
+}
+
+// NewService создает сервис API-ключей, работающий с хранилищем apiKeyRepository.
+// Отзывы ключей записываются в журнал аудита auditService.
+func NewService(apiKeyRepository repository.APIKeyRepository, auditService service.AuditService) service.APIKeyService {
+	return &serv{
+		apiKeyRepository: apiKeyRepository,
+		auditService:     auditService,
+	}
+}
+
+// Issue выпускает случайный API-ключ и сохраняет его хеш.
+func (s *serv) Issue(ctx context.Context, key *model.APIKeyToCreate) (int64, string, error) {
+	opaque, err := token.GenerateOpaque()
+	if err != nil {
+		return 0, "", err
+	}
+	apiKey := keyPrefix + opaque
+
+	id, err := s.apiKeyRepository.Create(ctx, key, token.HashOpaque(apiKey))
+	if err != nil {
+		return 0, "", err
+	}
+
+	return id, apiKey, nil
+}
+
+// Revoke отзывает ключ с ID id. Запросы с отозванным ключом сразу перестают проходить проверку доступа.
+func (s *serv) Revoke(ctx context.Context, id int64) error {
+	err := s.apiKeyRepository.Revoke(ctx, id)
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return service.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventAPIKeyRevoked, 0, map[string]string{"api_key_id": strconv.FormatInt(id, 10)})
+
+	return nil
+}
+
+// List возвращает все выпущенные ключи.
+func (s *serv) List(ctx context.Context) ([]*model.APIKey, error) {
+	return s.apiKeyRepository.List(ctx)
+}
@@ -0,0 +1,137 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// fakeAPIKeyRepository - хранилище API-ключей в памяти с той же семантикой, что и хранилище в БД.
+type fakeAPIKeyRepository struct {
+	keys   []*model.APIKey
+	hashes map[string]int64
+}
+
+func (r *fakeAPIKeyRepository) Create(_ context.Context, key *model.APIKeyToCreate, keyHash string) (int64, error) {
+	id := int64(len(r.keys) + 1)
+	r.keys = append(r.keys, &model.APIKey{
+		ID:             id,
+		ServiceAccount: key.ServiceAccount,
+		Role:           key.Role,
+		CreatedAt:      time.Now(),
+		ExpiresAt:      key.ExpiresAt,
+	})
+
+	if r.hashes == nil {
+		r.hashes = make(map[string]int64)
+	}
+	r.hashes[keyHash] = id
+
+	return id, nil
+}
+
+func (r *fakeAPIKeyRepository) Use(_ context.Context, keyHash string) (*model.APIKey, error) {
+	id, ok := r.hashes[keyHash]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+
+	key := r.keys[id-1]
+	if key.RevokedAt.Valid || (key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now())) {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepository) Revoke(_ context.Context, id int64) error {
+	if id < 1 || id > int64(len(r.keys)) || r.keys[id-1].RevokedAt.Valid {
+		return repository.ErrAPIKeyNotFound
+	}
+	r.keys[id-1].RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) List(_ context.Context) ([]*model.APIKey, error) {
+	return r.keys, nil
+}
+
+// fakeAuditService - журнал аудита, запоминающий типы записанных событий.
+type fakeAuditService struct {
+	service.AuditService
+	events []model.AuditEventType
+}
+
+func (s *fakeAuditService) Record(_ context.Context, eventType model.AuditEventType, _ int64, _ map[string]string) {
+	s.events = append(s.events, eventType)
+}
+
+func TestIssue(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	s := NewService(repo, &fakeAuditService{})
+
+	id, apiKey, err := s.Issue(context.Background(), &model.APIKeyToCreate{ServiceAccount: "billing", Role: 1})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	if !strings.HasPrefix(apiKey, keyPrefix) {
+		t.Errorf("Issue() key = %q, want prefix %q", apiKey, keyPrefix)
+	}
+	// Хранится только хеш ключа
+	if _, ok := repo.hashes[apiKey]; ok {
+		t.Error("Issue() stored the key itself")
+	}
+	if got := repo.hashes[token.HashOpaque(apiKey)]; got != id {
+		t.Errorf("Issue() stored the key hash with ID %d, want %d", got, id)
+	}
+
+	_, otherKey, err := s.Issue(context.Background(), &model.APIKeyToCreate{ServiceAccount: "billing", Role: 1})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if otherKey == apiKey {
+		t.Error("Issue() returned the same key twice")
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	audit := &fakeAuditService{}
+	s := NewService(repo, audit)
+
+	id, _, err := s.Issue(context.Background(), &model.APIKeyToCreate{ServiceAccount: "billing", Role: 1})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	if err = s.Revoke(context.Background(), id); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if len(audit.events) != 1 || audit.events[0] != model.AuditEventAPIKeyRevoked {
+		t.Errorf("Revoke() recorded %v, want [%s]", audit.events, model.AuditEventAPIKeyRevoked)
+	}
+
+	if !repo.keys[id-1].RevokedAt.Valid {
+		t.Error("Revoke() did not revoke the key")
+	}
+
+	for _, id := range []int64{id, 100} {
+		if err = s.Revoke(context.Background(), id); !errors.Is(err, service.ErrAPIKeyNotFound) {
+			t.Errorf("Revoke(%d) error = %v, want %v", id, err, service.ErrAPIKeyNotFound)
+		}
+	}
+	if len(audit.events) != 1 {
+		t.Errorf("failed Revoke() recorded %v", audit.events[1:])
+	}
+}
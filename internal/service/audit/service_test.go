@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/internal/identity"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+)
+
+// fakeAuditRepository - журнал аудита в памяти.
+type fakeAuditRepository struct {
+	repository.AuditRepository
+	events []*model.AuditEventToCreate
+}
+
+func (r *fakeAuditRepository) Create(_ context.Context, event *model.AuditEventToCreate) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestRecordActor(t *testing.T) {
+	tests := []struct {
+		name        string
+		caller      *model.Caller
+		wantActorID sql.NullInt64
+		wantDetails map[string]string
+	}{
+		{
+			name:        "anonymous",
+			wantDetails: map[string]string{},
+		},
+		{
+			name:        "user",
+			caller:      &model.Caller{UserID: 7, Role: 2},
+			wantActorID: sql.NullInt64{Int64: 7, Valid: true},
+			wantDetails: map[string]string{},
+		},
+		{
+			name:        "impersonation",
+			caller:      &model.Caller{UserID: 7, Role: 1, ImpersonatedBy: 3},
+			wantActorID: sql.NullInt64{Int64: 7, Valid: true},
+			wantDetails: map[string]string{"impersonated_by": "3"},
+		},
+		{
+			name:        "service account",
+			caller:      &model.Caller{Role: 2, ClientID: "billing"},
+			wantDetails: map[string]string{"actor_client_id": "billing"},
+		},
+		{
+			name:        "api key",
+			caller:      &model.Caller{Role: 2, APIKeyID: 5},
+			wantDetails: map[string]string{"actor_api_key_id": "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = identity.WithCaller(ctx, tt.caller)
+			}
+
+			auditRepository := &fakeAuditRepository{}
+			NewService(auditRepository, zap.NewNop()).Record(ctx, model.AuditEventServiceAccountDisabled, 0, nil)
+
+			if len(auditRepository.events) != 1 {
+				t.Fatalf("recorded %d events, want 1", len(auditRepository.events))
+			}
+			event := auditRepository.events[0]
+			if event.ActorID != tt.wantActorID {
+				t.Errorf("ActorID = %v, want %v", event.ActorID, tt.wantActorID)
+			}
+			if !reflect.DeepEqual(event.Details, tt.wantDetails) {
+				t.Errorf("Details = %v, want %v", event.Details, tt.wantDetails)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// serv - сервис аутентификации, реализующий интерфейс service.AuthService.
+type serv struct {
+	userService          service.UserService
+	twoFactorService     service.TwoFactorService
+	lockoutService       service.LockoutService
+	auditService         service.AuditService
+	userRepository       repository.UserRepository
+	revocationRepository repository.RevocationRepository
+	jwtConfig            env.JWTConfig
+	accessKeys           *token.Keyring
+	refreshKeys          *token.Keyring
+
+	// requireVerifiedEmail - true, если пользователи с неподтвержденным email не могут войти.
+	requireVerifiedEmail bool
+}
+
+// NewService создает сервис аутентификации.
+//
+// Параметры:
+//   - userService: сервис пользователей, проверяющий пароль.
+//   - twoFactorService: сервис двухфакторной аутентификации, проверяющий одноразовый код.
+//   - lockoutService: сервис защиты от подбора пароля, учитывающий неверные коды второго фактора
+//     и сбрасывающий неудачные попытки после входа.
+//   - auditService: журнал аудита, в который записываются входы, неудачные попытки входа, выход
+//     и выпуск токенов от имени пользователя.
+//   - userRepository: хранилище пользователей, из которого при обновлении токенов берется текущая роль
+//     и время отзыва refresh-токенов.
+//   - revocationRepository: хранилище отозванных токенов.
+//   - jwtConfig: время жизни, издатель и получатели токенов.
+//   - accessKeys: ключи подписи access-токенов.
+//   - refreshKeys: ключи подписи refresh-токенов.
+//   - requireVerifiedEmail: true, если пользователи с неподтвержденным email не могут войти.
+func NewService(
+	userService service.UserService,
+	twoFactorService service.TwoFactorService,
+	lockoutService service.LockoutService,
+	auditService service.AuditService,
+	userRepository repository.UserRepository,
+	revocationRepository repository.RevocationRepository,
+	jwtConfig env.JWTConfig,
+	accessKeys *token.Keyring,
+	refreshKeys *token.Keyring,
+	requireVerifiedEmail bool,
+) service.AuthService {
+	return &serv{
+		userService:          userService,
+		twoFactorService:     twoFactorService,
+		lockoutService:       lockoutService,
+		auditService:         auditService,
+		userRepository:       userRepository,
+		revocationRepository: revocationRepository,
+		jwtConfig:            jwtConfig,
+		accessKeys:           accessKeys,
+		refreshKeys:          refreshKeys,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Login проверяет email, пароль и второй фактор и выпускает access-токен и refresh-токен.
+//
+// Пока email или IP-адрес клиента заблокирован после неудачных попыток, пароль не проверяется.
+// Неудачной попыткой считается неверный пароль (учитывается сервисом пользователей при проверке
+// пароля) или неверный второй фактор. Неудачные попытки email сбрасываются после проверки второго фактора.
+//
+// Подтверждение email и второй фактор проверяются только после пароля, чтобы по ответу нельзя
+// было узнать, подтвержден ли email чужого пользователя и включена ли у него двухфакторная
+// аутентификация.
+func (s *serv) Login(ctx context.Context, email, password, clientIP string, secondFactor *model.SecondFactor) (*model.Token, *model.Token, error) {
+	credentials, err := s.userService.Authenticate(ctx, email, password, clientIP)
+	if errors.Is(err, service.ErrLoginLocked) {
+		s.recordLoginFailure(ctx, 0, email, "locked")
+		return nil, nil, err
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		s.recordLoginFailure(ctx, 0, email, "invalid_credentials")
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.requireVerifiedEmail && !credentials.EmailVerified {
+		s.recordLoginFailure(ctx, credentials.ID, email, "email_not_verified")
+		return nil, nil, service.ErrEmailNotVerified
+	}
+
+	err = s.verifySecondFactor(ctx, credentials.ID, secondFactor)
+	if errors.Is(err, service.ErrInvalidSecondFactor) {
+		s.recordLoginFailure(ctx, credentials.ID, email, "invalid_second_factor")
+		return nil, nil, s.registerFailure(ctx, email, clientIP, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = s.lockoutService.RegisterSuccess(ctx, email); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := s.generate(credentials.ID, credentials.Role, token.UseAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := s.generate(credentials.ID, credentials.Role, token.UseRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventLogin, credentials.ID, map[string]string{"email": email})
+
+	return accessToken, refreshToken, nil
+}
+
+// GetRefreshToken выпускает новый refresh-токен взамен действующего и отзывает действующий.
+func (s *serv) GetRefreshToken(ctx context.Context, refreshToken string) (*model.Token, error) {
+	claims, role, err := s.userFromRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := s.generate(claims.UserID, role, token.UseRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.revocationRepository.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	return newRefreshToken, nil
+}
+
+// GetAccessToken выпускает новый access-токен по действующему refresh-токену.
+//
+// Роль в новом токене берется из БД, поэтому изменение роли пользователя вступает в силу
+// при следующем обновлении access-токена.
+func (s *serv) GetAccessToken(ctx context.Context, refreshToken string) (*model.Token, error) {
+	claims, role, err := s.userFromRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generate(claims.UserID, role, token.UseAccess)
+}
+
+// Logout отзывает refresh-токен и access-токен, чтобы их нельзя было использовать до истечения.
+//
+// Access-токен необязателен: если он не передан или уже недействителен, отзывается только refresh-токен.
+func (s *serv) Logout(ctx context.Context, refreshToken, accessToken string) error {
+	refreshClaims, err := s.verify(ctx, refreshToken, token.UseRefresh)
+	if err != nil {
+		return err
+	}
+
+	if err = s.revocationRepository.Revoke(ctx, refreshClaims.ID, refreshClaims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	revoked := map[string]string{"refresh_token_id": refreshClaims.ID}
+	if len(accessToken) > 0 {
+		accessClaims, verifyErr := token.Verify(accessToken, token.UseAccess, s.accessKeys, s.issuerParams())
+		if verifyErr == nil && accessClaims.UserID == refreshClaims.UserID {
+			err = s.revocationRepository.Revoke(ctx, accessClaims.ID, accessClaims.ExpiresAt.Time)
+			if err == nil {
+				revoked["access_token_id"] = accessClaims.ID
+			}
+		}
+	}
+
+	// Refresh-токен уже отозван, поэтому событие записывается и при ошибке отзыва access-токена
+	s.auditService.Record(ctx, model.AuditEventTokenRevoked, refreshClaims.UserID, revoked)
+
+	return err
+}
+
+// recordLoginFailure записывает в журнал аудита неудачную попытку входа с email по причине reason.
+// userID - 0, если пользователь еще не определен.
+func (s *serv) recordLoginFailure(ctx context.Context, userID int64, email, reason string) {
+	s.auditService.Record(ctx, model.AuditEventLoginFailed, userID, map[string]string{
+		"email":  email,
+		"reason": reason,
+	})
+}
+
+// registerFailure учитывает неудачную проверку второго фактора и возвращает loginErr либо ошибку учета попытки.
+//
+// Если попытку не удалось учесть, возвращается ошибка учета, чтобы сбой БД не отключал защиту
+// от подбора пароля.
+func (s *serv) registerFailure(ctx context.Context, email, clientIP string, loginErr error) error {
+	if err := s.lockoutService.RegisterFailure(ctx, email, clientIP); err != nil {
+		return err
+	}
+
+	return loginErr
+}
+
+// verifySecondFactor проверяет второй фактор, если у пользователя включена двухфакторная аутентификация.
+//
+// Код из приложения проверяется в первую очередь, код восстановления - только если код
+// из приложения не передан.
+func (s *serv) verifySecondFactor(ctx context.Context, userID int64, secondFactor *model.SecondFactor) error {
+	enabled, err := s.twoFactorService.IsEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	switch {
+	case secondFactor != nil && len(secondFactor.TOTPCode) > 0:
+		return s.twoFactorService.Verify(ctx, userID, secondFactor.TOTPCode)
+	case secondFactor != nil && len(secondFactor.RecoveryCode) > 0:
+		return s.twoFactorService.VerifyRecoveryCode(ctx, userID, secondFactor.RecoveryCode)
+	default:
+		return service.ErrSecondFactorRequired
+	}
+}
+
+// VerifyAccessToken проверяет подпись и срок действия access-токена и что токен не отозван.
+//
+// Токен сервисного аккаунта считается недействительным: пользователя у него нет.
+func (s *serv) VerifyAccessToken(ctx context.Context, accessToken string) (int64, error) {
+	claims, err := s.verify(ctx, accessToken, token.UseAccess)
+	if err != nil {
+		return 0, err
+	}
+	if len(claims.ClientID) > 0 {
+		return 0, service.ErrInvalidToken
+	}
+
+	return claims.UserID, nil
+}
+
+// ImpersonateUser выпускает администратору access-токен от имени пользователя targetUserID.
+//
+// Роль администратора проверяется до вызова (метод доступен только администраторам). Токен
+// содержит текущую роль пользователя и claim "impersonated_by" с ID администратора, refresh-токен
+// не выпускается. Действовать от своего имени или выпускать новый токен по токену, уже выпущенному
+// от имени другого пользователя, нельзя, чтобы в claim всегда был реальный администратор.
+func (s *serv) ImpersonateUser(ctx context.Context, adminAccessToken string, targetUserID int64) (*model.Impersonation, error) {
+	adminClaims, err := s.verify(ctx, adminAccessToken, token.UseAccess)
+	if err != nil {
+		return nil, err
+	}
+	if adminClaims.ImpersonatedBy != 0 || adminClaims.UserID == targetUserID {
+		return nil, service.ErrImpersonationNotAllowed
+	}
+
+	role, err := s.userRepository.GetRole(ctx, targetUserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, service.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, claims, err := token.GenerateImpersonation(targetUserID, role, adminClaims.UserID, s.accessKeys,
+		s.jwtConfig.ImpersonationTokenTTL(), s.issuerParams())
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventImpersonation, targetUserID, map[string]string{
+		"admin_id": strconv.FormatInt(adminClaims.UserID, 10),
+		"token_id": claims.ID,
+	})
+
+	return &model.Impersonation{
+		Token: model.Token{
+			Value:     value,
+			ExpiresAt: claims.ExpiresAt.Time,
+		},
+		TokenID: claims.ID,
+		AdminID: adminClaims.UserID,
+		UserID:  targetUserID,
+	}, nil
+}
+
+// VerifyPassword повторно проверяет пароль вошедшего пользователя userID и выпускает короткоживущий
+// токен повторной аутентификации.
+//
+// Токен подтверждает, что пользователь только что ввел пароль, и передается в чувствительные
+// операции вместе с access-токеном. Новые access- и refresh-токены не выпускаются. Пароль
+// проверяется с защитой от подбора, как при входе.
+func (s *serv) VerifyPassword(ctx context.Context, userID int64, password, clientIP string) (*model.Token, error) {
+	if err := s.userService.VerifyPassword(ctx, userID, password, clientIP); err != nil {
+		return nil, err
+	}
+
+	role, err := s.userRepository.GetRole(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, service.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generate(userID, role, token.UseReauth)
+}
+
+// VerifyReauthToken проверяет, что токен повторной аутентификации действителен и выпущен
+// пользователю userID.
+func (s *serv) VerifyReauthToken(ctx context.Context, reauthToken string, userID int64) error {
+	claims, err := s.verify(ctx, reauthToken, token.UseReauth)
+	if err != nil {
+		return err
+	}
+	if claims.UserID != userID {
+		return service.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// userFromRefreshToken проверяет refresh-токен и возвращает его данные и текущую роль пользователя из БД.
+//
+// Токен, выпущенный до смены или сброса пароля, недействителен. Время выпуска в токене и время
+// отзыва хранятся с точностью до микросекунды и сравниваются без округления.
+func (s *serv) userFromRefreshToken(ctx context.Context, refreshToken string) (*token.UserClaims, int32, error) {
+	claims, err := s.verify(ctx, refreshToken, token.UseRefresh)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	state, err := s.userRepository.GetTokenState(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, 0, service.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if state.TokensValidAfter.Valid && (claims.IssuedAt == nil ||
+		claims.IssuedAt.Time.Before(state.TokensValidAfter.Time)) {
+		return nil, 0, service.ErrInvalidToken
+	}
+
+	return claims, state.Role, nil
+}
+
+// verify проверяет подпись, срок действия и назначение use токена и что токен не отозван.
+func (s *serv) verify(ctx context.Context, tokenString string, use token.Use) (*token.UserClaims, error) {
+	keys, _ := s.tokenParams(use)
+	claims, err := token.Verify(tokenString, use, keys, s.issuerParams())
+	if err != nil {
+		return nil, service.ErrInvalidToken
+	}
+
+	revoked, err := s.revocationRepository.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, service.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// generate выпускает пользователю токен с назначением use.
+func (s *serv) generate(userID int64, role int32, use token.Use) (*model.Token, error) {
+	keys, ttl := s.tokenParams(use)
+	value, claims, err := token.Generate(userID, role, use, keys, ttl, s.issuerParams())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Token{
+		Value:     value,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// tokenParams возвращает ключи подписи и время жизни токенов с назначением use.
+func (s *serv) tokenParams(use token.Use) (*token.Keyring, time.Duration) {
+	switch use {
+	case token.UseRefresh:
+		return s.refreshKeys, s.jwtConfig.RefreshTokenTTL()
+	case token.UseReauth:
+		return s.accessKeys, s.jwtConfig.ReauthTokenTTL()
+	default:
+		return s.accessKeys, s.jwtConfig.AccessTokenTTL()
+	}
+}
+
+// issuerParams возвращает издателя и получателей токенов из конфига.
+func (s *serv) issuerParams() token.IssuerParams {
+	return token.IssuerParams{
+		Issuer:   s.jwtConfig.Issuer(),
+		Audience: s.jwtConfig.Audience(),
+	}
+}
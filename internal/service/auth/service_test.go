@@ -0,0 +1,534 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+// fakeJWTConfig - конфиг токенов со временем жизни refresh-токена в один час.
+type fakeJWTConfig struct {
+	env.JWTConfig
+}
+
+func (c *fakeJWTConfig) AccessTokenTTL() time.Duration        { return time.Minute }
+func (c *fakeJWTConfig) RefreshTokenTTL() time.Duration       { return time.Hour }
+func (c *fakeJWTConfig) ImpersonationTokenTTL() time.Duration { return 10 * time.Minute }
+func (c *fakeJWTConfig) ReauthTokenTTL() time.Duration        { return 5 * time.Minute }
+func (c *fakeJWTConfig) Issuer() string                       { return "" }
+func (c *fakeJWTConfig) Audience() []string                   { return nil }
+
+// fakeUserRepository - хранилище с единственным пользователем и заданным временем отзыва токенов.
+type fakeUserRepository struct {
+	repository.UserRepository
+	state *model.UserTokenState
+}
+
+func (r *fakeUserRepository) GetTokenState(_ context.Context, _ int64) (*model.UserTokenState, error) {
+	if r.state == nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return r.state, nil
+}
+
+func (r *fakeUserRepository) GetRole(_ context.Context, _ int64) (int32, error) {
+	if r.state == nil {
+		return 0, repository.ErrUserNotFound
+	}
+
+	return r.state.Role, nil
+}
+
+// fakeRevocationRepository - хранилище отозванных токенов в памяти.
+type fakeRevocationRepository struct {
+	revoked map[string]struct{}
+}
+
+func (r *fakeRevocationRepository) Revoke(_ context.Context, jti string, _ time.Time) error {
+	if r.revoked == nil {
+		r.revoked = make(map[string]struct{})
+	}
+	r.revoked[jti] = struct{}{}
+
+	return nil
+}
+
+func (r *fakeRevocationRepository) IsRevoked(_ context.Context, jti string) (bool, error) {
+	_, ok := r.revoked[jti]
+	return ok, nil
+}
+
+// fakeUserService - сервис пользователей с единственным пользователем credentials и паролем password.
+type fakeUserService struct {
+	service.UserService
+	credentials *model.UserCredentials
+	password    string
+	locked      bool
+}
+
+func (s *fakeUserService) Authenticate(_ context.Context, email, password, _ string) (*model.UserCredentials, error) {
+	if s.locked {
+		return nil, service.ErrLoginLocked
+	}
+	if email != s.credentials.Email || password != s.password {
+		return nil, service.ErrInvalidCredentials
+	}
+
+	return s.credentials, nil
+}
+
+func (s *fakeUserService) VerifyPassword(_ context.Context, id int64, password, _ string) error {
+	if s.locked {
+		return service.ErrLoginLocked
+	}
+	if id != s.credentials.ID {
+		return service.ErrUserNotFound
+	}
+	if password != s.password {
+		return service.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// fakeTwoFactorService - сервис двухфакторной аутентификации с единственным верным кодом code.
+type fakeTwoFactorService struct {
+	service.TwoFactorService
+	enabled bool
+	code    string
+}
+
+func (s *fakeTwoFactorService) IsEnabled(_ context.Context, _ int64) (bool, error) {
+	return s.enabled, nil
+}
+
+func (s *fakeTwoFactorService) Verify(_ context.Context, _ int64, code string) error {
+	if code != s.code {
+		return service.ErrInvalidSecondFactor
+	}
+
+	return nil
+}
+
+// fakeLockoutService - сервис защиты от подбора пароля, запоминающий учтенные попытки.
+type fakeLockoutService struct {
+	service.LockoutService
+	failures  int
+	successes int
+}
+
+func (s *fakeLockoutService) RegisterFailure(_ context.Context, _, _ string) error {
+	s.failures++
+	return nil
+}
+
+func (s *fakeLockoutService) RegisterSuccess(_ context.Context, _ string) error {
+	s.successes++
+	return nil
+}
+
+// fakeAuditService - журнал аудита, запоминающий типы записанных событий.
+type fakeAuditService struct {
+	service.AuditService
+	events []model.AuditEventType
+}
+
+func (s *fakeAuditService) Record(_ context.Context, eventType model.AuditEventType, _ int64, _ map[string]string) {
+	s.events = append(s.events, eventType)
+}
+
+// testKeys возвращает ключи подписи токенов с единственным ключом.
+func testKeys(t *testing.T) *token.Keyring {
+	t.Helper()
+
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	return keys
+}
+
+func TestGetAccessTokenAfterPasswordChange(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	refreshToken, claims, err := token.Generate(1, 1, token.UseRefresh, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	issuedAt := claims.IssuedAt.Time
+
+	tests := []struct {
+		name    string
+		state   *model.UserTokenState
+		wantErr error
+	}{
+		{
+			name:  "tokens never revoked",
+			state: &model.UserTokenState{Role: 1},
+		},
+		{
+			name:  "password changed before token was issued",
+			state: &model.UserTokenState{Role: 1, TokensValidAfter: sql.NullTime{Time: issuedAt.Add(-time.Minute), Valid: true}},
+		},
+		{
+			name:  "password changed a millisecond before token was issued",
+			state: &model.UserTokenState{Role: 1, TokensValidAfter: sql.NullTime{Time: issuedAt.Add(-time.Millisecond), Valid: true}},
+		},
+		{
+			name:    "password changed a millisecond after token was issued",
+			state:   &model.UserTokenState{Role: 1, TokensValidAfter: sql.NullTime{Time: issuedAt.Add(time.Millisecond), Valid: true}},
+			wantErr: service.ErrInvalidToken,
+		},
+		{
+			name:    "password changed after token was issued",
+			state:   &model.UserTokenState{Role: 1, TokensValidAfter: sql.NullTime{Time: issuedAt.Add(time.Second), Valid: true}},
+			wantErr: service.ErrInvalidToken,
+		},
+		{
+			name:    "user deleted",
+			wantErr: service.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(nil, nil, nil, nil, &fakeUserRepository{state: tt.state}, &fakeRevocationRepository{},
+				&fakeJWTConfig{}, keys, keys, false)
+
+			_, err := s.GetAccessToken(context.Background(), refreshToken)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetAccessToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAccessTokenWithAccessToken(t *testing.T) {
+	keys, err := token.NewKeyring([]token.Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	accessToken, _, err := token.Generate(1, 1, token.UseAccess, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// Даже если ключи подписи совпадают, access-токен не принимается вместо refresh-токена
+	s := NewService(nil, nil, nil, nil, &fakeUserRepository{state: &model.UserTokenState{Role: 1}},
+		&fakeRevocationRepository{}, &fakeJWTConfig{}, keys, keys, false)
+
+	if _, err = s.GetAccessToken(context.Background(), accessToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("GetAccessToken() error = %v, want %v", err, service.ErrInvalidToken)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	credentials := &model.UserCredentials{ID: 7, Email: "user@example.com", Role: 1, EmailVerified: true}
+
+	tests := []struct {
+		name         string
+		password     string
+		locked       bool
+		twoFactor    *fakeTwoFactorService
+		secondFactor *model.SecondFactor
+		wantErr      error
+		wantEvent    model.AuditEventType
+		wantFailures int
+	}{
+		{
+			name:      "valid password",
+			password:  "password",
+			twoFactor: &fakeTwoFactorService{},
+			wantEvent: model.AuditEventLogin,
+		},
+		{
+			name:      "wrong password",
+			password:  "wrong",
+			twoFactor: &fakeTwoFactorService{},
+			wantErr:   service.ErrInvalidCredentials,
+			wantEvent: model.AuditEventLoginFailed,
+		},
+		{
+			name:      "locked",
+			password:  "password",
+			locked:    true,
+			twoFactor: &fakeTwoFactorService{},
+			wantErr:   service.ErrLoginLocked,
+			wantEvent: model.AuditEventLoginFailed,
+		},
+		{
+			name:      "second factor required",
+			password:  "password",
+			twoFactor: &fakeTwoFactorService{enabled: true, code: "123456"},
+			wantErr:   service.ErrSecondFactorRequired,
+		},
+		{
+			name:         "wrong second factor",
+			password:     "password",
+			twoFactor:    &fakeTwoFactorService{enabled: true, code: "123456"},
+			secondFactor: &model.SecondFactor{TOTPCode: "654321"},
+			wantErr:      service.ErrInvalidSecondFactor,
+			wantEvent:    model.AuditEventLoginFailed,
+			wantFailures: 1,
+		},
+		{
+			name:         "valid second factor",
+			password:     "password",
+			twoFactor:    &fakeTwoFactorService{enabled: true, code: "123456"},
+			secondFactor: &model.SecondFactor{TOTPCode: "123456"},
+			wantEvent:    model.AuditEventLogin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := testKeys(t)
+			lockout := &fakeLockoutService{}
+			audit := &fakeAuditService{}
+			users := &fakeUserService{credentials: credentials, password: "password", locked: tt.locked}
+			s := NewService(users, tt.twoFactor, lockout, audit, &fakeUserRepository{state: &model.UserTokenState{Role: 1}},
+				&fakeRevocationRepository{}, &fakeJWTConfig{}, keys, keys, false)
+
+			accessToken, refreshToken, err := s.Login(context.Background(), credentials.Email, tt.password, "10.0.0.1", tt.secondFactor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if lockout.failures != tt.wantFailures {
+				t.Errorf("Login() registered %d failures, want %d", lockout.failures, tt.wantFailures)
+			}
+			if tt.wantEvent != "" && (len(audit.events) != 1 || audit.events[0] != tt.wantEvent) {
+				t.Errorf("Login() recorded %v, want [%s]", audit.events, tt.wantEvent)
+			}
+			if err != nil {
+				return
+			}
+
+			if lockout.successes != 1 {
+				t.Errorf("Login() registered %d successes, want 1", lockout.successes)
+			}
+
+			userID, err := s.VerifyAccessToken(context.Background(), accessToken.Value)
+			if err != nil || userID != credentials.ID {
+				t.Errorf("VerifyAccessToken() = %d, %v, want %d", userID, err, credentials.ID)
+			}
+			if _, err = s.GetAccessToken(context.Background(), refreshToken.Value); err != nil {
+				t.Errorf("GetAccessToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	keys := testKeys(t)
+
+	generate := func(userID int64, use token.Use) string {
+		value, _, err := token.Generate(userID, 1, use, keys, time.Hour, token.IssuerParams{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		return value
+	}
+
+	tests := []struct {
+		name             string
+		accessUserID     int64
+		wantAccessActive bool
+	}{
+		{name: "access token of the same user", accessUserID: 1},
+		// Чужой access-токен не отзывается, даже если передан вместе с refresh-токеном
+		{name: "access token of another user", accessUserID: 2, wantAccessActive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAuditService{}
+			s := NewService(nil, nil, nil, audit, &fakeUserRepository{state: &model.UserTokenState{Role: 1}},
+				&fakeRevocationRepository{}, &fakeJWTConfig{}, keys, keys, false)
+
+			refreshToken := generate(1, token.UseRefresh)
+			accessToken := generate(tt.accessUserID, token.UseAccess)
+
+			if err := s.Logout(context.Background(), refreshToken, accessToken); err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+			if len(audit.events) != 1 || audit.events[0] != model.AuditEventTokenRevoked {
+				t.Errorf("Logout() recorded %v, want [%s]", audit.events, model.AuditEventTokenRevoked)
+			}
+
+			if _, err := s.GetAccessToken(context.Background(), refreshToken); !errors.Is(err, service.ErrInvalidToken) {
+				t.Errorf("GetAccessToken() after Logout() error = %v, want %v", err, service.ErrInvalidToken)
+			}
+
+			_, err := s.VerifyAccessToken(context.Background(), accessToken)
+			if active := err == nil; active != tt.wantAccessActive {
+				t.Errorf("VerifyAccessToken() after Logout() error = %v, want active = %t", err, tt.wantAccessActive)
+			}
+
+			// Повторный выход с тем же refresh-токеном невозможен
+			if err = s.Logout(context.Background(), refreshToken, ""); !errors.Is(err, service.ErrInvalidToken) {
+				t.Errorf("second Logout() error = %v, want %v", err, service.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestLoginRequireVerifiedEmail(t *testing.T) {
+	tests := []struct {
+		name                 string
+		emailVerified        bool
+		requireVerifiedEmail bool
+		wantErr              error
+	}{
+		{name: "verified", emailVerified: true, requireVerifiedEmail: true},
+		{name: "not verified", requireVerifiedEmail: true, wantErr: service.ErrEmailNotVerified},
+		{name: "not verified and not required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := testKeys(t)
+			users := &fakeUserService{
+				credentials: &model.UserCredentials{ID: 7, Email: "user@example.com", Role: 1, EmailVerified: tt.emailVerified},
+				password:    "password",
+			}
+			s := NewService(users, &fakeTwoFactorService{}, &fakeLockoutService{}, &fakeAuditService{}, nil,
+				&fakeRevocationRepository{}, &fakeJWTConfig{}, keys, keys, tt.requireVerifiedEmail)
+
+			_, _, err := s.Login(context.Background(), "user@example.com", "password", "", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImpersonateUser(t *testing.T) {
+	keys := testKeys(t)
+
+	adminToken, _, err := token.Generate(1, 2, token.UseAccess, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	adminRefreshToken, _, err := token.Generate(1, 2, token.UseRefresh, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	impersonationToken, _, err := token.GenerateImpersonation(3, 1, 1, keys, time.Hour, token.IssuerParams{})
+	if err != nil {
+		t.Fatalf("GenerateImpersonation() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		adminToken   string
+		targetUserID int64
+		state        *model.UserTokenState
+		wantErr      error
+	}{
+		{name: "other user", adminToken: adminToken, targetUserID: 5, state: &model.UserTokenState{Role: 1}},
+		{name: "self", adminToken: adminToken, targetUserID: 1, state: &model.UserTokenState{Role: 2}, wantErr: service.ErrImpersonationNotAllowed},
+		// Токен, уже выпущенный от имени пользователя, нельзя использовать для выпуска нового
+		{name: "nested impersonation", adminToken: impersonationToken, targetUserID: 5, state: &model.UserTokenState{Role: 1}, wantErr: service.ErrImpersonationNotAllowed},
+		{name: "refresh token", adminToken: adminRefreshToken, targetUserID: 5, state: &model.UserTokenState{Role: 1}, wantErr: service.ErrInvalidToken},
+		{name: "unknown user", adminToken: adminToken, targetUserID: 5, wantErr: service.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAuditService{}
+			s := NewService(nil, nil, nil, audit, &fakeUserRepository{state: tt.state}, &fakeRevocationRepository{},
+				&fakeJWTConfig{}, keys, keys, false)
+
+			got, err := s.ImpersonateUser(context.Background(), tt.adminToken, tt.targetUserID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImpersonateUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(audit.events) > 0 {
+					t.Errorf("failed ImpersonateUser() recorded %v", audit.events)
+				}
+				return
+			}
+
+			if len(audit.events) != 1 || audit.events[0] != model.AuditEventImpersonation {
+				t.Errorf("ImpersonateUser() recorded %v, want [%s]", audit.events, model.AuditEventImpersonation)
+			}
+			if got.AdminID != 1 || got.UserID != tt.targetUserID {
+				t.Errorf("ImpersonateUser() = %+v, want admin 1 and user %d", got, tt.targetUserID)
+			}
+			if ttl := time.Until(got.ExpiresAt); ttl > 10*time.Minute {
+				t.Errorf("ImpersonateUser() token expires in %s, want at most 10m", ttl)
+			}
+
+			claims, err := token.Verify(got.Value, token.UseAccess, keys, token.IssuerParams{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.UserID != tt.targetUserID || claims.Role != tt.state.Role || claims.ImpersonatedBy != 1 || claims.ID != got.TokenID {
+				t.Errorf("impersonation token claims = %+v, want user %d with role %d impersonated by 1",
+					claims, tt.targetUserID, tt.state.Role)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	credentials := &model.UserCredentials{ID: 7, Email: "user@example.com", Role: 1}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "correct password", password: "password"},
+		{name: "wrong password", password: "wrong", wantErr: service.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := testKeys(t)
+			users := &fakeUserService{credentials: credentials, password: "password"}
+			s := NewService(users, nil, nil, nil, &fakeUserRepository{state: &model.UserTokenState{Role: 1}},
+				&fakeRevocationRepository{}, &fakeJWTConfig{}, keys, keys, false)
+
+			reauthToken, err := s.VerifyPassword(context.Background(), credentials.ID, tt.password, "10.0.0.1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if reauthToken != nil {
+					t.Errorf("VerifyPassword() = %+v, want nil", reauthToken)
+				}
+				return
+			}
+
+			if ttl := time.Until(reauthToken.ExpiresAt); ttl <= 0 || ttl > 5*time.Minute {
+				t.Errorf("VerifyPassword() token expires in %s, want at most 5m", ttl)
+			}
+			if err = s.VerifyReauthToken(context.Background(), reauthToken.Value, credentials.ID); err != nil {
+				t.Errorf("VerifyReauthToken() error = %v", err)
+			}
+			if err = s.VerifyReauthToken(context.Background(), reauthToken.Value, credentials.ID+1); !errors.Is(err, service.ErrInvalidToken) {
+				t.Errorf("VerifyReauthToken() for another user error = %v, want %v", err, service.ErrInvalidToken)
+			}
+			// Токен повторной аутентификации не заменяет access-токен
+			if _, err = s.VerifyAccessToken(context.Background(), reauthToken.Value); !errors.Is(err, service.ErrInvalidToken) {
+				t.Errorf("VerifyAccessToken() with reauth token error = %v, want %v", err, service.ErrInvalidToken)
+			}
+		})
+	}
+}
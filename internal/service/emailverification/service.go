@@ -0,0 +1,110 @@
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/mail"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+const (
+	// sendTimeout - максимальное время отправки письма со ссылкой.
+	sendTimeout = 30 * time.Second
+
+	mailSubject = "Confirm your email"
+)
+
+// serv - сервис подтверждения email, реализующий интерфейс service.EmailVerificationService.
+type serv struct {
+	emailVerificationRepository repository.EmailVerificationRepository
+	mailSender                  mail.Sender
+	config                      env.EmailVerificationConfig
+	log                         *zap.Logger
+}
+
+// NewService создает сервис подтверждения email.
+//
+// Параметры:
+//   - emailVerificationRepository: хранилище токенов подтверждения email.
+//   - mailSender: способ отправки письма со ссылкой.
+//   - config: адрес страницы подтверждения и время жизни токена.
+//   - log: логгер для ошибок отправки писем.
+func NewService(
+	emailVerificationRepository repository.EmailVerificationRepository,
+	mailSender mail.Sender,
+	config env.EmailVerificationConfig,
+	log *zap.Logger,
+) service.EmailVerificationService {
+	return &serv{
+		emailVerificationRepository: emailVerificationRepository,
+		mailSender:                  mailSender,
+		config:                      config,
+		log:                         log,
+	}
+}
+
+// RequestVerification выпускает токен подтверждения email для пользователя userID
+// и отправляет на email письмо со ссылкой.
+//
+// Письмо отправляется в фоне, чтобы недоступность почтового сервера не задерживала регистрацию.
+// Ошибка отправки только логируется.
+func (s *serv) RequestVerification(ctx context.Context, userID int64, email string) error {
+	verificationToken, err := token.GenerateOpaque()
+	if err != nil {
+		return err
+	}
+
+	err = s.emailVerificationRepository.Create(ctx, userID, token.HashOpaque(verificationToken), time.Now().Add(s.config.TokenTTL()))
+	if err != nil {
+		return err
+	}
+
+	msg := &mail.Message{
+		To:      email,
+		Subject: mailSubject,
+		Body:    s.mailBody(verificationToken),
+	}
+
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+		defer cancel()
+
+		if err := s.mailSender.Send(sendCtx, msg); err != nil {
+			s.log.Error("Unable to send email verification mail", zap.Int64("User-id", userID), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// Verify отмечает email пользователя, которому выпущен токен verificationToken, подтвержденным.
+func (s *serv) Verify(ctx context.Context, verificationToken string) error {
+	_, err := s.emailVerificationRepository.Verify(ctx, token.HashOpaque(verificationToken))
+	if errors.Is(err, repository.ErrEmailVerificationTokenNotFound) {
+		return service.ErrInvalidEmailVerificationToken
+	}
+
+	return err
+}
+
+// mailBody возвращает текст письма со ссылкой на страницу подтверждения email с токеном verificationToken.
+func (s *serv) mailBody(verificationToken string) string {
+	verificationURL := s.config.URL()
+	query := verificationURL.Query()
+	query.Set("token", verificationToken)
+	verificationURL.RawQuery = query.Encode()
+
+	return fmt.Sprintf(
+		"To confirm your email, follow the link below. The link is valid for %s.\n\n%s\n\n"+
+			"If you did not sign up, ignore this message.\n",
+		s.config.TokenTTL(), verificationURL,
+	)
+}
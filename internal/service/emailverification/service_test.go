@@ -0,0 +1,159 @@
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/mail"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// fakeConfig - конфиг подтверждения email с заданным временем жизни токена.
+type fakeConfig struct {
+	env.EmailVerificationConfig
+	tokenTTL time.Duration
+}
+
+func (c *fakeConfig) URL() *url.URL {
+	return &url.URL{Scheme: "https", Host: "example.com", Path: "/verify"}
+}
+
+func (c *fakeConfig) TokenTTL() time.Duration { return c.tokenTTL }
+
+// verificationToken - токен подтверждения email в fakeEmailVerificationRepository.
+type verificationToken struct {
+	userID    int64
+	expiresAt time.Time
+}
+
+// fakeEmailVerificationRepository - хранилище токенов подтверждения email в памяти с той же
+// семантикой, что и хранилище в БД.
+type fakeEmailVerificationRepository struct {
+	tokens   map[string]verificationToken
+	verified map[int64]bool
+}
+
+func (r *fakeEmailVerificationRepository) Create(_ context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	if r.tokens == nil {
+		r.tokens = make(map[string]verificationToken)
+	}
+	r.tokens[tokenHash] = verificationToken{userID: userID, expiresAt: expiresAt}
+
+	return nil
+}
+
+func (r *fakeEmailVerificationRepository) Verify(_ context.Context, tokenHash string) (int64, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || !t.expiresAt.After(time.Now()) {
+		return 0, repository.ErrEmailVerificationTokenNotFound
+	}
+
+	if r.verified == nil {
+		r.verified = make(map[int64]bool)
+	}
+	r.verified[t.userID] = true
+
+	for hash, other := range r.tokens {
+		if other.userID == t.userID {
+			delete(r.tokens, hash)
+		}
+	}
+
+	return t.userID, nil
+}
+
+// fakeSender - отправка писем в канал.
+type fakeSender struct {
+	messages chan *mail.Message
+}
+
+func (s *fakeSender) Send(_ context.Context, msg *mail.Message) error {
+	s.messages <- msg
+	return nil
+}
+
+// requestToken запрашивает подтверждение email пользователя userID и возвращает токен из ссылки
+// в отправленном письме.
+func requestToken(t *testing.T, s service.EmailVerificationService, sender *fakeSender, userID int64) string {
+	t.Helper()
+
+	if err := s.RequestVerification(context.Background(), userID, "user@example.com"); err != nil {
+		t.Fatalf("RequestVerification() error = %v", err)
+	}
+
+	var msg *mail.Message
+	select {
+	case msg = <-sender.messages:
+	case <-time.After(time.Second):
+		t.Fatal("RequestVerification() did not send a mail")
+	}
+	if msg.To != "user@example.com" {
+		t.Errorf("RequestVerification() sent a mail to %s, want user@example.com", msg.To)
+	}
+
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if verificationURL, err := url.Parse(line); err == nil && verificationURL.Host == "example.com" {
+			return verificationURL.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("RequestVerification() sent a mail without a link: %q", msg.Body)
+	return ""
+}
+
+func TestVerify(t *testing.T) {
+	repo := &fakeEmailVerificationRepository{}
+	sender := &fakeSender{messages: make(chan *mail.Message, 10)}
+	s := NewService(repo, sender, &fakeConfig{tokenTTL: time.Hour}, zap.NewNop())
+
+	firstToken := requestToken(t, s, sender, 1)
+	secondToken := requestToken(t, s, sender, 1)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "unknown token", token: "unknown", wantErr: service.ErrInvalidEmailVerificationToken},
+		{name: "valid token", token: secondToken},
+		// После подтверждения остальные токены пользователя удаляются
+		{name: "earlier token", token: firstToken, wantErr: service.ErrInvalidEmailVerificationToken},
+		{name: "same token again", token: secondToken, wantErr: service.ErrInvalidEmailVerificationToken},
+	}
+
+	// Случаи выполняются по порядку: каждый использует состояние после предыдущего
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Verify(context.Background(), tt.token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !repo.verified[1] {
+		t.Error("Verify() did not mark the email verified")
+	}
+}
+
+func TestVerifyExpiredToken(t *testing.T) {
+	repo := &fakeEmailVerificationRepository{}
+	sender := &fakeSender{messages: make(chan *mail.Message, 10)}
+	s := NewService(repo, sender, &fakeConfig{tokenTTL: -time.Minute}, zap.NewNop())
+
+	verificationToken := requestToken(t, s, sender, 1)
+
+	if err := s.Verify(context.Background(), verificationToken); !errors.Is(err, service.ErrInvalidEmailVerificationToken) {
+		t.Errorf("Verify() error = %v, want %v", err, service.ErrInvalidEmailVerificationToken)
+	}
+	if repo.verified[1] {
+		t.Error("Verify() marked the email verified with an expired token")
+	}
+}
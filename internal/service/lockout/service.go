@@ -0,0 +1,136 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/anton0701/auth/config/env"
+	desc "github.com/anton0701/auth/grpc/pkg/auth_v1"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// Префиксы subject блокировок.
+const (
+	emailSubjectPrefix = desc.LockoutSubjectEmailPrefix
+	ipSubjectPrefix    = desc.LockoutSubjectIPPrefix
+)
+
+// serv - сервис защиты от подбора пароля, реализующий интерфейс service.LockoutService.
+type serv struct {
+	lockoutRepository repository.LockoutRepository
+	config            env.LockoutConfig
+}
+
+// NewService создает сервис защиты от подбора пароля.
+//
+// Параметры:
+//   - lockoutRepository: хранилище неудачных попыток и блокировок.
+//   - config: пороги и длительность блокировок.
+func NewService(lockoutRepository repository.LockoutRepository, config env.LockoutConfig) service.LockoutService {
+	return &serv{
+		lockoutRepository: lockoutRepository,
+		config:            config,
+	}
+}
+
+// Check возвращает ErrLoginLocked, если email или IP-адрес клиента заблокирован.
+func (s *serv) Check(ctx context.Context, email, clientIP string) error {
+	lockedUntil, err := s.lockoutRepository.GetLockedUntil(ctx, s.subjects(email, clientIP))
+	if err != nil {
+		return err
+	}
+	if !lockedUntil.IsZero() {
+		return service.ErrLoginLocked
+	}
+
+	return nil
+}
+
+// RegisterFailure учитывает неудачную попытку входа для email и IP-адреса клиента и блокирует те из них,
+// для которых количество попыток за окно достигло порога.
+func (s *serv) RegisterFailure(ctx context.Context, email, clientIP string) error {
+	thresholds := map[string]int{
+		emailSubject(email): s.config.UserMaxFailures(),
+	}
+	if len(clientIP) > 0 {
+		thresholds[ipSubject(clientIP)] = s.config.IPMaxFailures()
+	}
+
+	for subject, maxFailures := range thresholds {
+		// Порог 0 выключает блокировку
+		if maxFailures == 0 {
+			continue
+		}
+
+		failures, err := s.lockoutRepository.AddFailure(ctx, subject, s.config.Window())
+		if err != nil {
+			return err
+		}
+		if failures < maxFailures {
+			continue
+		}
+
+		if _, err = s.lockoutRepository.Lock(ctx, subject, s.lockoutDuration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterSuccess сбрасывает неудачные попытки и счетчик блокировок email.
+//
+// Попытки с IP-адреса не сбрасываются: с одного адреса могут подбирать пароли к разным
+// пользователям, и успешный вход одного из них не должен снимать ограничение.
+func (s *serv) RegisterSuccess(ctx context.Context, email string) error {
+	return s.lockoutRepository.Reset(ctx, emailSubject(email))
+}
+
+// ListLockouts возвращает действующие блокировки.
+func (s *serv) ListLockouts(ctx context.Context) ([]*model.Lockout, error) {
+	return s.lockoutRepository.ListActive(ctx)
+}
+
+// ClearLockout снимает блокировку subject. Email в subject приводится к нижнему регистру.
+func (s *serv) ClearLockout(ctx context.Context, subject string) error {
+	subject = strings.TrimSpace(subject)
+	if email, ok := strings.CutPrefix(subject, emailSubjectPrefix); ok {
+		subject = emailSubject(email)
+	}
+
+	return s.lockoutRepository.Reset(ctx, subject)
+}
+
+// lockoutDuration возвращает длительность блокировки с номером lockoutCount подряд: первая длится
+// config.Duration(), каждая следующая - вдвое дольше, но не дольше config.MaxDuration().
+func (s *serv) lockoutDuration(lockoutCount int) time.Duration {
+	duration := s.config.Duration()
+	for i := 1; i < lockoutCount && duration < s.config.MaxDuration(); i++ {
+		duration *= 2
+	}
+
+	return min(duration, s.config.MaxDuration())
+}
+
+// subjects возвращает subject блокировок для email и, если он известен, IP-адреса клиента.
+func (s *serv) subjects(email, clientIP string) []string {
+	subjects := []string{emailSubject(email)}
+	if len(clientIP) > 0 {
+		subjects = append(subjects, ipSubject(clientIP))
+	}
+
+	return subjects
+}
+
+// emailSubject возвращает subject блокировки для email без учета регистра и пробелов по краям.
+func emailSubject(email string) string {
+	return emailSubjectPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ipSubject возвращает subject блокировки для IP-адреса.
+func ipSubject(clientIP string) string {
+	return ipSubjectPrefix + clientIP
+}
@@ -0,0 +1,214 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// fakeConfig - конфиг защиты от подбора пароля с заданными порогами.
+type fakeConfig struct {
+	env.LockoutConfig
+	userMaxFailures int
+	ipMaxFailures   int
+}
+
+func (c *fakeConfig) UserMaxFailures() int       { return c.userMaxFailures }
+func (c *fakeConfig) IPMaxFailures() int         { return c.ipMaxFailures }
+func (c *fakeConfig) Window() time.Duration      { return time.Hour }
+func (c *fakeConfig) Duration() time.Duration    { return time.Minute }
+func (c *fakeConfig) MaxDuration() time.Duration { return 5 * time.Minute }
+
+// fakeLockoutRepository - хранилище неудачных попыток и блокировок в памяти. Окно попыток
+// не учитывается: все попытки считаются попавшими в него.
+type fakeLockoutRepository struct {
+	failures map[string]int
+	lockouts map[string]*model.Lockout
+}
+
+func newFakeLockoutRepository() *fakeLockoutRepository {
+	return &fakeLockoutRepository{
+		failures: make(map[string]int),
+		lockouts: make(map[string]*model.Lockout),
+	}
+}
+
+func (r *fakeLockoutRepository) GetLockedUntil(_ context.Context, subjects []string) (time.Time, error) {
+	var lockedUntil time.Time
+	for _, subject := range subjects {
+		if lockout, ok := r.lockouts[subject]; ok && lockout.LockedUntil.After(time.Now()) && lockout.LockedUntil.After(lockedUntil) {
+			lockedUntil = lockout.LockedUntil
+		}
+	}
+
+	return lockedUntil, nil
+}
+
+func (r *fakeLockoutRepository) AddFailure(_ context.Context, subject string, _ time.Duration) (int, error) {
+	r.failures[subject]++
+	return r.failures[subject], nil
+}
+
+func (r *fakeLockoutRepository) Lock(_ context.Context, subject string, duration func(lockoutCount int) time.Duration) (*model.Lockout, error) {
+	lockout := &model.Lockout{Subject: subject, LockoutCount: 1}
+	if previous, ok := r.lockouts[subject]; ok {
+		lockout.LockoutCount = previous.LockoutCount + 1
+	}
+	lockout.LockedUntil = time.Now().Add(duration(lockout.LockoutCount))
+
+	r.lockouts[subject] = lockout
+	delete(r.failures, subject)
+
+	return lockout, nil
+}
+
+func (r *fakeLockoutRepository) Reset(_ context.Context, subject string) error {
+	delete(r.failures, subject)
+	delete(r.lockouts, subject)
+	return nil
+}
+
+func (r *fakeLockoutRepository) ListActive(_ context.Context) ([]*model.Lockout, error) {
+	var lockouts []*model.Lockout
+	for _, lockout := range r.lockouts {
+		if lockout.LockedUntil.After(time.Now()) {
+			lockouts = append(lockouts, lockout)
+		}
+	}
+
+	return lockouts, nil
+}
+
+// registerFailures учитывает n неудачных попыток входа с email и IP-адреса clientIP.
+func registerFailures(t *testing.T, s service.LockoutService, email, clientIP string, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		if err := s.RegisterFailure(context.Background(), email, clientIP); err != nil {
+			t.Fatalf("RegisterFailure() error = %v", err)
+		}
+	}
+}
+
+func TestRegisterFailureUserThreshold(t *testing.T) {
+	s := NewService(newFakeLockoutRepository(), &fakeConfig{userMaxFailures: 3, ipMaxFailures: 10})
+
+	registerFailures(t, s, "user@example.com", "10.0.0.1", 2)
+	if err := s.Check(context.Background(), "user@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() below threshold error = %v", err)
+	}
+
+	registerFailures(t, s, "user@example.com", "10.0.0.1", 1)
+
+	tests := []struct {
+		name     string
+		email    string
+		clientIP string
+		wantErr  error
+	}{
+		{name: "same email and address", email: "user@example.com", clientIP: "10.0.0.1", wantErr: service.ErrLoginLocked},
+		{name: "same email in other case from other address", email: " USER@example.com", clientIP: "10.0.0.2", wantErr: service.ErrLoginLocked},
+		{name: "other email from same address", email: "other@example.com", clientIP: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Check(context.Background(), tt.email, tt.clientIP); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterFailureIPThreshold(t *testing.T) {
+	s := NewService(newFakeLockoutRepository(), &fakeConfig{userMaxFailures: 3, ipMaxFailures: 4})
+
+	// Подбор паролей к разным пользователям с одного адреса
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		registerFailures(t, s, email, "10.0.0.1", 1)
+	}
+
+	if err := s.Check(context.Background(), "e@example.com", "10.0.0.1"); !errors.Is(err, service.ErrLoginLocked) {
+		t.Errorf("Check() from locked address error = %v, want %v", err, service.ErrLoginLocked)
+	}
+	if err := s.Check(context.Background(), "e@example.com", "10.0.0.2"); err != nil {
+		t.Errorf("Check() from other address error = %v", err)
+	}
+}
+
+func TestRegisterFailureDisabled(t *testing.T) {
+	s := NewService(newFakeLockoutRepository(), &fakeConfig{})
+
+	registerFailures(t, s, "user@example.com", "10.0.0.1", 100)
+	if err := s.Check(context.Background(), "user@example.com", "10.0.0.1"); err != nil {
+		t.Errorf("Check() with zero thresholds error = %v", err)
+	}
+}
+
+func TestRegisterSuccess(t *testing.T) {
+	repo := newFakeLockoutRepository()
+	s := NewService(repo, &fakeConfig{userMaxFailures: 3, ipMaxFailures: 5})
+
+	registerFailures(t, s, "user@example.com", "10.0.0.1", 2)
+	if err := s.RegisterSuccess(context.Background(), "User@Example.com"); err != nil {
+		t.Fatalf("RegisterSuccess() error = %v", err)
+	}
+
+	// Неудачные попытки email сброшены, попытки с адреса - нет
+	if got := repo.failures[emailSubject("user@example.com")]; got != 0 {
+		t.Errorf("email failures after RegisterSuccess() = %d, want 0", got)
+	}
+	if got := repo.failures[ipSubject("10.0.0.1")]; got != 2 {
+		t.Errorf("address failures after RegisterSuccess() = %d, want 2", got)
+	}
+}
+
+func TestClearLockout(t *testing.T) {
+	s := NewService(newFakeLockoutRepository(), &fakeConfig{userMaxFailures: 1, ipMaxFailures: 1})
+
+	registerFailures(t, s, "user@example.com", "10.0.0.1", 1)
+
+	lockouts, err := s.ListLockouts(context.Background())
+	if err != nil {
+		t.Fatalf("ListLockouts() error = %v", err)
+	}
+	if len(lockouts) != 2 {
+		t.Fatalf("ListLockouts() returned %d lockouts, want 2", len(lockouts))
+	}
+
+	for _, subject := range []string{" email:User@Example.com", "ip:10.0.0.1"} {
+		if err = s.ClearLockout(context.Background(), subject); err != nil {
+			t.Fatalf("ClearLockout(%q) error = %v", subject, err)
+		}
+	}
+
+	if err = s.Check(context.Background(), "user@example.com", "10.0.0.1"); err != nil {
+		t.Errorf("Check() after ClearLockout() error = %v", err)
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	s := &serv{config: &fakeConfig{}}
+
+	tests := []struct {
+		lockoutCount int
+		want         time.Duration
+	}{
+		{lockoutCount: 1, want: time.Minute},
+		{lockoutCount: 2, want: 2 * time.Minute},
+		{lockoutCount: 3, want: 4 * time.Minute},
+		{lockoutCount: 4, want: 5 * time.Minute},
+		{lockoutCount: 100, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := s.lockoutDuration(tt.lockoutCount); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, want %s", tt.lockoutCount, got, tt.want)
+		}
+	}
+}
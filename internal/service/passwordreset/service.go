@@ -0,0 +1,181 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/hasher"
+	"github.com/anton0701/auth/internal/mail"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+const (
+	// sendTimeout - максимальное время отправки письма со ссылкой.
+	sendTimeout = 30 * time.Second
+
+	mailSubject = "Password reset"
+)
+
+// serv - сервис сброса пароля, реализующий интерфейс service.PasswordResetService.
+type serv struct {
+	userRepository          repository.UserRepository
+	passwordResetRepository repository.PasswordResetRepository
+	passwordHasher          hasher.Hasher
+	passwordPolicy          *passwordpolicy.Policy
+	auditService            service.AuditService
+	mailSender              mail.Sender
+	config                  env.PasswordResetConfig
+	log                     *zap.Logger
+}
+
+// NewService создает сервис сброса пароля.
+//
+// Параметры:
+//   - userRepository: хранилище пользователей.
+//   - passwordResetRepository: хранилище токенов сброса пароля.
+//   - passwordHasher: hasher, которым вычисляется хеш нового пароля.
+//   - passwordPolicy: политика, которой проверяется новый пароль.
+//   - auditService: журнал аудита, в который записывается смена пароля.
+//   - mailSender: способ отправки письма со ссылкой.
+//   - config: адрес страницы сброса пароля и время жизни токена.
+//   - log: логгер для ошибок отправки писем.
+func NewService(
+	userRepository repository.UserRepository,
+	passwordResetRepository repository.PasswordResetRepository,
+	passwordHasher hasher.Hasher,
+	passwordPolicy *passwordpolicy.Policy,
+	auditService service.AuditService,
+	mailSender mail.Sender,
+	config env.PasswordResetConfig,
+	log *zap.Logger,
+) service.PasswordResetService {
+	return &serv{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		passwordHasher:          passwordHasher,
+		passwordPolicy:          passwordPolicy,
+		auditService:            auditService,
+		mailSender:              mailSender,
+		config:                  config,
+		log:                     log,
+	}
+}
+
+// RequestReset выпускает токен сброса пароля для пользователя с email (без учета регистра)
+// и отправляет ему письмо со ссылкой.
+//
+// Письмо отправляется в фоне, чтобы время ответа не зависело от того, зарегистрирован ли email.
+// Ошибка отправки только логируется.
+func (s *serv) RequestReset(ctx context.Context, email string) error {
+	credentials, err := s.userRepository.GetCredentialsByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	resetToken, err := token.GenerateOpaque()
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordResetRepository.Create(ctx, credentials.ID, token.HashOpaque(resetToken), time.Now().Add(s.config.TokenTTL()))
+	if err != nil {
+		return err
+	}
+
+	msg := &mail.Message{
+		To:      email,
+		Subject: mailSubject,
+		Body:    s.mailBody(resetToken),
+	}
+
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+		defer cancel()
+
+		if err := s.mailSender.Send(sendCtx, msg); err != nil {
+			s.log.Error("Unable to send password reset mail", zap.Int64("User-id", credentials.ID), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// ConfirmReset заменяет пароль пользователя, которому выпущен токен resetToken.
+//
+// Новый пароль проверяется политикой паролей, а его хеш вычисляется до использования токена,
+// чтобы слабый пароль или ошибка хеширования не делали токен недействительным.
+func (s *serv) ConfirmReset(ctx context.Context, resetToken, newPassword string) error {
+	tokenHash := token.HashOpaque(resetToken)
+
+	userID, err := s.passwordResetRepository.GetUserID(ctx, tokenHash)
+	if errors.Is(err, repository.ErrPasswordResetTokenNotFound) {
+		return service.ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	credentials, err := s.userRepository.GetCredentials(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return service.ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.passwordPolicy.Validate(newPassword, credentials.Email, credentials.Name); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Токен мог быть использован параллельным запросом, поэтому проверяется еще раз при использовании
+	userID, err = s.passwordResetRepository.Consume(ctx, tokenHash)
+	if errors.Is(err, repository.ErrPasswordResetTokenNotFound) {
+		return service.ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepository.UpdatePassword(ctx, userID, passwordHash)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return service.ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventPasswordChanged, userID, map[string]string{"method": "reset"})
+
+	return nil
+}
+
+// mailBody возвращает текст письма со ссылкой на страницу сброса пароля с токеном resetToken.
+func (s *serv) mailBody(resetToken string) string {
+	resetURL := s.config.URL()
+	query := resetURL.Query()
+	query.Set("token", resetToken)
+	resetURL.RawQuery = query.Encode()
+
+	return fmt.Sprintf(
+		"To reset your password, follow the link below. The link is valid for %s and can be used only once.\n\n%s\n\n"+
+			"If you did not request a password reset, ignore this message.\n",
+		s.config.TokenTTL(), resetURL,
+	)
+}
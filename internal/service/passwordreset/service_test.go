@@ -0,0 +1,261 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/config/env"
+	"github.com/anton0701/auth/internal/mail"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// fakeConfig - конфиг сброса пароля с заданным временем жизни токена.
+type fakeConfig struct {
+	env.PasswordResetConfig
+	tokenTTL time.Duration
+}
+
+func (c *fakeConfig) URL() *url.URL {
+	return &url.URL{Scheme: "https", Host: "example.com", Path: "/reset"}
+}
+
+func (c *fakeConfig) TokenTTL() time.Duration { return c.tokenTTL }
+
+// fakeUserRepository - хранилище с единственным пользователем credentials.
+type fakeUserRepository struct {
+	repository.UserRepository
+	credentials *model.UserCredentials
+}
+
+func (r *fakeUserRepository) GetCredentialsByEmail(_ context.Context, email string) (*model.UserCredentials, error) {
+	if !strings.EqualFold(email, r.credentials.Email) {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return r.credentials, nil
+}
+
+func (r *fakeUserRepository) GetCredentials(_ context.Context, id int64) (*model.UserCredentials, error) {
+	if id != r.credentials.ID {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return r.credentials, nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(_ context.Context, id int64, passwordHash string) error {
+	if id != r.credentials.ID {
+		return repository.ErrUserNotFound
+	}
+	r.credentials.PasswordHash = passwordHash
+
+	return nil
+}
+
+// resetToken - токен сброса пароля в fakePasswordResetRepository.
+type resetToken struct {
+	userID    int64
+	expiresAt time.Time
+	used      bool
+}
+
+// fakePasswordResetRepository - хранилище токенов сброса пароля в памяти с той же семантикой,
+// что и хранилище в БД.
+type fakePasswordResetRepository struct {
+	tokens map[string]*resetToken
+}
+
+func (r *fakePasswordResetRepository) Create(_ context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	if r.tokens == nil {
+		r.tokens = make(map[string]*resetToken)
+	}
+	r.tokens[tokenHash] = &resetToken{userID: userID, expiresAt: expiresAt}
+
+	return nil
+}
+
+func (r *fakePasswordResetRepository) GetUserID(_ context.Context, tokenHash string) (int64, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.used || !t.expiresAt.After(time.Now()) {
+		return 0, repository.ErrPasswordResetTokenNotFound
+	}
+
+	return t.userID, nil
+}
+
+func (r *fakePasswordResetRepository) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	userID, err := r.GetUserID(ctx, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	r.tokens[tokenHash].used = true
+
+	for hash, t := range r.tokens {
+		if t.userID == userID && !t.used {
+			delete(r.tokens, hash)
+		}
+	}
+
+	return userID, nil
+}
+
+// fakeHasher - hasher, хеш которого - пароль с префиксом.
+type fakeHasher struct{}
+
+func (h fakeHasher) Hash(password string) (string, error) { return "hash:" + password, nil }
+
+func (h fakeHasher) Verify(encodedHash, password string) (bool, error) {
+	return encodedHash == "hash:"+password, nil
+}
+
+func (h fakeHasher) NeedsRehash(_ string) bool { return false }
+
+// fakeAuditService - журнал аудита, запоминающий типы записанных событий.
+type fakeAuditService struct {
+	service.AuditService
+	events []model.AuditEventType
+}
+
+func (s *fakeAuditService) Record(_ context.Context, eventType model.AuditEventType, _ int64, _ map[string]string) {
+	s.events = append(s.events, eventType)
+}
+
+// fakeSender - отправка писем в канал.
+type fakeSender struct {
+	messages chan *mail.Message
+}
+
+func (s *fakeSender) Send(_ context.Context, msg *mail.Message) error {
+	s.messages <- msg
+	return nil
+}
+
+// testService - сервис сброса пароля с хранилищами в памяти.
+type testService struct {
+	service.PasswordResetService
+	users  *fakeUserRepository
+	audit  *fakeAuditService
+	sender *fakeSender
+}
+
+func newTestService(t *testing.T, tokenTTL time.Duration) *testService {
+	t.Helper()
+
+	policy, err := passwordpolicy.New(passwordpolicy.Rules{MinLength: 8})
+	if err != nil {
+		t.Fatalf("passwordpolicy.New() error = %v", err)
+	}
+
+	ts := &testService{
+		users:  &fakeUserRepository{credentials: &model.UserCredentials{ID: 1, Email: "user@example.com", PasswordHash: "hash:old"}},
+		audit:  &fakeAuditService{},
+		sender: &fakeSender{messages: make(chan *mail.Message, 10)},
+	}
+	ts.PasswordResetService = NewService(ts.users, &fakePasswordResetRepository{}, fakeHasher{}, policy, ts.audit,
+		ts.sender, &fakeConfig{tokenTTL: tokenTTL}, zap.NewNop())
+
+	return ts
+}
+
+// requestToken запрашивает сброс пароля и возвращает токен из ссылки в отправленном письме.
+func (ts *testService) requestToken(t *testing.T) string {
+	t.Helper()
+
+	if err := ts.RequestReset(context.Background(), "User@Example.com"); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+
+	var msg *mail.Message
+	select {
+	case msg = <-ts.sender.messages:
+	case <-time.After(time.Second):
+		t.Fatal("RequestReset() did not send a mail")
+	}
+
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if resetURL, err := url.Parse(line); err == nil && resetURL.Host == "example.com" {
+			return resetURL.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("RequestReset() sent a mail without a link: %q", msg.Body)
+	return ""
+}
+
+func TestConfirmReset(t *testing.T) {
+	ts := newTestService(t, time.Hour)
+	firstToken := ts.requestToken(t)
+	secondToken := ts.requestToken(t)
+
+	tests := []struct {
+		name        string
+		token       string
+		newPassword string
+		wantErr     error
+	}{
+		{name: "unknown token", token: "unknown", newPassword: "new-password", wantErr: service.ErrInvalidPasswordResetToken},
+		{name: "weak password keeps the token", token: secondToken, newPassword: "short", wantErr: &passwordpolicy.ViolationError{}},
+		{name: "valid token", token: secondToken, newPassword: "new-password"},
+		{name: "same token again", token: secondToken, newPassword: "other-password", wantErr: service.ErrInvalidPasswordResetToken},
+		// Остальные ссылки пользователя перестают действовать после сброса
+		{name: "earlier token", token: firstToken, newPassword: "other-password", wantErr: service.ErrInvalidPasswordResetToken},
+	}
+
+	// Случаи выполняются по порядку: каждый использует состояние после предыдущего
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ts.ConfirmReset(context.Background(), tt.token, tt.newPassword)
+
+			var violation *passwordpolicy.ViolationError
+			switch {
+			case errors.As(tt.wantErr, &violation):
+				if !errors.As(err, &violation) {
+					t.Errorf("ConfirmReset() error = %v, want *passwordpolicy.ViolationError", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("ConfirmReset() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if ts.users.credentials.PasswordHash != "hash:new-password" {
+		t.Errorf("password hash = %q, want %q", ts.users.credentials.PasswordHash, "hash:new-password")
+	}
+	if len(ts.audit.events) != 1 || ts.audit.events[0] != model.AuditEventPasswordChanged {
+		t.Errorf("ConfirmReset() recorded %v, want [%s]", ts.audit.events, model.AuditEventPasswordChanged)
+	}
+}
+
+func TestConfirmResetExpiredToken(t *testing.T) {
+	ts := newTestService(t, -time.Minute)
+	resetToken := ts.requestToken(t)
+
+	err := ts.ConfirmReset(context.Background(), resetToken, "new-password")
+	if !errors.Is(err, service.ErrInvalidPasswordResetToken) {
+		t.Errorf("ConfirmReset() error = %v, want %v", err, service.ErrInvalidPasswordResetToken)
+	}
+}
+
+func TestRequestResetUnknownEmail(t *testing.T) {
+	ts := newTestService(t, time.Hour)
+
+	if err := ts.RequestReset(context.Background(), "unknown@example.com"); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+
+	// Для неизвестного email письмо не отправляется и в фоне
+	select {
+	case msg := <-ts.sender.messages:
+		t.Errorf("RequestReset() sent a mail to %s", msg.To)
+	default:
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/anton0701/auth/internal/model"
+)
+
+var (
+	// ErrInvalidCredentials - пользователь с таким email не найден либо пароль неверный.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrUserNotFound - пользователь не найден.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrInvalidToken - токен некорректный, подписан другим ключом либо истек.
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrInvalidPasswordResetToken - токен сброса пароля не найден, истек либо уже использован.
+	ErrInvalidPasswordResetToken = errors.New("invalid password reset token")
+
+	// ErrInvalidEmailVerificationToken - токен подтверждения email не найден либо истек.
+	ErrInvalidEmailVerificationToken = errors.New("invalid email verification token")
+
+	// ErrEmailNotVerified - email пользователя не подтвержден, а вход без подтверждения запрещен.
+	ErrEmailNotVerified = errors.New("email is not verified")
+
+	// ErrSecondFactorRequired - у пользователя включена двухфакторная аутентификация,
+	// а одноразовый код не передан.
+	ErrSecondFactorRequired = errors.New("second factor code is required")
+
+	// ErrInvalidSecondFactor - одноразовый код неверный, истек либо уже использован.
+	ErrInvalidSecondFactor = errors.New("invalid second factor code")
+
+	// ErrTOTPAlreadyEnabled - двухфакторная аутентификация уже включена.
+	ErrTOTPAlreadyEnabled = errors.New("totp is already enabled")
+
+	// ErrTOTPNotEnrolled - пользователь не начинал подключение двухфакторной аутентификации.
+	ErrTOTPNotEnrolled = errors.New("totp enrollment is not started")
+
+	// ErrTOTPNotEnabled - двухфакторная аутентификация не включена.
+	ErrTOTPNotEnabled = errors.New("totp is not enabled")
+
+	// ErrLoginLocked - проверка пароля (вход или смена пароля) временно заблокирована после неудачных
+	// попыток с этим email или IP-адресом.
+	ErrLoginLocked = errors.New("login is temporarily locked")
+
+	// ErrAccessDenied - роли пользователя недостаточно для вызова метода.
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrProtectedEndpoint - правила доступа к методу заданы в коде и не могут быть изменены.
+	ErrProtectedEndpoint = errors.New("endpoint access policy cannot be changed")
+
+	// ErrInvalidAPIKey - API-ключ не найден, отозван либо истек.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrAPIKeyNotFound - API-ключ не найден либо уже отозван.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrServiceAccountNotFound - сервисный аккаунт не найден либо отключен.
+	ErrServiceAccountNotFound = errors.New("service account not found")
+
+	// ErrServiceAccountExists - сервисный аккаунт с таким именем уже существует.
+	ErrServiceAccountExists = errors.New("service account already exists")
+
+	// ErrInvalidClientCredentials - client_id или секрет сервисного аккаунта неверны либо аккаунт отключен.
+	ErrInvalidClientCredentials = errors.New("invalid client credentials")
+
+	// ErrImpersonationNotAllowed - администратор пытается действовать от своего имени либо уже
+	// действует от имени другого пользователя.
+	ErrImpersonationNotAllowed = errors.New("impersonation is not allowed")
+
+	// ErrBatchAborted - пользователь из пакета не создан, т.к. пакет отменен из-за ошибок в других элементах.
+	ErrBatchAborted = errors.New("batch aborted because of errors in other items")
+)
+
+// UserService - интерфейс сервиса пользователей.
+//
+// Методы:
+//   - Create: создает пользователя, сохраняя только хеш пароля, и возвращает его ID.
+//   - CreateBatch: создает пакет пользователей и возвращает результаты в порядке users. Если bestEffort
+//     равен false, пользователи создаются в одной транзакции: при ошибке в любом элементе не создается
+//     никто, а элементы без собственной ошибки получают ErrBatchAborted. Иначе каждый пользователь
+//     создается независимо. Ошибки элементов возвращаются в результатах, error - только ошибка
+//     транзакции в целом.
+//   - Authenticate: проверяет email и пароль и возвращает данные пользователя
+//     либо ErrInvalidCredentials.
+//   - ChangePassword: проверяет текущий пароль пользователя, заменяет его новым и отзывает
+//     refresh-токены пользователя либо возвращает ErrUserNotFound или ErrInvalidCredentials.
+//   - VerifyPassword: проверяет пароль пользователя по сохраненному хешу либо возвращает
+//     ErrUserNotFound или ErrInvalidCredentials.
+//
+// Authenticate, ChangePassword и VerifyPassword проверяют пароль с защитой от подбора: неверный
+// пароль учитывается как неудачная попытка для email пользователя и IP-адреса клиента clientIP,
+// а пока email или IP-адрес заблокирован, пароль не проверяется и возвращается ErrLoginLocked.
+//
+// Create, CreateBatch и ChangePassword проверяют новый пароль политикой паролей и возвращают
+// *passwordpolicy.ViolationError, если он ей не соответствует.
+type UserService interface {
+	Create(ctx context.Context, user *model.UserToCreate) (int64, error)
+	CreateBatch(ctx context.Context, users []*model.UserToCreate, bestEffort bool) ([]model.UserCreateResult, error)
+	Authenticate(ctx context.Context, email, password, clientIP string) (*model.UserCredentials, error)
+	ChangePassword(ctx context.Context, id int64, oldPassword, newPassword, clientIP string) error
+	VerifyPassword(ctx context.Context, id int64, password, clientIP string) error
+}
+
+// AccessService - интерфейс сервиса проверки доступа к методам.
+//
+// Методы:
+//   - Check: проверяет access-токен и возвращает вызывающего, если роли пользователя достаточно
+//     для вызова метода endpoint, ErrInvalidToken, если токен недействителен, либо ErrAccessDenied.
+//   - CheckAPIKey: то же для API-ключа другого сервиса. Возвращает ErrInvalidAPIKey, если ключ
+//     не найден, отозван или истек.
+//   - IsPublic: возвращает true, если метод endpoint можно вызывать без токена и ключа. По умолчанию
+//     методы не публичные: публичны только методы из заданного в коде списка без требований к роли.
+//   - ListAccessibleRoles: возвращает роли, которым разрешен вызов метода, для всех методов
+//     с требованиями к роли.
+//   - SetAccessibleRoles: заменяет список ролей метода endpoint либо возвращает ErrProtectedEndpoint.
+//   - IntrospectToken: проверяет access-токен и возвращает его данные и методы, разрешенные его роли.
+//     Недействительный, истекший или отозванный токен не является ошибкой: возвращается Active == false.
+//     Вызывающий caller, если он не администратор, может проверить только свой токен, чужой токен
+//     тоже считается недействительным.
+//   - GetRolePermissions: возвращает методы с требованиями к роли, вызов которых разрешен роли role,
+//     либо ErrAccessDenied, если вызывающий caller не администратор и запрашивает права чужой роли.
+type AccessService interface {
+	Check(ctx context.Context, accessToken, endpoint string) (*model.Caller, error)
+	CheckAPIKey(ctx context.Context, apiKey, endpoint string) (*model.Caller, error)
+	IsPublic(ctx context.Context, endpoint string) (bool, error)
+	ListAccessibleRoles(ctx context.Context) (map[string][]int32, error)
+	SetAccessibleRoles(ctx context.Context, endpoint string, roles []int32) error
+	IntrospectToken(ctx context.Context, caller *model.Caller, accessToken string) (*model.TokenIntrospection, error)
+	GetRolePermissions(ctx context.Context, caller *model.Caller, role int32) ([]string, error)
+}
+
+// AuthService - интерфейс сервиса аутентификации.
+//
+// Методы:
+//   - Login: проверяет email, пароль и, если у пользователя включена двухфакторная аутентификация,
+//     одноразовый код или код восстановления и выпускает access-токен и refresh-токен либо возвращает
+//     ErrLoginLocked, ErrInvalidCredentials, ErrSecondFactorRequired, ErrInvalidSecondFactor или,
+//     если вход без подтверждения email запрещен, ErrEmailNotVerified.
+//   - GetRefreshToken: выпускает новый refresh-токен взамен действующего.
+//   - GetAccessToken: выпускает новый access-токен по действующему refresh-токену.
+//   - Logout: отзывает refresh-токен и, если он передан и действителен, access-токен.
+//   - VerifyAccessToken: проверяет access-токен и возвращает ID пользователя либо ErrInvalidToken,
+//     в том числе для токена сервисного аккаунта.
+//   - ImpersonateUser: выпускает администратору с access-токеном adminAccessToken короткоживущий
+//     access-токен от имени пользователя targetUserID либо возвращает ErrInvalidToken, ErrUserNotFound
+//     или ErrImpersonationNotAllowed.
+//   - VerifyPassword: повторно проверяет пароль вошедшего пользователя userID и выпускает
+//     короткоживущий токен повторной аутентификации без новой сессии либо возвращает ErrLoginLocked,
+//     ErrInvalidCredentials или ErrUserNotFound.
+//   - VerifyReauthToken: проверяет, что токен повторной аутентификации действителен и выпущен
+//     пользователю userID, либо возвращает ErrInvalidToken.
+//
+// GetRefreshToken, GetAccessToken и Logout возвращают ErrInvalidToken, если refresh-токен
+// недействителен, отозван либо пользователь удален.
+type AuthService interface {
+	Login(ctx context.Context, email, password, clientIP string, secondFactor *model.SecondFactor) (accessToken, refreshToken *model.Token, err error)
+	GetRefreshToken(ctx context.Context, refreshToken string) (*model.Token, error)
+	GetAccessToken(ctx context.Context, refreshToken string) (*model.Token, error)
+	Logout(ctx context.Context, refreshToken, accessToken string) error
+	VerifyAccessToken(ctx context.Context, accessToken string) (int64, error)
+	ImpersonateUser(ctx context.Context, adminAccessToken string, targetUserID int64) (*model.Impersonation, error)
+	VerifyPassword(ctx context.Context, userID int64, password, clientIP string) (*model.Token, error)
+	VerifyReauthToken(ctx context.Context, reauthToken string, userID int64) error
+}
+
+// PasswordResetService - интерфейс сервиса сброса забытого пароля.
+//
+// Методы:
+//   - RequestReset: выпускает одноразовый токен сброса пароля и отправляет пользователю с email
+//     письмо со ссылкой. Если пользователь не найден, ничего не делает и не возвращает ошибку.
+//   - ConfirmReset: заменяет пароль пользователя, которому выпущен токен, и делает токен
+//     недействительным либо возвращает ErrInvalidPasswordResetToken. Если новый пароль не
+//     соответствует политике паролей, возвращает *passwordpolicy.ViolationError, а токен
+//     остается действующим.
+type PasswordResetService interface {
+	RequestReset(ctx context.Context, email string) error
+	ConfirmReset(ctx context.Context, token, newPassword string) error
+}
+
+// EmailVerificationService - интерфейс сервиса подтверждения email.
+//
+// Методы:
+//   - RequestVerification: выпускает одноразовый токен подтверждения email и отправляет
+//     пользователю письмо со ссылкой.
+//   - Verify: отмечает email пользователя, которому выпущен токен, подтвержденным
+//     либо возвращает ErrInvalidEmailVerificationToken.
+type EmailVerificationService interface {
+	RequestVerification(ctx context.Context, userID int64, email string) error
+	Verify(ctx context.Context, token string) error
+}
+
+// TwoFactorService - интерфейс сервиса двухфакторной аутентификации.
+//
+// Методы:
+//   - EnableTOTP: выпускает новый секрет TOTP для пользователя и возвращает данные для приложения-
+//     аутентификатора либо ErrTOTPAlreadyEnabled. До подтверждения секрет при входе не требуется.
+//   - ConfirmTOTP: проверяет код из приложения, включает двухфакторную аутентификацию и возвращает
+//     коды восстановления либо ErrTOTPNotEnrolled, ErrTOTPAlreadyEnabled или ErrInvalidSecondFactor.
+//   - IsEnabled: возвращает true, если у пользователя включена двухфакторная аутентификация.
+//   - Verify: проверяет одноразовый код при входе либо возвращает ErrInvalidSecondFactor.
+//   - VerifyRecoveryCode: проверяет и погашает код восстановления при входе
+//     либо возвращает ErrInvalidSecondFactor.
+//   - RegenerateRecoveryCodes: заменяет коды восстановления пользователя новыми и возвращает их
+//     либо ErrTOTPNotEnabled.
+type TwoFactorService interface {
+	EnableTOTP(ctx context.Context, userID int64) (*model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) (recoveryCodes []string, err error)
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	Verify(ctx context.Context, userID int64, code string) error
+	VerifyRecoveryCode(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error)
+}
+
+// LockoutService - интерфейс сервиса защиты от подбора пароля.
+//
+// Неудачные попытки входа считаются отдельно для email и для IP-адреса клиента. Пустой IP-адрес
+// не учитывается.
+//
+// Методы:
+//   - Check: возвращает ErrLoginLocked, если вход с email или IP-адреса заблокирован.
+//   - RegisterFailure: учитывает неудачную попытку входа и блокирует email или IP-адрес,
+//     если попыток слишком много.
+//   - RegisterSuccess: сбрасывает неудачные попытки и блокировки email после успешного входа.
+//   - ListLockouts: возвращает действующие блокировки.
+//   - ClearLockout: снимает блокировку subject ("email:<email>" или "ip:<IP-адрес>") и сбрасывает
+//     его неудачные попытки.
+type LockoutService interface {
+	Check(ctx context.Context, email, clientIP string) error
+	RegisterFailure(ctx context.Context, email, clientIP string) error
+	RegisterSuccess(ctx context.Context, email string) error
+	ListLockouts(ctx context.Context) ([]*model.Lockout, error)
+	ClearLockout(ctx context.Context, subject string) error
+}
+
+// APIKeyService - интерфейс сервиса API-ключей для вызовов от других сервисов.
+//
+// Методы:
+//   - Issue: выпускает ключ и возвращает его ID и сам ключ. Ключ больше нигде не хранится.
+//   - Revoke: отзывает ключ либо возвращает ErrAPIKeyNotFound.
+//   - List: возвращает все выпущенные ключи, включая отозванные и истекшие.
+type APIKeyService interface {
+	Issue(ctx context.Context, key *model.APIKeyToCreate) (id int64, apiKey string, err error)
+	Revoke(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*model.APIKey, error)
+}
+
+// ServiceAccountService - интерфейс сервиса сервисных аккаунтов: учетных записей других сервисов,
+// которые получают access-токены по client_id и секрету без пароля и двухфакторной аутентификации.
+//
+// Методы:
+//   - Create: создает аккаунт и возвращает его ID, client_id и секрет либо ErrServiceAccountExists.
+//     Секрет больше нигде не хранится.
+//   - RotateSecret: заменяет секрет аккаунта и возвращает новые учетные данные
+//     либо ErrServiceAccountNotFound.
+//   - Disable: отключает аккаунт либо возвращает ErrServiceAccountNotFound.
+//   - List: возвращает все аккаунты, включая отключенные.
+//   - IssueToken: проверяет client_id и секрет и выпускает access-токен (client credentials grant)
+//     либо возвращает ErrInvalidClientCredentials.
+type ServiceAccountService interface {
+	Create(ctx context.Context, account *model.ServiceAccountToCreate) (*model.ServiceAccountCredentials, error)
+	RotateSecret(ctx context.Context, id int64) (*model.ServiceAccountCredentials, error)
+	Disable(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*model.ServiceAccount, error)
+	IssueToken(ctx context.Context, clientID, clientSecret string) (*model.Token, error)
+}
+
+// AuditService - интерфейс сервиса журнала аудита событий безопасности.
+//
+// Методы:
+//   - Record: записывает событие eventType, касающееся пользователя userID (0 - пользователь
+//     неизвестен), с дополнительными данными details. Пользователь, выполнивший действие,
+//     IP-адрес и сертификат клиента берутся из контекста запроса. Ошибка записи не возвращается,
+//     а логируется, чтобы сбой журнала не прерывал уже выполненное действие.
+//   - List: возвращает события, подходящие под filter, начиная с самых новых.
+type AuditService interface {
+	Record(ctx context.Context, eventType model.AuditEventType, userID int64, details map[string]string)
+	List(ctx context.Context, filter *model.AuditFilter) ([]*model.AuditEvent, error)
+}
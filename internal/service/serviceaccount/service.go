@@ -0,0 +1,167 @@
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+const (
+	// clientIDPrefix - префикс client_id сервисных аккаунтов, по которому их токены легко отличить
+	// от токенов пользователей (claim "sub").
+	clientIDPrefix = "sa_"
+	// clientSecretPrefix - префикс секретов, по которому их легко отличить от других секретов
+	// (например, при поиске утекших секретов в логах и репозиториях).
+	clientSecretPrefix = "sas_"
+)
+
+// serv - сервис сервисных аккаунтов, реализующий интерфейс service.ServiceAccountService.
+type serv struct {
+	serviceAccountRepository repository.ServiceAccountRepository
+	auditService             service.AuditService
+	accessKeys               *token.Keyring
+	accessTokenTTL           time.Duration
+	issuer                   token.IssuerParams
+}
+
+// NewService создает сервис сервисных аккаунтов.
+//
+// Параметры:
+//   - serviceAccountRepository: хранилище сервисных аккаунтов.
+//   - auditService: журнал аудита, в который записываются выпуск токенов и отключение аккаунтов.
+//   - accessKeys: ключи подписи access-токенов.
+//   - accessTokenTTL: время жизни access-токена сервисного аккаунта.
+//   - issuer: издатель и получатели access-токенов.
+func NewService(
+	serviceAccountRepository repository.ServiceAccountRepository,
+	auditService service.AuditService,
+	accessKeys *token.Keyring,
+	accessTokenTTL time.Duration,
+	issuer token.IssuerParams,
+) service.ServiceAccountService {
+	return &serv{
+		serviceAccountRepository: serviceAccountRepository,
+		auditService:             auditService,
+		accessKeys:               accessKeys,
+		accessTokenTTL:           accessTokenTTL,
+		issuer:                   issuer,
+	}
+}
+
+// Create создает аккаунт со случайными client_id и секретом и сохраняет хеш секрета.
+func (s *serv) Create(ctx context.Context, account *model.ServiceAccountToCreate) (*model.ServiceAccountCredentials, error) {
+	clientID, err := token.GenerateOpaque()
+	if err != nil {
+		return nil, err
+	}
+	clientID = clientIDPrefix + clientID
+
+	clientSecret, err := newClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.serviceAccountRepository.Create(ctx, account, clientID, token.HashOpaque(clientSecret))
+	if errors.Is(err, repository.ErrServiceAccountExists) {
+		return nil, service.ErrServiceAccountExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ServiceAccountCredentials{
+		ID:           id,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+// RotateSecret заменяет секрет аккаунта с ID id. Прежний секрет сразу перестает действовать,
+// выпущенные по нему токены действуют до истечения.
+func (s *serv) RotateSecret(ctx context.Context, id int64) (*model.ServiceAccountCredentials, error) {
+	clientSecret, err := newClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.serviceAccountRepository.UpdateSecretHash(ctx, id, token.HashOpaque(clientSecret))
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return nil, service.ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ServiceAccountCredentials{
+		ID:           account.ID,
+		ClientID:     account.ClientID,
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+// Disable отключает аккаунт с ID id. Новые токены аккаунту не выпускаются, выпущенные
+// действуют до истечения.
+func (s *serv) Disable(ctx context.Context, id int64) error {
+	err := s.serviceAccountRepository.Disable(ctx, id)
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return service.ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventServiceAccountDisabled, 0, map[string]string{
+		"service_account_id": strconv.FormatInt(id, 10),
+	})
+
+	return nil
+}
+
+// List возвращает все аккаунты.
+func (s *serv) List(ctx context.Context) ([]*model.ServiceAccount, error) {
+	return s.serviceAccountRepository.List(ctx)
+}
+
+// IssueToken выпускает access-токен с ролью аккаунта, если client_id и секрет верны и аккаунт
+// не отключен. Refresh-токен не выпускается: сервис получает новый токен по тем же учетным данным.
+func (s *serv) IssueToken(ctx context.Context, clientID, clientSecret string) (*model.Token, error) {
+	account, err := s.serviceAccountRepository.UseCredentials(ctx, clientID, token.HashOpaque(clientSecret))
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		s.auditService.Record(ctx, model.AuditEventServiceAccountTokenFailed, 0, map[string]string{"client_id": clientID})
+		return nil, service.ErrInvalidClientCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, claims, err := token.GenerateServiceAccount(account.ClientID, account.Role, s.accessKeys, s.accessTokenTTL, s.issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventServiceAccountToken, 0, map[string]string{
+		"client_id": account.ClientID,
+		"token_id":  claims.ID,
+	})
+
+	return &model.Token{
+		Value:     value,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// newClientSecret возвращает случайный секрет сервисного аккаунта.
+func newClientSecret() (string, error) {
+	secret, err := token.GenerateOpaque()
+	if err != nil {
+		return "", err
+	}
+
+	return clientSecretPrefix + secret, nil
+}
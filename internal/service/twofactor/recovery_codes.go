@@ -0,0 +1,110 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/token"
+)
+
+const (
+	// recoveryCodeCount - количество кодов восстановления в наборе.
+	recoveryCodeCount = 10
+
+	// recoveryCodeBytes - количество случайных байт в коде восстановления (80 бит). Этого достаточно,
+	// чтобы хранить быстрый хеш кода, как для токенов из писем.
+	recoveryCodeBytes = 10
+
+	// recoveryCodeGroupLength - длина группы символов кода, группы разделяются дефисом для удобства ввода.
+	recoveryCodeGroupLength = 4
+)
+
+// recoveryCodeEncoding - кодировка кода восстановления: в алфавите base32 нет цифр 0, 1 и 8,
+// которые легко спутать с буквами O, I и B.
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// VerifyRecoveryCode погашает код восстановления code пользователя userID.
+//
+// Каждый код принимается только один раз.
+func (s *serv) VerifyRecoveryCode(ctx context.Context, userID int64, code string) error {
+	used, err := s.recoveryCodes.Use(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return service.ErrInvalidSecondFactor
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes заменяет коды восстановления пользователя userID новыми.
+// Неиспользованные старые коды перестают действовать.
+func (s *serv) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, service.ErrTOTPNotEnabled
+	}
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// replaceRecoveryCodes выпускает новый набор кодов восстановления и сохраняет их хеши.
+func (s *serv) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	codeHashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, hashRecoveryCode(code))
+	}
+
+	if err := s.recoveryCodes.Replace(ctx, userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode возвращает случайный код восстановления вида "abcd-efgh-ijkl-mnop".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "unable to generate recovery code")
+	}
+
+	encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+
+	groups := make([]string, 0, len(encoded)/recoveryCodeGroupLength)
+	for len(encoded) > 0 {
+		n := min(recoveryCodeGroupLength, len(encoded))
+		groups = append(groups, encoded[:n])
+		encoded = encoded[n:]
+	}
+
+	return strings.Join(groups, "-"), nil
+}
+
+// hashRecoveryCode возвращает хеш кода восстановления без учета регистра, пробелов и дефисов,
+// чтобы код можно было ввести в любом виде.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+
+	return token.HashOpaque(normalized)
+}
@@ -0,0 +1,106 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/anton0701/auth/internal/service"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`)
+
+	seen := make(map[string]struct{})
+	for i := 0; i < 100; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode() error = %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("generateRecoveryCode() = %q, want format xxxx-xxxx-xxxx-xxxx", code)
+		}
+		if _, ok := seen[code]; ok {
+			t.Fatalf("generateRecoveryCode() repeated code %q", code)
+		}
+		seen[code] = struct{}{}
+	}
+}
+
+func TestHashRecoveryCodeNormalization(t *testing.T) {
+	want := hashRecoveryCode("abcd-efgh-ijkl-mnop")
+
+	for _, code := range []string{
+		"abcdefghijklmnop",
+		"ABCD-EFGH-IJKL-MNOP",
+		" abcd efgh ijkl mnop ",
+		"AbCd-eFgH ijkl-MNOP",
+	} {
+		if got := hashRecoveryCode(code); got != want {
+			t.Errorf("hashRecoveryCode(%q) differs from the hash of the issued form", code)
+		}
+	}
+
+	if hashRecoveryCode("abcd-efgh-ijkl-mnoq") == want {
+		t.Error("hashRecoveryCode() is the same for different codes")
+	}
+}
+
+func TestVerifyRecoveryCode(t *testing.T) {
+	recoveryCodes := &fakeRecoveryCodeRepository{}
+	s := newTestService(t, &fakeTOTPRepository{}, recoveryCodes, true)
+
+	codes, err := s.RegenerateRecoveryCodes(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+	code := codes[0]
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "uppercase without dashes", code: strings.ToUpper(strings.ReplaceAll(code, "-", ""))},
+		{name: "same code again", code: code, wantErr: service.ErrInvalidSecondFactor},
+		{name: "another code as issued", code: codes[1]},
+		{name: "unknown code", code: "aaaa-bbbb-cccc-dddd", wantErr: service.ErrInvalidSecondFactor},
+	}
+
+	// Случаи выполняются по порядку: погашенный код не принимается повторно
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.VerifyRecoveryCode(context.Background(), 1, tt.code); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyRecoveryCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	recoveryCodes := &fakeRecoveryCodeRepository{}
+	s := newTestService(t, &fakeTOTPRepository{}, recoveryCodes, true)
+
+	oldCodes, err := s.RegenerateRecoveryCodes(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+	if _, err = s.RegenerateRecoveryCodes(context.Background(), 1); err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+
+	// Неиспользованные старые коды перестают действовать
+	if err = s.VerifyRecoveryCode(context.Background(), 1, oldCodes[0]); !errors.Is(err, service.ErrInvalidSecondFactor) {
+		t.Errorf("VerifyRecoveryCode() with old code error = %v, want %v", err, service.ErrInvalidSecondFactor)
+	}
+}
+
+func TestRegenerateRecoveryCodesNotEnabled(t *testing.T) {
+	s := newTestService(t, &fakeTOTPRepository{}, &fakeRecoveryCodeRepository{}, false)
+
+	if _, err := s.RegenerateRecoveryCodes(context.Background(), 1); !errors.Is(err, service.ErrTOTPNotEnabled) {
+		t.Errorf("RegenerateRecoveryCodes() error = %v, want %v", err, service.ErrTOTPNotEnabled)
+	}
+}
@@ -0,0 +1,185 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/secretbox"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/totp"
+)
+
+// serv - сервис двухфакторной аутентификации, реализующий интерфейс service.TwoFactorService.
+type serv struct {
+	userRepository repository.UserRepository
+	totpRepository repository.TOTPRepository
+	recoveryCodes  repository.RecoveryCodeRepository
+	secretBox      *secretbox.SecretBox
+	issuer         string
+}
+
+// NewService создает сервис двухфакторной аутентификации.
+//
+// Параметры:
+//   - userRepository: хранилище пользователей, email из которого используется как имя учетной записи.
+//   - totpRepository: хранилище секретов TOTP.
+//   - recoveryCodes: хранилище кодов восстановления.
+//   - secretBox: шифрование секретов перед сохранением в БД.
+//   - issuer: название сервиса, которое показывает приложение-аутентификатор.
+func NewService(
+	userRepository repository.UserRepository,
+	totpRepository repository.TOTPRepository,
+	recoveryCodes repository.RecoveryCodeRepository,
+	secretBox *secretbox.SecretBox,
+	issuer string,
+) service.TwoFactorService {
+	return &serv{
+		userRepository: userRepository,
+		totpRepository: totpRepository,
+		recoveryCodes:  recoveryCodes,
+		secretBox:      secretBox,
+		issuer:         issuer,
+	}
+}
+
+// EnableTOTP выпускает новый секрет TOTP для пользователя userID и сохраняет его зашифрованным.
+//
+// Повторный вызов до подтверждения заменяет секрет, поэтому работает только последний QR-код.
+func (s *serv) EnableTOTP(ctx context.Context, userID int64) (*model.TOTPEnrollment, error) {
+	credentials, err := s.userRepository.GetCredentials(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, service.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, service.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := s.secretBox.Seal([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.totpRepository.SavePending(ctx, userID, encryptedSecret); err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, credentials.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP проверяет код code по неподтвержденному секрету пользователя userID, включает
+// двухфакторную аутентификацию и возвращает новый набор кодов восстановления.
+//
+// Коды сохраняются до включения, поэтому у пользователя с включенной двухфакторной
+// аутентификацией всегда есть коды восстановления.
+func (s *serv) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	stored, err := s.totpRepository.Get(ctx, userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return nil, service.ErrTOTPNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.Confirmed {
+		return nil, service.ErrTOTPAlreadyEnabled
+	}
+
+	step, ok, err := s.validate(stored, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, service.ErrInvalidSecondFactor
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.totpRepository.Confirm(ctx, userID, step)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		// Секрет подтвержден одновременным запросом
+		return nil, service.ErrTOTPAlreadyEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// IsEnabled возвращает true, если у пользователя userID есть подтвержденный секрет TOTP.
+func (s *serv) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	stored, err := s.totpRepository.Get(ctx, userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return stored.Confirmed, nil
+}
+
+// Verify проверяет код code по подтвержденному секрету пользователя userID.
+//
+// Каждый код принимается только один раз.
+func (s *serv) Verify(ctx context.Context, userID int64, code string) error {
+	stored, err := s.totpRepository.Get(ctx, userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return service.ErrInvalidSecondFactor
+	}
+	if err != nil {
+		return err
+	}
+	if !stored.Confirmed {
+		return service.ErrInvalidSecondFactor
+	}
+
+	step, ok, err := s.validate(stored, code)
+	if err != nil {
+		return err
+	}
+	if !ok || step <= stored.LastUsedStep {
+		return service.ErrInvalidSecondFactor
+	}
+
+	used, err := s.totpRepository.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return service.ErrInvalidSecondFactor
+	}
+
+	return nil
+}
+
+// validate расшифровывает сохраненный секрет и проверяет по нему код code.
+func (s *serv) validate(stored *model.TOTP, code string) (int64, bool, error) {
+	secret, err := s.secretBox.Open(stored.EncryptedSecret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return totp.Validate(string(secret), code, time.Now())
+}
@@ -0,0 +1,232 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/secretbox"
+	"github.com/anton0701/auth/internal/service"
+	"github.com/anton0701/auth/internal/totp"
+)
+
+// testSecret - секрет TOTP пользователя в тестах.
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// fakeTOTPRepository - хранилище секрета TOTP одного пользователя в памяти. Если concurrentUse
+// равно true, UseStep ведет себя так, будто код уже принят одновременным запросом.
+type fakeTOTPRepository struct {
+	repository.TOTPRepository
+	stored        *model.TOTP
+	concurrentUse bool
+}
+
+func (r *fakeTOTPRepository) Get(_ context.Context, _ int64) (*model.TOTP, error) {
+	if r.stored == nil {
+		return nil, repository.ErrTOTPNotFound
+	}
+
+	stored := *r.stored
+	return &stored, nil
+}
+
+func (r *fakeTOTPRepository) Confirm(_ context.Context, _ int64, step int64) error {
+	if r.stored == nil || r.stored.Confirmed {
+		return repository.ErrTOTPNotFound
+	}
+
+	r.stored.Confirmed = true
+	r.stored.LastUsedStep = step
+	return nil
+}
+
+func (r *fakeTOTPRepository) UseStep(_ context.Context, _ int64, step int64) (bool, error) {
+	if r.concurrentUse || step <= r.stored.LastUsedStep {
+		return false, nil
+	}
+
+	r.stored.LastUsedStep = step
+	return true, nil
+}
+
+// fakeRecoveryCodeRepository - хранилище хешей кодов восстановления одного пользователя в памяти.
+type fakeRecoveryCodeRepository struct {
+	hashes map[string]bool
+}
+
+func (r *fakeRecoveryCodeRepository) Replace(_ context.Context, _ int64, codeHashes []string) error {
+	r.hashes = make(map[string]bool, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		r.hashes[codeHash] = false
+	}
+
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepository) Use(_ context.Context, _ int64, codeHash string) (bool, error) {
+	used, ok := r.hashes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+
+	r.hashes[codeHash] = true
+	return true, nil
+}
+
+// newTestService создает сервис с секретом testSecret, сохраненным в totpRepository
+// (confirmed - подтвержден ли он), и хранилищем кодов восстановления recoveryCodes.
+func newTestService(t *testing.T, totpRepository *fakeTOTPRepository, recoveryCodes repository.RecoveryCodeRepository, confirmed bool) *serv {
+	t.Helper()
+
+	box, err := secretbox.New([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("secretbox.New() error = %v", err)
+	}
+
+	encryptedSecret, err := box.Seal([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if totpRepository.stored == nil {
+		totpRepository.stored = &model.TOTP{EncryptedSecret: encryptedSecret, Confirmed: confirmed}
+	}
+
+	return NewService(nil, totpRepository, recoveryCodes, box, "Auth").(*serv)
+}
+
+// codeAt возвращает код testSecret для момента at.
+func codeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+
+	code, err := totp.Code(testSecret, at)
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+
+	return code
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name          string
+		confirmed     bool
+		lastUsedStep  func() int64
+		offset        time.Duration
+		code          string
+		concurrentUse bool
+		wantErr       error
+	}{
+		{name: "current code", confirmed: true},
+		{name: "code of previous step", confirmed: true, offset: -totp.Period},
+		{name: "code outside skew window", confirmed: true, offset: -3 * totp.Period, wantErr: service.ErrInvalidSecondFactor},
+		{name: "wrong code", confirmed: true, code: "abcdef", wantErr: service.ErrInvalidSecondFactor},
+		{name: "not confirmed", confirmed: false, wantErr: service.ErrInvalidSecondFactor},
+		{
+			name:         "replayed step",
+			confirmed:    true,
+			lastUsedStep: func() int64 { return totp.Step(time.Now()) },
+			wantErr:      service.ErrInvalidSecondFactor,
+		},
+		{
+			name:         "step before last used",
+			confirmed:    true,
+			lastUsedStep: func() int64 { return totp.Step(time.Now()) },
+			offset:       -totp.Period,
+			wantErr:      service.ErrInvalidSecondFactor,
+		},
+		{name: "used by concurrent request", confirmed: true, concurrentUse: true, wantErr: service.ErrInvalidSecondFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totpRepository := &fakeTOTPRepository{concurrentUse: tt.concurrentUse}
+			s := newTestService(t, totpRepository, nil, tt.confirmed)
+			if tt.lastUsedStep != nil {
+				totpRepository.stored.LastUsedStep = tt.lastUsedStep()
+			}
+
+			code := tt.code
+			if len(code) == 0 {
+				code = codeAt(t, time.Now().Add(tt.offset))
+			}
+
+			err := s.Verify(context.Background(), 1, code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	s := newTestService(t, &fakeTOTPRepository{}, nil, true)
+	code := codeAt(t, time.Now())
+
+	if err := s.Verify(context.Background(), 1, code); err != nil {
+		t.Fatalf("first Verify() error = %v", err)
+	}
+	if err := s.Verify(context.Background(), 1, code); !errors.Is(err, service.ErrInvalidSecondFactor) {
+		t.Errorf("second Verify() error = %v, want %v", err, service.ErrInvalidSecondFactor)
+	}
+}
+
+func TestVerifyNotEnrolled(t *testing.T) {
+	s := NewService(nil, &fakeTOTPRepository{}, nil, nil, "Auth")
+
+	if err := s.Verify(context.Background(), 1, "123456"); !errors.Is(err, service.ErrInvalidSecondFactor) {
+		t.Errorf("Verify() error = %v, want %v", err, service.ErrInvalidSecondFactor)
+	}
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		confirmed bool
+		code      string
+		wantErr   error
+	}{
+		{name: "valid code"},
+		{name: "wrong code", code: "abcdef", wantErr: service.ErrInvalidSecondFactor},
+		{name: "already confirmed", confirmed: true, wantErr: service.ErrTOTPAlreadyEnabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totpRepository := &fakeTOTPRepository{}
+			recoveryCodes := &fakeRecoveryCodeRepository{}
+			s := newTestService(t, totpRepository, recoveryCodes, tt.confirmed)
+
+			codeTime := time.Now()
+			code := tt.code
+			if len(code) == 0 {
+				code = codeAt(t, codeTime)
+			}
+
+			codes, err := s.ConfirmTOTP(context.Background(), 1, code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmTOTP() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(recoveryCodes.hashes) > 0 {
+					t.Errorf("recovery codes saved on error")
+				}
+				return
+			}
+
+			if len(codes) != recoveryCodeCount || len(recoveryCodes.hashes) != recoveryCodeCount {
+				t.Errorf("ConfirmTOTP() returned %d codes and saved %d, want %d", len(codes), len(recoveryCodes.hashes), recoveryCodeCount)
+			}
+			if !totpRepository.stored.Confirmed || totpRepository.stored.LastUsedStep != totp.Step(codeTime) {
+				t.Errorf("stored totp = %+v, want confirmed with the step of the code", totpRepository.stored)
+			}
+
+			// Код, которым подтверждено подключение, нельзя использовать для входа
+			if err = s.Verify(context.Background(), 1, code); !errors.Is(err, service.ErrInvalidSecondFactor) {
+				t.Errorf("Verify() with confirmation code error = %v, want %v", err, service.ErrInvalidSecondFactor)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/internal/hasher"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+)
+
+// dummyPassword - пароль, хеш которого проверяется, если пользователь не найден.
+const dummyPassword = "dummy-password-for-timing"
+
+// serv - сервис пользователей, реализующий интерфейс service.UserService.
+type serv struct {
+	userRepository repository.UserRepository
+	passwordHasher hasher.Hasher
+	passwordPolicy *passwordpolicy.Policy
+	lockoutService service.LockoutService
+	auditService   service.AuditService
+	log            *zap.Logger
+
+	// dummyPasswordHash - хеш, с которым сравнивается пароль, если пользователь не найден.
+	// Так время ответа не зависит от того, зарегистрирован ли email.
+	dummyPasswordHash string
+}
+
+// NewService создает сервис пользователей, работающий с хранилищем userRepository,
+// проверяющий новые пароли политикой passwordPolicy и хеширующий их с помощью passwordHasher.
+// Неудачные проверки пароля учитываются сервисом защиты от подбора lockoutService,
+// смены паролей записываются в журнал аудита auditService.
+func NewService(
+	userRepository repository.UserRepository,
+	passwordHasher hasher.Hasher,
+	passwordPolicy *passwordpolicy.Policy,
+	lockoutService service.LockoutService,
+	auditService service.AuditService,
+	log *zap.Logger,
+) (service.UserService, error) {
+	dummyPasswordHash, err := passwordHasher.Hash(dummyPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	return &serv{
+		userRepository:    userRepository,
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		lockoutService:    lockoutService,
+		auditService:      auditService,
+		log:               log,
+		dummyPasswordHash: dummyPasswordHash,
+	}, nil
+}
+
+// Create проверяет пароль политикой паролей, вычисляет его хеш и сохраняет пользователя.
+func (s *serv) Create(ctx context.Context, user *model.UserToCreate) (int64, error) {
+	passwordHash, err := s.newPasswordHash(user)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.userRepository.Create(ctx, user, passwordHash)
+}
+
+// CreateBatch проверяет пароли всех пользователей политикой паролей, вычисляет их хеши
+// и сохраняет пользователей в одной транзакции либо по одному в режиме bestEffort.
+//
+// Хеши вычисляются до начала транзакции, чтобы она не держала соединение с БД на время хеширования.
+func (s *serv) CreateBatch(ctx context.Context, users []*model.UserToCreate, bestEffort bool) ([]model.UserCreateResult, error) {
+	results := make([]model.UserCreateResult, len(users))
+	passwordHashes := make([]string, len(users))
+	failed := false
+	for i, user := range users {
+		passwordHashes[i], results[i].Err = s.newPasswordHash(user)
+		if results[i].Err != nil {
+			failed = true
+		}
+	}
+
+	if bestEffort {
+		for i, user := range users {
+			if results[i].Err != nil {
+				continue
+			}
+			results[i].ID, results[i].Err = s.userRepository.Create(ctx, user, passwordHashes[i])
+		}
+
+		return results, nil
+	}
+
+	if failed {
+		abortBatch(results)
+		return results, nil
+	}
+
+	ids, err := s.userRepository.CreateBatch(ctx, users, passwordHashes)
+	var itemErr *repository.BatchItemError
+	if errors.As(err, &itemErr) {
+		results[itemErr.Index].Err = itemErr.Err
+		abortBatch(results)
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		results[i].ID = id
+	}
+
+	return results, nil
+}
+
+// abortBatch отмечает элементы пакета без собственной ошибки ошибкой ErrBatchAborted.
+func abortBatch(results []model.UserCreateResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = service.ErrBatchAborted
+		}
+	}
+}
+
+// newPasswordHash проверяет пароль пользователя user политикой паролей и возвращает его хеш.
+func (s *serv) newPasswordHash(user *model.UserToCreate) (string, error) {
+	if err := s.passwordPolicy.Validate(user.Password, user.Email, user.Name); err != nil {
+		return "", err
+	}
+
+	return s.passwordHasher.Hash(user.Password)
+}
+
+// Authenticate проверяет пароль пользователя с email по сохраненному хешу.
+//
+// Неудачные попытки не сбрасываются после верного пароля: это делает вызывающий код после проверки
+// второго фактора (см. auth.Login), иначе подбор кода второго фактора не приводил бы к блокировке.
+//
+// Если хеш вычислен устаревшим алгоритмом или с устаревшими параметрами, после успешной
+// проверки он пересчитывается текущим алгоритмом. Ошибка пересчета не мешает входу.
+func (s *serv) Authenticate(ctx context.Context, email, password, clientIP string) (*model.UserCredentials, error) {
+	credentials, err := s.userRepository.GetCredentialsByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		// Попытка учитывается и для несуществующего email, чтобы по блокировке нельзя было узнать,
+		// зарегистрирован ли он
+		return nil, s.verifyPassword(ctx, email, clientIP, s.dummyPasswordHash, password, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyPassword(ctx, email, clientIP, credentials.PasswordHash, password, true); err != nil {
+		return nil, err
+	}
+
+	if s.passwordHasher.NeedsRehash(credentials.PasswordHash) {
+		s.rehashPassword(ctx, credentials, password)
+	}
+
+	return credentials, nil
+}
+
+// ChangePassword проверяет текущий пароль пользователя по сохраненному хешу, а новый пароль -
+// политикой паролей, и сохраняет хеш нового пароля.
+//
+// Текущий пароль проверяется с той же защитой от подбора, что и при входе: попытки учитываются
+// для email пользователя, поэтому блокировка действует и на вход, и на смену пароля.
+func (s *serv) ChangePassword(ctx context.Context, id int64, oldPassword, newPassword, clientIP string) error {
+	credentials, err := s.userRepository.GetCredentials(ctx, id)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return service.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.verifyPassword(ctx, credentials.Email, clientIP, credentials.PasswordHash, oldPassword, true); err != nil {
+		return err
+	}
+	if err = s.lockoutService.RegisterSuccess(ctx, credentials.Email); err != nil {
+		return err
+	}
+
+	if err = s.passwordPolicy.Validate(newPassword, credentials.Email, credentials.Name); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepository.UpdatePassword(ctx, id, passwordHash)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return service.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, model.AuditEventPasswordChanged, id, map[string]string{"method": "change"})
+
+	return nil
+}
+
+// VerifyPassword проверяет пароль вошедшего пользователя по сохраненному хешу перед
+// чувствительной операцией.
+//
+// Пароль проверяется с той же защитой от подбора, что и при входе и смене пароля.
+func (s *serv) VerifyPassword(ctx context.Context, id int64, password, clientIP string) error {
+	credentials, err := s.userRepository.GetCredentials(ctx, id)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return service.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.verifyPassword(ctx, credentials.Email, clientIP, credentials.PasswordHash, password, true); err != nil {
+		return err
+	}
+
+	return s.lockoutService.RegisterSuccess(ctx, credentials.Email)
+}
+
+// verifyPassword проверяет пароль password по хешу passwordHash с защитой от подбора для email
+// и IP-адреса клиента clientIP.
+//
+// Пока email или IP-адрес заблокирован, пароль не проверяется и возвращается ErrLoginLocked.
+// Неверный пароль, а также проверка для несуществующего пользователя (userExists == false, хеш
+// сравнивается только для выравнивания времени ответа) учитываются как неудачная попытка
+// и возвращают ErrInvalidCredentials.
+func (s *serv) verifyPassword(ctx context.Context, email, clientIP, passwordHash, password string, userExists bool) error {
+	if err := s.lockoutService.Check(ctx, email, clientIP); err != nil {
+		return err
+	}
+
+	ok, err := s.passwordHasher.Verify(passwordHash, password)
+	if err != nil && userExists {
+		return fmt.Errorf("unable to verify password hash: %w", err)
+	}
+	if !ok || !userExists {
+		return s.registerFailure(ctx, email, clientIP)
+	}
+
+	return nil
+}
+
+// registerFailure учитывает неудачную проверку пароля и возвращает ErrInvalidCredentials либо
+// ошибку учета попытки, чтобы сбой БД не отключал защиту от подбора пароля.
+func (s *serv) registerFailure(ctx context.Context, email, clientIP string) error {
+	if err := s.lockoutService.RegisterFailure(ctx, email, clientIP); err != nil {
+		return err
+	}
+
+	return service.ErrInvalidCredentials
+}
+
+// rehashPassword пересчитывает хеш пароля пользователя текущим алгоритмом.
+func (s *serv) rehashPassword(ctx context.Context, credentials *model.UserCredentials, password string) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		s.log.Error("Unable to rehash password", zap.Int64("User-id", credentials.ID), zap.Error(err))
+		return
+	}
+
+	if err = s.userRepository.UpdatePasswordHash(ctx, credentials.ID, passwordHash); err != nil {
+		s.log.Error("Unable to save rehashed password", zap.Int64("User-id", credentials.ID), zap.Error(err))
+		return
+	}
+
+	credentials.PasswordHash = passwordHash
+}
@@ -0,0 +1,378 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/anton0701/auth/internal/hasher"
+	"github.com/anton0701/auth/internal/model"
+	"github.com/anton0701/auth/internal/passwordpolicy"
+	"github.com/anton0701/auth/internal/repository"
+	"github.com/anton0701/auth/internal/service"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testClientIP = "192.0.2.1"
+	testPassword = "correct-password"
+	testUserID   = 7
+)
+
+// errDuplicateEmail - ошибка сохранения пользователя с email fakeUserRepository.duplicateEmail.
+var errDuplicateEmail = errors.New("duplicate email")
+
+// fakeUserRepository - хранилище с единственным пользователем testUserID. Созданным пользователям
+// назначаются ID по порядку, пользователь с email duplicateEmail не сохраняется.
+type fakeUserRepository struct {
+	repository.UserRepository
+	credentials     *model.UserCredentials
+	updatedPassword bool
+	duplicateEmail  string
+	created         []string
+	batches         int
+}
+
+func (r *fakeUserRepository) Create(_ context.Context, user *model.UserToCreate, _ string) (int64, error) {
+	if user.Email == r.duplicateEmail {
+		return 0, errDuplicateEmail
+	}
+
+	r.created = append(r.created, user.Email)
+	return int64(len(r.created)), nil
+}
+
+func (r *fakeUserRepository) CreateBatch(_ context.Context, users []*model.UserToCreate, _ []string) ([]int64, error) {
+	r.batches++
+
+	// Транзакция откатывается целиком: ни один пользователь не сохраняется
+	ids := make([]int64, len(users))
+	for i, user := range users {
+		if user.Email == r.duplicateEmail {
+			return nil, &repository.BatchItemError{Index: i, Err: errDuplicateEmail}
+		}
+		ids[i] = int64(len(r.created) + i + 1)
+	}
+
+	for _, user := range users {
+		r.created = append(r.created, user.Email)
+	}
+
+	return ids, nil
+}
+
+func (r *fakeUserRepository) GetCredentialsByEmail(_ context.Context, email string) (*model.UserCredentials, error) {
+	if email != r.credentials.Email {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return r.credentials, nil
+}
+
+func (r *fakeUserRepository) GetCredentials(_ context.Context, id int64) (*model.UserCredentials, error) {
+	if id != r.credentials.ID {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return r.credentials, nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(_ context.Context, _ int64, passwordHash string) error {
+	r.credentials.PasswordHash = passwordHash
+	r.updatedPassword = true
+	return nil
+}
+
+// fakeLockoutService - сервис защиты от подбора, запоминающий вызовы.
+type fakeLockoutService struct {
+	service.LockoutService
+	locked    bool
+	failures  int
+	successes int
+}
+
+func (l *fakeLockoutService) Check(_ context.Context, _, _ string) error {
+	if l.locked {
+		return service.ErrLoginLocked
+	}
+
+	return nil
+}
+
+func (l *fakeLockoutService) RegisterFailure(_ context.Context, _, _ string) error {
+	l.failures++
+	return nil
+}
+
+func (l *fakeLockoutService) RegisterSuccess(_ context.Context, _ string) error {
+	l.successes++
+	return nil
+}
+
+// fakeAuditService - журнал аудита, который ничего не записывает.
+type fakeAuditService struct {
+	service.AuditService
+}
+
+func (a *fakeAuditService) Record(_ context.Context, _ model.AuditEventType, _ int64, _ map[string]string) {
+}
+
+func newTestService(t *testing.T, locked bool) (*serv, *fakeUserRepository, *fakeLockoutService) {
+	t.Helper()
+
+	passwordHasher, err := hasher.New("bcrypt", 4, hasher.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1})
+	if err != nil {
+		t.Fatalf("hasher.New() error = %v", err)
+	}
+	passwordHash, err := passwordHasher.Hash(testPassword)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	passwordPolicy, err := passwordpolicy.New(passwordpolicy.Rules{MinLength: 8})
+	if err != nil {
+		t.Fatalf("passwordpolicy.New() error = %v", err)
+	}
+
+	userRepository := &fakeUserRepository{credentials: &model.UserCredentials{
+		ID:           testUserID,
+		Email:        testEmail,
+		PasswordHash: passwordHash,
+	}}
+	lockoutService := &fakeLockoutService{locked: locked}
+
+	s, err := NewService(userRepository, passwordHasher, passwordPolicy, lockoutService, &fakeAuditService{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	return s.(*serv), userRepository, lockoutService
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		password     string
+		locked       bool
+		wantErr      error
+		wantFailures int
+	}{
+		{name: "correct password", email: testEmail, password: testPassword},
+		{name: "wrong password", email: testEmail, password: "wrong", wantErr: service.ErrInvalidCredentials, wantFailures: 1},
+		{name: "unknown email", email: "other@example.com", password: testPassword, wantErr: service.ErrInvalidCredentials, wantFailures: 1},
+		{name: "locked", email: testEmail, password: testPassword, locked: true, wantErr: service.ErrLoginLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, lockoutService := newTestService(t, tt.locked)
+
+			_, err := s.Authenticate(context.Background(), tt.email, tt.password, testClientIP)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if lockoutService.failures != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", lockoutService.failures, tt.wantFailures)
+			}
+			// Попытки сбрасывает вызывающий код после проверки второго фактора
+			if lockoutService.successes != 0 {
+				t.Errorf("successes = %d, want 0", lockoutService.successes)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		oldPassword   string
+		locked        bool
+		wantErr       error
+		wantFailures  int
+		wantSuccesses int
+		wantUpdated   bool
+	}{
+		{name: "correct password", oldPassword: testPassword, wantSuccesses: 1, wantUpdated: true},
+		{name: "wrong password", oldPassword: "wrong", wantErr: service.ErrInvalidCredentials, wantFailures: 1},
+		{name: "locked", oldPassword: testPassword, locked: true, wantErr: service.ErrLoginLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userRepository, lockoutService := newTestService(t, tt.locked)
+
+			err := s.ChangePassword(context.Background(), testUserID, tt.oldPassword, "new-password-123", testClientIP)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if lockoutService.failures != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", lockoutService.failures, tt.wantFailures)
+			}
+			if lockoutService.successes != tt.wantSuccesses {
+				t.Errorf("successes = %d, want %d", lockoutService.successes, tt.wantSuccesses)
+			}
+			if userRepository.updatedPassword != tt.wantUpdated {
+				t.Errorf("password updated = %t, want %t", userRepository.updatedPassword, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		userID        int64
+		password      string
+		locked        bool
+		wantErr       error
+		wantFailures  int
+		wantSuccesses int
+	}{
+		{name: "correct password", userID: testUserID, password: testPassword, wantSuccesses: 1},
+		{name: "wrong password", userID: testUserID, password: "wrong", wantErr: service.ErrInvalidCredentials, wantFailures: 1},
+		{name: "locked", userID: testUserID, password: testPassword, locked: true, wantErr: service.ErrLoginLocked},
+		{name: "unknown user", userID: testUserID + 1, password: testPassword, wantErr: service.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, lockoutService := newTestService(t, tt.locked)
+
+			err := s.VerifyPassword(context.Background(), tt.userID, tt.password, testClientIP)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if lockoutService.failures != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", lockoutService.failures, tt.wantFailures)
+			}
+			if lockoutService.successes != tt.wantSuccesses {
+				t.Errorf("successes = %d, want %d", lockoutService.successes, tt.wantSuccesses)
+			}
+		})
+	}
+}
+
+// batchResultKind возвращает вид результата создания пользователя из пакета для сравнения в тестах.
+func batchResultKind(result model.UserCreateResult) string {
+	var violationErr *passwordpolicy.ViolationError
+	switch {
+	case result.Err == nil:
+		return "created"
+	case errors.Is(result.Err, service.ErrBatchAborted):
+		return "aborted"
+	case errors.Is(result.Err, errDuplicateEmail):
+		return "duplicate"
+	case errors.As(result.Err, &violationErr):
+		return "weak password"
+	default:
+		return result.Err.Error()
+	}
+}
+
+func TestCreateBatch(t *testing.T) {
+	// newUsers возвращает пользователей с паролями passwords и email user<N>@example.com.
+	newUsers := func(passwords ...string) []*model.UserToCreate {
+		users := make([]*model.UserToCreate, 0, len(passwords))
+		for i, password := range passwords {
+			users = append(users, &model.UserToCreate{
+				Name:     "User",
+				Email:    fmt.Sprintf("user%d@example.com", i),
+				Password: password,
+			})
+		}
+
+		return users
+	}
+
+	tests := []struct {
+		name           string
+		users          []*model.UserToCreate
+		bestEffort     bool
+		duplicateEmail string
+		want           []string
+		wantIDs        []int64
+		wantCreated    int
+		wantBatches    int
+	}{
+		{
+			name:        "atomic",
+			users:       newUsers("Str0ng-password", "An0ther-password"),
+			want:        []string{"created", "created"},
+			wantIDs:     []int64{1, 2},
+			wantCreated: 2,
+			wantBatches: 1,
+		},
+		{
+			name:    "atomic with weak password",
+			users:   newUsers("Str0ng-password", "short", "An0ther-password"),
+			want:    []string{"aborted", "weak password", "aborted"},
+			wantIDs: []int64{0, 0, 0},
+		},
+		{
+			name:           "atomic with duplicate",
+			users:          newUsers("Str0ng-password", "An0ther-password"),
+			duplicateEmail: "user1@example.com",
+			want:           []string{"aborted", "duplicate"},
+			wantIDs:        []int64{0, 0},
+			wantBatches:    1,
+		},
+		{
+			name:        "best effort",
+			users:       newUsers("Str0ng-password", "An0ther-password"),
+			bestEffort:  true,
+			want:        []string{"created", "created"},
+			wantIDs:     []int64{1, 2},
+			wantCreated: 2,
+		},
+		{
+			name:        "best effort with weak password",
+			users:       newUsers("Str0ng-password", "short", "An0ther-password"),
+			bestEffort:  true,
+			want:        []string{"created", "weak password", "created"},
+			wantIDs:     []int64{1, 0, 2},
+			wantCreated: 2,
+		},
+		{
+			name:           "best effort with duplicate",
+			users:          newUsers("Str0ng-password", "An0ther-password", "Th1rd-password"),
+			bestEffort:     true,
+			duplicateEmail: "user1@example.com",
+			want:           []string{"created", "duplicate", "created"},
+			wantIDs:        []int64{1, 0, 2},
+			wantCreated:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userRepository, _ := newTestService(t, false)
+			userRepository.duplicateEmail = tt.duplicateEmail
+
+			results, err := s.CreateBatch(context.Background(), tt.users, tt.bestEffort)
+			if err != nil {
+				t.Fatalf("CreateBatch() error = %v", err)
+			}
+
+			got := make([]string, 0, len(results))
+			ids := make([]int64, 0, len(results))
+			for _, result := range results {
+				got = append(got, batchResultKind(result))
+				ids = append(ids, result.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CreateBatch() results = %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("CreateBatch() ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(userRepository.created) != tt.wantCreated || userRepository.batches != tt.wantBatches {
+				t.Errorf("saved %d users in %d batches, want %d users in %d batches",
+					len(userRepository.created), userRepository.batches, tt.wantCreated, tt.wantBatches)
+			}
+		})
+	}
+}
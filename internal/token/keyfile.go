@@ -0,0 +1,137 @@
+package token
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// keyFile - файл с описанием ключей подписи токенов.
+//
+// Пример:
+//
+//	{
+//	  "keys": [
+//	    {"kid": "2024-10", "secret_file": "/run/secrets/jwt-access-2024-10"},
+//	    {"kid": "2024-11", "secret_env": "JWT_ACCESS_KEY_2024_11", "activates_at": "2024-11-01T00:00:00Z"},
+//	    {"kid": "2024-12", "private_key_file": "/run/secrets/jwt-access-2024-12.pem", "activates_at": "2024-12-01T00:00:00Z"}
+//	  ]
+//	}
+//
+// Сами секреты и закрытые ключи в файле не хранятся: они читаются из отдельных файлов (например,
+// смонтированных из хранилища секретов) или из переменных окружения, которые заполняет хранилище секретов.
+type keyFile struct {
+	Keys []keyFileEntry `json:"keys"`
+}
+
+// keyFileEntry - описание одного ключа в keyFile.
+type keyFileEntry struct {
+	ID             string     `json:"kid"`
+	SecretFile     string     `json:"secret_file"`
+	SecretEnv      string     `json:"secret_env"`
+	PrivateKeyFile string     `json:"private_key_file"`
+	PrivateKeyEnv  string     `json:"private_key_env"`
+	ActivatesAt    *time.Time `json:"activates_at"`
+}
+
+// LoadKeys читает ключи подписи токенов из JSON-файла path.
+//
+// Каждый ключ задается ровно одним из полей: secret_file - путь к файлу с секретом HS256
+// (завершающий перевод строки отбрасывается), secret_env - имя переменной окружения с секретом,
+// private_key_file или private_key_env - файл или переменная окружения с закрытым ключом RSA
+// или Ed25519 в формате PEM (см. ParsePrivateKey).
+// Время активации activates_at необязательно, без него ключ активен всегда.
+//
+// Возвращает:
+//   - []Key: ключи в порядке описания в файле.
+//   - error: ошибка, если файл или секрет не удалось прочитать либо описание ключа некорректно.
+func LoadKeys(path string) ([]Key, error) {
+	content, err := os.ReadFile(path) // #nosec G304 -- путь к файлу ключей задается в конфиге
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read signing keys file")
+	}
+
+	var file keyFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrap(err, "unable to parse signing keys file")
+	}
+
+	keys := make([]Key, 0, len(file.Keys))
+	for _, entry := range file.Keys {
+		if len(entry.ID) == 0 {
+			return nil, errors.New("signing key kid must not be empty")
+		}
+
+		key, err := entry.key()
+		if err != nil {
+			return nil, errors.Wrapf(err, "signing key %q", entry.ID)
+		}
+		if entry.ActivatesAt != nil {
+			key.ActivatesAt = *entry.ActivatesAt
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// key возвращает ключ с секретом или закрытым ключом из файла или переменной окружения.
+func (e *keyFileEntry) key() (Key, error) {
+	sources := 0
+	for _, source := range []string{e.SecretFile, e.SecretEnv, e.PrivateKeyFile, e.PrivateKeyEnv} {
+		if len(source) > 0 {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return Key{}, errors.New("exactly one of secret_file, secret_env, private_key_file and private_key_env must be set")
+	}
+
+	switch {
+	case len(e.SecretFile) > 0:
+		secret, err := os.ReadFile(e.SecretFile) // #nosec G304 -- путь к секрету задается в файле ключей
+		if err != nil {
+			return Key{}, errors.Wrap(err, "unable to read secret file")
+		}
+		return Key{ID: e.ID, Secret: []byte(strings.TrimRight(string(secret), "\r\n"))}, nil
+	case len(e.SecretEnv) > 0:
+		secret, err := lookupEnv(e.SecretEnv)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: e.ID, Secret: []byte(secret)}, nil
+	case len(e.PrivateKeyFile) > 0:
+		content, err := os.ReadFile(e.PrivateKeyFile) // #nosec G304 -- путь к ключу задается в файле ключей
+		if err != nil {
+			return Key{}, errors.Wrap(err, "unable to read private key file")
+		}
+		privateKey, err := ParsePrivateKey(content)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: e.ID, PrivateKey: privateKey}, nil
+	default:
+		content, err := lookupEnv(e.PrivateKeyEnv)
+		if err != nil {
+			return Key{}, err
+		}
+		privateKey, err := ParsePrivateKey([]byte(content))
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: e.ID, PrivateKey: privateKey}, nil
+	}
+}
+
+// lookupEnv возвращает значение переменной окружения envName или ошибку, если она не задана.
+func lookupEnv(envName string) (string, error) {
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return "", errors.Errorf("environment variable %s is not set", envName)
+	}
+
+	return value, nil
+}
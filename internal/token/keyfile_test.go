@@ -0,0 +1,137 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	secretFile := writeFile("secret", "0123456789abcdef0123456789abcdef\n")
+	t.Setenv("JWT_TEST_SECRET", "fedcba9876543210fedcba9876543210")
+
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name: "secret file and env",
+			content: `{"keys": [
+				{"kid": "2024-10", "secret_file": "` + secretFile + `"},
+				{"kid": "2024-11", "secret_env": "JWT_TEST_SECRET", "activates_at": "2024-11-01T00:00:00Z"}
+			]}`,
+			wantIDs: []string{"2024-10", "2024-11"},
+		},
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantErr: true},
+		{name: "invalid json", content: `{"keys": [`, wantErr: true},
+		{name: "invalid activates_at", content: `{"keys": [{"kid": "1", "secret_env": "JWT_TEST_SECRET", "activates_at": "tomorrow"}]}`, wantErr: true},
+		{name: "empty kid", content: `{"keys": [{"secret_env": "JWT_TEST_SECRET"}]}`, wantErr: true},
+		{name: "no source", content: `{"keys": [{"kid": "1"}]}`, wantErr: true},
+		{
+			name:    "several sources",
+			content: `{"keys": [{"kid": "1", "secret_env": "JWT_TEST_SECRET", "secret_file": "` + secretFile + `"}]}`,
+			wantErr: true,
+		},
+		{name: "unset env", content: `{"keys": [{"kid": "1", "secret_env": "JWT_TEST_UNSET"}]}`, wantErr: true},
+		{name: "missing secret file", content: `{"keys": [{"kid": "1", "secret_file": "` + filepath.Join(dir, "missing") + `"}]}`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if len(path) == 0 {
+				path = writeFile(fmt.Sprintf("keys-%d.json", i), tt.content)
+			}
+
+			keys, err := LoadKeys(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeys() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantIDs) {
+				t.Fatalf("LoadKeys() returned %d keys, want %d", len(keys), len(tt.wantIDs))
+			}
+			for j, key := range keys {
+				if key.ID != tt.wantIDs[j] {
+					t.Errorf("key %d id = %q, want %q", j, key.ID, tt.wantIDs[j])
+				}
+				if len(key.Secret) != minSecretLength {
+					t.Errorf("key %q secret length = %d, want %d without trailing newline", key.ID, len(key.Secret), minSecretLength)
+				}
+			}
+			if len(keys) == 2 && !keys[1].ActivatesAt.Equal(time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("key %q activates at %s", keys[1].ID, keys[1].ActivatesAt)
+			}
+		})
+	}
+}
+
+func TestLoadKeysPrivateKey(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ed25519Key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	privateKeyPEM := pemBlock("PRIVATE KEY", der)
+
+	dir := t.TempDir()
+	privateKeyFile := filepath.Join(dir, "key.pem")
+	if err = os.WriteFile(privateKeyFile, privateKeyPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	invalidKeyFile := filepath.Join(dir, "invalid.pem")
+	if err = os.WriteFile(invalidKeyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("JWT_TEST_PRIVATE_KEY", string(privateKeyPEM))
+	t.Setenv("JWT_TEST_INVALID_PRIVATE_KEY", "not a key")
+
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr bool
+	}{
+		{name: "private key file", entry: `"private_key_file": "` + privateKeyFile + `"`},
+		{name: "private key env", entry: `"private_key_env": "JWT_TEST_PRIVATE_KEY"`},
+		{name: "invalid private key file", entry: `"private_key_file": "` + invalidKeyFile + `"`, wantErr: true},
+		{name: "invalid private key env", entry: `"private_key_env": "JWT_TEST_INVALID_PRIVATE_KEY"`, wantErr: true},
+		{name: "missing private key file", entry: `"private_key_file": "` + filepath.Join(dir, "missing.pem") + `"`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("keys-%d.json", i))
+			content := `{"keys": [{"kid": "2024-12", ` + tt.entry + `}]}`
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			keys, err := LoadKeys(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeys() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && (len(keys) != 1 || !keys[0].equal(&Key{PrivateKey: ed25519Key})) {
+				t.Errorf("LoadKeys() = %v, want the ed25519 key", keys)
+			}
+		})
+	}
+}
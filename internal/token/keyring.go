@@ -0,0 +1,276 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+
+	"github.com/anton0701/auth/internal/oidc"
+)
+
+const (
+	// minSecretLength - минимальная длина секрета для подписи токенов алгоритмом HS256 (256 бит).
+	minSecretLength = 32
+	// minRSAKeyBits - минимальный размер RSA-ключа для подписи токенов алгоритмом RS256.
+	minRSAKeyBits = 2048
+)
+
+// Key - ключ подписи токенов.
+//
+// Задается либо секрет Secret (алгоритм HS256), либо закрытый ключ PrivateKey: RSA (RS256)
+// или Ed25519 (EdDSA). Открытые части асимметричных ключей публикуются в JWKS, и другие сервисы
+// проверяют токены без общего секрета.
+type Key struct {
+	// ID - идентификатор ключа, записывается в заголовок "kid" подписанных им токенов.
+	// Пустой ID допустим только у единственного ключа (токены без "kid").
+	ID string
+	// Secret - секрет для подписи алгоритмом HS256.
+	Secret []byte
+	// PrivateKey - закрытый ключ *rsa.PrivateKey или ed25519.PrivateKey.
+	PrivateKey crypto.Signer
+	// ActivatesAt - время, с которого ключ используется для подписи новых токенов.
+	// Нулевое время - ключ активен всегда.
+	ActivatesAt time.Time
+}
+
+// Keyring - набор ключей подписи токенов одного вида (access или refresh).
+//
+// Новые токены подписываются самым поздним из активных ключей. Предыдущий ключ после активации
+// следующего еще overlap принимается при проверке, чтобы выпущенные им токены действовали до
+// истечения. Ключи, время активации которых еще не наступило, тоже принимаются при проверке:
+// так другие экземпляры сервера с небольшим расхождением часов не отвергают новые токены.
+//
+// Набор ключей можно заменить без перезапуска сервера (Replace), например при плановой ротации.
+type Keyring struct {
+	overlap time.Duration
+
+	mu   sync.RWMutex
+	keys []Key
+}
+
+// NewKeyring создает набор ключей keys, в котором предыдущий ключ принимается при проверке
+// еще overlap после активации следующего.
+//
+// Возвращает ошибку, если ключи некорректны (см. Replace).
+func NewKeyring(keys []Key, overlap time.Duration) (*Keyring, error) {
+	k := &Keyring{overlap: overlap}
+	if err := k.Replace(keys); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Replace заменяет набор ключей.
+//
+// Возвращает ошибку и оставляет прежний набор, если ключей нет, ID ключей повторяются, ID пустой
+// у одного из нескольких ключей, секрет короче minSecretLength байт, RSA-ключ короче minRSAKeyBits бит
+// или закрытый ключ другого типа.
+func (k *Keyring) Replace(keys []Key) error {
+	if len(keys) == 0 {
+		return errors.New("no signing keys")
+	}
+
+	sorted := make([]Key, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.Before(sorted[j].ActivatesAt)
+	})
+
+	ids := make(map[string]struct{}, len(sorted))
+	for _, key := range sorted {
+		if len(key.ID) == 0 && len(sorted) > 1 {
+			return errors.New("signing key id must not be empty when there are several keys")
+		}
+		if _, ok := ids[key.ID]; ok {
+			return errors.Errorf("duplicate signing key id %q", key.ID)
+		}
+		ids[key.ID] = struct{}{}
+
+		if err := key.validate(); err != nil {
+			return errors.Wrapf(err, "signing key %q", key.ID)
+		}
+	}
+
+	k.mu.Lock()
+	k.keys = sorted
+	k.mu.Unlock()
+
+	return nil
+}
+
+// signingKey возвращает ключ для подписи новых токенов в момент now: самый поздний из активных.
+func (k *Keyring) signingKey(now time.Time) (Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if !k.keys[i].ActivatesAt.After(now) {
+			return k.keys[i], nil
+		}
+	}
+
+	return Key{}, errors.New("no active signing key")
+}
+
+// verificationKey возвращает ключ с ID kid, если в момент now он принимается при проверке.
+func (k *Keyring) verificationKey(kid string, now time.Time) (Key, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for i, key := range k.keys {
+		if key.ID != kid {
+			continue
+		}
+
+		if k.retired(i, now) {
+			return Key{}, false
+		}
+
+		return key, true
+	}
+
+	return Key{}, false
+}
+
+// retired возвращает true, если ключ с индексом i в момент now вышел из обращения: после активации
+// следующего ключа прошло больше overlap. Вызывается под блокировкой mu.
+func (k *Keyring) retired(i int, now time.Time) bool {
+	return i+1 < len(k.keys) && now.Sub(k.keys[i+1].ActivatesAt) > k.overlap
+}
+
+// SharesKey возвращает true, если хотя бы один из keys совпадает с ключом набора: у них одинаковый
+// секрет или закрытый ключ. Access- и refresh-токены должны подписываться разными ключами, иначе
+// при ошибке в проверке назначения токена один вид токенов можно было бы использовать вместо другого.
+func (k *Keyring) SharesKey(keys []Key) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		for _, other := range keys {
+			if key.equal(&other) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SigningAlgorithms возвращает алгоритмы подписи ключей набора.
+func (k *Keyring) SigningAlgorithms() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var algorithms []string
+	seen := make(map[string]struct{}, len(k.keys))
+	for _, key := range k.keys {
+		alg := key.method().Alg()
+		if _, ok := seen[alg]; ok {
+			continue
+		}
+		seen[alg] = struct{}{}
+		algorithms = append(algorithms, alg)
+	}
+
+	return algorithms
+}
+
+// PublicKeys возвращает открытые ключи для проверки подписи: асимметричные ключи набора, которые
+// еще принимаются при проверке, в том числе не активированные, чтобы другие сервисы получили их
+// заранее. Секреты HS256 не публикуются.
+func (k *Keyring) PublicKeys() []oidc.JWK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+
+	var keys []oidc.JWK
+	for i, key := range k.keys {
+		if key.PrivateKey == nil {
+			continue
+		}
+		if k.retired(i, now) {
+			continue
+		}
+
+		jwk, err := publicJWK(key.ID, key.PrivateKey.Public())
+		if err != nil {
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+
+	return keys
+}
+
+// validate проверяет, что ключ пригоден для подписи токенов.
+func (key *Key) validate() error {
+	switch privateKey := key.PrivateKey.(type) {
+	case nil:
+		if len(key.Secret) < minSecretLength {
+			return errors.Errorf("secret must be at least %d bytes long", minSecretLength)
+		}
+	case *rsa.PrivateKey:
+		if privateKey.N.BitLen() < minRSAKeyBits {
+			return errors.Errorf("rsa key must be at least %d bits long", minRSAKeyBits)
+		}
+	case ed25519.PrivateKey:
+	default:
+		return errors.Errorf("unsupported private key type %T", privateKey)
+	}
+
+	if key.PrivateKey != nil && len(key.Secret) > 0 {
+		return errors.New("only one of secret and private key must be set")
+	}
+
+	return nil
+}
+
+// equal возвращает true, если у ключей одинаковый секрет или закрытый ключ. ID и время активации
+// не сравниваются.
+func (key *Key) equal(other *Key) bool {
+	if key.PrivateKey == nil || other.PrivateKey == nil {
+		return key.PrivateKey == nil && other.PrivateKey == nil && bytes.Equal(key.Secret, other.Secret)
+	}
+
+	publicKey, ok := key.PrivateKey.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(other.PrivateKey.Public())
+}
+
+// method возвращает алгоритм подписи ключа.
+func (key *Key) method() jwt.SigningMethod {
+	switch key.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// signingMaterial возвращает ключ для подписи токена: секрет или закрытый ключ.
+func (key *Key) signingMaterial() interface{} {
+	if key.PrivateKey != nil {
+		return key.PrivateKey
+	}
+
+	return key.Secret
+}
+
+// verificationMaterial возвращает ключ для проверки подписи токена: секрет или открытый ключ.
+func (key *Key) verificationMaterial() interface{} {
+	if key.PrivateKey != nil {
+		return key.PrivateKey.Public()
+	}
+
+	return key.Secret
+}
@@ -0,0 +1,186 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+)
+
+func TestKeyringSharesKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	keys, err := NewKeyring([]Key{
+		{ID: "secret", Secret: secret},
+		{ID: "ed25519", PrivateKey: privateKey},
+	}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		keys []Key
+		want bool
+	}{
+		{name: "same secret with another id", keys: []Key{{ID: "other", Secret: secret}}, want: true},
+		{name: "same private key", keys: []Key{{ID: "other", PrivateKey: privateKey}}, want: true},
+		{name: "another secret", keys: []Key{{ID: "secret", Secret: []byte("fedcba9876543210fedcba9876543210")}}},
+		{name: "another private key", keys: []Key{{ID: "ed25519", PrivateKey: otherPrivateKey}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys.SharesKey(tt.keys); got != tt.want {
+				t.Errorf("SharesKey() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyringReplace(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name    string
+		keys    []Key
+		wantErr bool
+	}{
+		{name: "single key without id", keys: []Key{{Secret: secret}}},
+		{name: "several keys", keys: []Key{{ID: "1", Secret: secret}, {ID: "2", Secret: secret}}},
+		{name: "no keys", wantErr: true},
+		{name: "empty id among several keys", keys: []Key{{ID: "1", Secret: secret}, {Secret: secret}}, wantErr: true},
+		{name: "duplicate id", keys: []Key{{ID: "1", Secret: secret}, {ID: "1", Secret: secret}}, wantErr: true},
+		{name: "short secret", keys: []Key{{ID: "1", Secret: secret[:minSecretLength-1]}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyring([]Key{{ID: "previous", Secret: secret}}, 0)
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+
+			err = keys.Replace(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Replace() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			// При ошибке остается прежний набор ключей
+			_, previousKept := keys.verificationKey("previous", time.Now())
+			if previousKept != tt.wantErr {
+				t.Errorf("previous key kept = %t, want %t", previousKept, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	now := time.Now()
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	// Ключ current активирован час назад, next будет активирован через час
+	keys := []Key{
+		{ID: "next", Secret: secret, ActivatesAt: now.Add(time.Hour)},
+		{ID: "previous", Secret: secret},
+		{ID: "current", Secret: secret, ActivatesAt: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		overlap time.Duration
+		kid     string
+		want    bool
+	}{
+		{name: "current key", overlap: time.Minute, kid: "current", want: true},
+		{name: "previous key within overlap", overlap: 2 * time.Hour, kid: "previous", want: true},
+		{name: "previous key retired", overlap: time.Minute, kid: "previous", want: false},
+		{name: "not yet active key", overlap: time.Minute, kid: "next", want: true},
+		{name: "unknown key", overlap: 2 * time.Hour, kid: "unknown", want: false},
+		{name: "token without kid", overlap: 2 * time.Hour, kid: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := NewKeyring(keys, tt.overlap)
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+
+			signingKey, err := keyring.signingKey(now)
+			if err != nil {
+				t.Fatalf("signingKey() error = %v", err)
+			}
+			if signingKey.ID != "current" {
+				t.Errorf("signingKey() = %q, want %q", signingKey.ID, "current")
+			}
+
+			if _, got := keyring.verificationKey(tt.kid, now); got != tt.want {
+				t.Errorf("verificationKey(%q) = %t, want %t", tt.kid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyringNoActiveKey(t *testing.T) {
+	keyring, err := NewKeyring([]Key{
+		{ID: "next", Secret: []byte("0123456789abcdef0123456789abcdef"), ActivatesAt: time.Now().Add(time.Hour)},
+	}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	if _, _, err = Generate(1, 1, UseAccess, keyring, time.Minute, IssuerParams{}); err == nil {
+		t.Error("Generate() error = nil, want error without active signing key")
+	}
+}
+
+func TestKeyValidatePrivateKey(t *testing.T) {
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     Key
+		wantErr bool
+	}{
+		{name: "rsa 2048", key: Key{PrivateKey: testRSAKey}},
+		{name: "rsa 1024", key: Key{PrivateKey: smallRSAKey}, wantErr: true},
+		{name: "ed25519", key: Key{PrivateKey: ed25519Key}},
+		{name: "ecdsa", key: Key{PrivateKey: ecdsaKey}, wantErr: true},
+		{
+			name:    "secret and private key",
+			key:     Key{PrivateKey: ed25519Key, Secret: []byte("0123456789abcdef0123456789abcdef")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring([]Key{tt.key}, 0); (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyring() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
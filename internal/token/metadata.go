@@ -0,0 +1,71 @@
+package token
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// authorizationHeader - ключ метаданных gRPC, в котором передается access-токен.
+	authorizationHeader = "authorization"
+
+	// bearerPrefix - префикс значения authorizationHeader.
+	bearerPrefix = "Bearer "
+
+	// apiKeyHeader - ключ метаданных gRPC, в котором другие сервисы передают API-ключ.
+	apiKeyHeader = "x-api-key"
+)
+
+var (
+	// ErrNoToken - в метаданных запроса нет access-токена.
+	ErrNoToken = errors.New("authorization token is not provided")
+
+	// ErrNoAPIKey - в метаданных запроса нет API-ключа.
+	ErrNoAPIKey = errors.New("api key is not provided")
+)
+
+// FromIncomingContext возвращает access-токен из метаданных входящего gRPC-запроса
+// ("authorization: Bearer <token>").
+//
+// Возвращает:
+//   - string: токен без префикса "Bearer ".
+//   - error: ErrNoToken, если заголовка нет, ошибка, если заголовок имеет неверный формат.
+func FromIncomingContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrNoToken
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return "", ErrNoToken
+	}
+
+	if !strings.HasPrefix(values[0], bearerPrefix) {
+		return "", errors.New("authorization header must have format \"Bearer <token>\"")
+	}
+
+	return strings.TrimPrefix(values[0], bearerPrefix), nil
+}
+
+// APIKeyFromIncomingContext возвращает API-ключ из метаданных входящего gRPC-запроса ("x-api-key: <key>").
+//
+// Возвращает:
+//   - string: API-ключ.
+//   - error: ErrNoAPIKey, если ключ не передан.
+func APIKeyFromIncomingContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrNoAPIKey
+	}
+
+	values := md.Get(apiKeyHeader)
+	if len(values) == 0 || len(values[0]) == 0 {
+		return "", ErrNoAPIKey
+	}
+
+	return values[0], nil
+}
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// opaqueLength - количество случайных байт в одноразовом токене.
+const opaqueLength = 32
+
+// GenerateOpaque возвращает случайный одноразовый токен (для ссылок из писем), пригодный
+// для использования в URL.
+//
+// В отличие от JWT, токен не содержит данных: пользователь, которому он выпущен, и срок
+// действия хранятся в БД рядом с HashOpaque(token).
+func GenerateOpaque() (string, error) {
+	b := make([]byte, opaqueLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "unable to generate opaque token")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaque возвращает хеш одноразового токена, который хранится в БД вместо самого токена.
+//
+// Токен содержит достаточно случайных байт, поэтому медленный хеш вроде bcrypt не нужен.
+func HashOpaque(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+
+	"github.com/anton0701/auth/internal/oidc"
+)
+
+// ParsePrivateKey разбирает закрытый ключ подписи токенов в формате PEM: RSA (PKCS #1 или PKCS #8)
+// или Ed25519 (PKCS #8).
+//
+// Возвращает:
+//   - crypto.Signer: *rsa.PrivateKey или ed25519.PrivateKey.
+//   - error: ошибка, если PEM некорректный либо ключ другого типа.
+func ParsePrivateKey(pemBytes []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("private key must be PEM encoded")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse rsa private key")
+		}
+		return privateKey, nil
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse private key")
+	}
+
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return privateKey, nil
+	case ed25519.PrivateKey:
+		return privateKey, nil
+	default:
+		return nil, errors.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// Thumbprint возвращает отпечаток открытой части ключа privateKey по RFC 7638 в base64url.
+// Подходит как "kid" ключа, для которого идентификатор не задан явно.
+func Thumbprint(privateKey crypto.Signer) (string, error) {
+	jwk, err := publicJWK("", privateKey.Public())
+	if err != nil {
+		return "", err
+	}
+
+	// Для отпечатка берутся только обязательные поля ключа в лексикографическом порядке
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{E: jwk.E, Kty: jwk.Kty, N: jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X}
+	}
+
+	content, err := json.Marshal(members)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to marshal jwk")
+	}
+
+	sum := sha256.Sum256(content)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// publicJWK возвращает открытый ключ publicKey с идентификатором kid в формате JWK.
+func publicJWK(kid string, publicKey crypto.PublicKey) (oidc.JWK, error) {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		return oidc.JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return oidc.JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Kid: kid,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(publicKey),
+		}, nil
+	default:
+		return oidc.JWK{}, errors.Errorf("unsupported public key type %T", publicKey)
+	}
+}
@@ -0,0 +1,198 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// idLength - длина случайного идентификатора токена (jti) в байтах.
+const idLength = 16
+
+func init() {
+	// Время выпуска и истечения записывается в токен с точностью до микросекунды, как timestamp
+	// в PostgreSQL: время выпуска refresh-токена сравнивается со временем отзыва токенов без округления
+	jwt.TimePrecision = time.Microsecond
+}
+
+// Use - назначение токена (claim "token_use").
+type Use string
+
+const (
+	// UseAccess - access-токен, с которым вызываются методы АПИ.
+	UseAccess Use = "access"
+	// UseRefresh - refresh-токен, по которому выпускаются новые access- и refresh-токены.
+	UseRefresh Use = "refresh"
+	// UseReauth - токен повторной аутентификации, подтверждающий, что пользователь недавно повторно
+	// ввел пароль. Подписывается ключами access-токенов, но вместо access-токена не принимается.
+	UseReauth Use = "reauth"
+)
+
+// UserClaims - данные пользователя, которые содержит JWT-токен.
+type UserClaims struct {
+	jwt.RegisteredClaims
+
+	// UserID - ID пользователя в таблице auth. 0 - токен выпущен сервисному аккаунту.
+	UserID int64 `json:"uid"`
+	// Role - роль пользователя (значение enum UserRole).
+	Role int32 `json:"role"`
+	// ImpersonatedBy - ID администратора, выпустившего токен от имени пользователя (см. GenerateImpersonation).
+	// 0 - токен выпущен самому пользователю.
+	ImpersonatedBy int64 `json:"impersonated_by,omitempty"`
+	// ClientID - client_id сервисного аккаунта, которому выпущен токен (см. GenerateServiceAccount).
+	// Пустая строка - токен выпущен пользователю.
+	ClientID string `json:"client_id,omitempty"`
+	// Use - назначение токена. Проверяется в Verify, чтобы access-токен нельзя было использовать
+	// вместо refresh-токена и наоборот, даже если ключи подписи совпадут.
+	Use Use `json:"token_use"`
+}
+
+// IssuerParams - издатель токенов (claim "iss") и их получатели (claim "aud").
+//
+// Записываются в выпускаемые токены и проверяются при проверке токена, чтобы другие сервисы
+// могли убедиться, что токен выпущен этим сервером и предназначен им.
+type IssuerParams struct {
+	// Issuer - URL издателя, совпадает с issuer из OIDC discovery.
+	Issuer string
+	// Audience - получатели токена. При проверке токен должен быть выпущен для первого из них.
+	Audience []string
+}
+
+// Generate выпускает JWT-токен для пользователя, подписанный текущим ключом из keys алгоритмом
+// этого ключа (HS256, RS256 или EdDSA). ID ключа записывается в заголовок "kid".
+//
+// Параметры:
+//   - userID: ID пользователя, записывается также в claim "sub".
+//   - role: роль пользователя.
+//   - use: назначение токена (claim "token_use").
+//   - keys: ключи подписи токенов.
+//   - ttl: время жизни токена.
+//   - issuer: издатель и получатели токена.
+//
+// Возвращает:
+//   - string: подписанный токен.
+//   - *UserClaims: данные, записанные в токен (в том числе время истечения и jti).
+//   - error: ошибка, если что-то пошло не так.
+func Generate(userID int64, role int32, use Use, keys *Keyring, ttl time.Duration, issuer IssuerParams) (string, *UserClaims, error) {
+	return generate(&UserClaims{UserID: userID, Role: role, Use: use}, keys, ttl, issuer)
+}
+
+// GenerateImpersonation выпускает access-токен, с которым администратор adminID действует от имени
+// пользователя userID. Токен отличается от обычного access-токена только claim "impersonated_by".
+//
+// Параметры и возвращаемые значения - как у Generate.
+func GenerateImpersonation(userID int64, role int32, adminID int64, keys *Keyring, ttl time.Duration, issuer IssuerParams) (string, *UserClaims, error) {
+	return generate(&UserClaims{UserID: userID, Role: role, ImpersonatedBy: adminID, Use: UseAccess}, keys, ttl, issuer)
+}
+
+// GenerateServiceAccount выпускает access-токен сервисному аккаунту clientID. В claim "sub"
+// записывается clientID, UserID равен 0.
+//
+// Параметры и возвращаемые значения - как у Generate.
+func GenerateServiceAccount(clientID string, role int32, keys *Keyring, ttl time.Duration, issuer IssuerParams) (string, *UserClaims, error) {
+	return generate(&UserClaims{Role: role, ClientID: clientID, Use: UseAccess}, keys, ttl, issuer)
+}
+
+// generate дополняет claims стандартными полями и подписывает токен текущим ключом из keys.
+func generate(claims *UserClaims, keys *Keyring, ttl time.Duration, issuer IssuerParams) (string, *UserClaims, error) {
+	id, err := newID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	key, err := keys.signingKey(now)
+	if err != nil {
+		return "", nil, err
+	}
+
+	subject := strconv.FormatInt(claims.UserID, 10)
+	if len(claims.ClientID) > 0 {
+		subject = claims.ClientID
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        id,
+		Issuer:    issuer.Issuer,
+		Subject:   subject,
+		Audience:  issuer.Audience,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	unsignedToken := jwt.NewWithClaims(key.method(), claims)
+	if len(key.ID) > 0 {
+		unsignedToken.Header["kid"] = key.ID
+	}
+
+	signedToken, err := unsignedToken.SignedString(key.signingMaterial())
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to sign token")
+	}
+
+	return signedToken, claims, nil
+}
+
+// Verify проверяет подпись, срок действия, назначение, издателя и получателя токена и возвращает его данные.
+//
+// Подпись проверяется ключом из keys с ID из заголовка "kid" токена, если этот ключ еще
+// не вышел из обращения, и только алгоритмом этого ключа.
+//
+// Параметры:
+//   - tokenString: токен.
+//   - use: ожидаемое назначение токена.
+//   - keys: ключи, одним из которых должен быть подписан токен.
+//   - issuer: ожидаемые издатель и получатели токена.
+//
+// Возвращает:
+//   - *UserClaims: данные токена.
+//   - error: ошибка, если токен некорректный, подписан неизвестным или вышедшим из обращения ключом
+//     либо другим алгоритмом, истек, имеет другое назначение
+//     либо выпущен другим издателем или для другого получателя.
+func Verify(tokenString string, use Use, keys *Keyring, issuer IssuerParams) (*UserClaims, error) {
+	claims := &UserClaims{}
+
+	options := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(issuer.Issuer),
+	}
+	if len(issuer.Audience) > 0 {
+		options = append(options, jwt.WithAudience(issuer.Audience[0]))
+	}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := keys.verificationKey(kid, time.Now())
+		if !ok {
+			return nil, errors.Errorf("unknown signing key %q", kid)
+		}
+		// Алгоритм берется из ключа, а не из заголовка токена, иначе открытый ключ
+		// можно было бы использовать как секрет HS256
+		if t.Method.Alg() != key.method().Alg() {
+			return nil, errors.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return key.verificationMaterial(), nil
+	}, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid token")
+	}
+	if claims.Use != use {
+		return nil, errors.Errorf("invalid token: unexpected token use %q", claims.Use)
+	}
+
+	return claims, nil
+}
+
+// newID возвращает случайный идентификатор токена (jti).
+func newID() (string, error) {
+	id := make([]byte, idLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", errors.Wrap(err, "unable to generate token id")
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,191 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerifyUse(t *testing.T) {
+	keys, err := NewKeyring([]Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		use     Use
+		wantUse Use
+		wantErr bool
+	}{
+		{name: "access as access", use: UseAccess, wantUse: UseAccess},
+		{name: "refresh as refresh", use: UseRefresh, wantUse: UseRefresh},
+		{name: "access as refresh", use: UseAccess, wantUse: UseRefresh, wantErr: true},
+		{name: "refresh as access", use: UseRefresh, wantUse: UseAccess, wantErr: true},
+		{name: "without use", use: "", wantUse: UseAccess, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenString, _, err := Generate(1, 1, tt.use, keys, time.Minute, IssuerParams{})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			claims, err := Verify(tokenString, tt.wantUse, keys, IssuerParams{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && claims.Use != tt.wantUse {
+				t.Errorf("Verify() use = %q, want %q", claims.Use, tt.wantUse)
+			}
+		})
+	}
+}
+
+func TestVerifyIssuedAtPrecision(t *testing.T) {
+	keys, err := NewKeyring([]Key{{Secret: []byte("0123456789abcdef0123456789abcdef")}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	tokenString, generated, err := Generate(1, 1, UseRefresh, keys, time.Minute, IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	claims, err := Verify(tokenString, UseRefresh, keys, IssuerParams{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// Время выпуска не округляется до секунды: расхождение не больше погрешности float64
+	if diff := generated.IssuedAt.Sub(claims.IssuedAt.Time); diff < 0 || diff > time.Microsecond {
+		t.Errorf("IssuedAt = %s, want %s", claims.IssuedAt.Time, generated.IssuedAt.Time)
+	}
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	oldSecret := []byte("0123456789abcdef0123456789abcdef")
+	newSecret := []byte("fedcba9876543210fedcba9876543210")
+
+	oldKeys, err := NewKeyring([]Key{{ID: "2024-10", Secret: oldSecret}}, 0)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	tokenString, _, err := Generate(1, 1, UseAccess, oldKeys, time.Hour, IssuerParams{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    []Key
+		overlap time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "previous key within overlap",
+			keys:    []Key{{ID: "2024-10", Secret: oldSecret}, {ID: "2024-11", Secret: newSecret, ActivatesAt: time.Now().Add(-time.Minute)}},
+			overlap: time.Hour,
+		},
+		{
+			name:    "previous key retired",
+			keys:    []Key{{ID: "2024-10", Secret: oldSecret}, {ID: "2024-11", Secret: newSecret, ActivatesAt: time.Now().Add(-2 * time.Hour)}},
+			overlap: time.Hour,
+			wantErr: true,
+		},
+		{
+			name:    "previous key removed",
+			keys:    []Key{{ID: "2024-11", Secret: newSecret}},
+			wantErr: true,
+		},
+		{
+			name:    "same kid with another secret",
+			keys:    []Key{{ID: "2024-10", Secret: newSecret}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyring(tt.keys, tt.overlap)
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+
+			if _, err = Verify(tokenString, UseAccess, keys, IssuerParams{}); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyAlgorithm(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(testRSAKey.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+
+	now := time.Now()
+	keys, err := NewKeyring([]Key{
+		{ID: "hs", Secret: secret},
+		{ID: "rsa", PrivateKey: testRSAKey, ActivatesAt: now.Add(-2 * time.Minute)},
+		{ID: "ed25519", PrivateKey: ed25519Key, ActivatesAt: now.Add(-time.Minute)},
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	// sign подписывает токен алгоритмом method с заголовком "kid"
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		claims := &UserClaims{
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+			UserID:           1,
+			Use:              UseAccess,
+		}
+		unsignedToken := jwt.NewWithClaims(method, claims)
+		unsignedToken.Header["kid"] = kid
+		tokenString, err := unsignedToken.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return tokenString
+	}
+
+	tests := []struct {
+		name        string
+		tokenString string
+		wantErr     bool
+	}{
+		{name: "hs256", tokenString: sign(jwt.SigningMethodHS256, "hs", secret)},
+		{name: "rs256", tokenString: sign(jwt.SigningMethodRS256, "rsa", testRSAKey)},
+		{name: "eddsa", tokenString: sign(jwt.SigningMethodEdDSA, "ed25519", ed25519Key)},
+		{
+			name:        "rsa public key as hs256 secret",
+			tokenString: sign(jwt.SigningMethodHS256, "rsa", pemBlock("PUBLIC KEY", publicKeyDER)),
+			wantErr:     true,
+		},
+		{name: "eddsa with kid of hs256 key", tokenString: sign(jwt.SigningMethodEdDSA, "hs", ed25519Key), wantErr: true},
+		{name: "rs256 with kid of eddsa key", tokenString: sign(jwt.SigningMethodRS256, "ed25519", testRSAKey), wantErr: true},
+		{name: "unknown kid", tokenString: sign(jwt.SigningMethodHS256, "other", secret), wantErr: true},
+		{name: "alg none", tokenString: sign(jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Verify(tt.tokenString, UseAccess, keys, IssuerParams{}); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- HMAC-SHA1 требуется RFC 6238 и поддерживается всеми приложениями-аутентификаторами
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Period - длительность одного шага TOTP.
+	Period = 30 * time.Second
+
+	// Digits - количество цифр в коде.
+	Digits = 6
+
+	// secretLength - длина секрета в байтах (160 бит, как рекомендует RFC 4226).
+	secretLength = 20
+
+	// skew - количество соседних шагов, коды которых тоже принимаются. Учитывает расхождение
+	// часов сервера и устройства пользователя и время на ввод кода.
+	skew = 1
+)
+
+// encoding - кодировка секрета, которую понимают приложения-аутентификаторы.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret возвращает случайный секрет в кодировке base32 без выравнивания.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "unable to generate totp secret")
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI возвращает URI "otpauth://totp/..." для добавления секрета в приложение-аутентификатор
+// (обычно показывается пользователю в виде QR-кода).
+//
+// Параметры:
+//   - issuer: название сервиса, которое показывает приложение.
+//   - account: имя учетной записи (email пользователя).
+//   - secret: секрет в кодировке base32.
+func ProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	provisioningURL := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return provisioningURL.String()
+}
+
+// Step возвращает номер шага TOTP для момента t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code возвращает код для момента t, который в этот момент показывает приложение-аутентификатор.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid totp secret")
+	}
+
+	return generate(key, Step(t)), nil
+}
+
+// Validate проверяет код для момента now с учетом соседних шагов.
+//
+// Возвращает:
+//   - int64: номер шага, которому соответствует код. Сохраняется, чтобы код нельзя было
+//     использовать повторно.
+//   - bool: true, если код верный.
+//   - error: ошибка, если секрет некорректный.
+func Validate(secret, code string, now time.Time) (int64, bool, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false, errors.Wrap(err, "invalid totp secret")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(generate(key, step)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// generate вычисляет код для шага step по алгоритму HOTP (RFC 4226).
+func generate(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
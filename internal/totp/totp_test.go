@@ -0,0 +1,132 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret - секрет "12345678901234567890" из RFC 6238 в кодировке base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// Тестовые значения из RFC 6238, приложение B (SHA1). В RFC коды из 8 цифр,
+	// код из 6 цифр - их последние 6 цифр
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			now := time.Unix(tt.unix, 0)
+
+			got, err := Code(rfcSecret, now)
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+
+			step, ok, err := Validate(rfcSecret, tt.want, now)
+			if err != nil || !ok {
+				t.Fatalf("Validate() = %t, %v, want true", ok, err)
+			}
+			if step != tt.unix/30 {
+				t.Errorf("Validate() step = %d, want %d", step, tt.unix/30)
+			}
+		})
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   bool
+	}{
+		{name: "current step", offset: 0, want: true},
+		{name: "previous step", offset: -Period, want: true},
+		{name: "next step", offset: Period, want: true},
+		{name: "two steps ago", offset: -2 * Period, want: false},
+		{name: "two steps ahead", offset: 2 * Period, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, now.Add(tt.offset))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+
+			step, ok, err := Validate(rfcSecret, code, now)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if ok != tt.want {
+				t.Fatalf("Validate() = %t, want %t", ok, tt.want)
+			}
+			if ok && step != Step(now.Add(tt.offset)) {
+				t.Errorf("Validate() step = %d, want %d", step, Step(now.Add(tt.offset)))
+			}
+		})
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name    string
+		secret  string
+		code    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "lowercase secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: "287082", want: true},
+		{name: "code with spaces", secret: rfcSecret, code: " 287082 ", want: true},
+		{name: "wrong code", secret: rfcSecret, code: "287083"},
+		{name: "short code", secret: rfcSecret, code: "28708"},
+		{name: "long code", secret: rfcSecret, code: "94287082"},
+		{name: "invalid secret", secret: "not base32!", code: "287082", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok, err := Validate(tt.secret, tt.code, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if ok != tt.want {
+				t.Errorf("Validate() = %t, want %t", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Auth", "alice@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Auth:alice@example.com" {
+		t.Errorf("ProvisioningURI() = %s", uri)
+	}
+	query := uri.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "Auth", "digits": "6", "period": "30", "algorithm": "SHA1"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
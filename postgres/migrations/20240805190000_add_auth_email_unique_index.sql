@@ -0,0 +1,5 @@
+-- +goose Up
+create unique index auth_email_unique_idx on auth (lower(email));
+
+-- +goose Down
+drop index auth_email_unique_idx;
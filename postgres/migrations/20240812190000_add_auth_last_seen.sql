@@ -0,0 +1,5 @@
+-- +goose Up
+alter table auth add column last_seen timestamp;
+
+-- +goose Down
+alter table auth drop column last_seen;
@@ -0,0 +1,5 @@
+-- +goose Up
+alter table auth add constraint auth_updated_at_check check (updated_at is null or updated_at >= created_at);
+
+-- +goose Down
+alter table auth drop constraint auth_updated_at_check;
@@ -0,0 +1,5 @@
+-- +goose Up
+alter table auth add column phone text;
+
+-- +goose Down
+alter table auth drop column phone;
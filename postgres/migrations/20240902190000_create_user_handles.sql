@@ -0,0 +1,9 @@
+-- +goose Up
+create table user_handles (
+    handle text primary key,
+    user_id int not null unique references auth (id) on delete cascade,
+    claimed_at timestamp not null default now()
+);
+
+-- +goose Down
+drop table user_handles;
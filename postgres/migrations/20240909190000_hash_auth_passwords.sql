@@ -0,0 +1,8 @@
+-- +goose Up
+create extension if not exists pgcrypto;
+update auth set password = crypt(password, gen_salt('bf', 10));
+alter table auth drop column password_confirm;
+
+-- +goose Down
+-- Исходные пароли не восстанавливаются: password остается bcrypt-хешем.
+alter table auth add column password_confirm text not null default '';
@@ -0,0 +1,9 @@
+-- +goose Up
+create table revoked_tokens (
+    jti text primary key,
+    expires_at timestamp not null
+);
+create index revoked_tokens_expires_at_idx on revoked_tokens (expires_at);
+
+-- +goose Down
+drop table revoked_tokens;
@@ -0,0 +1,15 @@
+-- +goose Up
+create table accessible_roles (
+    endpoint_address text not null,
+    role int not null,
+    primary key (endpoint_address, role)
+);
+
+insert into accessible_roles (endpoint_address, role) values
+    ('/user_v1.UserV1/DeleteUser', 2),
+    ('/user_v1.UserV1/ListEmailDomainStats', 2),
+    ('/user_v1.UserV1/GetSignupTimeSeries', 2),
+    ('/user_v1.UserV1/FindDuplicateCandidates', 2);
+
+-- +goose Down
+drop table accessible_roles;
@@ -0,0 +1,12 @@
+-- +goose Up
+create table password_reset_tokens (
+    token_hash text primary key,
+    user_id int not null references auth (id) on delete cascade,
+    expires_at timestamp not null,
+    used_at timestamp,
+    created_at timestamp not null default now()
+);
+create index password_reset_tokens_user_id_idx on password_reset_tokens (user_id);
+
+-- +goose Down
+drop table password_reset_tokens;
@@ -0,0 +1,16 @@
+-- +goose Up
+alter table auth add column email_verified boolean not null default false;
+-- Пользователи, зарегистрированные до появления подтверждения email, считаются подтвердившими его
+update auth set email_verified = true;
+
+create table email_verification_tokens (
+    token_hash text primary key,
+    user_id int not null references auth (id) on delete cascade,
+    expires_at timestamp not null,
+    created_at timestamp not null default now()
+);
+create index email_verification_tokens_user_id_idx on email_verification_tokens (user_id);
+
+-- +goose Down
+drop table email_verification_tokens;
+alter table auth drop column email_verified;
@@ -0,0 +1,11 @@
+-- +goose Up
+create table user_totp (
+    user_id int primary key references auth (id) on delete cascade,
+    encrypted_secret text not null,
+    confirmed_at timestamp,
+    last_used_step bigint not null default 0,
+    created_at timestamp not null default now()
+);
+
+-- +goose Down
+drop table user_totp;
@@ -0,0 +1,11 @@
+-- +goose Up
+create table recovery_codes (
+    user_id int not null references auth (id) on delete cascade,
+    code_hash text not null,
+    used_at timestamp,
+    created_at timestamp not null default now(),
+    primary key (user_id, code_hash)
+);
+
+-- +goose Down
+drop table recovery_codes;
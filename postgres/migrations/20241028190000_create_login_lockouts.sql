@@ -0,0 +1,17 @@
+-- +goose Up
+-- subject - "email:<email в нижнем регистре>" либо "ip:<IP-адрес>"
+create table login_failures (
+    subject text not null,
+    failed_at timestamp not null
+);
+create index login_failures_subject_failed_at_idx on login_failures (subject, failed_at);
+
+create table login_lockouts (
+    subject text primary key,
+    locked_until timestamp not null,
+    lockout_count int not null
+);
+
+-- +goose Down
+drop table login_lockouts;
+drop table login_failures;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Хранится только хеш ключа, сам ключ возвращается один раз при выпуске
+create table api_keys (
+    id bigserial primary key,
+    key_hash text not null unique,
+    service_account text not null,
+    role int not null,
+    created_at timestamp not null,
+    expires_at timestamp,
+    last_used_at timestamp,
+    revoked_at timestamp
+);
+
+-- +goose Down
+drop table api_keys;
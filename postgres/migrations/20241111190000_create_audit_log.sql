@@ -0,0 +1,18 @@
+-- +goose Up
+-- Журнал событий безопасности. Внешних ключей на auth нет: записи об удаленных
+-- пользователях должны сохраняться
+create table audit_log (
+    id bigserial primary key,
+    event_type text not null,
+    user_id bigint,
+    actor_id bigint,
+    client_ip text not null default '',
+    details jsonb not null default '{}',
+    created_at timestamp not null
+);
+
+create index audit_log_user_id_created_at_idx on audit_log (user_id, created_at);
+create index audit_log_created_at_idx on audit_log (created_at);
+
+-- +goose Down
+drop table audit_log;
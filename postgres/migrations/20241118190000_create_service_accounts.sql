@@ -0,0 +1,17 @@
+-- +goose Up
+-- Сервисные аккаунты получают access-токены по client_id и client_secret.
+-- Хранится только хеш секрета, сам секрет возвращается один раз при создании или замене
+create table service_accounts (
+    id bigserial primary key,
+    name text not null unique,
+    client_id text not null unique,
+    client_secret_hash text not null,
+    role int not null,
+    created_at timestamp not null,
+    secret_rotated_at timestamp,
+    last_token_at timestamp,
+    disabled_at timestamp
+);
+
+-- +goose Down
+drop table service_accounts;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Список пользователей содержит email и телефоны всех пользователей, поэтому доступен только администраторам
+insert into accessible_roles (endpoint_address, role) values
+    ('/user_v1.UserV1/ListUsers', 2)
+on conflict do nothing;
+
+-- +goose Down
+delete from accessible_roles where endpoint_address = '/user_v1.UserV1/ListUsers';
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Refresh-токены пользователя, выпущенные раньше tokens_valid_after, недействительны.
+-- Время устанавливается при смене и сбросе пароля; NULL - токены не отзывались
+alter table auth add column tokens_valid_after timestamptz;
+
+-- +goose Down
+alter table auth drop column tokens_valid_after;
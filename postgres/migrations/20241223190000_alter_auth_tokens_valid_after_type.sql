@@ -0,0 +1,6 @@
+-- +goose Up
+-- Время отзыва токенов хранится в UTC без часового пояса, как и остальные столбцы времени
+alter table auth alter column tokens_valid_after type timestamp using tokens_valid_after at time zone 'utc';
+
+-- +goose Down
+alter table auth alter column tokens_valid_after type timestamptz using tokens_valid_after at time zone 'utc';
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Статистика пользователей, как и остальная статистика, доступна только администраторам
+insert into accessible_roles (endpoint_address, role) values
+    ('/user_v1.UserV1/GetUserStats', 2)
+on conflict do nothing;
+
+-- +goose Down
+delete from accessible_roles where endpoint_address = '/user_v1.UserV1/GetUserStats';